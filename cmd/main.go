@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"runtime"
@@ -26,6 +27,7 @@ import (
 	"github.com/seventv/api/data/model"
 	"github.com/seventv/api/data/mutate"
 	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/admin"
 	"github.com/seventv/api/internal/api/eventbridge"
 	"github.com/seventv/api/internal/api/gql"
 	"github.com/seventv/api/internal/api/rest"
@@ -34,13 +36,23 @@ import (
 	"github.com/seventv/api/internal/loaders"
 	"github.com/seventv/api/internal/search"
 	"github.com/seventv/api/internal/svc/auth"
+	"github.com/seventv/api/internal/svc/cleanup"
+	"github.com/seventv/api/internal/svc/database"
+	"github.com/seventv/api/internal/svc/flags"
 	"github.com/seventv/api/internal/svc/health"
+	"github.com/seventv/api/internal/svc/lifecycle"
 	"github.com/seventv/api/internal/svc/limiter"
 	"github.com/seventv/api/internal/svc/monitoring"
+	"github.com/seventv/api/internal/svc/news"
+	"github.com/seventv/api/internal/svc/persisted"
 	"github.com/seventv/api/internal/svc/pprof"
 	"github.com/seventv/api/internal/svc/presences"
 	"github.com/seventv/api/internal/svc/prometheus"
+	"github.com/seventv/api/internal/svc/reload"
+	"github.com/seventv/api/internal/svc/status"
+	"github.com/seventv/api/internal/svc/tracing"
 	"github.com/seventv/api/internal/svc/youtube"
+	"github.com/seventv/api/internal/templates"
 )
 
 var (
@@ -89,6 +101,50 @@ func main() {
 
 	gctx, cancel := global.WithCancel(global.New(context.Background(), config))
 
+	gctx.Inst().Lifecycle = lifecycle.New()
+
+	// An admin command runs in place of the servers
+	var adminCmd *admin.Command
+
+	if len(config.Args) > 0 {
+		cmd, ok := admin.Lookup(config.Args[0])
+		if !ok {
+			fmt.Fprint(os.Stderr, admin.Usage())
+			os.Exit(2)
+		}
+
+		if cmd.Offline {
+			os.Exit(admin.Execute(gctx, cmd))
+		}
+
+		adminCmd = &cmd
+	}
+
+	// Metrics and tracing are set up first, so that the clients below can be instrumented
+	{
+		gctx.Inst().Prometheus = prometheus.New(prometheus.Options{
+			Labels: config.Monitoring.Labels.ToPrometheus(),
+		})
+	}
+
+	shutdownTracing := func(ctx context.Context) error { return nil }
+
+	if config.Tracing.Enabled {
+		shutdownTracing, err = tracing.Setup(gctx, tracing.Options{
+			Endpoint:       config.Tracing.Endpoint,
+			Headers:        config.Tracing.Headers,
+			SampleRate:     config.Tracing.SampleRate,
+			ServiceName:    "api",
+			ServiceVersion: Version,
+			InstanceID:     config.K8S.PodName,
+		})
+		if err != nil {
+			zap.S().Fatalw("failed to setup tracing",
+				"error", err,
+			)
+		}
+	}
+
 	{
 		gctx.Inst().Redis, err = redis.Setup(gctx, redis.SetupOptions{
 			Username:   config.Redis.Username,
@@ -104,20 +160,33 @@ func main() {
 				"error", err,
 			)
 		}
+
+		gctx.Inst().Redis.RawClient().AddHook(gctx.Inst().Prometheus.RedisHook())
+		gctx.Inst().Redis.RawClient().AddHook(tracing.RedisHook())
 	}
 
 	// INITIALIZE MEILISEARCH
 	gctx.Inst().Meilisearch = search.New(gctx.Config())
 
+	// Filters and sorts added to the emote index are usable once its settings are updated
+	go func() {
+		ctx, cancel := context.WithTimeout(gctx, time.Minute*5)
+		defer cancel()
+
+		if err := gctx.Inst().Meilisearch.ConfigureEmoteIndex(ctx); err != nil {
+			zap.S().Warnw("failed to configure emote search index", "error", err)
+		}
+	}()
+
 	{
-		gctx.Inst().Mongo, err = mongo.Setup(gctx, mongo.SetupOptions{
+		gctx.Inst().Mongo, err = database.SetupMongo(gctx, mongo.SetupOptions{
 			URI:         config.Mongo.URI,
 			DB:          config.Mongo.DB,
 			Direct:      config.Mongo.Direct,
 			Username:    config.Mongo.Username,
 			Password:    config.Mongo.Password,
 			HedgedReads: config.Mongo.HedgedReads,
-		})
+		}, database.Monitors(gctx.Inst().Prometheus.MongoMonitor(), tracing.MongoMonitor()))
 		if err != nil {
 			zap.S().Fatalw("failed to setup mongo handler",
 				"error", err,
@@ -131,6 +200,8 @@ func main() {
 					"error", err,
 				)
 			}
+
+			query.EnsureIndexes(gctx, gctx.Inst().Mongo)
 		}()
 	}
 
@@ -157,6 +228,8 @@ func main() {
 			zap.S().Errorw("failed to setup mq handler",
 				"error", err,
 			)
+		} else if gctx.Inst().MessageQueue != nil {
+			gctx.Inst().MessageQueue = tracing.MessageQueue(gctx.Inst().MessageQueue)
 		}
 	}
 
@@ -185,12 +258,6 @@ func main() {
 		}
 	}
 
-	{
-		gctx.Inst().Prometheus = prometheus.New(prometheus.Options{
-			Labels: config.Monitoring.Labels.ToPrometheus(),
-		})
-	}
-
 	nc, err := nats.Connect(config.Nats.Url)
 	if err != nil {
 		zap.S().Errorw("failed to connect to nats",
@@ -207,7 +274,27 @@ func main() {
 		)
 	}()
 
-	gctx.Inst().Events = events.NewPublisher(nc, config.Nats.Subject)
+	gctx.Inst().Events = events.NewPublisher(nc, config.Nats.Subject, gctx.Inst().Prometheus)
+
+	var s3Status *status.S3Options
+	if gctx.Inst().S3 != nil {
+		s3Status = &status.S3Options{
+			Region:      config.S3.Region,
+			Endpoint:    config.S3.Endpoint,
+			AccessToken: config.S3.AccessToken,
+			SecretKey:   config.S3.SecretKey,
+			Bucket:      config.S3.InternalBucket,
+		}
+	}
+
+	gctx.Inst().Status = status.New(status.Options{
+		Mongo:        gctx.Inst().Mongo,
+		Redis:        gctx.Inst().Redis,
+		Nats:         nc,
+		MessageQueue: gctx.Inst().MessageQueue,
+		S3:           s3Status,
+		Meilisearch:  gctx.Inst().Meilisearch,
+	})
 
 	{
 		id := svc.AppIdentity{
@@ -216,37 +303,52 @@ func main() {
 			CDN:  config.CdnURL,
 		}
 
-		gctx.Inst().Limiter, err = limiter.New(gctx, gctx.Inst().Redis)
+		gctx.Inst().Limiter, err = limiter.New(gctx, gctx.Inst().Redis, gctx.Inst().Prometheus)
 		if err != nil {
 			zap.S().Fatalw("failed to setup rate limiter", "error", err)
 		}
 
+		gctx.Inst().Persisted, err = persisted.New(gctx, gctx.Inst().Redis, persisted.Options{
+			Files: config.Limits.PersistedQueries.Manifests,
+		})
+		if err != nil {
+			zap.S().Fatalw("failed to setup persisted query registry", "error", err)
+		}
+
 		gctx.Inst().CD = compactdisc.New(config.Platforms.Discord.API)
 
+		gctx.Inst().Templates, err = templates.New()
+		if err != nil {
+			zap.S().Fatalw("failed to load templates", "error", err)
+		}
+
 		gctx.Inst().Modelizer = model.NewInstance(model.ModelInstanceOptions{
 			CDN:     config.CdnURL,
 			Website: config.WebsiteURL,
 		})
 		gctx.Inst().Query = query.New(gctx.Inst().Mongo, gctx.Inst().Redis, gctx.Inst().Meilisearch)
-		gctx.Inst().Loaders = loaders.New(gctx, gctx.Inst().Mongo, gctx.Inst().Redis, gctx.Inst().Query)
+		gctx.Inst().Loaders = loaders.New(gctx, gctx.Inst().Mongo, gctx.Inst().Redis, gctx.Inst().Query, gctx.Inst().Prometheus)
+		gctx.Inst().Flags = flags.New(gctx, gctx.Inst().Query)
 
 		gctx.Inst().Mutate = mutate.New(mutate.InstanceOptions{
-			ID:        id,
-			Mongo:     gctx.Inst().Mongo,
-			Loaders:   gctx.Inst().Loaders,
-			Redis:     gctx.Inst().Redis,
-			S3:        gctx.Inst().S3,
-			Modelizer: gctx.Inst().Modelizer,
-			Events:    gctx.Inst().Events,
-			CD:        gctx.Inst().CD,
+			ID:         id,
+			Mongo:      gctx.Inst().Mongo,
+			Loaders:    gctx.Inst().Loaders,
+			Redis:      gctx.Inst().Redis,
+			S3:         gctx.Inst().S3,
+			Modelizer:  gctx.Inst().Modelizer,
+			Events:     gctx.Inst().Events,
+			CD:         gctx.Inst().CD,
+			Prometheus: gctx.Inst().Prometheus,
 		})
 
 		gctx.Inst().Presences = presences.New(presences.Options{
-			Mongo:     gctx.Inst().Mongo,
-			Loaders:   gctx.Inst().Loaders,
-			Events:    gctx.Inst().Events,
-			Config:    config,
-			Modelizer: gctx.Inst().Modelizer,
+			Mongo:      gctx.Inst().Mongo,
+			Loaders:    gctx.Inst().Loaders,
+			Events:     gctx.Inst().Events,
+			Config:     config,
+			Modelizer:  gctx.Inst().Modelizer,
+			Prometheus: gctx.Inst().Prometheus,
 		})
 	}
 
@@ -261,6 +363,20 @@ func main() {
 		}
 	}
 
+	if adminCmd != nil {
+		code := admin.Execute(gctx, *adminCmd)
+
+		cancel()
+		os.Exit(code)
+	}
+
+	// Limits, quotas and client downloads are reloaded when the config file changes
+	if err := reload.New(gctx); err != nil {
+		zap.S().Warnw("failed to watch the config file, changes will only apply after a restart",
+			"error", err,
+		)
+	}
+
 	wg := sync.WaitGroup{}
 
 	if gctx.Config().Health.Enabled {
@@ -299,22 +415,83 @@ func main() {
 		}()
 	}
 
+	if gctx.Config().ModRequests.Cleanup.Enabled {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			<-cleanup.New(gctx)
+		}()
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		<-news.New(gctx)
+	}()
+
 	done := make(chan struct{})
 
+	// The servers return once the requests in flight have finished
+	servers := sync.WaitGroup{}
+	servers.Add(2)
+
 	go func() {
 		<-sig
-		cancel()
+
+		timeout := time.Minute
+		if config.Shutdown.Timeout > 0 {
+			timeout = time.Duration(config.Shutdown.Timeout) * time.Second
+		}
 
 		go func() {
 			select {
-			case <-time.After(time.Minute):
+			case <-time.After(timeout):
 			case <-sig:
 			}
 			zap.S().Fatal("force shutdown")
 		}()
 
+		// Report as not ready and stop consuming, while still serving requests until load balancers have caught up
+		drainPeriod := time.Duration(config.Shutdown.DrainPeriod) * time.Second
+
+		zap.S().Infow("draining",
+			"drain_period", drainPeriod,
+		)
+
+		gctx.Inst().Lifecycle.Drain()
+
+		<-time.After(drainPeriod)
+
+		// Stop the servers, which finish the requests in flight, and wait for the messages being handled.
+		// Requests are served with the global context, so it is only canceled once the servers have stopped
 		zap.S().Info("shutting down")
 
+		sctx, scancel := context.WithTimeout(context.Background(), timeout)
+
+		gctx.Inst().Lifecycle.Stop()
+
+		if err := gctx.Inst().Lifecycle.Wait(sctx); err != nil {
+			zap.S().Warnw("background work did not complete", "error", err)
+		}
+
+		serversDone := make(chan struct{})
+
+		go func() {
+			servers.Wait()
+			close(serversDone)
+		}()
+
+		select {
+		case <-serversDone:
+		case <-sctx.Done():
+			zap.S().Warnw("requests in flight did not complete", "error", sctx.Err())
+		}
+
+		scancel()
+		cancel()
+
 		wg.Wait()
 
 		close(done)
@@ -324,6 +501,7 @@ func main() {
 
 	go func() {
 		defer wg.Done()
+		defer servers.Done()
 
 		if err := rest.New(gctx); err != nil {
 			zap.S().Fatalw("rest failed",
@@ -334,6 +512,7 @@ func main() {
 
 	go func() {
 		defer wg.Done()
+		defer servers.Done()
 
 		if err := gql.New(gctx); err != nil {
 			zap.S().Fatalw("gql failed",
@@ -346,6 +525,14 @@ func main() {
 
 	<-done
 
+	// Flush the spans which have not been exported yet
+	tctx, tcancel := context.WithTimeout(context.Background(), time.Second*5)
+	if err := shutdownTracing(tctx); err != nil {
+		zap.S().Warnw("failed to flush traces", "error", err)
+	}
+
+	tcancel()
+
 	zap.S().Info("shutdown")
 	os.Exit(0)
 }
@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"hash/crc32"
 	"time"
@@ -8,25 +9,33 @@ import (
 	"github.com/nats-io/nats.go"
 	"github.com/seventv/common/structures/v3"
 	"github.com/seventv/common/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
 	"github.com/seventv/api/data/model"
+	"github.com/seventv/api/internal/svc/prometheus"
+	"github.com/seventv/api/internal/svc/tracing"
 )
 
 type Instance interface {
-	Dispatch(t EventType, cm ChangeMap, cond ...EventCondition)
-	DispatchWithEffect(t EventType, cm ChangeMap, opt DispatchOptions, cond ...EventCondition) Message[DispatchPayload]
+	Dispatch(ctx context.Context, t EventType, cm ChangeMap, cond ...EventCondition)
+	DispatchWithEffect(ctx context.Context, t EventType, cm ChangeMap, opt DispatchOptions, cond ...EventCondition) Message[DispatchPayload]
 }
 
 type EventsInst struct {
 	nc      *nats.Conn
 	subject string
+	prom    prometheus.Instance
 }
 
-func NewPublisher(nc *nats.Conn, subject string) Instance {
+func NewPublisher(nc *nats.Conn, subject string, prom prometheus.Instance) Instance {
 	return &EventsInst{
 		nc:      nc,
 		subject: subject,
+		prom:    prom,
 	}
 }
 
@@ -38,7 +47,7 @@ var systemUser = model.UserModel{
 	DisplayName: structures.SystemUser.DisplayName,
 }
 
-func (inst *EventsInst) Dispatch(t EventType, cm ChangeMap, cond ...EventCondition) {
+func (inst *EventsInst) Dispatch(ctx context.Context, t EventType, cm ChangeMap, cond ...EventCondition) {
 	if cm.Actor.ID.IsZero() {
 		cm.Actor = systemUser.ToPartial()
 	}
@@ -73,16 +82,22 @@ func (inst *EventsInst) Dispatch(t EventType, cm ChangeMap, cond ...EventConditi
 		return
 	}
 
+	inst.prom.EventDispatched(string(t))
+
+	ctx, span := startDispatch(ctx, t, len(cond))
+
 	for _, c := range cond {
 		// TODO: check if it's necesary to publish for both bools
-		err = inst.nc.Publish(inst.subject+"."+CreateDispatchKey(t, c), data)
+		err = inst.publish(ctx, inst.subject+"."+CreateDispatchKey(t, c), data)
 		if err != nil {
 			zap.S().Errorw("nats publish", "error", err)
 		}
 	}
+
+	tracing.End(span, err)
 }
 
-func (inst *EventsInst) DispatchWithEffect(t EventType, cm ChangeMap, opt DispatchOptions, cond ...EventCondition) Message[DispatchPayload] {
+func (inst *EventsInst) DispatchWithEffect(ctx context.Context, t EventType, cm ChangeMap, opt DispatchOptions, cond ...EventCondition) Message[DispatchPayload] {
 	if cm.Actor.ID.IsZero() {
 		cm.Actor = systemUser.ToPartial()
 	}
@@ -118,6 +133,8 @@ func (inst *EventsInst) DispatchWithEffect(t EventType, cm ChangeMap, opt Dispat
 		return msg
 	}
 
+	inst.prom.EventDispatched(string(t))
+
 	payloads := make(map[string][]byte)
 
 	if opt.Whisper == "" {
@@ -128,22 +145,55 @@ func (inst *EventsInst) DispatchWithEffect(t EventType, cm ChangeMap, opt Dispat
 		payloads[CreateDispatchKey(EventTypeWhisper, EventCondition{"session_id": opt.Whisper})] = data
 	}
 
+	// The request's context may be reused once it was served, so only its span is kept
+	ctx = tracing.Detach(ctx)
+
 	go func() {
 		if opt.Delay > 0 {
 			<-time.After(opt.Delay)
 		}
 
+		ctx, span := startDispatch(ctx, t, len(payloads))
+
+		var err error
+
 		for key, data := range payloads {
-			err = inst.nc.Publish(inst.subject+"."+key, data)
+			err = inst.publish(ctx, inst.subject+"."+key, data)
 			if err != nil {
 				zap.S().Errorw("nats publish", "error", err)
 			}
 		}
+
+		tracing.End(span, err)
 	}()
 
 	return msg
 }
 
+// startDispatch starts the span of a dispatch, child of the span of the request which caused it.
+// Dispatches may be delayed past the end of the request, so they are not cancelled with it
+func startDispatch(ctx context.Context, t EventType, subjects int) (context.Context, trace.Span) {
+	return tracing.Start(tracing.Detach(ctx), "dispatch "+string(t),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("event.type", string(t)),
+			attribute.Int("event.subjects", subjects),
+		),
+	)
+}
+
+// publish sends a message over NATS, passing on the trace context in its headers
+func (inst *EventsInst) publish(ctx context.Context, subject string, data []byte) error {
+	msg := nats.NewMsg(subject)
+	msg.Data = data
+
+	if trace.SpanContextFromContext(ctx).IsSampled() {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(msg.Header))
+	}
+
+	return inst.nc.PublishMsg(msg)
+}
+
 type DispatchOptions struct {
 	Delay         time.Duration
 	Whisper       string
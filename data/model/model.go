@@ -21,6 +21,7 @@ type Modelizer interface {
 	Badge(v structures.Cosmetic[structures.CosmeticDataBadge]) CosmeticBadgeModel
 	Avatar(v structures.User) CosmeticModel[CosmeticAvatarModel]
 	EmoteSet(v structures.EmoteSet) EmoteSetModel
+	EmoteSetOrigin(v structures.EmoteSetOrigin) EmoteSetOrigin
 	ActiveEmote(v structures.ActiveEmote) ActiveEmoteModel
 	Role(v structures.Role) RoleModel
 	InboxMessage(v structures.Message[structures.MessageDataInbox]) InboxMessageModel
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/structures/v3"
@@ -99,6 +100,7 @@ func (m *Mutate) CreateBan(ctx context.Context, bb *structures.BanBuilder, opt C
 		Actor:                actor,
 		Recipients:           []primitive.ObjectID{victim.ID},
 		ConsiderBlockedUsers: false,
+		Category:             query.NotificationCategoryBans,
 	}); err != nil {
 		zap.S().Errorw("failed to send inbox message to victim about created ban",
 			"error", err,
@@ -18,6 +18,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
 )
 
 const EMOTE_CLAIMANTS_MOST = 10
@@ -151,6 +152,7 @@ func (m *Mutate) EditEmote(ctx context.Context, eb *structures.EmoteBuilder, opt
 						Actor:                &actor,
 						Recipients:           []primitive.ObjectID{emote.OwnerID},
 						ConsiderBlockedUsers: true,
+						Category:             query.NotificationCategoryEmoteOwnership,
 					}); err != nil {
 						return err
 					}
@@ -369,7 +371,7 @@ func (m *Mutate) EditEmote(ctx context.Context, eb *structures.EmoteBuilder, opt
 			for _, ver := range emote.Versions {
 				go func(ver structures.EmoteVersion) {
 					// Emit to the Event API
-					m.events.Dispatch(events.EventTypeUpdateEmote, events.ChangeMap{
+					m.events.Dispatch(ctx, events.EventTypeUpdateEmote, events.ChangeMap{
 						ID:      ver.ID,
 						Kind:    structures.ObjectKindEmote,
 						Actor:   m.modelizer.User(actor).ToPartial(),
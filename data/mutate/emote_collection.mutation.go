@@ -0,0 +1,373 @@
+package mutate
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const (
+	EMOTE_COLLECTION_MOST_COUNT        = 25
+	EMOTE_COLLECTION_CAPACITY          = 1000
+	EMOTE_COLLECTION_NAME_LIMIT        = 40
+	EMOTE_COLLECTION_DESCRIPTION_LIMIT = 300
+)
+
+type EmoteCollectionOptions struct {
+	Actor       structures.User
+	Name        *string
+	Description *string
+	Private     *bool
+}
+
+// CreateEmoteCollection creates a collection owned by the actor
+func (m *Mutate) CreateEmoteCollection(ctx context.Context, opt EmoteCollectionOptions) (query.EmoteCollection, error) {
+	actor := opt.Actor
+	col := query.EmoteCollection{}
+
+	if actor.ID.IsZero() {
+		return col, errors.ErrUnauthorized()
+	}
+
+	count, err := m.mongo.Collection(query.CollectionNameEmoteCollections).CountDocuments(ctx, bson.M{
+		"owner_id": actor.ID,
+		"kind":     query.EmoteCollectionKindCustom,
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to count emote collections", "error", err)
+
+		return col, errors.ErrInternalServerError()
+	}
+
+	if count >= EMOTE_COLLECTION_MOST_COUNT {
+		return col, errors.ErrInvalidRequest().SetDetail("You have reached the maximum amount of collections allowed (%d)", EMOTE_COLLECTION_MOST_COUNT)
+	}
+
+	now := time.Now()
+	col = query.EmoteCollection{
+		ID:        primitive.NewObjectIDFromTimestamp(now),
+		OwnerID:   actor.ID,
+		Kind:      query.EmoteCollectionKindCustom,
+		Emotes:    []query.EmoteCollectionItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if opt.Name == nil {
+		return col, errors.ErrMissingRequiredField().SetDetail("Name")
+	}
+
+	if err := applyEmoteCollectionOptions(&col, opt); err != nil {
+		return col, err
+	}
+
+	if _, err := m.mongo.Collection(query.CollectionNameEmoteCollections).InsertOne(ctx, col); err != nil {
+		zap.S().Errorw("mongo, failed to create emote collection", "error", err)
+
+		return col, errors.ErrInternalServerError()
+	}
+
+	return col, nil
+}
+
+// EditEmoteCollection updates the name, description or privacy of a collection.
+// The favorites collection may only change its privacy
+func (m *Mutate) EditEmoteCollection(ctx context.Context, id primitive.ObjectID, opt EmoteCollectionOptions) (query.EmoteCollection, error) {
+	col, err := m.fetchOwnEmoteCollection(ctx, opt.Actor, id)
+	if err != nil {
+		return col, err
+	}
+
+	if col.Kind == query.EmoteCollectionKindFavorites && (opt.Name != nil || opt.Description != nil) {
+		return col, errors.ErrInvalidRequest().SetDetail("The favorites collection cannot be renamed")
+	}
+
+	if err := applyEmoteCollectionOptions(&col, opt); err != nil {
+		return col, err
+	}
+
+	col.UpdatedAt = time.Now()
+
+	if _, err := m.mongo.Collection(query.CollectionNameEmoteCollections).UpdateOne(ctx, bson.M{
+		"_id": col.ID,
+	}, bson.M{
+		"$set": bson.M{
+			"name":        col.Name,
+			"description": col.Description,
+			"private":     col.Private,
+			"updated_at":  col.UpdatedAt,
+		},
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to edit emote collection", "error", err)
+
+		return col, errors.ErrInternalServerError()
+	}
+
+	return col, nil
+}
+
+// DeleteEmoteCollection removes a collection along with its follows
+func (m *Mutate) DeleteEmoteCollection(ctx context.Context, actor structures.User, id primitive.ObjectID) error {
+	col, err := m.fetchOwnEmoteCollection(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := m.mongo.Collection(query.CollectionNameEmoteCollections).DeleteOne(ctx, bson.M{"_id": col.ID}); err != nil {
+		zap.S().Errorw("mongo, failed to delete emote collection", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if _, err := m.mongo.Collection(query.CollectionNameEmoteCollectionFollows).DeleteMany(ctx, bson.M{"collection_id": col.ID}); err != nil {
+		zap.S().Errorw("mongo, failed to delete emote collection follows", "error", err)
+	}
+
+	return nil
+}
+
+// EditEmotesInCollection adds or removes an emote from a collection
+func (m *Mutate) EditEmotesInCollection(ctx context.Context, actor structures.User, id primitive.ObjectID, emoteID primitive.ObjectID, action structures.ListItemAction) (query.EmoteCollection, error) {
+	col, err := m.fetchOwnEmoteCollection(ctx, actor, id)
+	if err != nil {
+		return col, err
+	}
+
+	return m.editEmotesInCollection(ctx, col.ID, emoteID, action)
+}
+
+// FavoriteEmote adds or removes an emote from the actor's favorites collection
+func (m *Mutate) FavoriteEmote(ctx context.Context, actor structures.User, emoteID primitive.ObjectID, favorite bool) (query.EmoteCollection, error) {
+	col := query.EmoteCollection{}
+
+	if actor.ID.IsZero() {
+		return col, errors.ErrUnauthorized()
+	}
+
+	// Create the favorites collection on first use
+	now := time.Now()
+
+	if err := m.mongo.Collection(query.CollectionNameEmoteCollections).FindOneAndUpdate(ctx, bson.M{
+		"owner_id": actor.ID,
+		"kind":     query.EmoteCollectionKindFavorites,
+	}, bson.M{
+		"$setOnInsert": bson.M{
+			"_id":            primitive.NewObjectIDFromTimestamp(now),
+			"name":           "Favorites",
+			"description":    "",
+			"private":        true,
+			"emotes":         []query.EmoteCollectionItem{},
+			"follower_count": 0,
+			"created_at":     now,
+			"updated_at":     now,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&col); err != nil {
+		zap.S().Errorw("mongo, failed to find favorites collection", "error", err)
+
+		return col, errors.ErrInternalServerError()
+	}
+
+	action := structures.ListItemActionAdd
+	if !favorite {
+		action = structures.ListItemActionRemove
+	}
+
+	return m.editEmotesInCollection(ctx, col.ID, emoteID, action)
+}
+
+// FollowEmoteCollection makes the actor follow or unfollow the collection of another user
+func (m *Mutate) FollowEmoteCollection(ctx context.Context, actor structures.User, id primitive.ObjectID, follow bool) error {
+	if actor.ID.IsZero() {
+		return errors.ErrUnauthorized()
+	}
+
+	col := query.EmoteCollection{}
+	if err := m.mongo.Collection(query.CollectionNameEmoteCollections).FindOne(ctx, bson.M{"_id": id}).Decode(&col); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrNoItems().SetDetail("Unknown Collection")
+		}
+
+		return errors.ErrInternalServerError()
+	}
+
+	if col.OwnerID == actor.ID {
+		return errors.ErrInvalidRequest().SetDetail("You cannot follow your own collection")
+	}
+
+	var (
+		changed bool
+		inc     int32
+	)
+
+	if follow {
+		if col.Private {
+			return errors.ErrNoItems().SetDetail("Unknown Collection")
+		}
+
+		res, err := m.mongo.Collection(query.CollectionNameEmoteCollectionFollows).UpdateOne(ctx, bson.M{
+			"collection_id": col.ID,
+			"user_id":       actor.ID,
+		}, bson.M{
+			"$setOnInsert": bson.M{
+				"_id":         primitive.NewObjectID(),
+				"followed_at": time.Now(),
+			},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			zap.S().Errorw("mongo, failed to follow emote collection", "error", err)
+
+			return errors.ErrInternalServerError()
+		}
+
+		changed, inc = res.UpsertedCount > 0, 1
+	} else {
+		res, err := m.mongo.Collection(query.CollectionNameEmoteCollectionFollows).DeleteOne(ctx, bson.M{
+			"collection_id": col.ID,
+			"user_id":       actor.ID,
+		})
+		if err != nil {
+			zap.S().Errorw("mongo, failed to unfollow emote collection", "error", err)
+
+			return errors.ErrInternalServerError()
+		}
+
+		changed, inc = res.DeletedCount > 0, -1
+	}
+
+	if changed {
+		if _, err := m.mongo.Collection(query.CollectionNameEmoteCollections).UpdateOne(ctx, bson.M{
+			"_id": col.ID,
+		}, bson.M{
+			"$inc": bson.M{"follower_count": inc},
+		}); err != nil {
+			zap.S().Errorw("mongo, failed to update emote collection follower count", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// fetchOwnEmoteCollection returns a collection which the actor is allowed to modify
+func (m *Mutate) fetchOwnEmoteCollection(ctx context.Context, actor structures.User, id primitive.ObjectID) (query.EmoteCollection, error) {
+	col := query.EmoteCollection{}
+
+	if actor.ID.IsZero() {
+		return col, errors.ErrUnauthorized()
+	}
+
+	if err := m.mongo.Collection(query.CollectionNameEmoteCollections).FindOne(ctx, bson.M{"_id": id}).Decode(&col); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return col, errors.ErrNoItems().SetDetail("Unknown Collection")
+		}
+
+		zap.S().Errorw("mongo, failed to query emote collection", "error", err)
+
+		return col, errors.ErrInternalServerError()
+	}
+
+	if col.OwnerID != actor.ID && !actor.HasPermission(structures.RolePermissionManageUsers) {
+		if col.Private {
+			return query.EmoteCollection{}, errors.ErrNoItems().SetDetail("Unknown Collection")
+		}
+
+		return col, errors.ErrInsufficientPrivilege()
+	}
+
+	return col, nil
+}
+
+func (m *Mutate) editEmotesInCollection(ctx context.Context, id primitive.ObjectID, emoteID primitive.ObjectID, action structures.ListItemAction) (query.EmoteCollection, error) {
+	col := query.EmoteCollection{}
+	now := time.Now()
+
+	var (
+		filter = bson.M{"_id": id}
+		update bson.M
+	)
+
+	switch action {
+	case structures.ListItemActionAdd:
+		if err := m.mongo.Collection(mongo.CollectionNameEmotes).FindOne(ctx, bson.M{"_id": emoteID}).Err(); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return col, errors.ErrUnknownEmote()
+			}
+
+			return col, errors.ErrInternalServerError()
+		}
+
+		// Only add the emote if it isn't in the collection already, and the collection has room for it
+		filter["emotes.id"] = bson.M{"$ne": emoteID}
+		filter["emotes."+strconv.Itoa(EMOTE_COLLECTION_CAPACITY-1)] = bson.M{"$exists": false}
+		update = bson.M{
+			"$push": bson.M{"emotes": query.EmoteCollectionItem{ID: emoteID, AddedAt: now}},
+			"$set":  bson.M{"updated_at": now},
+		}
+	case structures.ListItemActionRemove:
+		update = bson.M{
+			"$pull": bson.M{"emotes": bson.M{"id": emoteID}},
+			"$set":  bson.M{"updated_at": now},
+		}
+	default:
+		return col, errors.ErrInvalidRequest().SetDetail("Emotes can only be added to or removed from a collection")
+	}
+
+	if err := m.mongo.Collection(query.CollectionNameEmoteCollections).FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&col); err != nil {
+		if err != mongo.ErrNoDocuments {
+			zap.S().Errorw("mongo, failed to edit emotes in collection", "error", err)
+
+			return col, errors.ErrInternalServerError()
+		}
+
+		// The emote is already in the collection, or the collection is full
+		if err := m.mongo.Collection(query.CollectionNameEmoteCollections).FindOne(ctx, bson.M{"_id": id}).Decode(&col); err != nil {
+			return col, errors.ErrInternalServerError()
+		}
+
+		if len(col.Emotes) >= EMOTE_COLLECTION_CAPACITY {
+			return col, errors.ErrInvalidRequest().SetDetail("This collection is full (%d emotes max)", EMOTE_COLLECTION_CAPACITY)
+		}
+	}
+
+	return col, nil
+}
+
+func applyEmoteCollectionOptions(col *query.EmoteCollection, opt EmoteCollectionOptions) error {
+	if opt.Name != nil {
+		name := *opt.Name
+
+		if name == "" {
+			return errors.ErrInvalidRequest().SetDetail("Name is required")
+		}
+
+		if len(name) > EMOTE_COLLECTION_NAME_LIMIT {
+			return errors.ErrInvalidRequest().SetDetail("Name is too long (%d characters max)", EMOTE_COLLECTION_NAME_LIMIT)
+		}
+
+		col.Name = name
+	}
+
+	if opt.Description != nil {
+		if len(*opt.Description) > EMOTE_COLLECTION_DESCRIPTION_LIMIT {
+			return errors.ErrInvalidRequest().SetDetail("Description is too long (%d characters max)", EMOTE_COLLECTION_DESCRIPTION_LIMIT)
+		}
+
+		col.Description = *opt.Description
+	}
+
+	if opt.Private != nil {
+		col.Private = *opt.Private
+	}
+
+	return nil
+}
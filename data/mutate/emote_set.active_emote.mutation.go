@@ -17,6 +17,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
 )
 
 const (
@@ -32,6 +33,32 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 		return errors.ErrMutateTaintedObject()
 	}
 
+	if opt.Collection != nil {
+		if !opt.Collection.VisibleTo(&opt.Actor) {
+			return errors.ErrNoItems().SetDetail("Unknown Collection")
+		}
+
+		if len(opt.Collection.Emotes) == 0 {
+			return errors.ErrInvalidRequest().SetDetail("This collection is empty")
+		}
+
+		if esb.EmoteSet.Flags.Has(structures.EmoteSetFlagPersonal) {
+			return errors.ErrInvalidRequest().SetDetail("Collections cannot be added to a personal emote set")
+		}
+
+		// The emotes of the collection are added under their default name
+		items := make([]EmoteSetMutationSetEmoteItem, 0, len(opt.Collection.Emotes)+len(opt.Emotes))
+		for _, item := range opt.Collection.Emotes {
+			items = append(items, EmoteSetMutationSetEmoteItem{
+				Action:         structures.ListItemActionAdd,
+				ID:             item.ID,
+				fromCollection: true,
+			})
+		}
+
+		opt.Emotes = append(items, opt.Emotes...)
+	}
+
 	if len(opt.Emotes) == 0 {
 		return errors.ErrMissingRequiredField().SetDetail("EmoteIDs")
 	}
@@ -104,7 +131,7 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 		}
 
 		// Fetch set owner
-		owner, err := m.loaders.UserByID().Load(set.OwnerID)
+		owner, err := m.loaders.UserByID().Load(ctx, set.OwnerID)
 		if err == nil {
 			set.Owner = &owner
 		}
@@ -130,6 +157,13 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 		}
 	}
 
+	// Emotes of a collection which don't fit in the set are skipped rather than failing the edit
+	if opt.Collection != nil {
+		if fitCollectionEmotes(actor, set, targetEmoteMap, *opt.Collection) == 0 {
+			return errors.ErrInvalidRequest().SetDetail("None of the emotes in this collection can be added to this set")
+		}
+	}
+
 	// Make a map of active set emotes
 	activeEmotes := map[primitive.ObjectID]*structures.Emote{}
 	for _, e := range set.Emotes {
@@ -137,10 +171,13 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 	}
 
 	// Set up audit log entry
+	//
+	// The changes are gathered and written at once, so that an edit of several emotes records all of them
 	c := &structures.AuditLogChange{
 		Format: structures.AuditLogChangeFormatArrayChange,
 		Key:    "emotes",
 	}
+	ac := structures.AuditLogChangeArrayChange{}
 	log := structures.NewAuditLogBuilder(structures.AuditLog{}).
 		SetKind(structures.AuditLogKindUpdateEmoteSet).
 		SetActor(actor.ID).
@@ -368,7 +405,7 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 			// Add active emote
 			at := time.Now()
 			esb.AddActiveEmote(tgt.ID, tgt.Name, at, &actor.ID)
-			c.WriteArrayAdded(structures.ActiveEmote{
+			ac.Added = append(ac.Added, structures.ActiveEmote{
 				ID:        tgt.ID,
 				Name:      tgt.Name,
 				Flags:     tgt.Flags,
@@ -377,7 +414,7 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 			})
 
 			// Publish a message to the Event API
-			m.events.Dispatch(events.EventTypeUpdateEmoteSet, events.ChangeMap{
+			m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
 				ID:    esb.EmoteSet.ID,
 				Kind:  structures.ObjectKindEmoteSet,
 				Actor: m.modelizer.User(actor).ToPartial(),
@@ -445,7 +482,7 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 
 			if tgt.Action == structures.ListItemActionUpdate {
 				// Modify active emote
-				c.WriteArrayUpdated(structures.AuditLogChangeSingleValue{
+				ac.Updated = append(ac.Updated, structures.AuditLogChangeSingleValue{
 					New: structures.ActiveEmote{
 						ID:              tgt.ID,
 						Name:            tgt.Name,
@@ -464,7 +501,7 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 				})
 				esb.UpdateActiveEmote(tgt.ID, tgt.Name)
 
-				m.events.Dispatch(events.EventTypeUpdateEmoteSet, events.ChangeMap{
+				m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
 					ID:    esb.EmoteSet.ID,
 					Kind:  structures.ObjectKindEmoteSet,
 					Actor: m.modelizer.User(actor).ToPartial(),
@@ -494,11 +531,12 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 			} else if tgt.Action == structures.ListItemActionRemove {
 				// Remove active emote
 				_, ind := esb.RemoveActiveEmote(tgt.ID)
-				c.WriteArrayRemoved(structures.ActiveEmote{
-					ID: tgt.ID,
+				ac.Removed = append(ac.Removed, structures.ActiveEmote{
+					ID:   tgt.ID,
+					Name: ae.Name,
 				})
 
-				m.events.Dispatch(events.EventTypeUpdateEmoteSet, events.ChangeMap{
+				m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
 					ID:    esb.EmoteSet.ID,
 					Kind:  structures.ObjectKindEmoteSet,
 					Actor: m.modelizer.User(actor).ToPartial(),
@@ -539,12 +577,17 @@ func (m *Mutate) EditEmotesInSet(ctx context.Context, esb *structures.EmoteSetBu
 	}
 
 	// Write audit log entry
+	c.Value, _ = bson.Marshal(ac)
+
 	if _, err := m.mongo.Collection(mongo.CollectionNameAuditLogs).InsertOne(ctx, log.AuditLog); err != nil {
 		zap.S().Errorw("mongo, failed to write audit log entry for changes to emote set",
 			"emote_set_id", esb.EmoteSet.ID.Hex(),
 		)
 	}
 
+	// Sets using this set as an origin need to refresh
+	m.queueOriginDependents(esb.EmoteSet.ID, actor)
+
 	esb.MarkAsTainted()
 
 	return nil
@@ -554,6 +597,8 @@ type EmoteSetMutationSetEmoteOptions struct {
 	Actor    structures.User
 	Emotes   []EmoteSetMutationSetEmoteItem
 	Channels []primitive.ObjectID
+	// Collection adds the emotes of a collection, skipping those which don't fit in the set
+	Collection *query.EmoteCollection
 }
 
 type EmoteSetMutationSetEmoteItem struct {
@@ -564,5 +609,69 @@ type EmoteSetMutationSetEmoteItem struct {
 	ChannelCount int32
 	Flags        structures.BitField[structures.ActiveEmoteFlag]
 
-	emote *structures.Emote
+	emote          *structures.Emote
+	fromCollection bool
+}
+
+// fitCollectionEmotes removes the emotes of a collection which cannot be added to the set from the targets,
+// keeping those which fit in the order of the collection. It returns how many targets remain
+func fitCollectionEmotes(actor structures.User, set structures.EmoteSet, targets map[primitive.ObjectID]EmoteSetMutationSetEmoteItem, col query.EmoteCollection) int {
+	owner := structures.User{}
+	if set.Owner != nil {
+		owner = *set.Owner
+	}
+
+	active := map[primitive.ObjectID]bool{}
+	names := map[string]bool{}
+	slots := 0
+
+	for _, ae := range set.Emotes {
+		active[ae.ID] = true
+		names[ae.Name] = true
+
+		if ae.Origin.ID.IsZero() {
+			slots++
+		}
+	}
+
+	// Emotes added alongside the collection take their slot and name first
+	for _, tgt := range targets {
+		if tgt.fromCollection || tgt.Action != structures.ListItemActionAdd || tgt.emote == nil {
+			continue
+		}
+
+		names[utils.Ternary(tgt.Name != "", tgt.Name, tgt.emote.Name)] = true
+		slots++
+	}
+
+	unlimited := actor.HasPermission(structures.RolePermissionEditAnyEmoteSet)
+
+	for _, item := range col.Emotes {
+		tgt, ok := targets[item.ID]
+		if !ok || !tgt.fromCollection {
+			continue
+		}
+
+		if tgt.emote == nil ||
+			active[item.ID] ||
+			names[tgt.emote.Name] ||
+			tgt.emote.Validator().Name() != nil ||
+			!canCopyEmote(actor, owner, *tgt.emote) ||
+			!unlimited && slots >= int(set.Capacity) {
+			delete(targets, item.ID)
+
+			continue
+		}
+
+		names[tgt.emote.Name] = true
+		slots++
+	}
+
+	for id, tgt := range targets {
+		if tgt.emote == nil {
+			delete(targets, id)
+		}
+	}
+
+	return len(targets)
 }
@@ -0,0 +1,98 @@
+package mutate
+
+import (
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestFitCollectionEmotes(t *testing.T) {
+	actor := structures.User{ID: primitive.NewObjectID()}
+
+	newEmote := func(name string, flags structures.EmoteFlag) *structures.Emote {
+		return &structures.Emote{ID: primitive.NewObjectID(), Name: name, Flags: structures.BitField[structures.EmoteFlag](0).Set(flags)}
+	}
+
+	active := newEmote("KEKW", 0)
+	conflicting := newEmote("KEKW", 0)
+	private := newEmote("Secret", structures.EmoteFlagsPrivate)
+	first := newEmote("PogU", 0)
+	second := newEmote("Clap", 0)
+	overflow := newEmote("Sadge", 0)
+
+	set := structures.EmoteSet{
+		Capacity: 3,
+		Emotes:   []structures.ActiveEmote{{ID: active.ID, Name: active.Name}},
+	}
+
+	col := query.EmoteCollection{}
+	targets := map[primitive.ObjectID]EmoteSetMutationSetEmoteItem{}
+
+	for _, e := range []*structures.Emote{active, conflicting, private, first, second, overflow} {
+		col.Emotes = append(col.Emotes, query.EmoteCollectionItem{ID: e.ID})
+		targets[e.ID] = EmoteSetMutationSetEmoteItem{
+			Action:         structures.ListItemActionAdd,
+			ID:             e.ID,
+			emote:          e,
+			fromCollection: true,
+		}
+	}
+
+	// An emote of the collection which no longer exists
+	missingID := primitive.NewObjectID()
+	col.Emotes = append(col.Emotes, query.EmoteCollectionItem{ID: missingID})
+	targets[missingID] = EmoteSetMutationSetEmoteItem{Action: structures.ListItemActionAdd, ID: missingID, fromCollection: true}
+
+	n := fitCollectionEmotes(actor, set, targets, col)
+
+	testutil.Assert(t, 2, n, "only the emotes which fit remain")
+
+	_, ok := targets[first.ID]
+	testutil.Assert(t, true, ok, "the first emote which fits is added")
+
+	_, ok = targets[second.ID]
+	testutil.Assert(t, true, ok, "the second emote which fits is added")
+
+	for _, e := range []*structures.Emote{active, conflicting, private, overflow} {
+		_, ok = targets[e.ID]
+		testutil.Assert(t, false, ok, "an emote which doesn't fit is skipped: "+e.Name)
+	}
+
+	_, ok = targets[missingID]
+	testutil.Assert(t, false, ok, "an unknown emote is skipped")
+}
+
+func TestFitCollectionEmotesWithOtherEmotes(t *testing.T) {
+	actor := structures.User{ID: primitive.NewObjectID()}
+	set := structures.EmoteSet{Capacity: 2, Emotes: []structures.ActiveEmote{}}
+
+	explicit := &structures.Emote{ID: primitive.NewObjectID(), Name: "PogU"}
+	conflicting := &structures.Emote{ID: primitive.NewObjectID(), Name: "PogU"}
+	fits := &structures.Emote{ID: primitive.NewObjectID(), Name: "Clap"}
+	overflow := &structures.Emote{ID: primitive.NewObjectID(), Name: "Sadge"}
+
+	targets := map[primitive.ObjectID]EmoteSetMutationSetEmoteItem{
+		explicit.ID: {Action: structures.ListItemActionAdd, ID: explicit.ID, emote: explicit},
+	}
+	col := query.EmoteCollection{}
+
+	for _, e := range []*structures.Emote{conflicting, fits, overflow} {
+		col.Emotes = append(col.Emotes, query.EmoteCollectionItem{ID: e.ID})
+		targets[e.ID] = EmoteSetMutationSetEmoteItem{Action: structures.ListItemActionAdd, ID: e.ID, emote: e, fromCollection: true}
+	}
+
+	testutil.Assert(t, 2, fitCollectionEmotes(actor, set, targets, col), "the emotes added alongside take their slot first")
+
+	_, ok := targets[explicit.ID]
+	testutil.Assert(t, true, ok, "an emote added alongside the collection is kept")
+
+	_, ok = targets[fits.ID]
+	testutil.Assert(t, true, ok, "the emote of the collection which fits is added")
+
+	_, ok = targets[conflicting.ID]
+	testutil.Assert(t, false, ok, "an emote whose name is taken by another added emote is skipped")
+}
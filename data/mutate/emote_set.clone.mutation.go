@@ -0,0 +1,423 @@
+package mutate
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/structures/v3/aggregations"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
+)
+
+// CloneEmoteSet: create a new emote set with the emotes, aliases and origins of another set
+//
+// The builder should hold the name, owner and capacity of the new set
+func (m *Mutate) CloneEmoteSet(ctx context.Context, esb *structures.EmoteSetBuilder, opt EmoteSetCloneOptions) error {
+	if esb == nil {
+		return errors.ErrInternalIncompleteMutation()
+	} else if esb.IsTainted() {
+		return errors.ErrMutateTaintedObject()
+	}
+
+	actor := opt.Actor
+	source := opt.Source
+
+	if source.ID.IsZero() {
+		return errors.ErrUnknownEmoteSet()
+	}
+
+	// Sets distributed as entitlements cannot be copied
+	if source.Flags.Has(structures.EmoteSetFlagPersonal) || source.Flags.Has(structures.EmoteSetFlagCommercial) {
+		if !actor.HasPermission(structures.RolePermissionEditAnyEmoteSet) {
+			return errors.ErrInsufficientPrivilege().SetDetail("This emote set cannot be cloned")
+		}
+	}
+
+	esb.SetOrigins(source.Origins)
+	esb.EmoteSet.Emotes = []structures.ActiveEmote{}
+
+	if err := m.CreateEmoteSet(ctx, esb, EmoteSetMutationOptions{
+		Actor: actor,
+	}); err != nil {
+		return err
+	}
+
+	target := esb.EmoteSet
+
+	if err := m.copyEmotesToSet(ctx, target, source, actor); err != nil {
+		// Don't leave an empty copy behind
+		if _, derr := m.mongo.Collection(mongo.CollectionNameEmoteSets).DeleteOne(ctx, bson.M{"_id": target.ID}); derr != nil {
+			zap.S().Errorw("mongo, failed to delete incomplete emote set clone", "error", derr, "emote_set_id", target.ID)
+		}
+
+		return err
+	}
+
+	if opt.Link {
+		if _, err := m.mongo.Collection(query.CollectionNameEmoteSetLinks).InsertOne(ctx, query.EmoteSetLink{
+			SetID:    target.ID,
+			SourceID: source.ID,
+			LinkedAt: time.Now(),
+			SyncedAt: time.Now(),
+		}); err != nil {
+			zap.S().Errorw("mongo, failed to link cloned emote set", "error", err, "emote_set_id", target.ID)
+
+			return errors.ErrInternalServerError()
+		}
+	}
+
+	// Return the final state of the set
+	if err := m.mongo.Collection(mongo.CollectionNameEmoteSets).FindOne(ctx, bson.M{"_id": target.ID}).Decode(&esb.EmoteSet); err != nil {
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// SyncEmoteSet: update a cloned emote set to match its source again
+//
+// Emotes removed from the source are removed from the set, and emotes added or renamed are copied over
+func (m *Mutate) SyncEmoteSet(ctx context.Context, esb *structures.EmoteSetBuilder, opt EmoteSetMutationOptions) error {
+	if esb == nil {
+		return errors.ErrInternalIncompleteMutation()
+	} else if esb.IsTainted() {
+		return errors.ErrMutateTaintedObject()
+	}
+
+	target := esb.EmoteSet
+
+	link := query.EmoteSetLink{}
+	if err := m.mongo.Collection(query.CollectionNameEmoteSetLinks).FindOne(ctx, bson.M{"_id": target.ID}).Decode(&link); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrInvalidRequest().SetDetail("This emote set is not linked to a source")
+		}
+
+		return errors.ErrInternalServerError()
+	}
+
+	source := structures.EmoteSet{}
+	if err := m.mongo.Collection(mongo.CollectionNameEmoteSets).FindOne(ctx, bson.M{"_id": link.SourceID}).Decode(&source); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrUnknownEmoteSet().SetDetail("The source of this emote set no longer exists")
+		}
+
+		return errors.ErrInternalServerError()
+	}
+
+	sourceEmotes := make(map[primitive.ObjectID]structures.ActiveEmote, len(source.Emotes))
+	for _, ae := range source.Emotes {
+		sourceEmotes[ae.ID] = ae
+	}
+
+	// Remove emotes which are no longer in the source first, freeing names and slots
+	removals := []EmoteSetMutationSetEmoteItem{}
+	renames := []EmoteSetMutationSetEmoteItem{}
+
+	for _, ae := range target.Emotes {
+		sae, ok := sourceEmotes[ae.ID]
+		if !ok {
+			removals = append(removals, EmoteSetMutationSetEmoteItem{
+				Action: structures.ListItemActionRemove,
+				ID:     ae.ID,
+			})
+		} else if sae.Name != ae.Name {
+			renames = append(renames, EmoteSetMutationSetEmoteItem{
+				Action: structures.ListItemActionUpdate,
+				ID:     ae.ID,
+				Name:   sae.Name,
+			})
+		}
+	}
+
+	for _, items := range [][]EmoteSetMutationSetEmoteItem{removals, renames} {
+		for _, item := range items {
+			if err := m.editEmoteSetFresh(ctx, target, opt.Actor, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := m.copyEmotesToSet(ctx, target, source, opt.Actor); err != nil {
+		return err
+	}
+
+	if _, err := m.mongo.Collection(query.CollectionNameEmoteSetLinks).UpdateOne(ctx, bson.M{
+		"_id": target.ID,
+	}, bson.M{
+		"$set": bson.M{"synced_at": time.Now()},
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to update emote set link", "error", err, "emote_set_id", target.ID)
+	}
+
+	if err := m.mongo.Collection(mongo.CollectionNameEmoteSets).FindOne(ctx, bson.M{"_id": target.ID}).Decode(&esb.EmoteSet); err != nil {
+		return errors.ErrInternalServerError()
+	}
+
+	esb.MarkAsTainted()
+
+	return nil
+}
+
+// copyEmotesToSet adds the emotes of the source which are missing from the target, up to the target's capacity.
+//
+// Emotes which cannot be added, such as deleted or private emotes and emotes whose name is taken, are skipped.
+// The others are validated first and then added in a single write, with one audit log entry and one event
+func (m *Mutate) copyEmotesToSet(ctx context.Context, target structures.EmoteSet, source structures.EmoteSet, actor structures.User) error {
+	if actor.ID.IsZero() || !actor.HasPermission(structures.RolePermissionEditEmoteSet) {
+		return errors.ErrInsufficientPrivilege().SetFields(errors.Fields{"MISSING_PERMISSION": "EDIT_EMOTE_SET"})
+	}
+
+	current := structures.EmoteSet{}
+	if err := m.mongo.Collection(mongo.CollectionNameEmoteSets).FindOne(ctx, bson.M{"_id": target.ID}).Decode(&current); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrUnknownEmoteSet()
+		}
+
+		return errors.ErrInternalServerError()
+	}
+
+	// Personal sets require each emote to be approved, so they are edited one emote at a time
+	if current.Flags.Has(structures.EmoteSetFlagPersonal) {
+		return errors.ErrInvalidRequest().SetDetail("Emotes cannot be copied to a personal emote set")
+	}
+
+	owner, err := m.loaders.UserByID().Load(ctx, current.OwnerID)
+	if err != nil {
+		return errors.ErrUnknownUser().SetDetail("emote set owner")
+	}
+
+	// The actor must have access to the emote set
+	if current.OwnerID != actor.ID && !actor.HasPermission(structures.RolePermissionEditAnyEmoteSet) {
+		if current.Privileged && !actor.HasPermission(structures.RolePermissionSuperAdministrator) {
+			return errors.ErrInsufficientPrivilege().SetDetail("This set is privileged")
+		}
+
+		ed, ok, _ := owner.GetEditor(actor.ID)
+		if !ok || !ed.HasPermission(structures.UserEditorPermissionModifyEmotes) {
+			return errors.ErrInsufficientPrivilege().SetDetail("You do not have permission to change content in this emote set")
+		}
+	}
+
+	existing := make(map[primitive.ObjectID]bool, len(current.Emotes))
+	names := make(map[string]bool, len(current.Emotes))
+
+	for _, ae := range current.Emotes {
+		existing[ae.ID] = true
+		names[ae.Name] = true
+	}
+
+	ids := []primitive.ObjectID{}
+
+	for _, ae := range source.Emotes {
+		if !existing[ae.ID] {
+			ids = append(ids, ae.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Fetch the emotes, with their owner's editors to allow private emotes
+	emoteList := []*structures.Emote{}
+
+	cur, err := m.mongo.Collection(mongo.CollectionNameEmotes).Aggregate(ctx, append(mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"versions.id": bson.M{"$in": ids}}}},
+	}, aggregations.GetEmoteRelationshipOwner(aggregations.UserRelationshipOptions{Editors: true})...))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query emotes to copy", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if err = cur.All(ctx, &emoteList); err != nil {
+		zap.S().Errorw("mongo, failed to decode emotes to copy", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	emotes := make(map[primitive.ObjectID]*structures.Emote, len(emoteList))
+
+	for _, e := range emoteList {
+		for _, ver := range e.Versions {
+			emotes[ver.ID] = e
+		}
+	}
+
+	slots := int(current.Capacity) - len(current.Emotes)
+	at := time.Now()
+	added := []structures.ActiveEmote{}
+
+	for _, ae := range source.Emotes {
+		if slots <= 0 {
+			break
+		}
+
+		e, ok := emotes[ae.ID]
+		if !ok || existing[ae.ID] || !canCopyEmote(actor, owner, *e) {
+			continue
+		}
+
+		name := utils.Ternary(ae.Name != "", ae.Name, e.Name)
+
+		// Validate the alias the same way as the emote's name
+		alias := *e
+		alias.Name = name
+
+		if names[name] || alias.Validator().Name() != nil {
+			continue
+		}
+
+		added = append(added, structures.ActiveEmote{
+			ID:        ae.ID,
+			Name:      name,
+			Flags:     ae.Flags,
+			Timestamp: at,
+			ActorID:   actor.ID,
+			Emote:     e,
+		})
+
+		existing[ae.ID] = true
+		names[name] = true
+		slots--
+	}
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	addedIDs := make([]primitive.ObjectID, len(added))
+	docs, change := copiedEmotesChange(added)
+	pushed := make([]events.ChangeField, len(added))
+
+	for i, ae := range added {
+		addedIDs[i] = ae.ID
+		pushed[i] = events.ChangeField{
+			Key:   "emotes",
+			Index: utils.PointerOf(int32(len(current.Emotes) + i)),
+			Type:  events.ChangeFieldTypeObject,
+			Value: m.modelizer.ActiveEmote(ae),
+		}
+	}
+
+	// The write fails if the set changed in a way which would conflict with the validation above
+	res, err := m.mongo.Collection(mongo.CollectionNameEmoteSets).UpdateOne(ctx, bson.M{
+		"_id":         current.ID,
+		"emotes.id":   bson.M{"$nin": addedIDs},
+		"emotes.name": bson.M{"$nin": utils.Map(added, func(ae structures.ActiveEmote) string { return ae.Name })},
+		"$expr": bson.M{"$lte": bson.A{
+			bson.M{"$add": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$emotes", bson.A{}}}}, len(added)}},
+			current.Capacity,
+		}},
+	}, bson.M{
+		"$push": bson.M{"emotes": bson.M{"$each": docs}},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to copy emotes to emote set", "error", err, "emote_set_id", current.ID)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.ErrEmoteNameConflict().SetDetail("The emote set changed while emotes were being copied, try again")
+	}
+
+	alb := structures.NewAuditLogBuilder(structures.AuditLog{}).
+		SetKind(structures.AuditLogKindUpdateEmoteSet).
+		SetActor(actor.ID).
+		SetTargetKind(structures.ObjectKindEmoteSet).
+		SetTargetID(current.ID).
+		AddChanges(change)
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameAuditLogs).InsertOne(ctx, alb.AuditLog); err != nil {
+		zap.S().Errorw("mongo, failed to write audit log entry for copied emotes", "error", err, "emote_set_id", current.ID)
+	}
+
+	m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
+		ID:     current.ID,
+		Kind:   structures.ObjectKindEmoteSet,
+		Actor:  m.modelizer.User(actor).ToPartial(),
+		Pushed: pushed,
+	}, events.EventCondition{
+		"object_id": current.ID.Hex(),
+	})
+
+	// Sets using this set as an origin need to refresh
+	m.queueOriginDependents(current.ID, actor)
+
+	return nil
+}
+
+// copiedEmotesChange returns the documents of emotes copied to a set and the audit log change listing all of them
+func copiedEmotesChange(added []structures.ActiveEmote) (bson.A, *structures.AuditLogChange) {
+	docs := make(bson.A, len(added))
+
+	for i, ae := range added {
+		docs[i] = structures.ActiveEmote{
+			ID:        ae.ID,
+			Name:      ae.Name,
+			Flags:     ae.Flags,
+			Timestamp: ae.Timestamp,
+			ActorID:   ae.ActorID,
+		}
+	}
+
+	// Each write replaces the value of the change, so the emotes must be written at once
+	change := &structures.AuditLogChange{
+		Format: structures.AuditLogChangeFormatArrayChange,
+		Key:    "emotes",
+	}
+	change.WriteArrayAdded(docs...)
+
+	return docs, change
+}
+
+// canCopyEmote tells whether an emote may be added to a set of the owner by the actor
+func canCopyEmote(actor structures.User, owner structures.User, e structures.Emote) bool {
+	// Zero-width emotes require the owner of the set to have the feature
+	if e.Flags.Has(structures.EmoteFlagsZeroWidth) && !owner.HasPermission(structures.RolePermissionFeatureZeroWidthEmoteType) {
+		return false
+	}
+
+	if !e.Flags.Has(structures.EmoteFlagsPrivate) || actor.HasPermission(structures.RolePermissionBypassPrivacy) {
+		return true
+	}
+
+	// Private emotes are usable by editors of their owner with the permission
+	if e.Owner != nil {
+		if ed, ok, _ := e.Owner.GetEditor(actor.ID); ok && ed.HasPermission(structures.UserEditorPermissionUsePrivateEmotes) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// editEmoteSetFresh applies a single emote change on an up to date copy of the set
+func (m *Mutate) editEmoteSetFresh(ctx context.Context, target structures.EmoteSet, actor structures.User, item EmoteSetMutationSetEmoteItem) error {
+	set := structures.EmoteSet{}
+	if err := m.mongo.Collection(mongo.CollectionNameEmoteSets).FindOne(ctx, bson.M{"_id": target.ID}).Decode(&set); err != nil {
+		return errors.ErrUnknownEmoteSet()
+	}
+
+	return m.EditEmotesInSet(ctx, structures.NewEmoteSetBuilder(set), EmoteSetMutationSetEmoteOptions{
+		Actor:  actor,
+		Emotes: []EmoteSetMutationSetEmoteItem{item},
+	})
+}
+
+type EmoteSetCloneOptions struct {
+	Actor structures.User
+	// The set to copy from
+	Source structures.EmoteSet
+	// Whether to keep a link to the source so that the clone can be re-synced
+	Link bool
+}
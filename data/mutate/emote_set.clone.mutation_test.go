@@ -0,0 +1,42 @@
+package mutate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestCopiedEmotesChange(t *testing.T) {
+	at := time.Now()
+	actorID := primitive.NewObjectID()
+	added := []structures.ActiveEmote{
+		{ID: primitive.NewObjectID(), Name: "KEKW", Timestamp: at, ActorID: actorID},
+		{ID: primitive.NewObjectID(), Name: "PogU", Timestamp: at, ActorID: actorID},
+		{ID: primitive.NewObjectID(), Name: "Clap", Timestamp: at, ActorID: actorID},
+	}
+
+	docs, change := copiedEmotesChange(added)
+
+	testutil.Assert(t, len(added), len(docs), "every copied emote is written")
+	testutil.Assert(t, structures.AuditLogChangeFormatArrayChange, change.Format, "the change is an array change")
+	testutil.Assert(t, "emotes", change.Key, "the change is on the emotes")
+
+	value := struct {
+		Added   []structures.ActiveEmote `bson:"added"`
+		Removed []structures.ActiveEmote `bson:"removed"`
+	}{}
+
+	testutil.IsNil(t, bson.Unmarshal(change.Value, &value), "the change value decodes")
+	testutil.Assert(t, len(added), len(value.Added), "the change lists every copied emote")
+	testutil.Assert(t, 0, len(value.Removed), "the change removes nothing")
+
+	for i, ae := range added {
+		testutil.Assert(t, ae.ID, value.Added[i].ID, "the change lists the copied emote")
+		testutil.Assert(t, ae.Name, value.Added[i].Name, "the change keeps the name of the copied emote")
+	}
+}
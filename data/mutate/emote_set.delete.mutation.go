@@ -10,6 +10,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
 )
 
 func (m *Mutate) DeleteEmoteSet(ctx context.Context, esb *structures.EmoteSetBuilder, opt EmoteSetMutationOptions) error {
@@ -55,8 +56,15 @@ func (m *Mutate) DeleteEmoteSet(ctx context.Context, esb *structures.EmoteSetBui
 		return errors.ErrInternalServerError()
 	}
 
+	// Remove the source link of the set, if it was cloned
+	if _, err := m.mongo.Collection(query.CollectionNameEmoteSetLinks).DeleteOne(ctx, bson.M{
+		"_id": esb.EmoteSet.ID,
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to delete emote set link", "error", err, "emote_set_id", esb.EmoteSet.ID)
+	}
+
 	// Emit event
-	m.events.Dispatch(events.EventTypeDeleteEmoteSet, events.ChangeMap{
+	m.events.Dispatch(ctx, events.EventTypeDeleteEmoteSet, events.ChangeMap{
 		ID:    esb.EmoteSet.OwnerID,
 		Kind:  structures.ObjectKindEmoteSet,
 		Actor: m.modelizer.User(actor).ToPartial(),
@@ -45,7 +45,7 @@ func (m *Mutate) UpdateEmoteSet(ctx context.Context, esb *structures.EmoteSetBui
 	}
 
 	// Find emote set's owner
-	setOwner, _ := m.loaders.UserByID().Load(esb.EmoteSet.OwnerID)
+	setOwner, _ := m.loaders.UserByID().Load(ctx, esb.EmoteSet.OwnerID)
 
 	esb.EmoteSet.Owner = &setOwner
 
@@ -160,7 +160,7 @@ func (m *Mutate) UpdateEmoteSet(ctx context.Context, esb *structures.EmoteSetBui
 		}
 
 		// Dispatch an event
-		m.events.Dispatch(events.EventTypeUpdateEmoteSet, events.ChangeMap{
+		m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
 			ID:      esb.EmoteSet.ID,
 			Kind:    structures.ObjectKindEmoteSet,
 			Actor:   m.modelizer.User(actor).ToPartial(),
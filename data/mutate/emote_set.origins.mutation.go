@@ -0,0 +1,198 @@
+package mutate
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
+)
+
+// SetEmoteSetOriginPolicy: change how an emote set resolves name conflicts with its origins
+func (m *Mutate) SetEmoteSetOriginPolicy(ctx context.Context, esb *structures.EmoteSetBuilder, policy query.EmoteSetOriginPolicy, opt EmoteSetMutationOptions) error {
+	if esb == nil || esb.EmoteSet.ID.IsZero() {
+		return errors.ErrInternalIncompleteMutation()
+	}
+
+	actor := opt.Actor
+	if !opt.SkipValidation {
+		if actor.ID.IsZero() {
+			return errors.ErrUnauthorized()
+		}
+
+		if !actor.HasPermission(structures.RolePermissionEditEmoteSet) {
+			return errors.ErrInsufficientPrivilege().SetFields(errors.Fields{"MISSING_PERMISSION": "EDIT_EMOTE_SET"})
+		}
+
+		if actor.ID != esb.EmoteSet.OwnerID && !actor.HasPermission(structures.RolePermissionEditAnyEmoteSet) {
+			owner, err := m.loaders.UserByID().Load(ctx, esb.EmoteSet.OwnerID)
+			if err != nil {
+				return errors.ErrUnknownUser()
+			}
+
+			ed, ok, _ := owner.GetEditor(actor.ID)
+			if !ok || !ed.HasPermission(structures.UserEditorPermissionManageEmoteSets) {
+				return errors.ErrInsufficientPrivilege().SetDetail("You are not allowed to modify this Emote Set")
+			}
+		}
+	}
+
+	switch policy.Mode {
+	case query.EmoteSetOriginConflictModeOriginWins, query.EmoteSetOriginConflictModeLocalWins:
+		policy.Prefix, policy.Suffix = "", ""
+	case query.EmoteSetOriginConflictModeRename:
+		if policy.Prefix == "" && policy.Suffix == "" {
+			return errors.ErrInvalidRequest().SetDetail("A prefix or suffix is required to rename conflicting emotes")
+		}
+
+		if len(policy.Prefix)+len(policy.Suffix) > 10 {
+			return errors.ErrInvalidRequest().SetDetail("The prefix and suffix cannot be longer than 10 characters combined")
+		}
+	default:
+		return errors.ErrInvalidRequest().SetDetail("Unknown conflict mode")
+	}
+
+	policy.SetID = esb.EmoteSet.ID
+	policy.UpdatedAt = time.Now()
+
+	old := query.EmoteSetOriginPolicy{}
+	if err := m.mongo.Collection(query.CollectionNameEmoteSetOriginPolicies).FindOneAndReplace(ctx, bson.M{
+		"_id": policy.SetID,
+	}, policy, options.FindOneAndReplace().SetUpsert(true)).Decode(&old); err != nil && err != mongo.ErrNoDocuments {
+		zap.S().Errorw("mongo, failed to write emote set origin policy", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if old.Mode == policy.Mode && old.Prefix == policy.Prefix && old.Suffix == policy.Suffix {
+		return nil
+	}
+
+	// Write audit log
+	alb := structures.NewAuditLogBuilder(structures.AuditLog{}).
+		SetKind(structures.AuditLogKindUpdateEmoteSet).
+		SetActor(actor.ID).
+		SetTargetKind(structures.ObjectKindEmoteSet).
+		SetTargetID(esb.EmoteSet.ID).
+		AddChanges(structures.NewAuditChange("origin_policy").WriteSingleValues(old, policy))
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameAuditLogs).InsertOne(ctx, alb.AuditLog); err != nil {
+		zap.S().Errorw("failed to write audit log", "error", err)
+	}
+
+	// The effective emotes of the set may have changed
+	m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
+		ID:    esb.EmoteSet.ID,
+		Kind:  structures.ObjectKindEmoteSet,
+		Actor: m.modelizer.User(actor).ToPartial(),
+		Updated: []events.ChangeField{{
+			Key:      "origin_policy",
+			Type:     events.ChangeFieldTypeObject,
+			OldValue: old,
+			Value:    policy,
+		}},
+	}, events.EventCondition{
+		"object_id": esb.EmoteSet.ID.Hex(),
+	})
+
+	return nil
+}
+
+const (
+	// ORIGIN_NOTIFY_CONCURRENCY is how many origin dependent notifications may run at once
+	ORIGIN_NOTIFY_CONCURRENCY = 16
+	// ORIGIN_NOTIFY_QUEUE_SIZE is how many origin dependent notifications may wait for a worker
+	ORIGIN_NOTIFY_QUEUE_SIZE = 1024
+	// ORIGIN_NOTIFY_TIMEOUT is how long an origin dependent notification may take
+	ORIGIN_NOTIFY_TIMEOUT = time.Second * 10
+)
+
+// originNotification is a change to an emote set which the sets using it as an origin must be told about
+type originNotification struct {
+	originID primitive.ObjectID
+	actor    structures.User
+}
+
+// queueOriginDependents notifies the sets using the given set as an origin in the background.
+// The notification is only dropped when the queue is full, which is logged and counted
+func (m *Mutate) queueOriginDependents(originID primitive.ObjectID, actor structures.User) {
+	select {
+	case m.originNotify <- originNotification{originID: originID, actor: actor}:
+		if m.prom != nil {
+			m.prom.OriginNotificationQueued(false)
+		}
+	default:
+		zap.S().Errorw("emote set origins, notification queue is full, dropping", "emote_set_id", originID)
+
+		if m.prom != nil {
+			m.prom.OriginNotificationQueued(true)
+		}
+	}
+}
+
+// runOriginNotifications sends the queued origin dependent notifications, one at a time
+func (m *Mutate) runOriginNotifications() {
+	for n := range m.originNotify {
+		ctx, cancel := context.WithTimeout(context.Background(), ORIGIN_NOTIFY_TIMEOUT)
+
+		m.notifyOriginDependents(ctx, n.originID, n.actor)
+
+		cancel()
+	}
+}
+
+// notifyOriginDependents lets clients know that the emotes of sets using the given set as an origin have changed.
+// Each dependent set receives its origin entry for the changed set
+func (m *Mutate) notifyOriginDependents(ctx context.Context, originID primitive.ObjectID, actor structures.User) {
+	cur, err := m.mongo.Collection(mongo.CollectionNameEmoteSets).Find(ctx, bson.M{
+		"origins.id": originID,
+	}, options.Find().SetProjection(bson.M{"_id": 1, "origins": 1}))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query origin dependents", "error", err, "emote_set_id", originID)
+
+		return
+	}
+
+	dependents := []struct {
+		ID      primitive.ObjectID          `bson:"_id"`
+		Origins []structures.EmoteSetOrigin `bson:"origins"`
+	}{}
+	if err := cur.All(ctx, &dependents); err != nil {
+		zap.S().Errorw("mongo, failed to decode origin dependents", "error", err, "emote_set_id", originID)
+
+		return
+	}
+
+	for _, dep := range dependents {
+		for i, origin := range dep.Origins {
+			if origin.ID != originID {
+				continue
+			}
+
+			m.events.Dispatch(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
+				ID:    dep.ID,
+				Kind:  structures.ObjectKindEmoteSet,
+				Actor: m.modelizer.User(actor).ToPartial(),
+				Updated: []events.ChangeField{{
+					Key:   "origins",
+					Index: utils.PointerOf(int32(i)),
+					Type:  events.ChangeFieldTypeObject,
+					Value: m.modelizer.EmoteSetOrigin(origin),
+				}},
+			}, events.EventCondition{
+				"object_id": dep.ID.Hex(),
+			})
+
+			break
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package mutate
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/query"
+)
+
+const (
+	FEATURE_FLAG_KEY_LIMIT         = 64
+	FEATURE_FLAG_DESCRIPTION_LIMIT = 500
+)
+
+var featureFlagKeyRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+type FeatureFlagOptions struct {
+	Actor structures.User
+	// The key, description, state, visibility and targeting of the flag
+	Data query.FeatureFlag
+}
+
+// CreateFeatureFlag adds a feature flag. Keys are unique
+func (m *Mutate) CreateFeatureFlag(ctx context.Context, opt FeatureFlagOptions) (query.FeatureFlag, error) {
+	flag := opt.Data
+
+	if !opt.Actor.HasPermission(structures.RolePermissionManageStack) {
+		return flag, errors.ErrInsufficientPrivilege()
+	}
+
+	if err := validateFeatureFlagKey(flag.Key); err != nil {
+		return flag, err
+	}
+
+	if err := validateFeatureFlag(flag); err != nil {
+		return flag, err
+	}
+
+	count, err := m.mongo.Collection(query.CollectionNameFeatureFlags).CountDocuments(ctx, bson.M{"key": flag.Key})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to count feature flags", "error", err)
+
+		return flag, errors.ErrInternalServerError()
+	}
+
+	if count > 0 {
+		return flag, errors.ErrInvalidRequest().SetDetail("A flag with the key '%s' already exists", flag.Key)
+	}
+
+	flag.ID = primitive.NewObjectIDFromTimestamp(time.Now())
+	flag.UpdatedAt = time.Now()
+
+	if _, err := m.mongo.Collection(query.CollectionNameFeatureFlags).InsertOne(ctx, flag); err != nil {
+		zap.S().Errorw("mongo, failed to create feature flag", "error", err)
+
+		return flag, errors.ErrInternalServerError()
+	}
+
+	m.clearFeatureFlagsCache(ctx)
+
+	m.writeFeatureFlagAuditLog(ctx, opt.Actor, flag.ID, query.AuditLogKindCreateFeatureFlag,
+		structures.NewAuditChange("flag").WriteSingleValues(nil, flag),
+	)
+
+	return flag, nil
+}
+
+// UpdateFeatureFlag replaces the description, state, visibility and targeting of a flag. The key cannot be changed
+func (m *Mutate) UpdateFeatureFlag(ctx context.Context, id primitive.ObjectID, opt FeatureFlagOptions) (query.FeatureFlag, error) {
+	flag := opt.Data
+
+	if !opt.Actor.HasPermission(structures.RolePermissionManageStack) {
+		return flag, errors.ErrInsufficientPrivilege()
+	}
+
+	if err := validateFeatureFlag(flag); err != nil {
+		return flag, err
+	}
+
+	old := query.FeatureFlag{}
+
+	if err := m.mongo.Collection(query.CollectionNameFeatureFlags).FindOneAndUpdate(ctx, bson.M{
+		"_id": id,
+	}, bson.M{
+		"$set": bson.M{
+			"description": flag.Description,
+			"enabled":     flag.Enabled,
+			"public":      flag.Public,
+			"targeting":   flag.Targeting,
+			"updated_at":  time.Now(),
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.Before)).Decode(&old); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return flag, errors.ErrNoItems().SetDetail("Unknown Feature Flag")
+		}
+
+		zap.S().Errorw("mongo, failed to update feature flag", "error", err)
+
+		return flag, errors.ErrInternalServerError()
+	}
+
+	m.clearFeatureFlagsCache(ctx)
+
+	flag.ID = old.ID
+	flag.Key = old.Key
+	flag.UpdatedAt = time.Now()
+
+	changes := []*structures.AuditLogChange{}
+
+	if old.Description != flag.Description {
+		changes = append(changes, structures.NewAuditChange("description").WriteSingleValues(old.Description, flag.Description))
+	}
+
+	if old.Enabled != flag.Enabled {
+		changes = append(changes, structures.NewAuditChange("enabled").WriteSingleValues(old.Enabled, flag.Enabled))
+	}
+
+	if old.Public != flag.Public {
+		changes = append(changes, structures.NewAuditChange("public").WriteSingleValues(old.Public, flag.Public))
+	}
+
+	if !old.Targeting.Equal(flag.Targeting) {
+		changes = append(changes, structures.NewAuditChange("targeting").WriteSingleValues(old.Targeting, flag.Targeting))
+	}
+
+	if len(changes) > 0 {
+		m.writeFeatureFlagAuditLog(ctx, opt.Actor, flag.ID, query.AuditLogKindUpdateFeatureFlag, changes...)
+	}
+
+	return flag, nil
+}
+
+// DeleteFeatureFlag removes a flag, which is then off for everyone
+func (m *Mutate) DeleteFeatureFlag(ctx context.Context, actor structures.User, id primitive.ObjectID) error {
+	if !actor.HasPermission(structures.RolePermissionManageStack) {
+		return errors.ErrInsufficientPrivilege()
+	}
+
+	old := query.FeatureFlag{}
+
+	if err := m.mongo.Collection(query.CollectionNameFeatureFlags).FindOneAndDelete(ctx, bson.M{
+		"_id": id,
+	}).Decode(&old); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrNoItems().SetDetail("Unknown Feature Flag")
+		}
+
+		zap.S().Errorw("mongo, failed to delete feature flag", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	m.clearFeatureFlagsCache(ctx)
+
+	m.writeFeatureFlagAuditLog(ctx, actor, id, query.AuditLogKindDeleteFeatureFlag,
+		structures.NewAuditChange("flag").WriteSingleValues(old, nil),
+	)
+
+	return nil
+}
+
+func validateFeatureFlagKey(key string) error {
+	if key == "" {
+		return errors.ErrMissingRequiredField().SetDetail("Key")
+	}
+
+	if len(key) > FEATURE_FLAG_KEY_LIMIT {
+		return errors.ErrInvalidRequest().SetDetail("Key is too long (%d characters max)", FEATURE_FLAG_KEY_LIMIT)
+	}
+
+	if !featureFlagKeyRegex.MatchString(key) {
+		return errors.ErrInvalidRequest().SetDetail("Key may only contain lowercase letters, digits, dots, dashes and underscores")
+	}
+
+	return nil
+}
+
+func validateFeatureFlag(flag query.FeatureFlag) error {
+	if len(flag.Description) > FEATURE_FLAG_DESCRIPTION_LIMIT {
+		return errors.ErrInvalidRequest().SetDetail("Description is too long (%d characters max)", FEATURE_FLAG_DESCRIPTION_LIMIT)
+	}
+
+	if flag.Targeting.Percentage < 0 || flag.Targeting.Percentage > 100 {
+		return errors.ErrInvalidRequest().SetDetail("Percentage must be between 0 and 100")
+	}
+
+	return nil
+}
+
+func (m *Mutate) clearFeatureFlagsCache(ctx context.Context) {
+	if _, err := m.redis.Del(ctx, query.FeatureFlagsCacheKey(m.redis)); err != nil {
+		zap.S().Warnw("redis, failed to clear the feature flags cache", "error", err)
+	}
+}
+
+func (m *Mutate) writeFeatureFlagAuditLog(ctx context.Context, actor structures.User, id primitive.ObjectID, kind structures.AuditLogKind, changes ...*structures.AuditLogChange) {
+	alb := structures.NewAuditLogBuilder(structures.AuditLog{}).
+		SetKind(kind).
+		SetActor(actor.ID).
+		SetTargetID(id).
+		AddChanges(changes...)
+
+	if _, err := m.mongo.Collection(query.CollectionNameFeatureFlagAuditLogs).InsertOne(ctx, alb.AuditLog); err != nil {
+		zap.S().Errorw("failed to write audit log", "error", err)
+	}
+}
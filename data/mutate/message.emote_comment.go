@@ -0,0 +1,486 @@
+package mutate
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
+)
+
+const EMOTE_COMMENT_CONTENT_LIMIT = 500
+
+// CreateEmoteComment: post a comment on an emote, or a reply to another comment
+func (m *Mutate) CreateEmoteComment(ctx context.Context, opt EmoteCommentOptions) (query.EmoteComment, error) {
+	actor := opt.Actor
+	comment := query.EmoteComment{}
+
+	if actor.ID.IsZero() {
+		return comment, errors.ErrUnauthorized()
+	}
+
+	if !actor.HasPermission(structures.RolePermissionSendMessages) {
+		return comment, errors.ErrInsufficientPrivilege().SetFields(errors.Fields{"MISSING_PERMISSION": "SEND_MESSAGES"})
+	}
+
+	if opt.Authoritative && !actor.HasPermission(structures.RolePermissionManageContent) {
+		return comment, errors.ErrInsufficientPrivilege().
+			SetDetail("You are not allowed to post authoritative comments").
+			SetFields(errors.Fields{"MISSING_PERMISSION": "MANAGE_CONTENT"})
+	}
+
+	content, err := validateEmoteCommentContent(opt.Content)
+	if err != nil {
+		return comment, err
+	}
+
+	mb := structures.NewMessageBuilder(structures.Message[structures.MessageDataEmoteComment]{}).
+		SetKind(structures.MessageKindEmoteComment).
+		SetAuthorID(actor.ID).
+		SetData(structures.MessageDataEmoteComment{
+			EmoteID:       opt.Emote.ID,
+			Authoritative: opt.Authoritative,
+			Content:       content,
+		})
+
+	mb.Message.ID = primitive.NewObjectIDFromTimestamp(mb.Message.CreatedAt)
+
+	// The message is stored with the threading and moderation state of the comment
+	comment = query.EmoteComment{
+		ID:        mb.Message.ID,
+		Kind:      mb.Message.Kind,
+		AuthorID:  mb.Message.AuthorID,
+		CreatedAt: mb.Message.CreatedAt,
+		Data: query.EmoteCommentData{
+			MessageDataEmoteComment: mb.Message.Data,
+		},
+	}
+
+	// Replies are attached to the top-level comment of the thread
+	if !opt.ReplyToID.IsZero() {
+		replyTo, err := m.emoteComment(ctx, opt.ReplyToID)
+		if err != nil {
+			return comment, err
+		}
+
+		if err := threadEmoteComment(&comment, replyTo); err != nil {
+			return comment, err
+		}
+	}
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessages).InsertOne(ctx, comment); err != nil {
+		zap.S().Errorw("mongo, failed to insert emote comment", "error", err)
+
+		return comment, errors.ErrInternalServerError()
+	}
+
+	// Create a read state, through which the comment is listed
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).InsertOne(ctx, query.EmoteCommentReadState{
+		MessageRead: structures.MessageRead{
+			MessageID: comment.ID,
+			Kind:      structures.MessageKindEmoteComment,
+			Timestamp: comment.CreatedAt,
+		},
+		EmoteID:  comment.Data.EmoteID,
+		ParentID: comment.Data.ParentID,
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to insert emote comment read state", "error", err)
+
+		if _, err := m.mongo.Collection(mongo.CollectionNameMessages).DeleteOne(ctx, bson.M{"_id": comment.ID}); err != nil {
+			zap.S().Errorw("mongo, failed to remove emote comment without a read state", "error", err)
+		}
+
+		return comment, errors.ErrInternalServerError()
+	}
+
+	mb.MarkAsTainted()
+
+	if !comment.Data.ParentID.IsZero() {
+		if _, err := m.mongo.Collection(mongo.CollectionNameMessages).UpdateOne(ctx, bson.M{
+			"_id": comment.Data.ParentID,
+		}, bson.M{
+			"$inc": bson.M{"data.reply_count": 1},
+		}); err != nil {
+			zap.S().Errorw("mongo, failed to update emote comment reply count", "error", err)
+		}
+	}
+
+	m.dispatchEmoteComment(ctx, opt.Emote.ID, actor, func(cm *events.ChangeMap) {
+		cm.Pushed = []events.ChangeField{{
+			Key:   "comments",
+			Type:  events.ChangeFieldTypeObject,
+			Value: comment,
+		}}
+	})
+
+	return comment, nil
+}
+
+// EditEmoteComment: change the content of a comment. Only the author can do this
+func (m *Mutate) EditEmoteComment(ctx context.Context, id primitive.ObjectID, content string, actor structures.User) (query.EmoteComment, error) {
+	comment, err := m.emoteComment(ctx, id)
+	if err != nil {
+		return comment, err
+	}
+
+	if actor.ID.IsZero() || comment.AuthorID != actor.ID {
+		return comment, errors.ErrInsufficientPrivilege().SetDetail("You can only edit your own comments")
+	}
+
+	if comment.Data.Deleted {
+		return comment, errors.ErrUnknownMessage()
+	}
+
+	content, err = validateEmoteCommentContent(content)
+	if err != nil {
+		return comment, err
+	}
+
+	old := comment.Data.Content
+	now := time.Now()
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessages).UpdateOne(ctx, bson.M{
+		"_id": comment.ID,
+	}, bson.M{
+		"$set": bson.M{
+			"data.content":   content,
+			"data.edited_at": now,
+		},
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to edit emote comment", "error", err)
+
+		return comment, errors.ErrInternalServerError()
+	}
+
+	comment.Data.Content = content
+	comment.Data.EditedAt = &now
+
+	// The content of hidden comments is not broadcast
+	if !comment.Data.Hidden {
+		m.dispatchEmoteComment(ctx, comment.Data.EmoteID, actor, func(cm *events.ChangeMap) {
+			cm.Updated = []events.ChangeField{{
+				Key:      "comments",
+				Type:     events.ChangeFieldTypeObject,
+				OldValue: old,
+				Value:    comment,
+			}}
+		})
+	}
+
+	return comment, nil
+}
+
+// DeleteEmoteComment: remove a comment. The author and moderators can do this
+func (m *Mutate) DeleteEmoteComment(ctx context.Context, id primitive.ObjectID, actor structures.User) error {
+	comment, err := m.emoteComment(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	isModerator := actor.HasPermission(structures.RolePermissionManageContent)
+
+	if actor.ID.IsZero() || (comment.AuthorID != actor.ID && !isModerator) {
+		return errors.ErrInsufficientPrivilege().SetDetail("You are not allowed to delete this comment")
+	}
+
+	if comment.Data.Deleted {
+		return nil
+	}
+
+	// A top-level comment with replies is emptied, so that the thread stays intact
+	if comment.Data.ReplyCount > 0 {
+		_, err = m.mongo.Collection(mongo.CollectionNameMessages).UpdateOne(ctx, bson.M{
+			"_id": comment.ID,
+		}, bson.M{
+			"$set": bson.M{
+				"data.deleted": true,
+				"data.content": "",
+				"data.pinned":  false,
+			},
+		})
+	} else if _, err = m.mongo.Collection(mongo.CollectionNameMessages).DeleteOne(ctx, bson.M{"_id": comment.ID}); err == nil {
+		_, err = m.mongo.Collection(mongo.CollectionNameMessagesRead).DeleteOne(ctx, bson.M{"message_id": comment.ID})
+	}
+
+	if err != nil {
+		zap.S().Errorw("mongo, failed to delete emote comment", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if !comment.Data.ParentID.IsZero() {
+		if _, err := m.mongo.Collection(mongo.CollectionNameMessages).UpdateOne(ctx, bson.M{
+			"_id": comment.Data.ParentID,
+		}, bson.M{
+			"$inc": bson.M{"data.reply_count": -1},
+		}); err != nil {
+			zap.S().Errorw("mongo, failed to update emote comment reply count", "error", err)
+		}
+	}
+
+	if comment.AuthorID != actor.ID {
+		m.writeEmoteCommentAuditLog(ctx, comment, actor, "comment_deleted", comment.Data.Content, nil)
+	}
+
+	m.dispatchEmoteComment(ctx, comment.Data.EmoteID, actor, func(cm *events.ChangeMap) {
+		cm.Pulled = []events.ChangeField{{
+			Key:      "comments",
+			Type:     events.ChangeFieldTypeObject,
+			OldValue: emoteCommentState{ID: comment.ID, Hidden: comment.Data.Hidden},
+		}}
+	})
+
+	return nil
+}
+
+// ModerateEmoteComment: hide or pin a comment.
+//
+// The owner of the emote can hide comments, and moderators can also pin them
+func (m *Mutate) ModerateEmoteComment(ctx context.Context, id primitive.ObjectID, opt EmoteCommentModerationOptions) (query.EmoteComment, error) {
+	actor := opt.Actor
+
+	comment, err := m.emoteComment(ctx, id)
+	if err != nil {
+		return comment, err
+	}
+
+	if comment.Data.Deleted {
+		return comment, errors.ErrUnknownMessage()
+	}
+
+	isModerator := actor.HasPermission(structures.RolePermissionManageContent)
+	isOwner := !actor.ID.IsZero() && opt.Emote.OwnerID == actor.ID
+
+	set, unset, err := moderateEmoteComment(&comment, opt)
+	if err != nil {
+		return comment, err
+	}
+
+	// Pinned comments are limited per emote
+	if set["data.pinned"] == true {
+		count, err := m.mongo.Collection(mongo.CollectionNameMessages).CountDocuments(ctx, bson.M{
+			"kind":          structures.MessageKindEmoteComment,
+			"data.emote_id": comment.Data.EmoteID,
+			"data.pinned":   true,
+		})
+		if err != nil {
+			zap.S().Errorw("mongo, failed to count pinned emote comments", "error", err)
+
+			return comment, errors.ErrInternalServerError()
+		}
+
+		if count >= query.EMOTE_COMMENTS_PINNED_LIMIT {
+			return comment, errors.ErrInvalidRequest().SetDetail("No more than %d comments can be pinned", query.EMOTE_COMMENTS_PINNED_LIMIT)
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return comment, nil
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessages).UpdateOne(ctx, bson.M{"_id": comment.ID}, update); err != nil {
+		zap.S().Errorw("mongo, failed to moderate emote comment", "error", err)
+
+		return comment, errors.ErrInternalServerError()
+	}
+
+	if isModerator && !isOwner {
+		m.writeEmoteCommentAuditLog(ctx, comment, actor, "comment_moderated", nil, bson.M{
+			"id":     comment.ID,
+			"hidden": comment.Data.Hidden,
+			"pinned": comment.Data.Pinned,
+		})
+	}
+
+	// Watchers only learn of the new state. Clients allowed to see a hidden comment fetch it
+	m.dispatchEmoteComment(ctx, comment.Data.EmoteID, actor, func(cm *events.ChangeMap) {
+		cm.Updated = []events.ChangeField{{
+			Key:  "comments",
+			Type: events.ChangeFieldTypeObject,
+			Value: emoteCommentState{
+				ID:     comment.ID,
+				Hidden: comment.Data.Hidden,
+				Pinned: comment.Data.Pinned,
+			},
+		}}
+	})
+
+	return comment, nil
+}
+
+// threadEmoteComment attaches a reply to the thread of the comment it replies to
+func threadEmoteComment(comment *query.EmoteComment, replyTo query.EmoteComment) error {
+	if replyTo.Data.EmoteID != comment.Data.EmoteID {
+		return errors.ErrInvalidRequest().SetDetail("The comment being replied to is on another emote")
+	}
+
+	if replyTo.Data.Deleted || replyTo.Data.Hidden {
+		return errors.ErrInvalidRequest().SetDetail("You cannot reply to this comment")
+	}
+
+	comment.Data.ReplyToID = replyTo.ID
+	comment.Data.ParentID = replyTo.ID
+
+	if !replyTo.Data.ParentID.IsZero() {
+		comment.Data.ParentID = replyTo.Data.ParentID
+	}
+
+	return nil
+}
+
+// moderateEmoteComment applies a moderation to a comment, if the actor is allowed to.
+// It returns the fields of the comment to set and to unset
+func moderateEmoteComment(comment *query.EmoteComment, opt EmoteCommentModerationOptions) (bson.M, bson.M, error) {
+	actor := opt.Actor
+	isModerator := actor.HasPermission(structures.RolePermissionManageContent)
+	isOwner := !actor.ID.IsZero() && opt.Emote.OwnerID == actor.ID
+
+	set := bson.M{}
+	unset := bson.M{}
+
+	if comment.Data.EmoteID != opt.Emote.ID {
+		return nil, nil, errors.ErrUnknownMessage()
+	}
+
+	if opt.Hidden != nil && *opt.Hidden != comment.Data.Hidden {
+		if !isOwner && !isModerator {
+			return nil, nil, errors.ErrInsufficientPrivilege().SetDetail("You are not allowed to hide comments on this emote")
+		}
+
+		// The owner cannot unhide a comment hidden by a moderator
+		if !*opt.Hidden && !isModerator && comment.Data.HiddenBy != actor.ID {
+			return nil, nil, errors.ErrInsufficientPrivilege().SetDetail("This comment was hidden by a moderator")
+		}
+	}
+
+	if opt.Pinned != nil && *opt.Pinned != comment.Data.Pinned {
+		if !isModerator {
+			return nil, nil, errors.ErrInsufficientPrivilege().SetFields(errors.Fields{"MISSING_PERMISSION": "MANAGE_CONTENT"})
+		}
+
+		if !comment.Data.ParentID.IsZero() {
+			return nil, nil, errors.ErrInvalidRequest().SetDetail("Replies cannot be pinned")
+		}
+	}
+
+	if opt.Hidden != nil && *opt.Hidden != comment.Data.Hidden {
+		comment.Data.Hidden = *opt.Hidden
+
+		if comment.Data.Hidden {
+			comment.Data.HiddenBy = actor.ID
+			set["data.hidden"] = true
+			set["data.hidden_by"] = actor.ID
+		} else {
+			comment.Data.HiddenBy = primitive.NilObjectID
+			unset["data.hidden"] = ""
+			unset["data.hidden_by"] = ""
+		}
+	}
+
+	if opt.Pinned != nil && *opt.Pinned != comment.Data.Pinned {
+		comment.Data.Pinned = *opt.Pinned
+		set["data.pinned"] = comment.Data.Pinned
+	}
+
+	return set, unset, nil
+}
+
+func (m *Mutate) emoteComment(ctx context.Context, id primitive.ObjectID) (query.EmoteComment, error) {
+	comment := query.EmoteComment{}
+
+	if err := m.mongo.Collection(mongo.CollectionNameMessages).FindOne(ctx, bson.M{
+		"_id":  id,
+		"kind": structures.MessageKindEmoteComment,
+	}).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return comment, errors.ErrUnknownMessage()
+		}
+
+		return comment, errors.ErrInternalServerError()
+	}
+
+	return comment, nil
+}
+
+func (m *Mutate) writeEmoteCommentAuditLog(ctx context.Context, comment query.EmoteComment, actor structures.User, key string, old any, new any) {
+	alb := structures.NewAuditLogBuilder(structures.AuditLog{}).
+		SetKind(structures.AuditLogKindUpdateEmote).
+		SetActor(actor.ID).
+		SetTargetKind(structures.ObjectKindEmote).
+		SetTargetID(comment.Data.EmoteID).
+		AddChanges(structures.NewAuditChange(key).WriteSingleValues(old, new))
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameAuditLogs).InsertOne(ctx, alb.AuditLog); err != nil {
+		zap.S().Errorw("failed to write audit log", "error", err)
+	}
+}
+
+// dispatchEmoteComment notifies clients watching an emote of a change to its comments
+func (m *Mutate) dispatchEmoteComment(ctx context.Context, emoteID primitive.ObjectID, actor structures.User, fn func(cm *events.ChangeMap)) {
+	cm := events.ChangeMap{
+		ID:    emoteID,
+		Kind:  structures.ObjectKindEmote,
+		Actor: m.modelizer.User(actor).ToPartial(),
+	}
+
+	fn(&cm)
+
+	m.events.Dispatch(ctx, events.EventTypeUpdateEmote, cm, events.EventCondition{
+		"object_id": emoteID.Hex(),
+	})
+}
+
+func validateEmoteCommentContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+
+	if content == "" {
+		return content, errors.ErrInvalidRequest().SetDetail("Comment cannot be empty")
+	}
+
+	if utf8.RuneCountInString(content) > EMOTE_COMMENT_CONTENT_LIMIT {
+		return content, errors.ErrInvalidRequest().SetDetail("Comment cannot be longer than %d characters", EMOTE_COMMENT_CONTENT_LIMIT)
+	}
+
+	return content, nil
+}
+
+type EmoteCommentOptions struct {
+	Actor structures.User
+	Emote structures.Emote
+	// The comment to reply to
+	ReplyToID     primitive.ObjectID
+	Content       string
+	Authoritative bool
+}
+
+// emoteCommentState is what watchers of an emote are sent when a comment is moderated or removed
+type emoteCommentState struct {
+	ID     primitive.ObjectID `json:"id"`
+	Hidden bool               `json:"hidden"`
+	Pinned bool               `json:"pinned,omitempty"`
+}
+
+type EmoteCommentModerationOptions struct {
+	Actor  structures.User
+	Emote  structures.Emote
+	Hidden *bool
+	Pinned *bool
+}
@@ -0,0 +1,100 @@
+package mutate
+
+import (
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func newEmoteComment(emoteID primitive.ObjectID, authorID primitive.ObjectID) query.EmoteComment {
+	c := query.EmoteComment{ID: primitive.NewObjectID(), AuthorID: authorID}
+	c.Data.EmoteID = emoteID
+
+	return c
+}
+
+func TestThreadEmoteComment(t *testing.T) {
+	emoteID := primitive.NewObjectID()
+	top := newEmoteComment(emoteID, primitive.NewObjectID())
+
+	reply := newEmoteComment(emoteID, primitive.NewObjectID())
+	testutil.IsNil(t, threadEmoteComment(&reply, top), "a top-level comment can be replied to")
+	testutil.Assert(t, top.ID, reply.Data.ParentID, "a reply to a top-level comment starts its thread")
+	testutil.Assert(t, top.ID, reply.Data.ReplyToID, "a reply refers to the comment it replies to")
+
+	nested := newEmoteComment(emoteID, primitive.NewObjectID())
+	testutil.IsNil(t, threadEmoteComment(&nested, reply), "a reply can be replied to")
+	testutil.Assert(t, top.ID, nested.Data.ParentID, "a reply to a reply stays in the thread of the top-level comment")
+	testutil.Assert(t, reply.ID, nested.Data.ReplyToID, "a reply to a reply refers to that reply")
+
+	other := newEmoteComment(primitive.NewObjectID(), primitive.NewObjectID())
+	testutil.IsNotNil(t, threadEmoteComment(&other, top), "a comment on another emote cannot be replied to")
+
+	hidden := newEmoteComment(emoteID, primitive.NewObjectID())
+	hidden.Data.Hidden = true
+
+	c := newEmoteComment(emoteID, primitive.NewObjectID())
+	testutil.IsNotNil(t, threadEmoteComment(&c, hidden), "a hidden comment cannot be replied to")
+
+	deleted := newEmoteComment(emoteID, primitive.NewObjectID())
+	deleted.Data.Deleted = true
+
+	c = newEmoteComment(emoteID, primitive.NewObjectID())
+	testutil.IsNotNil(t, threadEmoteComment(&c, deleted), "a deleted comment cannot be replied to")
+}
+
+func TestModerateEmoteComment(t *testing.T) {
+	owner := structures.User{ID: primitive.NewObjectID()}
+	moderator := structures.User{
+		ID:    primitive.NewObjectID(),
+		Roles: []structures.Role{{Allowed: structures.RolePermissionManageContent}},
+	}
+	stranger := structures.User{ID: primitive.NewObjectID()}
+	emote := structures.Emote{ID: primitive.NewObjectID(), OwnerID: owner.ID}
+	yes, no := true, false
+
+	c := newEmoteComment(emote.ID, stranger.ID)
+	_, _, err := moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: stranger, Emote: emote, Hidden: &yes})
+	testutil.IsNotNil(t, err, "a user cannot hide comments on the emote of another user")
+	testutil.Assert(t, false, c.Data.Hidden, "a refused moderation leaves the comment as is")
+
+	set, _, err := moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: owner, Emote: emote, Hidden: &yes})
+	testutil.IsNil(t, err, "the owner can hide comments on their emote")
+	testutil.Assert(t, true, c.Data.Hidden, "the comment is hidden")
+	testutil.Assert(t, owner.ID, c.Data.HiddenBy, "the comment was hidden by the owner")
+	testutil.Assert(t, true, set["data.hidden"] == true, "the comment is hidden in the database")
+
+	_, unset, err := moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: owner, Emote: emote, Hidden: &no})
+	testutil.IsNil(t, err, "the owner can unhide a comment they hid")
+	testutil.Assert(t, false, c.Data.Hidden, "the comment is visible again")
+	_, ok := unset["data.hidden"]
+	testutil.Assert(t, true, ok, "the comment is visible again in the database")
+
+	_, _, err = moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: moderator, Emote: emote, Hidden: &yes})
+	testutil.IsNil(t, err, "a moderator can hide any comment")
+
+	_, _, err = moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: owner, Emote: emote, Hidden: &no})
+	testutil.IsNotNil(t, err, "the owner cannot unhide a comment hidden by a moderator")
+	testutil.Assert(t, true, c.Data.Hidden, "the comment stays hidden")
+
+	_, _, err = moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: owner, Emote: emote, Pinned: &yes})
+	testutil.IsNotNil(t, err, "the owner cannot pin comments")
+
+	set, _, err = moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: moderator, Emote: emote, Pinned: &yes})
+	testutil.IsNil(t, err, "a moderator can pin comments")
+	testutil.Assert(t, true, set["data.pinned"] == true, "the comment is pinned in the database")
+
+	reply := newEmoteComment(emote.ID, stranger.ID)
+	reply.Data.ParentID = c.ID
+
+	_, _, err = moderateEmoteComment(&reply, EmoteCommentModerationOptions{Actor: moderator, Emote: emote, Pinned: &yes})
+	testutil.IsNotNil(t, err, "replies cannot be pinned")
+
+	other := structures.Emote{ID: primitive.NewObjectID(), OwnerID: owner.ID}
+	_, _, err = moderateEmoteComment(&c, EmoteCommentModerationOptions{Actor: moderator, Emote: other, Hidden: &no})
+	testutil.IsNotNil(t, err, "a comment is only moderated through its emote")
+}
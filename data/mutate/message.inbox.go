@@ -2,13 +2,17 @@ package mutate
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/structures/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
 )
 
 func (m *Mutate) SendInboxMessage(ctx context.Context, mb *structures.MessageBuilder[structures.MessageDataInbox], opt SendInboxMessageOptions) error {
@@ -24,11 +28,29 @@ func (m *Mutate) SendInboxMessage(ctx context.Context, mb *structures.MessageBui
 		return errors.ErrInsufficientPrivilege()
 	}
 
+	// Leave out the recipients who opted out of this category of messages, unless it's important
+	recipientIDs := opt.Recipients
+
+	if !mb.Message.Data.Important {
+		ids, err := query.NotificationRecipients(ctx, m.mongo, opt.Category, query.NotificationChannelInbox, opt.Recipients)
+		if err != nil {
+			return err
+		}
+
+		recipientIDs = ids
+	}
+
+	if len(recipientIDs) == 0 {
+		mb.MarkAsTainted()
+
+		return nil
+	}
+
 	// Find recipients
 	recipients := []*structures.User{}
 	cur, err := m.mongo.Collection(mongo.CollectionNameUsers).Find(ctx, bson.M{
 		"$and": func() bson.A {
-			a := bson.A{bson.M{"_id": bson.M{"$in": opt.Recipients}}}
+			a := bson.A{bson.M{"_id": bson.M{"$in": recipientIDs}}}
 			if opt.ConsiderBlockedUsers { // omit blocked users from recipients?
 				a = append(a, bson.M{"blocked_user_ids": bson.M{"$not": bson.M{"$eq": actor.ID}}})
 			}
@@ -79,11 +101,49 @@ func (m *Mutate) SendInboxMessage(ctx context.Context, mb *structures.MessageBui
 	mb.Message.ID = msgID
 	mb.MarkAsTainted()
 
+	m.whisperInboxMessage(ctx, mb.Message, opt.Category, recipients)
+
 	return nil
 }
 
+// whisperInboxMessage notifies the recipients of a message who are connected to the Event API
+// and opted into whispers for the category of the message, even if it is important
+func (m *Mutate) whisperInboxMessage(ctx context.Context, msg structures.Message[structures.MessageDataInbox], category query.NotificationCategory, recipients []*structures.User) {
+	ids := make([]primitive.ObjectID, len(recipients))
+	for i, u := range recipients {
+		ids[i] = u.ID
+	}
+
+	ids, err := query.NotificationRecipients(ctx, m.mongo, category, query.NotificationChannelWhisper, ids)
+	if err != nil {
+		zap.S().Warnw("failed to find whisper recipients of inbox message", "error", err)
+
+		return
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	obj, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	// Dispatched separately for each recipient, so that they don't learn about each other
+	for _, id := range ids {
+		m.events.Dispatch(ctx, events.EventTypeWhisper, events.ChangeMap{
+			ID:     msg.ID,
+			Kind:   structures.ObjectKindMessage,
+			Object: obj,
+		}, events.EventCondition{}.SetObjectID(id))
+	}
+}
+
 type SendInboxMessageOptions struct {
 	Actor                *structures.User
 	Recipients           []primitive.ObjectID
 	ConsiderBlockedUsers bool
+	// The category of the message, which recipients may have opted out of
+	Category query.NotificationCategory
 }
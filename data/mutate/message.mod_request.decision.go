@@ -0,0 +1,379 @@
+package mutate
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const MOD_REQUEST_DECISION_NOTE_LIMIT = 500
+
+// ModRequestClaim is the lock held by a moderator reviewing a mod request
+type ModRequestClaim struct {
+	MessageID   primitive.ObjectID `json:"message_id" bson:"message_id"`
+	ModeratorID primitive.ObjectID `json:"claimed_by" bson:"claimed_by"`
+	ClaimedAt   time.Time          `json:"claimed_at" bson:"claimed_at"`
+	ExpireAt    time.Time          `json:"claim_expire_at" bson:"claim_expire_at"`
+}
+
+// modRequestReadState is the read state of a mod request, along with its claim
+type modRequestReadState struct {
+	structures.MessageRead `bson:",inline"`
+	ClaimedBy              primitive.ObjectID `bson:"claimed_by,omitempty"`
+	ClaimedAt              time.Time          `bson:"claimed_at,omitempty"`
+	ClaimExpireAt          time.Time          `bson:"claim_expire_at,omitempty"`
+}
+
+// ClaimModRequest locks a pending mod request to the actor for the duration of the timeout,
+// so that other moderators don't review it at the same time. Claiming again extends the lock
+func (m *Mutate) ClaimModRequest(ctx context.Context, actor structures.User, messageID primitive.ObjectID, timeout time.Duration) (ModRequestClaim, error) {
+	claim := ModRequestClaim{}
+
+	if _, err := m.fetchModRequest(ctx, actor, messageID); err != nil {
+		return claim, err
+	}
+
+	now := time.Now()
+
+	rs := modRequestReadState{}
+	if err := m.mongo.Collection(mongo.CollectionNameMessagesRead).FindOneAndUpdate(ctx, bson.M{
+		"message_id": messageID,
+		"read":       false,
+		"$or": bson.A{
+			bson.M{"claimed_by": bson.M{"$exists": false}},
+			bson.M{"claimed_by": actor.ID},
+			bson.M{"claim_expire_at": bson.M{"$lt": now}},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"claimed_by":      actor.ID,
+			"claimed_at":      now,
+			"claim_expire_at": now.Add(timeout),
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&rs); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return claim, m.modRequestUnavailable(ctx, messageID)
+		}
+
+		zap.S().Errorw("mongo, failed to claim mod request", "error", err)
+
+		return claim, errors.ErrInternalServerError()
+	}
+
+	return ModRequestClaim{
+		MessageID:   messageID,
+		ModeratorID: rs.ClaimedBy,
+		ClaimedAt:   rs.ClaimedAt,
+		ExpireAt:    rs.ClaimExpireAt,
+	}, nil
+}
+
+// ReleaseModRequest gives up the actor's claim on a mod request
+func (m *Mutate) ReleaseModRequest(ctx context.Context, actor structures.User, messageID primitive.ObjectID) error {
+	if _, err := m.fetchModRequest(ctx, actor, messageID); err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"message_id": messageID,
+		"read":       false,
+	}
+
+	// Stack managers may release the claims of other moderators
+	if !actor.HasPermission(structures.RolePermissionManageStack) {
+		filter["claimed_by"] = actor.ID
+	}
+
+	res, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).UpdateOne(ctx, filter, bson.M{
+		"$unset": bson.M{
+			"claimed_by":      "",
+			"claimed_at":      "",
+			"claim_expire_at": "",
+		},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to release mod request", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if res.MatchedCount == 0 {
+		return errors.ErrInvalidRequest().SetDetail("You have not claimed this request")
+	}
+
+	return nil
+}
+
+type ModRequestDecisionOptions struct {
+	Actor     structures.User
+	MessageID primitive.ObjectID
+	Kind      query.ModRequestDecisionKind
+	ReasonID  string
+	Note      string
+}
+
+// DecideModRequest approves or denies a mod request claimed by the actor.
+//
+// The wish of the request is applied to the target, the request is closed,
+// and its author is notified through their inbox
+func (m *Mutate) DecideModRequest(ctx context.Context, opt ModRequestDecisionOptions) (query.ModRequestDecision, error) {
+	actor := opt.Actor
+	decision := query.ModRequestDecision{}
+
+	msg, err := m.fetchModRequest(ctx, actor, opt.MessageID)
+	if err != nil {
+		return decision, err
+	}
+
+	req := msg.Data
+
+	// Validate the reason
+	reason, ok := query.ModRequestReasonByID(opt.ReasonID)
+	if !ok {
+		return decision, errors.ErrInvalidRequest().SetDetail("Unknown reason")
+	}
+
+	if reason.Kind != opt.Kind {
+		return decision, errors.ErrInvalidRequest().SetDetail("This reason cannot be given for this decision")
+	}
+
+	if !reason.AppliesTo(req.Wish) {
+		return decision, errors.ErrInvalidRequest().SetDetail("This reason cannot be given for this request")
+	}
+
+	if reason.Note && opt.Note == "" {
+		return decision, errors.ErrInvalidRequest().SetDetail("A note is required with this reason")
+	}
+
+	if len(opt.Note) > MOD_REQUEST_DECISION_NOTE_LIMIT {
+		return decision, errors.ErrInvalidRequest().SetDetail("Note is too long (%d characters max)", MOD_REQUEST_DECISION_NOTE_LIMIT)
+	}
+
+	// Only decisions on emotes can be applied
+	if req.TargetKind != structures.ObjectKindEmote {
+		return decision, errors.ErrInvalidRequest().SetDetail("Decisions on this kind of request are not supported")
+	}
+
+	// Close the request. This only succeeds while the actor holds the claim,
+	// which also ensures that a request cannot be decided twice
+	now := time.Now()
+
+	rs := modRequestReadState{}
+	if err := m.mongo.Collection(mongo.CollectionNameMessagesRead).FindOneAndUpdate(ctx, bson.M{
+		"message_id":      opt.MessageID,
+		"read":            false,
+		"claimed_by":      actor.ID,
+		"claim_expire_at": bson.M{"$gte": now},
+	}, bson.M{
+		"$set": bson.M{
+			"read":    true,
+			"read_at": now,
+		},
+	}).Decode(&rs); err != nil {
+		if err == mongo.ErrNoDocuments {
+			if err := m.modRequestUnavailable(ctx, opt.MessageID); err != nil {
+				return decision, err
+			}
+
+			return decision, errors.ErrInsufficientPrivilege().SetDetail("You must claim this request before deciding on it")
+		}
+
+		zap.S().Errorw("mongo, failed to close mod request", "error", err)
+
+		return decision, errors.ErrInternalServerError()
+	}
+
+	approve := opt.Kind == query.ModRequestDecisionApprove
+
+	decision = query.ModRequestDecision{
+		ID:          primitive.NewObjectIDFromTimestamp(now),
+		MessageID:   opt.MessageID,
+		TargetKind:  req.TargetKind,
+		TargetID:    req.TargetID,
+		Wish:        req.Wish,
+		Kind:        opt.Kind,
+		ReasonID:    reason.ID,
+		Note:        opt.Note,
+		ModeratorID: actor.ID,
+		RequestedAt: msg.CreatedAt,
+		ClaimedAt:   rs.ClaimedAt,
+		DecidedAt:   now,
+	}
+
+	// The decision is recorded before it is applied, so that every applied decision has a record
+	if _, err := m.mongo.Collection(query.CollectionNameModRequestDecisions).InsertOne(ctx, decision); err != nil {
+		zap.S().Errorw("mongo, failed to record mod request decision", "error", err)
+
+		m.reopenModRequest(ctx, rs)
+
+		return query.ModRequestDecision{}, errors.ErrInternalServerError()
+	}
+
+	emote, err := m.applyEmoteModRequestDecision(ctx, actor, req, approve)
+	if err != nil {
+		if _, err := m.mongo.Collection(query.CollectionNameModRequestDecisions).DeleteOne(ctx, bson.M{"_id": decision.ID}); err != nil {
+			zap.S().Errorw("mongo, failed to remove mod request decision", "error", err)
+		}
+
+		m.reopenModRequest(ctx, rs)
+
+		return query.ModRequestDecision{}, err
+	}
+
+	// Let the author of the request know about the decision
+	recipientID := utils.Ternary(msg.AuthorID.IsZero(), emote.OwnerID, msg.AuthorID)
+	if !recipientID.IsZero() {
+		outcome := utils.Ternary(approve, "approved", "denied")
+
+		mb := structures.NewMessageBuilder(structures.Message[structures.MessageDataInbox]{}).
+			SetKind(structures.MessageKindInbox).
+			SetAuthorID(actor.ID).
+			SetTimestamp(now).
+			SetAnonymous(true).
+			SetData(structures.MessageDataInbox{
+				Subject: "inbox.generic.mod_request_" + outcome + ".subject",
+				Content: "inbox.generic.mod_request_" + outcome + ".content",
+				Locale:  true,
+				System:  true,
+				Placeholders: map[string]string{
+					"EMOTE_NAME": emote.Name,
+					"EMOTE_URL":  emote.WebURL(m.id.Web),
+					"WISH":       req.Wish,
+					"REASON":     reason.Label,
+					"NOTE":       opt.Note,
+				},
+			})
+
+		if err := m.SendInboxMessage(ctx, mb, SendInboxMessageOptions{
+			Actor:      &actor,
+			Recipients: []primitive.ObjectID{recipientID},
+			Category:   query.NotificationCategoryModRequests,
+		}); err != nil {
+			zap.S().Warnw("failed to notify author of mod request decision", "error", err)
+		}
+	}
+
+	return decision, nil
+}
+
+// reopenModRequest reverts the closing of a mod request, restoring its claim, so that the decision can be retried
+func (m *Mutate) reopenModRequest(ctx context.Context, rs modRequestReadState) {
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).UpdateOne(ctx, bson.M{
+		"_id": rs.ID,
+	}, bson.M{
+		"$set": bson.M{
+			"read":            false,
+			"claimed_by":      rs.ClaimedBy,
+			"claimed_at":      rs.ClaimedAt,
+			"claim_expire_at": rs.ClaimExpireAt,
+		},
+		"$unset": bson.M{"read_at": ""},
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to reopen mod request", "error", err)
+	}
+}
+
+// applyEmoteModRequestDecision updates the state of the emote version targeted by a mod request
+func (m *Mutate) applyEmoteModRequestDecision(ctx context.Context, actor structures.User, req structures.MessageDataModRequest, approve bool) (structures.Emote, error) {
+	emote, err := m.loaders.EmoteByID().Load(ctx, req.TargetID)
+	if err != nil {
+		return emote, err
+	}
+
+	ver, _ := emote.GetVersion(req.TargetID)
+	if ver.ID.IsZero() {
+		return emote, errors.ErrUnknownEmote()
+	}
+
+	switch req.Wish {
+	case "list":
+		ver.State.Listed = approve
+	case "personal_use":
+		ver.State.AllowPersonal = utils.PointerOf(approve)
+	default:
+		return emote, nil
+	}
+
+	eb := structures.NewEmoteBuilder(emote)
+	eb.UpdateVersion(ver.ID, ver)
+
+	if err := m.EditEmote(ctx, eb, EmoteEditOptions{
+		Actor: actor,
+	}); err != nil {
+		return emote, err
+	}
+
+	return eb.Emote, nil
+}
+
+// fetchModRequest returns a mod request the actor is allowed to review
+func (m *Mutate) fetchModRequest(ctx context.Context, actor structures.User, messageID primitive.ObjectID) (structures.Message[structures.MessageDataModRequest], error) {
+	msg := structures.Message[structures.MessageDataModRequest]{}
+
+	if actor.ID.IsZero() {
+		return msg, errors.ErrUnauthorized()
+	}
+
+	if err := m.mongo.Collection(mongo.CollectionNameMessages).FindOne(ctx, bson.M{
+		"_id":  messageID,
+		"kind": structures.MessageKindModRequest,
+	}).Decode(&msg); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return msg, errors.ErrUnknownMessage()
+		}
+
+		zap.S().Errorw("mongo, failed to find mod request", "error", err)
+
+		return msg, errors.ErrInternalServerError()
+	}
+
+	permitted := false
+
+	switch msg.Data.TargetKind {
+	case structures.ObjectKindEmote:
+		permitted = actor.HasPermission(structures.RolePermissionEditAnyEmote)
+	case structures.ObjectKindEmoteSet:
+		permitted = actor.HasPermission(structures.RolePermissionEditAnyEmoteSet)
+	case structures.ObjectKindReport:
+		permitted = actor.HasPermission(structures.RolePermissionManageReports)
+	}
+
+	if !permitted {
+		return msg, errors.ErrInsufficientPrivilege()
+	}
+
+	return msg, nil
+}
+
+// modRequestUnavailable explains why a mod request could not be claimed or decided
+func (m *Mutate) modRequestUnavailable(ctx context.Context, messageID primitive.ObjectID) error {
+	rs := modRequestReadState{}
+	if err := m.mongo.Collection(mongo.CollectionNameMessagesRead).FindOne(ctx, bson.M{
+		"message_id": messageID,
+	}).Decode(&rs); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrUnknownMessage()
+		}
+
+		return errors.ErrInternalServerError()
+	}
+
+	if rs.Read {
+		return errors.ErrInvalidRequest().SetDetail("This request has already been handled")
+	}
+
+	if !rs.ClaimedBy.IsZero() && rs.ClaimExpireAt.After(time.Now()) {
+		return errors.ErrInvalidRequest().SetDetail("This request was claimed by another moderator until %s", rs.ClaimExpireAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
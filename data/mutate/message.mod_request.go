@@ -9,6 +9,7 @@ import (
 	"github.com/seventv/common/structures/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
 )
 
 func (m *Mutate) SendModRequestMessage(ctx context.Context, mb *structures.MessageBuilder[structures.MessageDataModRequest], weight int32) error {
@@ -67,3 +68,131 @@ func (m *Mutate) SendModRequestMessage(ctx context.Context, mb *structures.Messa
 
 	return err
 }
+
+// DismissVoidTargetModRequests closes the pending mod requests whose target no longer exists or is unavailable
+func (m *Mutate) DismissVoidTargetModRequests(ctx context.Context, targetKind structures.ObjectKind) (int, error) {
+	// Only open requests are checked, starting from their read states
+	cur, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).Aggregate(ctx, mongo.Pipeline{
+		{{
+			Key: "$match",
+			Value: bson.M{
+				"kind": structures.MessageKindModRequest,
+				"read": false,
+			},
+		}},
+		{{
+			Key: "$lookup",
+			Value: mongo.Lookup{
+				From:         mongo.CollectionNameMessages,
+				LocalField:   "message_id",
+				ForeignField: "_id",
+				As:           "message",
+			},
+		}},
+		{{Key: "$unwind", Value: "$message"}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$message"}}},
+		{{
+			Key: "$match",
+			Value: bson.M{
+				"data.target_kind": targetKind,
+			},
+		}},
+		{{
+			Key: "$lookup",
+			Value: mongo.Lookup{
+				From: map[structures.ObjectKind]mongo.CollectionName{
+					structures.ObjectKindUser:  mongo.CollectionNameUsers,
+					structures.ObjectKindEmote: mongo.CollectionNameEmotes,
+				}[targetKind],
+				LocalField: "data.target_id",
+				ForeignField: map[structures.ObjectKind]string{
+					structures.ObjectKindUser:  "_id",
+					structures.ObjectKindEmote: "versions.id",
+				}[targetKind],
+				As: "_target",
+			},
+		}},
+		{{
+			Key: "$set",
+			Value: bson.M{
+				"data.target": bson.M{"$arrayElemAt": bson.A{"$_target", 0}},
+			},
+		}},
+		{{Key: "$unset", Value: bson.A{"_target"}}},
+	})
+	if err != nil {
+		zap.S().Errorw("failed to fetch mod requests", "error", err)
+
+		return 0, errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	reqs := []structures.Message[structures.MessageDataModRequest]{}
+
+	if err := cur.All(ctx, &reqs); err != nil {
+		zap.S().Errorw("failed to fetch mod requests", "error", err)
+
+		return 0, errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	w := []mongo.WriteModel{}
+
+	switch targetKind {
+	case structures.ObjectKindEmote:
+		for _, req := range reqs {
+			emote := structures.Emote{}
+
+			if err := bson.Unmarshal(req.Data.Target, &emote); err != nil {
+				continue
+			}
+
+			ver, _ := emote.GetVersion(req.Data.TargetID)
+			if !ver.ID.IsZero() && !ver.IsUnavailable() {
+				continue
+			}
+
+			// Only close requests attached to an emote in an unavailable state
+			w = append(w, &mongo.UpdateOneModel{
+				Filter: bson.M{"message_id": req.ID, "read": false},
+				Update: bson.M{
+					"$set": bson.M{"read": true, "_manual_dismiss": 1},
+				},
+			})
+		}
+	}
+
+	if len(w) == 0 {
+		return 0, nil
+	}
+
+	zap.S().Infow("dismissing mod requests", "count", len(w))
+
+	res, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).BulkWrite(ctx, w)
+	if err != nil {
+		zap.S().Errorw("failed to dismiss mod requests", "error", err)
+
+		return 0, errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	return int(res.ModifiedCount), nil
+}
+
+// ReleaseExpiredModRequestClaims removes the claims of pending mod requests whose lock has timed out
+func (m *Mutate) ReleaseExpiredModRequestClaims(ctx context.Context) (int, error) {
+	res, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).UpdateMany(ctx, bson.M{
+		"kind":            structures.MessageKindModRequest,
+		"claim_expire_at": bson.M{"$lt": time.Now()},
+	}, bson.M{
+		"$unset": bson.M{
+			"claimed_by":      "",
+			"claimed_at":      "",
+			"claim_expire_at": "",
+		},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to release expired mod request claims", "error", err)
+
+		return 0, errors.ErrInternalServerError()
+	}
+
+	return int(res.ModifiedCount), nil
+}
@@ -0,0 +1,245 @@
+package mutate
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+const (
+	NEWS_TITLE_LIMIT   = 100
+	NEWS_CONTENT_LIMIT = 4000
+)
+
+type NewsPostOptions struct {
+	Actor structures.User
+	Data  query.NewsPostData
+}
+
+// CreateNewsPost publishes a news post. It is announced once its start time is reached
+func (m *Mutate) CreateNewsPost(ctx context.Context, opt NewsPostOptions) (query.NewsPost, error) {
+	post := query.NewsPost{}
+
+	if !opt.Actor.HasPermission(structures.RolePermissionManageContent) {
+		return post, errors.ErrInsufficientPrivilege()
+	}
+
+	if err := validateNewsPostData(&opt.Data); err != nil {
+		return post, err
+	}
+
+	post = query.NewsPost{
+		ID:        primitive.NewObjectIDFromTimestamp(time.Now()),
+		Kind:      structures.MessageKindNews,
+		AuthorID:  opt.Actor.ID,
+		CreatedAt: time.Now(),
+		Data:      opt.Data,
+	}
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessages).InsertOne(ctx, post); err != nil {
+		zap.S().Errorw("mongo, failed to create news post", "error", err)
+
+		return post, errors.ErrInternalServerError()
+	}
+
+	if post.IsLive(time.Now()) {
+		if _, err := m.DispatchLiveNews(ctx); err != nil {
+			zap.S().Warnw("failed to announce news post", "error", err)
+		}
+	}
+
+	return post, nil
+}
+
+// EditNewsPost replaces the content, schedule and audience of a news post.
+// The post is announced again, now if it is live or otherwise once it goes live
+func (m *Mutate) EditNewsPost(ctx context.Context, id primitive.ObjectID, opt NewsPostOptions) (query.NewsPost, error) {
+	post := query.NewsPost{}
+
+	if !opt.Actor.HasPermission(structures.RolePermissionManageContent) {
+		return post, errors.ErrInsufficientPrivilege()
+	}
+
+	if err := validateNewsPostData(&opt.Data); err != nil {
+		return post, err
+	}
+
+	if err := m.mongo.Collection(mongo.CollectionNameMessages).FindOneAndUpdate(ctx, bson.M{
+		"_id":  id,
+		"kind": structures.MessageKindNews,
+	}, bson.M{
+		"$set": bson.M{
+			"data.title":      opt.Data.Title,
+			"data.content":    opt.Data.Content,
+			"data.image_url":  opt.Data.ImageURL,
+			"data.important":  opt.Data.Important,
+			"data.starts_at":  opt.Data.StartsAt,
+			"data.ends_at":    opt.Data.EndsAt,
+			"data.audience":   opt.Data.Audience,
+			"data.dispatched": false,
+		},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return post, errors.ErrUnknownMessage()
+		}
+
+		zap.S().Errorw("mongo, failed to edit news post", "error", err)
+
+		return post, errors.ErrInternalServerError()
+	}
+
+	if post.IsLive(time.Now()) {
+		if _, err := m.DispatchLiveNews(ctx); err != nil {
+			zap.S().Warnw("failed to announce news post", "error", err)
+		}
+	}
+
+	return post, nil
+}
+
+// DeleteNewsPost removes a news post and its dismissals
+func (m *Mutate) DeleteNewsPost(ctx context.Context, actor structures.User, id primitive.ObjectID) error {
+	if !actor.HasPermission(structures.RolePermissionManageContent) {
+		return errors.ErrInsufficientPrivilege()
+	}
+
+	res, err := m.mongo.Collection(mongo.CollectionNameMessages).DeleteOne(ctx, bson.M{
+		"_id":  id,
+		"kind": structures.MessageKindNews,
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to delete news post", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if res.DeletedCount == 0 {
+		return errors.ErrUnknownMessage()
+	}
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).DeleteMany(ctx, bson.M{
+		"message_id": id,
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to delete news post read states", "error", err)
+	}
+
+	return nil
+}
+
+// DismissNewsPost hides a news post for the actor
+func (m *Mutate) DismissNewsPost(ctx context.Context, actor structures.User, id primitive.ObjectID, dismissed bool) error {
+	if actor.ID.IsZero() {
+		return errors.ErrUnauthorized()
+	}
+
+	if err := m.mongo.Collection(mongo.CollectionNameMessages).FindOne(ctx, bson.M{
+		"_id":  id,
+		"kind": structures.MessageKindNews,
+	}).Err(); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.ErrUnknownMessage()
+		}
+
+		return errors.ErrInternalServerError()
+	}
+
+	now := time.Now()
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameMessagesRead).UpdateOne(ctx, bson.M{
+		"message_id":   id,
+		"recipient_id": actor.ID,
+	}, bson.M{
+		"$set": bson.M{
+			"read":    dismissed,
+			"read_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"kind":      structures.MessageKindNews,
+			"timestamp": now,
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		zap.S().Errorw("mongo, failed to dismiss news post", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// DispatchLiveNews announces the news posts which have gone live to the Event API.
+//
+// Each post is claimed before being dispatched, so that it is announced only once across replicas
+func (m *Mutate) DispatchLiveNews(ctx context.Context) (int, error) {
+	now := time.Now()
+	count := 0
+
+	for {
+		post := query.NewsPost{}
+
+		if err := m.mongo.Collection(mongo.CollectionNameMessages).FindOneAndUpdate(ctx, bson.M{
+			"kind":            structures.MessageKindNews,
+			"data.dispatched": bson.M{"$ne": true},
+			"data.starts_at":  bson.M{"$lte": now},
+			"$or": bson.A{
+				bson.M{"data.ends_at": nil},
+				bson.M{"data.ends_at": bson.M{"$gt": now}},
+			},
+		}, bson.M{
+			"$set": bson.M{"data.dispatched": true},
+		}).Decode(&post); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return count, nil
+			}
+
+			zap.S().Errorw("mongo, failed to claim live news post", "error", err)
+
+			return count, errors.ErrInternalServerError()
+		}
+
+		// Only the ID is announced, as the post may be meant for a specific audience.
+		// Clients fetch its content through Query.news, which checks the audience
+		m.events.Dispatch(ctx, events.EventTypeSystemAnnouncement, events.ChangeMap{
+			ID:   post.ID,
+			Kind: structures.ObjectKindMessage,
+		}, events.EventCondition{})
+
+		count++
+	}
+}
+
+func validateNewsPostData(d *query.NewsPostData) error {
+	if d.StartsAt.IsZero() {
+		d.StartsAt = time.Now()
+	}
+
+	switch {
+	case d.Title == "":
+		return errors.ErrInvalidRequest().SetDetail("Title is required")
+	case len(d.Title) > NEWS_TITLE_LIMIT:
+		return errors.ErrInvalidRequest().SetDetail("Title is too long (%d characters max)", NEWS_TITLE_LIMIT)
+	case len(d.Content) > NEWS_CONTENT_LIMIT:
+		return errors.ErrInvalidRequest().SetDetail("Content is too long (%d characters max)", NEWS_CONTENT_LIMIT)
+	case d.EndsAt != nil && !d.EndsAt.After(d.StartsAt):
+		return errors.ErrInvalidRequest().SetDetail("The end time must be after the start time")
+	}
+
+	if d.ImageURL != "" {
+		if u, err := url.Parse(d.ImageURL); err != nil || u.Scheme != "https" {
+			return errors.ErrInvalidRequest().SetDetail("Image must be an https URL")
+		}
+	}
+
+	d.Dispatched = false
+
+	return nil
+}
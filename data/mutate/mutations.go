@@ -6,6 +6,7 @@ import (
 	"github.com/seventv/api/data/events"
 	"github.com/seventv/api/data/model"
 	"github.com/seventv/api/internal/loaders"
+	"github.com/seventv/api/internal/svc/prometheus"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/redis"
 	"github.com/seventv/common/svc"
@@ -22,11 +23,14 @@ type Mutate struct {
 	modelizer model.Modelizer
 	events    events.Instance
 	cd        compactdisc.Instance
+	prom      prometheus.Instance
 	mx        map[string]*sync.Mutex
+	// Origin dependent notifications waiting for a worker
+	originNotify chan originNotification
 }
 
 func New(opt InstanceOptions) *Mutate {
-	return &Mutate{
+	m := &Mutate{
 		id:        opt.ID,
 		mongo:     opt.Mongo,
 		loaders:   opt.Loaders,
@@ -35,17 +39,27 @@ func New(opt InstanceOptions) *Mutate {
 		modelizer: opt.Modelizer,
 		events:    opt.Events,
 		cd:        opt.CD,
+		prom:      opt.Prometheus,
 		mx:        map[string]*sync.Mutex{},
+
+		originNotify: make(chan originNotification, ORIGIN_NOTIFY_QUEUE_SIZE),
+	}
+
+	for i := 0; i < ORIGIN_NOTIFY_CONCURRENCY; i++ {
+		go m.runOriginNotifications()
 	}
+
+	return m
 }
 
 type InstanceOptions struct {
-	ID        svc.AppIdentity
-	Mongo     mongo.Instance
-	Loaders   loaders.Instance
-	Redis     redis.Instance
-	S3        s3.Instance
-	Modelizer model.Modelizer
-	Events    events.Instance
-	CD        compactdisc.Instance
+	ID         svc.AppIdentity
+	Mongo      mongo.Instance
+	Loaders    loaders.Instance
+	Redis      redis.Instance
+	S3         s3.Instance
+	Modelizer  model.Modelizer
+	Events     events.Instance
+	CD         compactdisc.Instance
+	Prometheus prometheus.Instance
 }
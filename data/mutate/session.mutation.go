@@ -0,0 +1,113 @@
+package mutate
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/query"
+)
+
+// CreateSession records a new login
+func (m *Mutate) CreateSession(ctx context.Context, session query.Session) error {
+	if session.ID.IsZero() || session.UserID.IsZero() {
+		return errors.ErrMissingRequiredField()
+	}
+
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+
+	session.LastSeenAt = session.CreatedAt
+
+	if _, err := m.mongo.Collection(query.CollectionNameSessions).InsertOne(ctx, session); err != nil {
+		zap.S().Errorw("mongo, failed to create session", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// SetSessionLocation updates the region a session is seen from
+func (m *Mutate) SetSessionLocation(ctx context.Context, sessionID primitive.ObjectID, countryCode, countryName string) error {
+	if _, err := m.mongo.Collection(query.CollectionNameSessions).UpdateOne(ctx, bson.M{
+		"_id": sessionID,
+	}, bson.M{
+		"$set": bson.M{
+			"country_code": countryCode,
+			"country_name": countryName,
+		},
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to update session location", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// TouchSession refreshes the last-seen time of a session, at most once per interval
+func (m *Mutate) TouchSession(ctx context.Context, sessionID primitive.ObjectID) error {
+	k := m.redis.ComposeKey("api", "sessions", "touched", sessionID.Hex())
+
+	ok, err := m.redis.RawClient().SetNX(ctx, k.String(), 1, query.SESSION_TOUCH_INTERVAL).Result()
+	if err != nil || !ok {
+		return err
+	}
+
+	if _, err := m.mongo.Collection(query.CollectionNameSessions).UpdateOne(ctx, bson.M{
+		"_id": sessionID,
+	}, bson.M{
+		"$set": bson.M{"last_seen_at": time.Now()},
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to touch session", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// RevokeSession signs out a session. Its access token is rejected from then on
+func (m *Mutate) RevokeSession(ctx context.Context, actor structures.User, session query.Session) error {
+	if _, err := m.mongo.Collection(query.CollectionNameSessions).DeleteOne(ctx, bson.M{
+		"_id": session.ID,
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to delete session", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	// The revocation only needs to outlive the token
+	ttl := time.Until(session.ExpireAt)
+	if ttl > 0 {
+		if err := m.redis.SetEX(ctx, query.SessionRevocationKey(m.redis, session.ID), time.Now().Unix(), ttl); err != nil {
+			zap.S().Errorw("redis, failed to write session revocation", "error", err)
+
+			return errors.ErrInternalServerError()
+		}
+	}
+
+	// Log the revocation when done on someone else's behalf
+	if actor.ID != session.UserID {
+		alb := structures.NewAuditLogBuilder(structures.AuditLog{}).
+			SetKind(structures.AuditLogKindEditUser).
+			SetActor(actor.ID).
+			SetTargetKind(structures.ObjectKindUser).
+			SetTargetID(session.UserID).
+			AddChanges(structures.NewAuditChange("sessions").WriteArrayRemoved(session.ID))
+
+		if _, err := m.mongo.Collection(mongo.CollectionNameAuditLogs).InsertOne(ctx, alb.AuditLog); err != nil {
+			zap.S().Errorw("mongo, failed to write audit log entry for session revocation", "error", err)
+		}
+	}
+
+	return nil
+}
@@ -87,7 +87,7 @@ func (m *Mutate) SetUserConnectionActiveEmoteSet(ctx context.Context, ub *struct
 		newSet.Emotes = nil // don't send the emotes with the event
 		oldSet.Emotes = nil
 
-		m.events.Dispatch(events.EventTypeUpdateUser, events.ChangeMap{
+		m.events.Dispatch(ctx, events.EventTypeUpdateUser, events.ChangeMap{
 			ID:    ub.User.ID,
 			Kind:  structures.ObjectKindUser,
 			Actor: m.modelizer.User(actor).ToPartial(),
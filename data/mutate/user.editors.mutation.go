@@ -2,11 +2,15 @@ package mutate
 
 import (
 	"context"
+	"time"
 
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.uber.org/zap"
 )
@@ -113,9 +117,45 @@ func (m *Mutate) ModifyUserEditors(ctx context.Context, ub *structures.UserBuild
 
 	ub.MarkAsTainted()
 
+	// Let the editor know, unless they made the change themselves
+	if opt.Action != structures.ListItemActionUpdate && editor.ID != actor.ID {
+		m.notifyEditorChange(ctx, actor, ub.User, editor.ID, opt.Action)
+	}
+
 	return nil
 }
 
+// notifyEditorChange sends a message to a user who was added as or removed from the editors of another user
+func (m *Mutate) notifyEditorChange(ctx context.Context, actor *structures.User, target structures.User, editorID primitive.ObjectID, action structures.ListItemAction) {
+	key := utils.Ternary(action == structures.ListItemActionAdd, "editor_added", "editor_removed")
+
+	mb := structures.NewMessageBuilder(structures.Message[structures.MessageDataInbox]{}).
+		SetKind(structures.MessageKindInbox).
+		SetAuthorID(actor.ID).
+		SetTimestamp(time.Now()).
+		SetData(structures.MessageDataInbox{
+			Subject: "inbox.generic." + key + ".subject",
+			Content: "inbox.generic." + key + ".content",
+			Locale:  true,
+			Placeholders: map[string]string{
+				"USER_DISPLAY_NAME": utils.Ternary(target.DisplayName != "", target.DisplayName, target.Username),
+			},
+		})
+
+	if err := m.SendInboxMessage(ctx, mb, SendInboxMessageOptions{
+		Actor:                actor,
+		Recipients:           []primitive.ObjectID{editorID},
+		ConsiderBlockedUsers: true,
+		Category:             query.NotificationCategoryEditors,
+	}); err != nil {
+		zap.S().Errorw("failed to send inbox message about editor change",
+			"error", err,
+			"user_id", target.ID.Hex(),
+			"editor_id", editorID.Hex(),
+		)
+	}
+}
+
 type UserEditorsOptions struct {
 	Actor             *structures.User
 	Editor            *structures.User
@@ -0,0 +1,60 @@
+package mutate
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type NotificationSettingChange struct {
+	Category query.NotificationCategory
+	Channel  query.NotificationChannel
+	Enabled  bool
+}
+
+// SetNotificationSettings opts a user in or out of categories of notifications, per channel
+func (m *Mutate) SetNotificationSettings(ctx context.Context, actor structures.User, userID primitive.ObjectID, changes []NotificationSettingChange) (query.NotificationSettings, error) {
+	settings := query.NotificationSettings{UserID: userID}
+
+	if actor.ID != userID && !actor.HasPermission(structures.RolePermissionManageUsers) {
+		return settings, errors.ErrInsufficientPrivilege()
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+
+	for _, c := range changes {
+		info, ok := query.NotificationCategoryByName(c.Category)
+		if !ok {
+			return settings, errors.ErrInvalidRequest().SetDetail("Unknown notification category '%s'", c.Category)
+		}
+
+		if _, ok := info.Defaults[c.Channel]; !ok {
+			return settings, errors.ErrInvalidRequest().SetDetail("Unknown notification channel '%s'", c.Channel)
+		}
+
+		if info.Mandatory && c.Channel == query.NotificationChannelInbox && !c.Enabled {
+			return settings, errors.ErrInvalidRequest().SetDetail("Notifications of category '%s' cannot be turned off", c.Category)
+		}
+
+		set["categories."+string(c.Category)+"."+string(c.Channel)] = c.Enabled
+	}
+
+	if err := m.mongo.Collection(query.CollectionNameNotificationSettings).FindOneAndUpdate(ctx, bson.M{
+		"_id": userID,
+	}, bson.M{
+		"$set": set,
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&settings); err != nil {
+		zap.S().Errorw("mongo, failed to update notification settings", "error", err)
+
+		return settings, errors.ErrInternalServerError()
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,184 @@
+package mutate
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/redis"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	mongod "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/query"
+)
+
+// CreateSecondFactor stores a pending TOTP secret for the actor.
+// The second factor only takes effect once confirmed with EnableSecondFactor
+func (m *Mutate) CreateSecondFactor(ctx context.Context, actor structures.User, secret string) error {
+	if actor.ID.IsZero() {
+		return errors.ErrUnauthorized()
+	}
+
+	// Do not overwrite an enrollment that is already active
+	if _, err := m.mongo.Collection(query.CollectionNameSecondFactors).UpdateOne(ctx, bson.M{
+		"_id":     actor.ID,
+		"enabled": bson.M{"$ne": true},
+	}, bson.M{
+		"$set": bson.M{
+			"secret":     secret,
+			"enabled":    false,
+			"created_at": time.Now(),
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		if mongod.IsDuplicateKeyError(err) {
+			return errors.ErrDontBeSilly().SetDetail("Second factor is already enabled")
+		}
+
+		zap.S().Errorw("mongo, failed to write second factor", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// EnableSecondFactor activates the actor's pending second factor
+func (m *Mutate) EnableSecondFactor(ctx context.Context, actor structures.User) error {
+	res, err := m.mongo.Collection(query.CollectionNameSecondFactors).UpdateOne(ctx, bson.M{
+		"_id":     actor.ID,
+		"enabled": false,
+	}, bson.M{
+		"$set": bson.M{
+			"enabled":    true,
+			"enabled_at": time.Now(),
+		},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to enable second factor", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if res.ModifiedCount == 0 {
+		return errors.ErrNothingHappened()
+	}
+
+	m.writeSecondFactorAuditLog(ctx, actor, false, true)
+
+	return nil
+}
+
+// DeleteSecondFactor removes the actor's second factor and any active verification
+func (m *Mutate) DeleteSecondFactor(ctx context.Context, actor structures.User) error {
+	if _, err := m.mongo.Collection(query.CollectionNameSecondFactors).DeleteOne(ctx, bson.M{
+		"_id": actor.ID,
+	}); err != nil {
+		zap.S().Errorw("mongo, failed to delete second factor", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if _, err := m.redis.Del(ctx, query.SecondFactorVerificationKey(m.redis, actor.ID)); err != nil {
+		zap.S().Errorw("redis, failed to clear second factor verification", "error", err)
+	}
+
+	m.writeSecondFactorAuditLog(ctx, actor, true, false)
+
+	return nil
+}
+
+// SetSecondFactorVerified marks the actor as having passed a second-factor check for the given duration
+func (m *Mutate) SetSecondFactorVerified(ctx context.Context, actor structures.User, ttl time.Duration) error {
+	if err := m.redis.SetEX(ctx, query.SecondFactorVerificationKey(m.redis, actor.ID), time.Now().Unix(), ttl); err != nil {
+		zap.S().Errorw("redis, failed to write second factor verification", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
+
+// RecordSecondFactorFailure counts an invalid code entered by the actor, locking them out after too many of them
+func (m *Mutate) RecordSecondFactorFailure(ctx context.Context, actor structures.User) {
+	k := query.SecondFactorAttemptsKey(m.redis, actor.ID)
+
+	n, err := m.redis.IncrBy(ctx, k, 1)
+	if err != nil {
+		zap.S().Errorw("redis, failed to count second factor attempt", "error", err)
+
+		return
+	}
+
+	// The window starts at the first invalid code
+	if n == 1 {
+		if err := m.redis.Expire(ctx, k, query.SECOND_FACTOR_LOCKOUT); err != nil {
+			zap.S().Errorw("redis, failed to expire second factor attempts", "error", err)
+		}
+	}
+}
+
+// ConsumeSecondFactorCode accepts a valid code of the given time step, so that neither it nor an older code can be used again.
+// It also clears the count of invalid codes of the actor
+func (m *Mutate) ConsumeSecondFactorCode(ctx context.Context, actor structures.User, step int64) error {
+	// The step is remembered for longer than a code stays valid
+	ttl := 5 * time.Minute
+	lastKey := query.SecondFactorLastStepKey(m.redis, actor.ID)
+
+	// A code older than the last accepted one is rejected
+	last, err := m.redis.Get(ctx, lastKey)
+	if err != nil && err != redis.Nil {
+		zap.S().Errorw("redis, failed to read last second factor code", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if n, err := strconv.ParseInt(last, 10, 64); err == nil && step < n {
+		return errors.ErrInvalidRequest().SetDetail("Code was already used")
+	}
+
+	// The step is claimed atomically, so that concurrent requests with the same code cannot both pass
+	k := query.SecondFactorStepKey(m.redis, actor.ID, step)
+
+	n, err := m.redis.IncrBy(ctx, k, 1)
+	if err != nil {
+		zap.S().Errorw("redis, failed to record second factor code", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	if n > 1 {
+		return errors.ErrInvalidRequest().SetDetail("Code was already used")
+	}
+
+	if err := m.redis.Expire(ctx, k, ttl); err != nil {
+		zap.S().Errorw("redis, failed to expire second factor code", "error", err)
+	}
+
+	if err := m.redis.SetEX(ctx, lastKey, step, ttl); err != nil {
+		zap.S().Errorw("redis, failed to record last second factor code", "error", err)
+	}
+
+	if _, err := m.redis.Del(ctx, query.SecondFactorAttemptsKey(m.redis, actor.ID)); err != nil {
+		zap.S().Errorw("redis, failed to clear second factor attempts", "error", err)
+	}
+
+	return nil
+}
+
+func (m *Mutate) writeSecondFactorAuditLog(ctx context.Context, actor structures.User, old, new bool) {
+	alb := structures.NewAuditLogBuilder(structures.AuditLog{}).
+		SetKind(structures.AuditLogKindEditUser).
+		SetActor(actor.ID).
+		SetTargetKind(structures.ObjectKindUser).
+		SetTargetID(actor.ID).
+		AddChanges(structures.NewAuditChange("second_factor").WriteSingleValues(old, new))
+
+	if _, err := m.mongo.Collection(mongo.CollectionNameAuditLogs).InsertOne(ctx, alb.AuditLog); err != nil {
+		zap.S().Errorw("mongo, failed to write audit log entry for second factor change", "error", err)
+	}
+}
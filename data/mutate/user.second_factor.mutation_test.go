@@ -0,0 +1,126 @@
+package mutate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seventv/common/redis"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/testutil"
+)
+
+// memoryRedis holds values in memory, ignoring their expiry
+type memoryRedis struct {
+	redis.Instance
+	values map[redis.Key]string
+}
+
+func (r *memoryRedis) ComposeKey(svc string, args ...string) redis.Key {
+	return redis.Key(fmt.Sprintf("%s:%s", svc, strings.Join(args, ":")))
+}
+
+func (r *memoryRedis) Get(ctx context.Context, key redis.Key) (string, error) {
+	v, ok := r.values[key]
+	if !ok {
+		return "", redis.Nil
+	}
+
+	return v, nil
+}
+
+func (r *memoryRedis) SetEX(ctx context.Context, key redis.Key, value interface{}, expiry time.Duration) error {
+	r.values[key] = fmt.Sprint(value)
+
+	return nil
+}
+
+func (r *memoryRedis) IncrBy(ctx context.Context, key redis.Key, amount int) (int, error) {
+	n, _ := strconv.Atoi(r.values[key])
+	n += amount
+	r.values[key] = strconv.Itoa(n)
+
+	return n, nil
+}
+
+func (r *memoryRedis) Expire(ctx context.Context, key redis.Key, expiry time.Duration) error {
+	return nil
+}
+
+func (r *memoryRedis) Del(ctx context.Context, keys ...redis.Key) (int, error) {
+	n := 0
+
+	for _, k := range keys {
+		if _, ok := r.values[k]; ok {
+			delete(r.values, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func TestSecondFactorLockout(t *testing.T) {
+	rdis := &memoryRedis{values: map[redis.Key]string{}}
+	m := &Mutate{redis: rdis}
+	q := query.New(nil, rdis, nil)
+	ctx := context.Background()
+	actor := structures.User{ID: primitive.NewObjectID()}
+
+	for i := 0; i < query.SECOND_FACTOR_MAX_ATTEMPTS-1; i++ {
+		m.RecordSecondFactorFailure(ctx, actor)
+	}
+
+	locked, err := q.SecondFactorLocked(ctx, actor.ID)
+	testutil.IsNil(t, err, "the lockout is checked")
+	testutil.Assert(t, false, locked, "a user is not locked out before the last attempt")
+
+	m.RecordSecondFactorFailure(ctx, actor)
+
+	locked, err = q.SecondFactorLocked(ctx, actor.ID)
+	testutil.IsNil(t, err, "the lockout is checked")
+	testutil.Assert(t, true, locked, "a user is locked out after too many invalid codes")
+
+	other, _ := q.SecondFactorLocked(ctx, primitive.NewObjectID())
+	testutil.Assert(t, false, other, "other users are not locked out")
+}
+
+func TestSecondFactorValidCodeClearsAttempts(t *testing.T) {
+	rdis := &memoryRedis{values: map[redis.Key]string{}}
+	m := &Mutate{redis: rdis}
+	q := query.New(nil, rdis, nil)
+	ctx := context.Background()
+	actor := structures.User{ID: primitive.NewObjectID()}
+
+	for i := 0; i < query.SECOND_FACTOR_MAX_ATTEMPTS-1; i++ {
+		m.RecordSecondFactorFailure(ctx, actor)
+	}
+
+	testutil.IsNil(t, m.ConsumeSecondFactorCode(ctx, actor, 100), "a valid code is accepted")
+
+	m.RecordSecondFactorFailure(ctx, actor)
+
+	locked, _ := q.SecondFactorLocked(ctx, actor.ID)
+	testutil.Assert(t, false, locked, "invalid codes are counted again after a valid one")
+}
+
+func TestSecondFactorCodeReuse(t *testing.T) {
+	rdis := &memoryRedis{values: map[redis.Key]string{}}
+	m := &Mutate{redis: rdis}
+	ctx := context.Background()
+	actor := structures.User{ID: primitive.NewObjectID()}
+
+	testutil.IsNil(t, m.ConsumeSecondFactorCode(ctx, actor, 100), "a code is accepted once")
+	testutil.IsNotNil(t, m.ConsumeSecondFactorCode(ctx, actor, 100), "a reused code is rejected")
+	testutil.IsNotNil(t, m.ConsumeSecondFactorCode(ctx, actor, 99), "a code older than the last one is rejected")
+	testutil.IsNil(t, m.ConsumeSecondFactorCode(ctx, actor, 101), "a newer code is accepted")
+
+	other := structures.User{ID: primitive.NewObjectID()}
+	testutil.IsNil(t, m.ConsumeSecondFactorCode(ctx, other, 100), "the code of another user is accepted")
+}
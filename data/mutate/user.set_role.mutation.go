@@ -118,7 +118,7 @@ func (m *Mutate) SetRole(ctx context.Context, ub *structures.UserBuilder, opt Se
 	}
 
 	// Emit a event
-	m.events.Dispatch(events.EventTypeUpdateUser, cm, events.EventCondition{
+	m.events.Dispatch(ctx, events.EventTypeUpdateUser, cm, events.EventCondition{
 		"object_id": ub.User.ID.Hex(),
 	})
 
@@ -0,0 +1,163 @@
+package query
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+type AuditLogFilter struct {
+	ActorID    primitive.ObjectID
+	TargetKind *structures.ObjectKind
+	TargetID   primitive.ObjectID
+	Kind       *structures.AuditLogKind
+	// only logs created at or after this time
+	Since time.Time
+	// only logs created before this time
+	Until time.Time
+	// only logs with a change to this key
+	ChangeKey string
+}
+
+func (f AuditLogFilter) toBSON() bson.M {
+	filter := bson.M{}
+
+	if !f.ActorID.IsZero() {
+		filter["actor_id"] = f.ActorID
+	}
+
+	if f.TargetKind != nil {
+		filter["target_kind"] = *f.TargetKind
+	}
+
+	if !f.TargetID.IsZero() {
+		filter["target_id"] = f.TargetID
+	}
+
+	if f.Kind != nil {
+		filter["kind"] = *f.Kind
+	}
+
+	// The creation date of a log is that of its id
+	idRange := bson.M{}
+
+	if !f.Since.IsZero() {
+		idRange["$gte"] = primitive.NewObjectIDFromTimestamp(f.Since)
+	}
+
+	if !f.Until.IsZero() {
+		idRange["$lt"] = primitive.NewObjectIDFromTimestamp(f.Until)
+	}
+
+	if len(idRange) > 0 {
+		filter["_id"] = idRange
+	}
+
+	if f.ChangeKey != "" {
+		filter["changes.key"] = f.ChangeKey
+	}
+
+	return filter
+}
+
+type AuditLogsResult struct {
+	Logs []structures.AuditLog `json:"logs"`
+	// pass as "after" to continue from the last returned log
+	Cursor primitive.ObjectID `json:"cursor"`
+	// whether more logs are available past the cursor
+	HasMore bool `json:"has_more"`
+}
+
+// AuditLogs returns the audit logs matching a filter which are older than the given cursor, newest first
+func (q *Query) AuditLogs(ctx context.Context, filter AuditLogFilter, after primitive.ObjectID, limit int) (AuditLogsResult, error) {
+	result := AuditLogsResult{
+		Logs:   []structures.AuditLog{},
+		Cursor: after,
+	}
+
+	f := filter.toBSON()
+
+	if !after.IsZero() {
+		idRange, _ := f["_id"].(bson.M)
+		if idRange == nil {
+			idRange = bson.M{}
+		}
+
+		// The cursor narrows the upper bound of the date range
+		if until, ok := idRange["$lt"].(primitive.ObjectID); !ok || bytes.Compare(after[:], until[:]) < 0 {
+			idRange["$lt"] = after
+		}
+
+		f["_id"] = idRange
+	}
+
+	cur, err := q.mongo.Collection(mongo.CollectionNameAuditLogs).Find(ctx, f,
+		options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(limit+1)),
+	)
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query audit logs", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if err = cur.All(ctx, &result.Logs); err != nil {
+		zap.S().Errorw("mongo, failed to decode audit logs", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if len(result.Logs) > limit {
+		result.HasMore = true
+		result.Logs = result.Logs[:limit]
+	}
+
+	if len(result.Logs) > 0 {
+		result.Cursor = result.Logs[len(result.Logs)-1].ID
+	}
+
+	return result, nil
+}
+
+// ExportAuditLogs calls fn with up to limit audit logs matching a filter, oldest first,
+// without holding the whole result in memory. It stops at the first error returned by fn
+func (q *Query) ExportAuditLogs(ctx context.Context, filter AuditLogFilter, limit int, fn func(structures.AuditLog) error) error {
+	cur, err := q.mongo.Collection(mongo.CollectionNameAuditLogs).Find(ctx, filter.toBSON(),
+		options.Find().SetSort(bson.M{"_id": 1}).SetBatchSize(500).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query audit logs for export", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	defer cur.Close(context.Background())
+
+	for cur.Next(ctx) {
+		l := structures.AuditLog{}
+		if err = cur.Decode(&l); err != nil {
+			zap.S().Errorw("mongo, failed to decode audit log for export", "error", err)
+
+			return errors.ErrInternalServerError()
+		}
+
+		if err = fn(l); err != nil {
+			return err
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		zap.S().Errorw("mongo, failed to export audit logs", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+var (
+	CollectionNameEmoteCollections       mongo.CollectionName = "emote_collections"
+	CollectionNameEmoteCollectionFollows mongo.CollectionName = "emote_collection_follows"
+)
+
+type EmoteCollectionKind string
+
+const (
+	// The favorite emotes of a user. Each user has at most one, created on their first favorite
+	EmoteCollectionKindFavorites EmoteCollectionKind = "FAVORITES"
+	EmoteCollectionKindCustom    EmoteCollectionKind = "CUSTOM"
+)
+
+// EmoteCollection is a named list of emotes curated by a user. Unlike emote sets,
+// collections are not activated in channels and only serve to group emotes
+type EmoteCollection struct {
+	ID            primitive.ObjectID    `json:"id" bson:"_id"`
+	OwnerID       primitive.ObjectID    `json:"owner_id" bson:"owner_id"`
+	Kind          EmoteCollectionKind   `json:"kind" bson:"kind"`
+	Name          string                `json:"name" bson:"name"`
+	Description   string                `json:"description" bson:"description"`
+	Private       bool                  `json:"private" bson:"private"`
+	Emotes        []EmoteCollectionItem `json:"emotes" bson:"emotes"`
+	FollowerCount int32                 `json:"follower_count" bson:"follower_count"`
+	CreatedAt     time.Time             `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at" bson:"updated_at"`
+}
+
+type EmoteCollectionItem struct {
+	ID      primitive.ObjectID `json:"id" bson:"id"`
+	AddedAt time.Time          `json:"added_at" bson:"added_at"`
+}
+
+// EmoteCollectionFollow is a user following the collection of another user
+type EmoteCollectionFollow struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	CollectionID primitive.ObjectID `json:"collection_id" bson:"collection_id"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	FollowedAt   time.Time          `json:"followed_at" bson:"followed_at"`
+}
+
+// EmoteIDs returns the IDs of the emotes in the collection, in the order they were added
+func (c EmoteCollection) EmoteIDs() []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, len(c.Emotes))
+	for i, e := range c.Emotes {
+		ids[i] = e.ID
+	}
+
+	return ids
+}
+
+// VisibleTo tells whether a user can see the collection. Private collections are only visible to their owner
+func (c EmoteCollection) VisibleTo(actor *structures.User) bool {
+	if !c.Private {
+		return true
+	}
+
+	return actor != nil && (actor.ID == c.OwnerID || actor.HasPermission(structures.RolePermissionManageUsers))
+}
+
+// EmoteCollection returns a collection visible to the actor
+func (q *Query) EmoteCollection(ctx context.Context, actor *structures.User, id primitive.ObjectID) (EmoteCollection, error) {
+	col := EmoteCollection{}
+
+	if err := q.mongo.Collection(CollectionNameEmoteCollections).FindOne(ctx, bson.M{"_id": id}).Decode(&col); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return col, errors.ErrNoItems().SetDetail("Unknown Collection")
+		}
+
+		zap.S().Errorw("mongo, failed to query emote collection", "error", err)
+
+		return col, errors.ErrInternalServerError()
+	}
+
+	if !col.VisibleTo(actor) {
+		return EmoteCollection{}, errors.ErrNoItems().SetDetail("Unknown Collection")
+	}
+
+	return col, nil
+}
+
+type UserEmoteCollectionsOptions struct {
+	Actor *structures.User
+	// List the collections followed by the user instead of the ones they own
+	Followed bool
+}
+
+// UserEmoteCollections returns the collections of a user which are visible to the actor, newest first
+func (q *Query) UserEmoteCollections(ctx context.Context, userID primitive.ObjectID, opt UserEmoteCollectionsOptions) ([]EmoteCollection, error) {
+	result := []EmoteCollection{}
+
+	filter := bson.M{"owner_id": userID}
+
+	if opt.Followed {
+		cur, err := q.mongo.Collection(CollectionNameEmoteCollectionFollows).Find(ctx, bson.M{
+			"user_id": userID,
+		}, options.Find().SetProjection(bson.M{"collection_id": 1}))
+		if err != nil {
+			zap.S().Errorw("mongo, failed to query emote collection follows", "error", err)
+
+			return result, errors.ErrInternalServerError()
+		}
+
+		follows := []EmoteCollectionFollow{}
+		if err = cur.All(ctx, &follows); err != nil {
+			zap.S().Errorw("mongo, failed to decode emote collection follows", "error", err)
+
+			return result, errors.ErrInternalServerError()
+		}
+
+		ids := make([]primitive.ObjectID, len(follows))
+		for i, f := range follows {
+			ids[i] = f.CollectionID
+		}
+
+		filter = bson.M{"_id": bson.M{"$in": ids}}
+	}
+
+	cur, err := q.mongo.Collection(CollectionNameEmoteCollections).Find(ctx, filter, options.Find().SetSort(bson.M{"_id": -1}))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query emote collections", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	cols := []EmoteCollection{}
+	if err = cur.All(ctx, &cols); err != nil {
+		zap.S().Errorw("mongo, failed to decode emote collections", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	for _, c := range cols {
+		if c.VisibleTo(opt.Actor) {
+			result = append(result, c)
+		}
+	}
+
+	return result, nil
+}
+
+// EmoteCollectionFollows returns the follows of the given users among the given collections
+func (q *Query) EmoteCollectionFollows(ctx context.Context, userIDs []primitive.ObjectID, collectionIDs []primitive.ObjectID) ([]EmoteCollectionFollow, error) {
+	result := []EmoteCollectionFollow{}
+
+	cur, err := q.mongo.Collection(CollectionNameEmoteCollectionFollows).Find(ctx, bson.M{
+		"user_id":       bson.M{"$in": userIDs},
+		"collection_id": bson.M{"$in": collectionIDs},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query emote collection follows", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if err := cur.All(ctx, &result); err != nil {
+		zap.S().Errorw("mongo, failed to decode emote collection follows", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	return result, nil
+}
+
+// EmoteFavoriteCounts returns the amount of users who have favorited each of the given emotes.
+// Emotes which nobody has favorited are absent from the result
+func (q *Query) EmoteFavoriteCounts(ctx context.Context, emoteIDs []primitive.ObjectID) (map[primitive.ObjectID]int64, error) {
+	result := make(map[primitive.ObjectID]int64, len(emoteIDs))
+
+	cur, err := q.mongo.Collection(CollectionNameEmoteCollections).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"kind":      EmoteCollectionKindFavorites,
+			"emotes.id": bson.M{"$in": emoteIDs},
+		}}},
+		{{Key: "$unwind", Value: "$emotes"}},
+		{{Key: "$match", Value: bson.M{"emotes.id": bson.M{"$in": emoteIDs}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$emotes.id",
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to count emote favorites", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	counts := []struct {
+		ID    primitive.ObjectID `bson:"_id"`
+		Count int64              `bson:"count"`
+	}{}
+	if err := cur.All(ctx, &counts); err != nil {
+		zap.S().Errorw("mongo, failed to decode emote favorite counts", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	for _, c := range counts {
+		result[c.ID] = c.Count
+	}
+
+	return result, nil
+}
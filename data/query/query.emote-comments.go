@@ -0,0 +1,257 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// EMOTE_COMMENTS_PINNED_LIMIT is how many comments of an emote can be pinned
+const EMOTE_COMMENTS_PINNED_LIMIT = 10
+
+// EmoteComment is a message of kind EMOTE_COMMENT
+type EmoteComment struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Kind      structures.MessageKind `json:"kind" bson:"kind"`
+	AuthorID  primitive.ObjectID     `json:"author_id" bson:"author_id,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+	Data      EmoteCommentData       `json:"data" bson:"data"`
+}
+
+// EmoteCommentData extends the comment data of a message with threading and moderation state
+type EmoteCommentData struct {
+	structures.MessageDataEmoteComment `bson:",inline"`
+	// The top-level comment of the thread. Zero for top-level comments
+	ParentID primitive.ObjectID `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	// The comment this is a reply to, which may be another reply in the thread
+	ReplyToID primitive.ObjectID `json:"reply_to_id,omitempty" bson:"reply_to_id,omitempty"`
+	// The amount of replies, for top-level comments
+	ReplyCount int32 `json:"reply_count,omitempty" bson:"reply_count,omitempty"`
+	// Hidden comments are only visible to their author, the emote owner and moderators
+	Hidden   bool               `json:"hidden,omitempty" bson:"hidden,omitempty"`
+	HiddenBy primitive.ObjectID `json:"hidden_by,omitempty" bson:"hidden_by,omitempty"`
+	// Deleted comments with replies are kept without their content, so that the thread stays intact
+	Deleted  bool       `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	EditedAt *time.Time `json:"edited_at,omitempty" bson:"edited_at,omitempty"`
+}
+
+// EmoteCommentReadState is the read state of an emote comment. Comments are listed through their read states with Messages,
+// which are filtered by the emote and thread of the comment
+type EmoteCommentReadState struct {
+	structures.MessageRead `bson:",inline"`
+	EmoteID                primitive.ObjectID `bson:"emote_id"`
+	ParentID               primitive.ObjectID `bson:"parent_id,omitempty"`
+}
+
+type EmoteCommentsQueryOptions struct {
+	Actor *structures.User
+	// List the replies of this comment, rather than the top-level comments
+	ParentID primitive.ObjectID
+	// Continue from a cursor returned by a previous query
+	After primitive.ObjectID
+	Limit int
+}
+
+type EmoteCommentsResult struct {
+	Items   []EmoteComment     `json:"items"`
+	Cursor  primitive.ObjectID `json:"cursor"`
+	HasMore bool               `json:"has_more"`
+}
+
+// EmoteComment returns a single emote comment
+func (q *Query) EmoteComment(ctx context.Context, id primitive.ObjectID) (EmoteComment, error) {
+	comment := EmoteComment{}
+
+	if err := q.mongo.Collection(mongo.CollectionNameMessages).FindOne(ctx, bson.M{
+		"_id":  id,
+		"kind": structures.MessageKindEmoteComment,
+	}).Decode(&comment); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return comment, errors.ErrUnknownMessage()
+		}
+
+		zap.S().Errorw("mongo, failed to find emote comment", "error", err)
+
+		return comment, errors.ErrInternalServerError()
+	}
+
+	return comment, nil
+}
+
+// EmoteComments returns the comments of an emote.
+//
+// Top-level comments are listed newest first, with pinned comments on the first page.
+// Replies are listed oldest first
+func (q *Query) EmoteComments(ctx context.Context, emoteID primitive.ObjectID, opt EmoteCommentsQueryOptions) (EmoteCommentsResult, error) {
+	result := EmoteCommentsResult{
+		Items:  []EmoteComment{},
+		Cursor: opt.After,
+	}
+
+	canSeeHidden, err := q.canSeeHiddenEmoteComments(ctx, emoteID, opt.Actor)
+	if err != nil {
+		return result, err
+	}
+
+	filter, messageFilter := emoteCommentsFilter(emoteID, opt, canSeeHidden)
+	isReplies := !opt.ParentID.IsZero()
+
+	sort := bson.D{{Key: "message_id", Value: -1}}
+	if isReplies {
+		sort = bson.D{{Key: "message_id", Value: 1}}
+	}
+
+	// Pinned comments come first, and are not repeated on the next pages
+	if !isReplies {
+		if opt.After.IsZero() {
+			pinned, err := q.emoteComments(ctx, filter, mergeFilter(messageFilter, bson.M{"data.pinned": true}), sort, EMOTE_COMMENTS_PINNED_LIMIT)
+			if err != nil {
+				return result, err
+			}
+
+			result.Items = append(result.Items, pinned...)
+		}
+
+		messageFilter["data.pinned"] = bson.M{"$ne": true}
+	}
+
+	if !opt.After.IsZero() {
+		filter["message_id"] = bson.M{map[bool]string{true: "$gt", false: "$lt"}[isReplies]: opt.After}
+	}
+
+	comments, err := q.emoteComments(ctx, filter, messageFilter, sort, opt.Limit+1)
+	if err != nil {
+		return result, err
+	}
+
+	if len(comments) > opt.Limit {
+		result.HasMore = true
+		comments = comments[:opt.Limit]
+	}
+
+	if len(comments) > 0 {
+		result.Cursor = comments[len(comments)-1].ID
+	}
+
+	result.Items = append(result.Items, comments...)
+
+	for i, c := range result.Items {
+		if c.Data.Deleted {
+			result.Items[i].Data.Content = ""
+		}
+	}
+
+	return result, nil
+}
+
+// emoteCommentsFilter returns the filter of the read states of the comments to list, and that of the comments themselves.
+// Read states are filtered by emote and thread, comments by their state
+func emoteCommentsFilter(emoteID primitive.ObjectID, opt EmoteCommentsQueryOptions, canSeeHidden bool) (bson.M, bson.M) {
+	filter := bson.M{
+		"kind":     structures.MessageKindEmoteComment,
+		"emote_id": emoteID,
+	}
+	messageFilter := bson.M{}
+
+	if !opt.ParentID.IsZero() {
+		filter["parent_id"] = opt.ParentID
+	} else {
+		filter["parent_id"] = bson.M{"$exists": false}
+	}
+
+	// Hidden comments are only visible to their author, unless the actor can see them all
+	if !canSeeHidden {
+		visible := bson.A{bson.M{"data.hidden": bson.M{"$ne": true}}}
+		if opt.Actor != nil && !opt.Actor.ID.IsZero() {
+			visible = append(visible, bson.M{"author_id": opt.Actor.ID})
+		}
+
+		messageFilter["$or"] = visible
+	}
+
+	// Deleted comments are only shown as placeholders for their replies
+	messageFilter["$and"] = bson.A{bson.M{"$or": bson.A{
+		bson.M{"data.deleted": bson.M{"$ne": true}},
+		bson.M{"data.reply_count": bson.M{"$gt": 0}},
+	}}}
+
+	return filter, messageFilter
+}
+
+// emoteComments lists comments with Messages, reading their data as emote comment data
+func (q *Query) emoteComments(ctx context.Context, filter bson.M, messageFilter bson.M, sort bson.D, limit int) ([]EmoteComment, error) {
+	messages, err := q.Messages(ctx, filter, MessageQueryOptions{
+		Limit:         limit,
+		MessageFilter: messageFilter,
+		Sort:          sort,
+	}).Items()
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]EmoteComment, 0, len(messages))
+
+	for _, msg := range messages {
+		c := EmoteComment{
+			ID:        msg.ID,
+			Kind:      msg.Kind,
+			AuthorID:  msg.AuthorID,
+			CreatedAt: msg.CreatedAt,
+		}
+
+		if err := bson.Unmarshal(msg.Data, &c.Data); err != nil {
+			zap.S().Errorw("failed to decode emote comment", "error", err, "message_id", msg.ID)
+
+			continue
+		}
+
+		comments = append(comments, c)
+	}
+
+	return comments, nil
+}
+
+// canSeeHiddenEmoteComments tells whether the actor is the owner of the emote or a moderator
+func (q *Query) canSeeHiddenEmoteComments(ctx context.Context, emoteID primitive.ObjectID, actor *structures.User) (bool, error) {
+	if actor == nil || actor.ID.IsZero() {
+		return false, nil
+	}
+
+	if actor.HasPermission(structures.RolePermissionManageContent) {
+		return true, nil
+	}
+
+	emote := structures.Emote{}
+	if err := q.mongo.Collection(mongo.CollectionNameEmotes).FindOne(ctx, bson.M{
+		"versions.id": emoteID,
+	}, options.FindOne().SetProjection(bson.M{"owner_id": 1})).Decode(&emote); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, errors.ErrUnknownEmote()
+		}
+
+		return false, errors.ErrInternalServerError()
+	}
+
+	return emote.OwnerID == actor.ID, nil
+}
+
+func mergeFilter(a bson.M, b bson.M) bson.M {
+	m := bson.M{}
+
+	for k, v := range a {
+		m[k] = v
+	}
+
+	for k, v := range b {
+		m[k] = v
+	}
+
+	return m
+}
@@ -0,0 +1,75 @@
+package query
+
+import (
+	"context"
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestEmoteCommentsFilterThreads(t *testing.T) {
+	emoteID := primitive.NewObjectID()
+	parentID := primitive.NewObjectID()
+
+	filter, _ := emoteCommentsFilter(emoteID, EmoteCommentsQueryOptions{}, false)
+	testutil.Assert(t, emoteID, filter["emote_id"].(primitive.ObjectID), "comments are listed by emote")
+
+	top, ok := filter["parent_id"].(bson.M)
+	testutil.Assert(t, true, ok, "top-level comments are filtered by their lack of a parent")
+	testutil.Assert(t, false, top["$exists"].(bool), "replies are not listed with top-level comments")
+
+	filter, _ = emoteCommentsFilter(emoteID, EmoteCommentsQueryOptions{ParentID: parentID}, false)
+	testutil.Assert(t, parentID, filter["parent_id"].(primitive.ObjectID), "replies are listed by thread")
+}
+
+func TestEmoteCommentsFilterVisibility(t *testing.T) {
+	emoteID := primitive.NewObjectID()
+	actor := &structures.User{ID: primitive.NewObjectID()}
+
+	// Anonymous users only see visible comments
+	_, messageFilter := emoteCommentsFilter(emoteID, EmoteCommentsQueryOptions{}, false)
+	visible, ok := messageFilter["$or"].(bson.A)
+	testutil.Assert(t, true, ok, "hidden comments are filtered out")
+	testutil.Assert(t, 1, len(visible), "anonymous users only see visible comments")
+
+	// Users also see their own hidden comments
+	_, messageFilter = emoteCommentsFilter(emoteID, EmoteCommentsQueryOptions{Actor: actor}, false)
+	visible = messageFilter["$or"].(bson.A)
+	testutil.Assert(t, 2, len(visible), "users also see their own comments")
+	testutil.Assert(t, actor.ID, visible[1].(bson.M)["author_id"].(primitive.ObjectID), "users see the comments they wrote")
+
+	// The owner and moderators see every comment
+	_, messageFilter = emoteCommentsFilter(emoteID, EmoteCommentsQueryOptions{Actor: actor}, true)
+	_, ok = messageFilter["$or"]
+	testutil.Assert(t, false, ok, "hidden comments are listed to those who can see them")
+
+	_, ok = messageFilter["$and"]
+	testutil.Assert(t, true, ok, "deleted comments without replies are left out for everyone")
+}
+
+func TestCanSeeHiddenEmoteComments(t *testing.T) {
+	q := New(nil, nil, nil)
+	ctx := context.Background()
+	emoteID := primitive.NewObjectID()
+
+	ok, err := q.canSeeHiddenEmoteComments(ctx, emoteID, nil)
+	testutil.IsNil(t, err, "anonymous users are checked")
+	testutil.Assert(t, false, ok, "anonymous users cannot see hidden comments")
+
+	ok, err = q.canSeeHiddenEmoteComments(ctx, emoteID, &structures.User{})
+	testutil.IsNil(t, err, "users without an id are checked")
+	testutil.Assert(t, false, ok, "users without an id cannot see hidden comments")
+
+	moderator := &structures.User{
+		ID:    primitive.NewObjectID(),
+		Roles: []structures.Role{{Allowed: structures.RolePermissionManageContent}},
+	}
+
+	ok, err = q.canSeeHiddenEmoteComments(ctx, emoteID, moderator)
+	testutil.IsNil(t, err, "moderators are checked")
+	testutil.Assert(t, true, ok, "moderators can see hidden comments")
+}
@@ -0,0 +1,196 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// EMOTE_SET_CHANGES_HISTORY_WINDOW is how far back the changes of an emote set can be replayed
+const EMOTE_SET_CHANGES_HISTORY_WINDOW = time.Hour * 24 * 30
+
+type EmoteSetChangeKind string
+
+const (
+	EmoteSetChangeKindAdd    EmoteSetChangeKind = "ADD"
+	EmoteSetChangeKindRename EmoteSetChangeKind = "RENAME"
+	// an active emote was changed without being renamed, such as its flags
+	EmoteSetChangeKindUpdate EmoteSetChangeKind = "UPDATE"
+	EmoteSetChangeKindRemove EmoteSetChangeKind = "REMOVE"
+	// a property of the set itself was changed, such as its name or capacity
+	EmoteSetChangeKindUpdateSet EmoteSetChangeKind = "UPDATE_SET"
+)
+
+type EmoteSetChange struct {
+	// the id of the audit log entry the change was recorded in
+	ID   primitive.ObjectID `json:"id"`
+	Kind EmoteSetChangeKind `json:"kind"`
+	// the emote which changed, unless the change is to the set itself
+	EmoteID primitive.ObjectID `json:"emote_id"`
+	Name    string             `json:"name,omitempty"`
+	// the property of the set which changed, for changes to the set itself
+	Field string `json:"field,omitempty"`
+	// the previous name of the emote, for renames
+	OldName   string             `json:"old_name,omitempty"`
+	ActorID   primitive.ObjectID `json:"actor_id"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+type EmoteSetChangesResult struct {
+	Changes []EmoteSetChange `json:"changes"`
+	// pass as "after" to continue from the last returned change
+	Cursor primitive.ObjectID `json:"cursor"`
+	// whether more changes are available past the cursor
+	HasMore bool `json:"has_more"`
+	// whether changes before the history window were omitted.
+	// In this case the client should fetch the full emote set instead
+	Truncated bool `json:"truncated"`
+}
+
+// EmoteSetChanges returns the emote changes of a set which happened after the given cursor, oldest first
+func (q *Query) EmoteSetChanges(ctx context.Context, setID primitive.ObjectID, after primitive.ObjectID, limit int) (EmoteSetChangesResult, error) {
+	result := EmoteSetChangesResult{
+		Changes: []EmoteSetChange{},
+		Cursor:  after,
+	}
+
+	filter := bson.M{
+		"kind":        structures.AuditLogKindUpdateEmoteSet,
+		"target_kind": structures.ObjectKindEmoteSet,
+		"target_id":   setID,
+	}
+
+	// Changes older than the history window are cut off. The result is truncated if any were
+	windowStart := primitive.NewObjectIDFromTimestamp(time.Now().Add(-EMOTE_SET_CHANGES_HISTORY_WINDOW))
+	if after.IsZero() || after.Timestamp().Before(windowStart.Timestamp()) {
+		older := bson.M{"$lte": windowStart}
+		if !after.IsZero() {
+			older["$gt"] = after
+		}
+
+		filter["_id"] = older
+
+		n, err := q.mongo.Collection(mongo.CollectionNameAuditLogs).CountDocuments(ctx, filter, options.Count().SetLimit(1))
+		if err != nil {
+			zap.S().Errorw("mongo, failed to count emote set changes before the history window", "error", err)
+
+			return result, errors.ErrInternalServerError()
+		}
+
+		result.Truncated = n > 0
+		after = windowStart
+	}
+
+	filter["_id"] = bson.M{"$gt": after}
+
+	// Each log may produce several changes, so the limit applies to logs
+	cur, err := q.mongo.Collection(mongo.CollectionNameAuditLogs).Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit+1)))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query emote set changes", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	logs := []structures.AuditLog{}
+	if err = cur.All(ctx, &logs); err != nil {
+		zap.S().Errorw("mongo, failed to decode emote set changes", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if len(logs) > limit {
+		result.HasMore = true
+		logs = logs[:limit]
+	}
+
+	for _, l := range logs {
+		result.Changes = append(result.Changes, emoteSetChangesFromLog(l)...)
+		result.Cursor = l.ID
+	}
+
+	return result, nil
+}
+
+// the shape of array changes to the "emotes" key of an emote set
+type emoteSetChangeArray struct {
+	Added   []structures.ActiveEmote `bson:"added,omitempty"`
+	Removed []structures.ActiveEmote `bson:"removed,omitempty"`
+	Updated []struct {
+		New structures.ActiveEmote `bson:"n"`
+		Old structures.ActiveEmote `bson:"o"`
+	} `bson:"updated,omitempty"`
+}
+
+func emoteSetChangesFromLog(l structures.AuditLog) []EmoteSetChange {
+	result := []EmoteSetChange{}
+
+	for _, c := range l.Changes {
+		base := EmoteSetChange{
+			ID:        l.ID,
+			ActorID:   l.ActorID,
+			Timestamp: l.ID.Timestamp(),
+		}
+
+		if c.Key != "emotes" {
+			ch := base
+			ch.Kind = EmoteSetChangeKindUpdateSet
+			ch.Field = c.Key
+
+			result = append(result, ch)
+
+			continue
+		}
+
+		if c.Format != structures.AuditLogChangeFormatArrayChange {
+			continue
+		}
+
+		ary := emoteSetChangeArray{}
+		if err := bson.Unmarshal(c.Value, &ary); err != nil {
+			zap.S().Warnw("failed to decode emote set change", "error", err, "audit_log_id", l.ID)
+
+			continue
+		}
+
+		for _, ae := range ary.Added {
+			ch := base
+			ch.Kind = EmoteSetChangeKindAdd
+			ch.EmoteID = ae.ID
+			ch.Name = ae.Name
+
+			result = append(result, ch)
+		}
+
+		for _, v := range ary.Updated {
+			ch := base
+			ch.Kind = EmoteSetChangeKindUpdate
+			ch.EmoteID = v.New.ID
+			ch.Name = v.New.Name
+
+			if v.Old.Name != v.New.Name {
+				ch.Kind = EmoteSetChangeKindRename
+				ch.OldName = v.Old.Name
+			}
+
+			result = append(result, ch)
+		}
+
+		for _, ae := range ary.Removed {
+			ch := base
+			ch.Kind = EmoteSetChangeKindRemove
+			ch.EmoteID = ae.ID
+			ch.Name = ae.Name
+
+			result = append(result, ch)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestEmoteSetChangesFromLogSeveralAdded(t *testing.T) {
+	actorID := primitive.NewObjectID()
+	added := []structures.ActiveEmote{
+		{ID: primitive.NewObjectID(), Name: "KEKW"},
+		{ID: primitive.NewObjectID(), Name: "PogU"},
+		{ID: primitive.NewObjectID(), Name: "Clap"},
+	}
+
+	values := make([]any, len(added))
+	for i, ae := range added {
+		values[i] = ae
+	}
+
+	// A single edit, such as copying a set or adding a collection, records all of its emotes in one change
+	c := &structures.AuditLogChange{Key: "emotes"}
+	c.WriteArrayAdded(values...)
+
+	l := structures.AuditLog{
+		ID:      primitive.NewObjectID(),
+		ActorID: actorID,
+		Changes: []*structures.AuditLogChange{c},
+	}
+
+	changes := emoteSetChangesFromLog(l)
+
+	testutil.Assert(t, len(added), len(changes), "every added emote is a change")
+
+	for i, ae := range added {
+		testutil.Assert(t, EmoteSetChangeKindAdd, changes[i].Kind, "the emote was added")
+		testutil.Assert(t, ae.ID, changes[i].EmoteID, "the change is for the added emote")
+		testutil.Assert(t, ae.Name, changes[i].Name, "the change has the name of the added emote")
+		testutil.Assert(t, l.ID, changes[i].ID, "the change refers to its log")
+		testutil.Assert(t, actorID, changes[i].ActorID, "the change has the actor of the edit")
+	}
+}
+
+func TestEmoteSetChangesFromLogMixed(t *testing.T) {
+	addedID := primitive.NewObjectID()
+	renamedID := primitive.NewObjectID()
+	removedID := primitive.NewObjectID()
+
+	value, err := bson.Marshal(structures.AuditLogChangeArrayChange{
+		Added:   []any{structures.ActiveEmote{ID: addedID, Name: "KEKW"}},
+		Removed: []any{structures.ActiveEmote{ID: removedID, Name: "Clap"}},
+		Updated: []structures.AuditLogChangeSingleValue{{
+			New: structures.ActiveEmote{ID: renamedID, Name: "PogU"},
+			Old: structures.ActiveEmote{ID: renamedID, Name: "Pog"},
+		}},
+	})
+	testutil.IsNil(t, err, "the change is encoded")
+
+	changes := emoteSetChangesFromLog(structures.AuditLog{
+		ID: primitive.NewObjectID(),
+		Changes: []*structures.AuditLogChange{
+			{Format: structures.AuditLogChangeFormatArrayChange, Key: "emotes", Value: value},
+			{Format: structures.AuditLogChangeFormatSingleValue, Key: "name"},
+		},
+	})
+
+	testutil.Assert(t, 4, len(changes), "every part of the edit is a change")
+	testutil.Assert(t, EmoteSetChangeKindAdd, changes[0].Kind, "the added emote comes first")
+	testutil.Assert(t, addedID, changes[0].EmoteID, "the added emote is listed")
+	testutil.Assert(t, EmoteSetChangeKindRename, changes[1].Kind, "the renamed emote follows")
+	testutil.Assert(t, "Pog", changes[1].OldName, "the rename has the previous name")
+	testutil.Assert(t, EmoteSetChangeKindRemove, changes[2].Kind, "the removed emote follows")
+	testutil.Assert(t, removedID, changes[2].EmoteID, "the removed emote is listed")
+	testutil.Assert(t, EmoteSetChangeKindUpdateSet, changes[3].Kind, "a change to the set itself is listed")
+	testutil.Assert(t, "name", changes[3].Field, "the change to the set has its field")
+}
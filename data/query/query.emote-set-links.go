@@ -0,0 +1,45 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+var CollectionNameEmoteSetLinks mongo.CollectionName = "emote_set_links"
+
+// EmoteSetLink binds a cloned emote set to the set it was cloned from, so that it can be re-synced
+type EmoteSetLink struct {
+	SetID    primitive.ObjectID `json:"set_id" bson:"_id"`
+	SourceID primitive.ObjectID `json:"source_id" bson:"source_id"`
+	LinkedAt time.Time          `json:"linked_at" bson:"linked_at"`
+	SyncedAt time.Time          `json:"synced_at" bson:"synced_at"`
+}
+
+// EmoteSetLinks returns the source links of emote sets, by the ID of the linked set. Sets which are not linked are left out
+func (q *Query) EmoteSetLinks(ctx context.Context, setIDs []primitive.ObjectID) (map[primitive.ObjectID]EmoteSetLink, error) {
+	links := []EmoteSetLink{}
+
+	cur, err := q.mongo.Collection(CollectionNameEmoteSetLinks).Find(ctx, bson.M{"_id": bson.M{"$in": setIDs}})
+	if err == nil {
+		err = cur.All(ctx, &links)
+	}
+
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query emote set links", "error", err)
+
+		return nil, errors.ErrInternalServerError()
+	}
+
+	result := make(map[primitive.ObjectID]EmoteSetLink, len(links))
+	for _, link := range links {
+		result[link.SetID] = link
+	}
+
+	return result, nil
+}
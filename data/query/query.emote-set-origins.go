@@ -0,0 +1,226 @@
+package query
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+var CollectionNameEmoteSetOriginPolicies mongo.CollectionName = "emote_set_origin_policies"
+
+// EmoteSetOriginConflictMode decides what happens when an emote from an origin set has the same name as another emote in the set
+type EmoteSetOriginConflictMode string
+
+const (
+	// The origin emote replaces the local emote. Between origins, the one with the highest weight wins
+	EmoteSetOriginConflictModeOriginWins EmoteSetOriginConflictMode = "ORIGIN_WINS"
+	// The local emote is kept and the origin emote is left out
+	EmoteSetOriginConflictModeLocalWins EmoteSetOriginConflictMode = "LOCAL_WINS"
+	// The origin emote is added under a new name, made with a prefix and/or suffix
+	EmoteSetOriginConflictModeRename EmoteSetOriginConflictMode = "RENAME"
+)
+
+// EmoteSetOriginPolicy is how an emote set resolves name conflicts with its origins
+type EmoteSetOriginPolicy struct {
+	SetID     primitive.ObjectID         `json:"set_id" bson:"_id"`
+	Mode      EmoteSetOriginConflictMode `json:"mode" bson:"mode"`
+	Prefix    string                     `json:"prefix,omitempty" bson:"prefix,omitempty"`
+	Suffix    string                     `json:"suffix,omitempty" bson:"suffix,omitempty"`
+	UpdatedAt time.Time                  `json:"updated_at" bson:"updated_at"`
+}
+
+type EmoteSetOriginConflictResolution string
+
+const (
+	EmoteSetOriginConflictResolutionReplaced EmoteSetOriginConflictResolution = "REPLACED"
+	EmoteSetOriginConflictResolutionDropped  EmoteSetOriginConflictResolution = "DROPPED"
+	EmoteSetOriginConflictResolutionRenamed  EmoteSetOriginConflictResolution = "RENAMED"
+)
+
+// EmoteSetOriginConflict describes an origin emote whose name was already taken
+type EmoteSetOriginConflict struct {
+	Name     string             `json:"name"`
+	EmoteID  primitive.ObjectID `json:"emote_id"`
+	OriginID primitive.ObjectID `json:"origin_id"`
+	// the emote which held the name. The origin is zero for local emotes
+	ConflictEmoteID  primitive.ObjectID               `json:"conflict_emote_id"`
+	ConflictOriginID primitive.ObjectID               `json:"conflict_origin_id,omitempty"`
+	Resolution       EmoteSetOriginConflictResolution `json:"resolution"`
+	RenamedTo        string                           `json:"renamed_to,omitempty"`
+}
+
+type EmoteSetOriginPreview struct {
+	Set       structures.EmoteSet      `json:"set"`
+	Conflicts []EmoteSetOriginConflict `json:"conflicts"`
+}
+
+// EmoteSetOriginPolicies returns the origin policies of the given sets. Sets without a policy are omitted
+func (q *Query) EmoteSetOriginPolicies(ctx context.Context, setIDs []primitive.ObjectID) (map[primitive.ObjectID]EmoteSetOriginPolicy, error) {
+	result := make(map[primitive.ObjectID]EmoteSetOriginPolicy)
+
+	if len(setIDs) == 0 {
+		return result, nil
+	}
+
+	cur, err := q.mongo.Collection(CollectionNameEmoteSetOriginPolicies).Find(ctx, bson.M{
+		"_id": bson.M{"$in": setIDs},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query emote set origin policies", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	policies := []EmoteSetOriginPolicy{}
+	if err = cur.All(ctx, &policies); err != nil {
+		zap.S().Errorw("mongo, failed to decode emote set origin policies", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	for _, p := range policies {
+		result[p.SetID] = p
+	}
+
+	return result, nil
+}
+
+// PreviewEmoteSetOrigins returns the effective emotes of a set merged with its origins, and the name conflicts that came up.
+// The origins and policy of the set are used unless overridden
+func (q *Query) PreviewEmoteSetOrigins(ctx context.Context, set structures.EmoteSet, policy *EmoteSetOriginPolicy) (EmoteSetOriginPreview, error) {
+	result := EmoteSetOriginPreview{
+		Conflicts: []EmoteSetOriginConflict{},
+	}
+
+	if policy == nil {
+		policies, err := q.EmoteSetOriginPolicies(ctx, []primitive.ObjectID{set.ID})
+		if err != nil {
+			return result, err
+		}
+
+		p := policies[set.ID]
+		policy = &p
+	}
+
+	originSets, err := q.EmoteSets(ctx, bson.M{"_id": bson.M{"$in": getOriginIds([]structures.EmoteSet{set})}}).Items()
+	if err != nil {
+		return result, err
+	}
+
+	originMap := make(map[primitive.ObjectID]structures.EmoteSet)
+	for _, os := range originSets {
+		originMap[os.ID] = os
+	}
+
+	// Work on a copy so that the input set is left untouched
+	set.Emotes = append([]structures.ActiveEmote{}, set.Emotes...)
+	set.Origins = append([]structures.EmoteSetOrigin{}, set.Origins...)
+
+	result.Set, result.Conflicts = mergeOriginSet(set, originMap, *policy)
+
+	return result, nil
+}
+
+// mergeOriginSet inserts the emotes of the origin sets into the set, resolving name conflicts with the policy
+func mergeOriginSet(set structures.EmoteSet, originMap map[primitive.ObjectID]structures.EmoteSet, policy EmoteSetOriginPolicy) (structures.EmoteSet, []EmoteSetOriginConflict) {
+	conflicts := []EmoteSetOriginConflict{}
+
+	emoteNameIndexes := make(map[string]int)
+	for emoteIndex, emote := range set.Emotes {
+		emoteNameIndexes[emote.Name] = emoteIndex
+	}
+
+	// Apply origins with a higher weight first, so that they take precedence
+	order := make([]int, len(set.Origins))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return set.Origins[order[i]].Weight > set.Origins[order[j]].Weight
+	})
+
+	for _, originIndex := range order {
+		origin := set.Origins[originIndex]
+
+		subset := originMap[origin.ID]
+		if subset.ID.IsZero() {
+			continue // set wasn't found
+		}
+
+		origin.Set = &subset
+
+		for _, emote := range subset.Emotes {
+			emote.Origin = origin
+
+			index, ok := emoteNameIndexes[emote.Name]
+			if !ok {
+				// don't exceed capacity, but we still replace other emotes
+				if len(set.Emotes) >= int(set.Capacity) {
+					continue
+				}
+
+				// add emote to set emotes
+				emoteNameIndexes[emote.Name] = len(set.Emotes)
+				set.Emotes = append(set.Emotes, emote)
+
+				continue
+			}
+
+			holder := set.Emotes[index]
+			if holder.ID == emote.ID {
+				continue
+			}
+
+			conflict := EmoteSetOriginConflict{
+				Name:             emote.Name,
+				EmoteID:          emote.ID,
+				OriginID:         origin.ID,
+				ConflictEmoteID:  holder.ID,
+				ConflictOriginID: holder.Origin.ID,
+				Resolution:       EmoteSetOriginConflictResolutionDropped,
+			}
+
+			switch {
+			case policy.Mode == EmoteSetOriginConflictModeRename:
+				name := policy.Prefix + emote.Name + policy.Suffix
+				if _, taken := emoteNameIndexes[name]; taken || name == emote.Name || len(set.Emotes) >= int(set.Capacity) {
+					break
+				}
+
+				emote.Name = name
+				emoteNameIndexes[name] = len(set.Emotes)
+				set.Emotes = append(set.Emotes, emote)
+
+				conflict.Resolution = EmoteSetOriginConflictResolutionRenamed
+				conflict.RenamedTo = name
+			case !holder.Origin.ID.IsZero():
+				// the name is held by an origin of equal or higher weight
+			case policy.Mode == EmoteSetOriginConflictModeLocalWins:
+				// the local emote is kept
+			default:
+				set.Emotes[index] = emote
+
+				conflict.Resolution = EmoteSetOriginConflictResolutionReplaced
+			}
+
+			conflicts = append(conflicts, conflict)
+		}
+
+		set.Origins[originIndex] = origin
+	}
+
+	// Local emotes come first
+	sort.SliceStable(set.Emotes, func(i, j int) bool {
+		return set.Emotes[i].Origin.ID.IsZero() && !set.Emotes[j].Origin.ID.IsZero()
+	})
+
+	return set, conflicts
+}
@@ -2,7 +2,6 @@ package query
 
 import (
 	"context"
-	"sort"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/seventv/common/errors"
@@ -63,62 +62,39 @@ func (q *Query) EmoteSets(ctx context.Context, filter bson.M, opts ...QueryEmote
 		return qr.setError(err)
 	}
 
-	return qr.setItems(applyOriginSets(items, originSets))
+	// fetch conflict policies
+	policies, err := q.EmoteSetOriginPolicies(ctx, getIdsWithOrigins(items))
+	if err != nil {
+		return qr.setError(err)
+	}
+
+	return qr.setItems(applyOriginSets(items, originSets, policies))
 }
 
 // applyOriginSets inserts origin sets into the emote sets
-func applyOriginSets(sets []structures.EmoteSet, originSets []structures.EmoteSet) []structures.EmoteSet {
+func applyOriginSets(sets []structures.EmoteSet, originSets []structures.EmoteSet, policies map[primitive.ObjectID]EmoteSetOriginPolicy) []structures.EmoteSet {
 	originMap := make(map[primitive.ObjectID]structures.EmoteSet)
 	for _, set := range originSets {
 		originMap[set.ID] = set
 	}
 
 	for i, set := range sets {
-		emoteNameIndexes := make(map[string]int)
-		for emoteIndex, emote := range set.Emotes {
-			emoteNameIndexes[emote.Name] = emoteIndex
-		}
-
-		for originIndex, origin := range set.Origins {
-			subset := originMap[origin.ID]
-			if subset.ID.IsZero() {
-				continue // set wasn't found
-			}
+		sets[i], _ = mergeOriginSet(set, originMap, policies[set.ID])
+	}
 
-			origin.Set = &subset
-
-			for _, emote := range subset.Emotes {
-				if index, ok := emoteNameIndexes[emote.Name]; ok {
-					if set.Emotes[index].ID == emote.ID {
-						continue
-					}
-
-					emote.Origin = origin
-					set.Emotes[index] = emote
-				} else {
-					// don't exceed capacity, but we still replace other origin set emotes
-					if len(set.Emotes) >= int(set.Capacity) {
-						continue
-					}
-
-					// add emote to set emotes
-					emoteNameIndexes[emote.Name] = len(set.Emotes)
-					set.Emotes = append(set.Emotes, emote)
-				}
-			}
+	return sets
+}
 
-			// TODO: figure out of this sort is needed
-			sort.Slice(set.Emotes, func(i, j int) bool {
-				return set.Emotes[i].Origin.ID.IsZero()
-			})
+func getIdsWithOrigins(items []structures.EmoteSet) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0)
 
-			set.Origins[originIndex] = origin
+	for _, set := range items {
+		if len(set.Origins) > 0 {
+			ids = append(ids, set.ID)
 		}
-
-		sets[i] = set
 	}
 
-	return sets
+	return ids
 }
 
 func getOriginIds(items []structures.EmoteSet) []primitive.ObjectID {
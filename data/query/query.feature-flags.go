@@ -0,0 +1,171 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/redis"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+var (
+	CollectionNameFeatureFlags mongo.CollectionName = "feature_flags"
+	// Changes to feature flags are logged apart from the audit log, so that their kinds can't collide with those of the shared structures
+	CollectionNameFeatureFlagAuditLogs mongo.CollectionName = "feature_flag_audit_logs"
+)
+
+// FEATURE_FLAGS_CACHE_TTL is for how long the flags are cached in Redis. The cache is cleared when a flag changes
+const FEATURE_FLAGS_CACHE_TTL = time.Minute * 5
+
+// The kinds of the audit logs of feature flags, which are only used in CollectionNameFeatureFlagAuditLogs
+const (
+	AuditLogKindCreateFeatureFlag structures.AuditLogKind = 100 // feature flag was created
+	AuditLogKindUpdateFeatureFlag structures.AuditLogKind = 101 // feature flag was updated
+	AuditLogKindDeleteFeatureFlag structures.AuditLogKind = 102 // feature flag was deleted
+)
+
+// FeatureFlag toggles a feature without a redeploy, for everyone or for a part of the users
+type FeatureFlag struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// The name the flag is looked up by, i.e "presences"
+	Key         string `json:"key" bson:"key"`
+	Description string `json:"description" bson:"description"`
+	// When false, the flag is off for everyone regardless of its targeting
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// Whether clients can read the flag
+	Public    bool               `json:"public" bson:"public"`
+	Targeting FeatureFlagTargets `json:"targeting" bson:"targeting"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// FeatureFlagTargets decides who an enabled flag is on for. Users matched by their ID, role or platform have the flag on,
+// and everyone else is subject to the rollout percentage
+type FeatureFlagTargets struct {
+	UserIDs   []primitive.ObjectID `json:"user_ids,omitempty" bson:"user_ids,omitempty"`
+	RoleIDs   []primitive.ObjectID `json:"role_ids,omitempty" bson:"role_ids,omitempty"`
+	Platforms []string             `json:"platforms,omitempty" bson:"platforms,omitempty"`
+	// The percentage of users, from 0 to 100, the flag is on for. At 100 it is also on for anonymous requests
+	Percentage int `json:"percentage" bson:"percentage"`
+}
+
+// Targeted tells whether the flag targets users by their ID, role or platform
+func (t FeatureFlagTargets) Targeted() bool {
+	return len(t.UserIDs) > 0 || len(t.RoleIDs) > 0 || len(t.Platforms) > 0
+}
+
+// Equal tells whether two targetings are on for the same users
+func (t FeatureFlagTargets) Equal(o FeatureFlagTargets) bool {
+	if t.Percentage != o.Percentage || len(t.UserIDs) != len(o.UserIDs) || len(t.RoleIDs) != len(o.RoleIDs) || len(t.Platforms) != len(o.Platforms) {
+		return false
+	}
+
+	for i := range t.UserIDs {
+		if t.UserIDs[i] != o.UserIDs[i] {
+			return false
+		}
+	}
+
+	for i := range t.RoleIDs {
+		if t.RoleIDs[i] != o.RoleIDs[i] {
+			return false
+		}
+	}
+
+	for i := range t.Platforms {
+		if t.Platforms[i] != o.Platforms[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsOn tells whether the flag is on for a user, which is nil for anonymous requests
+func (f FeatureFlag) IsOn(user *structures.User) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	t := f.Targeting
+
+	if t.Percentage >= 100 {
+		return true
+	}
+
+	if user == nil || user.ID.IsZero() {
+		return false
+	}
+
+	for _, id := range t.UserIDs {
+		if id == user.ID {
+			return true
+		}
+	}
+
+	if len(t.RoleIDs) > 0 && (NewsAudience{RoleIDs: t.RoleIDs}).hasRole(user) {
+		return true
+	}
+
+	if len(t.Platforms) > 0 && (NewsAudience{Platforms: t.Platforms}).hasPlatform(user) {
+		return true
+	}
+
+	return t.Percentage > 0 && f.bucket(user.ID) < t.Percentage
+}
+
+// bucket places a user between 0 and 99 for the rollout of this flag.
+// Raising the percentage only adds users, and each flag rolls out to a different part of the users
+func (f FeatureFlag) bucket(userID primitive.ObjectID) int {
+	h := sha256.Sum256(append([]byte(strings.ToLower(f.Key)+":"), userID[:]...))
+
+	return int(binary.BigEndian.Uint32(h[:4]) % 100)
+}
+
+// FeatureFlagsCacheKey is where the flags are cached
+func FeatureFlagsCacheKey(r redis.Instance) redis.Key {
+	return r.ComposeKey("api", "feature_flags")
+}
+
+// FeatureFlags returns all the feature flags, sorted by key
+func (q *Query) FeatureFlags(ctx context.Context) ([]FeatureFlag, error) {
+	flags := []FeatureFlag{}
+
+	k := FeatureFlagsCacheKey(q.redis)
+
+	if s, err := q.redis.Get(ctx, k); err == nil {
+		if err = json.Unmarshal([]byte(s), &flags); err == nil {
+			return flags, nil
+		}
+	}
+
+	cur, err := q.mongo.Collection(CollectionNameFeatureFlags).Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"key": 1}))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query feature flags", "error", err)
+
+		return flags, errors.ErrInternalServerError()
+	}
+
+	if err = cur.All(ctx, &flags); err != nil {
+		zap.S().Errorw("mongo, failed to decode feature flags", "error", err)
+
+		return flags, errors.ErrInternalServerError()
+	}
+
+	if b, err := json.Marshal(flags); err == nil {
+		if err = q.redis.SetEX(ctx, k, string(b), FEATURE_FLAGS_CACHE_TTL); err != nil {
+			zap.S().Warnw("redis, failed to cache feature flags", "error", err)
+		}
+	}
+
+	return flags, nil
+}
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestFeatureFlagDisabled(t *testing.T) {
+	user := &structures.User{ID: primitive.NewObjectID()}
+	f := FeatureFlag{
+		Key:       "presences",
+		Targeting: FeatureFlagTargets{UserIDs: []primitive.ObjectID{user.ID}, Percentage: 100},
+	}
+
+	testutil.Assert(t, false, f.IsOn(user), "a disabled flag is off for targeted users")
+	testutil.Assert(t, false, f.IsOn(nil), "a disabled flag is off for everyone")
+}
+
+func TestFeatureFlagUserTargeting(t *testing.T) {
+	user := &structures.User{ID: primitive.NewObjectID()}
+	other := &structures.User{ID: primitive.NewObjectID()}
+	f := FeatureFlag{
+		Key:       "presences",
+		Enabled:   true,
+		Targeting: FeatureFlagTargets{UserIDs: []primitive.ObjectID{user.ID}},
+	}
+
+	testutil.Assert(t, true, f.Targeting.Targeted(), "the flag targets users")
+	testutil.Assert(t, true, f.IsOn(user), "the flag is on for a targeted user")
+	testutil.Assert(t, false, f.IsOn(other), "the flag is off for other users")
+	testutil.Assert(t, false, f.IsOn(nil), "the flag is off for anonymous requests")
+	testutil.Assert(t, false, f.IsOn(&structures.User{}), "the flag is off for users without an id")
+}
+
+func TestFeatureFlagRoleTargeting(t *testing.T) {
+	roleID := primitive.NewObjectID()
+	f := FeatureFlag{
+		Key:       "presences",
+		Enabled:   true,
+		Targeting: FeatureFlagTargets{RoleIDs: []primitive.ObjectID{roleID}},
+	}
+
+	withRole := &structures.User{ID: primitive.NewObjectID(), Roles: []structures.Role{{ID: roleID}}}
+	withRoleID := &structures.User{ID: primitive.NewObjectID(), RoleIDs: []primitive.ObjectID{roleID}}
+	withOtherRole := &structures.User{ID: primitive.NewObjectID(), Roles: []structures.Role{{ID: primitive.NewObjectID()}}}
+
+	testutil.Assert(t, true, f.IsOn(withRole), "the flag is on for users with the role")
+	testutil.Assert(t, true, f.IsOn(withRoleID), "the flag is on for users bound to the role")
+	testutil.Assert(t, false, f.IsOn(withOtherRole), "the flag is off for users with other roles")
+}
+
+func TestFeatureFlagPlatformTargeting(t *testing.T) {
+	f := FeatureFlag{
+		Key:       "presences",
+		Enabled:   true,
+		Targeting: FeatureFlagTargets{Platforms: []string{"twitch"}},
+	}
+
+	onTwitch := &structures.User{
+		ID:          primitive.NewObjectID(),
+		Connections: structures.UserConnectionList{{Platform: structures.UserConnectionPlatformTwitch}},
+	}
+	onDiscord := &structures.User{
+		ID:          primitive.NewObjectID(),
+		Connections: structures.UserConnectionList{{Platform: structures.UserConnectionPlatformDiscord}},
+	}
+
+	testutil.Assert(t, true, f.IsOn(onTwitch), "the flag is on for users connected to the platform, regardless of case")
+	testutil.Assert(t, false, f.IsOn(onDiscord), "the flag is off for users of other platforms")
+	testutil.Assert(t, false, f.IsOn(&structures.User{ID: primitive.NewObjectID()}), "the flag is off for users without connections")
+}
+
+func TestFeatureFlagPercentage(t *testing.T) {
+	users := make([]*structures.User, 1000)
+	for i := range users {
+		users[i] = &structures.User{ID: primitive.NewObjectID()}
+	}
+
+	count := func(f FeatureFlag) (int, map[primitive.ObjectID]bool) {
+		on := map[primitive.ObjectID]bool{}
+
+		for _, u := range users {
+			if f.IsOn(u) {
+				on[u.ID] = true
+			}
+		}
+
+		return len(on), on
+	}
+
+	f := FeatureFlag{Key: "presences", Enabled: true}
+
+	n, _ := count(f)
+	testutil.Assert(t, 0, n, "the flag is off for everyone at 0%")
+
+	f.Targeting.Percentage = 100
+	n, _ = count(f)
+	testutil.Assert(t, len(users), n, "the flag is on for everyone at 100%")
+	testutil.Assert(t, true, f.IsOn(nil), "the flag is on for anonymous requests at 100%")
+
+	f.Targeting.Percentage = 20
+	n, low := count(f)
+	testutil.Assert(t, true, n > 100 && n < 300, "the flag is on for about a fifth of the users")
+	testutil.Assert(t, false, f.IsOn(nil), "the flag is off for anonymous requests below 100%")
+
+	_, again := count(f)
+	testutil.Assert(t, len(low), len(again), "a user stays in or out of the rollout")
+
+	for id := range again {
+		testutil.Assert(t, true, low[id], "a user stays in or out of the rollout")
+	}
+
+	f.Targeting.Percentage = 50
+	_, high := count(f)
+
+	for id := range low {
+		testutil.Assert(t, true, high[id], "raising the percentage keeps the users who had the flag")
+	}
+
+	// Each flag rolls out to a different part of the users
+	_, otherFlag := count(FeatureFlag{Key: "cosmetics", Enabled: true, Targeting: FeatureFlagTargets{Percentage: 20}})
+
+	same := 0
+
+	for id := range low {
+		if otherFlag[id] {
+			same++
+		}
+	}
+
+	testutil.Assert(t, true, same < len(low), "another flag rolls out to other users")
+}
+
+func TestFeatureFlagTargetsWithPercentage(t *testing.T) {
+	user := &structures.User{ID: primitive.NewObjectID()}
+	f := FeatureFlag{
+		Key:     "presences",
+		Enabled: true,
+		Targeting: FeatureFlagTargets{
+			UserIDs:    []primitive.ObjectID{user.ID},
+			Percentage: 1,
+		},
+	}
+
+	testutil.Assert(t, true, f.IsOn(user), "a targeted user has the flag regardless of the rollout")
+}
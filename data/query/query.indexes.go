@@ -0,0 +1,77 @@
+package query
+
+import (
+	"context"
+
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/mongo/indexing"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// Indexes are the indexes which the queries of the API rely on, in addition to those set up by indexing.CollSync
+var Indexes = []indexing.IndexRef{
+	// Sessions are deleted once they expire
+	{Collection: CollectionNameSessions, Index: mongo.IndexModel{
+		Keys:    bson.D{{Key: "expire_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}},
+	// Active sessions of a user, see Sessions
+	{Collection: CollectionNameSessions, Index: mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "expire_at", Value: 1}},
+	}},
+	// Sets using a set as an origin, see mutate.notifyOriginDependents
+	{Collection: mongo.CollectionNameEmoteSets, Index: mongo.IndexModel{Keys: bson.M{"origins.id": -1}}},
+	// Open mod requests, see mutate.DismissVoidTargetModRequests
+	{Collection: mongo.CollectionNameMessagesRead, Index: mongo.IndexModel{
+		Keys: bson.D{{Key: "kind", Value: 1}, {Key: "read", Value: 1}},
+	}},
+	// Comments of an emote, see EmoteComments
+	{Collection: mongo.CollectionNameMessagesRead, Index: mongo.IndexModel{
+		Keys: bson.D{{Key: "emote_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "message_id", Value: -1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{
+			"kind": structures.MessageKindEmoteComment,
+		}),
+	}},
+	// Favorites of an emote, see EmoteFavoriteCounts
+	{Collection: CollectionNameEmoteCollections, Index: mongo.IndexModel{
+		Keys: bson.D{{Key: "emotes.id", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{
+			"kind": EmoteCollectionKindFavorites,
+		}),
+	}},
+	// A user has at most one favorites collection, which is upserted on their first favorite
+	{Collection: CollectionNameEmoteCollections, Index: mongo.IndexModel{
+		Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "kind", Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"kind": EmoteCollectionKindFavorites,
+		}),
+	}},
+	// A user follows a collection at most once, see EmoteCollectionFollows
+	{Collection: CollectionNameEmoteCollectionFollows, Index: mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "collection_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}},
+}
+
+// EnsureIndexes creates the indexes which do not exist yet. Failures are logged, and do not stop the other indexes from being created
+func EnsureIndexes(ctx context.Context, inst mongo.Instance) {
+	for _, ref := range Indexes {
+		name, err := inst.Collection(ref.Collection).Indexes().CreateOne(ctx, ref.Index)
+		if err != nil {
+			zap.S().Errorw("mongo, failed to set up index",
+				"collection", ref.Collection,
+				"error", err,
+			)
+
+			continue
+		}
+
+		zap.S().Debugw("Index ensured",
+			"collection", ref.Collection,
+			"index", name,
+		)
+	}
+}
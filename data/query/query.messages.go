@@ -80,14 +80,27 @@ func (q *Query) ModRequestMessages(ctx context.Context, opt ModRequestMessagesQu
 		}
 	}
 
-	return q.Messages(ctx, bson.M{
+	filter := bson.M{
 		"kind": structures.MessageKindModRequest,
-	}, MessageQueryOptions{
+	}
+
+	// Hide requests currently claimed by other moderators.
+	// The time is rounded so that the cached count of the query remains usable
+	if opt.HideClaimed && actor != nil {
+		filter["$or"] = bson.A{
+			bson.M{"claimed_by": bson.M{"$exists": false}},
+			bson.M{"claimed_by": actor.ID},
+			bson.M{"claim_expire_at": bson.M{"$lt": time.Now().Truncate(time.Minute)}},
+		}
+	}
+
+	return q.Messages(ctx, filter, MessageQueryOptions{
 		UnreadOnly:    true,
 		MessageFilter: opt.Filter,
 		Actor:         actor,
 		Sort:          opt.Sort,
 		Limit:         opt.Limit,
+		CountTotal:    opt.CountTotal,
 	})
 }
 
@@ -123,67 +136,17 @@ func (q *Query) Messages(ctx context.Context, filter bson.M, opt MessageQueryOpt
 	}
 
 	wg := sync.WaitGroup{}
-	wg.Add(1)
 
-	h := sha256.New()
+	// The total is counted alongside the page, only when it was asked for
+	if opt.CountTotal {
+		wg.Add(1)
 
-	fb, err := json.Marshal(filter)
-	if err == nil {
-		h.Write(fb)
-	}
-
-	rKey := q.redis.ComposeKey("api", "messages", hex.EncodeToString(h.Sum(nil)), "count")
-
-	go func() {
-		defer wg.Done()
+		go func() {
+			defer wg.Done()
 
-		count, err := q.redis.Get(ctx, rKey)
-		if err != redis.Nil {
-			n, _ := strconv.ParseInt(count, 10, 64)
-
-			qr.setTotal(n)
-			return
-		}
-
-		cur, err := q.mongo.Collection(mongo.CollectionNameMessagesRead).Aggregate(ctx, aggregations.Combine(
-			matcherPipeline,
-			func() mongo.Pipeline {
-				if len(opt.MessageFilter) == 0 {
-					return mongo.Pipeline{}
-				}
-
-				return mongo.Pipeline{
-					{{
-						Key: "$match",
-						Value: bson.M{
-							"message": bson.M{
-								"$elemMatch": opt.MessageFilter,
-							},
-						},
-					}}}
-			}(),
-			mongo.Pipeline{{{Key: "$count", Value: "count"}}},
-		))
-		if err != nil {
-			zap.S().Errorw("failed to count total messages", "error", err)
-
-			return
-		}
-
-		v := struct {
-			Count int64 `bson:"count"`
-		}{}
-
-		if cur.Next(ctx) {
-			if err = cur.Decode(&v); err != nil {
-				zap.S().Errorw("failed to decode total messages", "error", err)
-			}
-		}
-
-		qr.setTotal(v.Count)
-
-		q.redis.SetEX(ctx, rKey, v.Count, time.Minute*5)
-	}()
+			q.countMessages(ctx, qr, filter, matcherPipeline, opt)
+		}()
+	}
 
 	cur, err := q.mongo.Collection(mongo.CollectionNameMessagesRead).Aggregate(ctx, aggregations.Combine(
 		matcherPipeline,
@@ -246,6 +209,65 @@ func (q *Query) Messages(ctx context.Context, filter bson.M, opt MessageQueryOpt
 	return qr.setItems(v)
 }
 
+// countMessages sets the total of messages matching a query, which is cached for a while
+func (q *Query) countMessages(ctx context.Context, qr *QueryResult[structures.Message[bson.Raw]], filter bson.M, matcherPipeline mongo.Pipeline, opt MessageQueryOptions) {
+	h := sha256.New()
+
+	fb, err := json.Marshal(filter)
+	if err == nil {
+		h.Write(fb)
+	}
+
+	rKey := q.redis.ComposeKey("api", "messages", hex.EncodeToString(h.Sum(nil)), "count")
+
+	count, err := q.redis.Get(ctx, rKey)
+	if err != redis.Nil {
+		n, _ := strconv.ParseInt(count, 10, 64)
+
+		qr.setTotal(n)
+		return
+	}
+
+	cur, err := q.mongo.Collection(mongo.CollectionNameMessagesRead).Aggregate(ctx, aggregations.Combine(
+		matcherPipeline,
+		func() mongo.Pipeline {
+			if len(opt.MessageFilter) == 0 {
+				return mongo.Pipeline{}
+			}
+
+			return mongo.Pipeline{
+				{{
+					Key: "$match",
+					Value: bson.M{
+						"message": bson.M{
+							"$elemMatch": opt.MessageFilter,
+						},
+					},
+				}}}
+		}(),
+		mongo.Pipeline{{{Key: "$count", Value: "count"}}},
+	))
+	if err != nil {
+		zap.S().Errorw("failed to count total messages", "error", err)
+
+		return
+	}
+
+	v := struct {
+		Count int64 `bson:"count"`
+	}{}
+
+	if cur.Next(ctx) {
+		if err = cur.Decode(&v); err != nil {
+			zap.S().Errorw("failed to decode total messages", "error", err)
+		}
+	}
+
+	qr.setTotal(v.Count)
+
+	q.redis.SetEX(ctx, rKey, v.Count, time.Minute*5)
+}
+
 type InboxMessagesQueryOptions struct {
 	Actor               *structures.User
 	User                *structures.User // The user to fetch inbox messagesq from
@@ -261,7 +283,10 @@ type ModRequestMessagesQueryOptions struct {
 	Filter              bson.M
 	Sort                bson.D
 	Limit               int
+	HideClaimed         bool
 	SkipPermissionCheck bool
+	// Count the total of matching messages
+	CountTotal bool
 }
 
 type MessageQueryOptions struct {
@@ -271,4 +296,6 @@ type MessageQueryOptions struct {
 	MessageFilter    bson.M
 	FilterRecipients []primitive.ObjectID
 	Sort             bson.D
+	// Count the total of matching messages, which is otherwise left at zero
+	CountTotal bool
 }
@@ -0,0 +1,154 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+var CollectionNameModRequestDecisions mongo.CollectionName = "mod_request_decisions"
+
+type ModRequestDecisionKind string
+
+const (
+	ModRequestDecisionApprove ModRequestDecisionKind = "APPROVE"
+	ModRequestDecisionDeny    ModRequestDecisionKind = "DENY"
+)
+
+// ModRequestReason is a predefined reason a moderator gives for a decision on a mod request.
+// The label is a locale key, which is also sent to the creator
+type ModRequestReason struct {
+	ID    string                 `json:"id"`
+	Label string                 `json:"label"`
+	Kind  ModRequestDecisionKind `json:"kind"`
+	Wish  []string               `json:"wish,omitempty"` // the wishes this reason applies to. Empty for all
+	Note  bool                   `json:"note"`           // whether a note must be written along with this reason
+}
+
+// ModRequestReasons is the catalog of reasons for mod request decisions
+var ModRequestReasons = []ModRequestReason{
+	{ID: "approved", Label: "mod_request.reason.approved", Kind: ModRequestDecisionApprove},
+	{ID: "low_quality", Label: "mod_request.reason.low_quality", Kind: ModRequestDecisionDeny},
+	{ID: "duplicate", Label: "mod_request.reason.duplicate", Kind: ModRequestDecisionDeny, Wish: []string{"list"}},
+	{ID: "offensive", Label: "mod_request.reason.offensive", Kind: ModRequestDecisionDeny},
+	{ID: "copyright", Label: "mod_request.reason.copyright", Kind: ModRequestDecisionDeny},
+	{ID: "not_an_emote", Label: "mod_request.reason.not_an_emote", Kind: ModRequestDecisionDeny},
+	{ID: "bad_name", Label: "mod_request.reason.bad_name", Kind: ModRequestDecisionDeny, Wish: []string{"list"}},
+	{ID: "personal_use_unsuitable", Label: "mod_request.reason.personal_use_unsuitable", Kind: ModRequestDecisionDeny, Wish: []string{"personal_use"}},
+	{ID: "other", Label: "mod_request.reason.other", Kind: ModRequestDecisionDeny, Note: true},
+}
+
+// ModRequestReasonByID returns a reason from the catalog
+func ModRequestReasonByID(id string) (ModRequestReason, bool) {
+	for _, r := range ModRequestReasons {
+		if r.ID == id {
+			return r, true
+		}
+	}
+
+	return ModRequestReason{}, false
+}
+
+// AppliesTo tells whether the reason can be given for a request with this wish
+func (r ModRequestReason) AppliesTo(wish string) bool {
+	if len(r.Wish) == 0 {
+		return true
+	}
+
+	for _, w := range r.Wish {
+		if w == wish {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ModRequestDecision is the record of a moderator's decision on a mod request
+type ModRequestDecision struct {
+	ID          primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	MessageID   primitive.ObjectID     `json:"message_id" bson:"message_id"`
+	TargetKind  structures.ObjectKind  `json:"target_kind" bson:"target_kind"`
+	TargetID    primitive.ObjectID     `json:"target_id" bson:"target_id"`
+	Wish        string                 `json:"wish" bson:"wish"`
+	Kind        ModRequestDecisionKind `json:"kind" bson:"kind"`
+	ReasonID    string                 `json:"reason_id" bson:"reason_id"`
+	Note        string                 `json:"note,omitempty" bson:"note,omitempty"`
+	ModeratorID primitive.ObjectID     `json:"moderator_id" bson:"moderator_id"`
+	// when the request was created, claimed and decided, to measure the time spent on it
+	RequestedAt time.Time `json:"requested_at" bson:"requested_at"`
+	ClaimedAt   time.Time `json:"claimed_at" bson:"claimed_at"`
+	DecidedAt   time.Time `json:"decided_at" bson:"decided_at"`
+}
+
+// ModRequestReviewerStats is the throughput of a moderator reviewing mod requests
+type ModRequestReviewerStats struct {
+	ModeratorID primitive.ObjectID `json:"moderator_id" bson:"_id"`
+	Approved    int                `json:"approved" bson:"approved"`
+	Denied      int                `json:"denied" bson:"denied"`
+	// average time between claiming and deciding, in seconds
+	AverageReviewTime float64 `json:"average_review_time" bson:"average_review_time"`
+	// average time between the request and the decision, in seconds
+	AverageWaitTime float64   `json:"average_wait_time" bson:"average_wait_time"`
+	LastDecisionAt  time.Time `json:"last_decision_at" bson:"last_decision_at"`
+}
+
+type ModRequestReviewerStatsOptions struct {
+	After       time.Time
+	ModeratorID primitive.ObjectID
+}
+
+// ModRequestReviewerStats aggregates the decisions made by moderators, most active first
+func (q *Query) ModRequestReviewerStats(ctx context.Context, opt ModRequestReviewerStatsOptions) ([]ModRequestReviewerStats, error) {
+	result := []ModRequestReviewerStats{}
+
+	match := bson.M{}
+	if !opt.After.IsZero() {
+		match["decided_at"] = bson.M{"$gte": opt.After}
+	}
+
+	if !opt.ModeratorID.IsZero() {
+		match["moderator_id"] = opt.ModeratorID
+	}
+
+	cur, err := q.mongo.Collection(CollectionNameModRequestDecisions).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": "$moderator_id",
+			"approved": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$kind", ModRequestDecisionApprove}}, 1, 0},
+			}},
+			"denied": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$kind", ModRequestDecisionDeny}}, 1, 0},
+			}},
+			"average_review_time": bson.M{"$avg": bson.M{
+				"$divide": bson.A{bson.M{"$subtract": bson.A{"$decided_at", "$claimed_at"}}, 1000},
+			}},
+			"average_wait_time": bson.M{"$avg": bson.M{
+				"$divide": bson.A{bson.M{"$subtract": bson.A{"$decided_at", "$requested_at"}}, 1000},
+			}},
+			"last_decision_at": bson.M{"$max": "$decided_at"},
+			"total":            bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "total", Value: -1}, {Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		zap.S().Errorw("mongo, failed to aggregate mod request reviewer stats", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if err = cur.All(ctx, &result); err != nil {
+		zap.S().Errorw("mongo, failed to decode mod request reviewer stats", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	return result, nil
+}
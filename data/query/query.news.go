@@ -0,0 +1,277 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// NewsPost is a message of kind NEWS
+type NewsPost struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Kind      structures.MessageKind `json:"kind" bson:"kind"`
+	AuthorID  primitive.ObjectID     `json:"author_id" bson:"author_id,omitempty"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+	Data      NewsPostData           `json:"data" bson:"data"`
+
+	// Whether the post was dismissed by the user it was fetched for
+	Dismissed bool `json:"dismissed" bson:"-"`
+}
+
+type NewsPostData struct {
+	Title     string `json:"title" bson:"title"`
+	Content   string `json:"content" bson:"content"`
+	ImageURL  string `json:"image_url,omitempty" bson:"image_url,omitempty"`
+	Important bool   `json:"important,omitempty" bson:"important,omitempty"`
+	// The time range during which the post is live
+	StartsAt time.Time  `json:"starts_at" bson:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at,omitempty" bson:"ends_at,omitempty"`
+	// Who the post is shown to
+	Audience NewsAudience `json:"audience" bson:"audience"`
+	// Whether the post was announced to the Event API when it went live
+	Dispatched bool `json:"-" bson:"dispatched,omitempty"`
+}
+
+// NewsAudience restricts who a news post is shown to. Empty criteria match everyone
+type NewsAudience struct {
+	RoleIDs   []primitive.ObjectID `json:"role_ids,omitempty" bson:"role_ids,omitempty"`
+	Platforms []string             `json:"platforms,omitempty" bson:"platforms,omitempty"`
+	Locales   []string             `json:"locales,omitempty" bson:"locales,omitempty"`
+}
+
+// Matches tells whether a user with a locale is part of the audience
+func (a NewsAudience) Matches(user *structures.User, locale string) bool {
+	if len(a.RoleIDs) > 0 {
+		if user == nil || !a.hasRole(user) {
+			return false
+		}
+	}
+
+	if len(a.Platforms) > 0 {
+		if user == nil || !a.hasPlatform(user) {
+			return false
+		}
+	}
+
+	if len(a.Locales) > 0 {
+		if locale == "" || !a.hasLocale(locale) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a NewsAudience) hasRole(user *structures.User) bool {
+	for _, id := range a.RoleIDs {
+		for _, r := range user.Roles {
+			if r.ID == id {
+				return true
+			}
+		}
+
+		for _, rid := range user.RoleIDs {
+			if rid == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (a NewsAudience) hasPlatform(user *structures.User) bool {
+	for _, p := range a.Platforms {
+		for _, con := range user.Connections {
+			if strings.EqualFold(string(con.Platform), p) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasLocale matches a locale by language, i.e "pt-BR" is part of an audience targeting "pt"
+func (a NewsAudience) hasLocale(locale string) bool {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	for _, l := range a.Locales {
+		l = strings.ToLower(l)
+
+		if l == locale || strings.HasPrefix(locale, l+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsLive tells whether the post is within its time range
+func (p NewsPost) IsLive(t time.Time) bool {
+	return !p.Data.StartsAt.After(t) && (p.Data.EndsAt == nil || p.Data.EndsAt.After(t))
+}
+
+// NEWS_QUERY_LIMIT is how many news posts can be returned by a single query
+const NEWS_QUERY_LIMIT = 100
+
+// NEWS_SCAN_LIMIT is how many of the newest posts are considered by a query, before they are filtered by audience
+const NEWS_SCAN_LIMIT = 500
+
+type NewsQueryOptions struct {
+	Actor *structures.User
+	// The locale of the client, to match posts targeting locales
+	Locale string
+	// Include the posts the actor has dismissed
+	IncludeDismissed bool
+	// Include posts which are scheduled or have ended, and ignore the audience. Requires MANAGE_CONTENT
+	IncludeInactive bool
+	Page            int
+	Limit           int
+}
+
+// NewsPost returns a single news post
+func (q *Query) NewsPost(ctx context.Context, id primitive.ObjectID) (NewsPost, error) {
+	post := NewsPost{}
+
+	if err := q.mongo.Collection(mongo.CollectionNameMessages).FindOne(ctx, bson.M{
+		"_id":  id,
+		"kind": structures.MessageKindNews,
+	}).Decode(&post); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return post, errors.ErrUnknownMessage()
+		}
+
+		zap.S().Errorw("mongo, failed to find news post", "error", err)
+
+		return post, errors.ErrInternalServerError()
+	}
+
+	return post, nil
+}
+
+// News returns a page of the news posts visible to the actor, important ones first, then newest first
+func (q *Query) News(ctx context.Context, opt NewsQueryOptions) ([]NewsPost, error) {
+	result := []NewsPost{}
+	now := time.Now()
+
+	manager := opt.Actor != nil && opt.Actor.HasPermission(structures.RolePermissionManageContent)
+	inactive := opt.IncludeInactive && manager
+
+	filter := bson.M{"kind": structures.MessageKindNews}
+	if !inactive {
+		filter["data.starts_at"] = bson.M{"$lte": now}
+		filter["$or"] = bson.A{
+			bson.M{"data.ends_at": nil},
+			bson.M{"data.ends_at": bson.M{"$gt": now}},
+		}
+	}
+
+	cur, err := q.mongo.Collection(mongo.CollectionNameMessages).Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "data.important", Value: -1}, {Key: "data.starts_at", Value: -1}}).
+		SetLimit(NEWS_SCAN_LIMIT),
+	)
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query news", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	posts := []NewsPost{}
+	if err = cur.All(ctx, &posts); err != nil {
+		zap.S().Errorw("mongo, failed to decode news", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	// Find the posts dismissed by the actor
+	dismissed := map[primitive.ObjectID]bool{}
+
+	if opt.Actor != nil && !opt.Actor.ID.IsZero() && len(posts) > 0 {
+		ids := make([]primitive.ObjectID, len(posts))
+		for i, p := range posts {
+			ids[i] = p.ID
+		}
+
+		cur, err := q.mongo.Collection(mongo.CollectionNameMessagesRead).Find(ctx, bson.M{
+			"message_id":   bson.M{"$in": ids},
+			"recipient_id": opt.Actor.ID,
+			"read":         true,
+		}, options.Find().SetProjection(bson.M{"message_id": 1}))
+		if err != nil {
+			zap.S().Errorw("mongo, failed to query dismissed news", "error", err)
+
+			return result, errors.ErrInternalServerError()
+		}
+
+		states := []structures.MessageRead{}
+		if err = cur.All(ctx, &states); err != nil {
+			zap.S().Errorw("mongo, failed to decode dismissed news", "error", err)
+
+			return result, errors.ErrInternalServerError()
+		}
+
+		for _, rs := range states {
+			dismissed[rs.MessageID] = true
+		}
+	}
+
+	// Users who opted out of announcements only see the important ones
+	announcements := true
+
+	if opt.Actor != nil && !opt.Actor.ID.IsZero() && !inactive {
+		settings, err := q.NotificationSettings(ctx, opt.Actor.ID)
+		if err != nil {
+			return result, err
+		}
+
+		announcements = settings.Enabled(NotificationCategoryAnnouncements, NotificationChannelInbox)
+	}
+
+	for _, p := range posts {
+		p.Dismissed = dismissed[p.ID]
+
+		if !announcements && !p.Data.Important {
+			continue
+		}
+
+		if !inactive && !p.Data.Audience.Matches(opt.Actor, opt.Locale) {
+			continue
+		}
+
+		if p.Dismissed && !opt.IncludeDismissed {
+			continue
+		}
+
+		result = append(result, p)
+	}
+
+	// Paginate the posts visible to the actor
+	if opt.Limit < 1 || opt.Limit > NEWS_QUERY_LIMIT {
+		opt.Limit = NEWS_QUERY_LIMIT
+	}
+
+	if opt.Page < 1 {
+		opt.Page = 1
+	}
+
+	start := (opt.Page - 1) * opt.Limit
+	if start >= len(result) {
+		return []NewsPost{}, nil
+	}
+
+	end := start + opt.Limit
+	if end > len(result) {
+		end = len(result)
+	}
+
+	return result[start:end], nil
+}
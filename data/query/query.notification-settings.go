@@ -0,0 +1,180 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+var CollectionNameNotificationSettings mongo.CollectionName = "user_notification_settings"
+
+// NotificationCategory is a kind of notification users can opt in or out of
+type NotificationCategory string
+
+const (
+	NotificationCategoryBans           NotificationCategory = "BANS"
+	NotificationCategoryModRequests    NotificationCategory = "MOD_REQUESTS"
+	NotificationCategoryEmoteOwnership NotificationCategory = "EMOTE_OWNERSHIP"
+	NotificationCategoryEditors        NotificationCategory = "EDITORS"
+	NotificationCategoryReports        NotificationCategory = "REPORTS"
+	NotificationCategoryAnnouncements  NotificationCategory = "ANNOUNCEMENTS"
+	NotificationCategoryDirect         NotificationCategory = "DIRECT"
+)
+
+// NotificationChannel is a way of delivering notifications
+type NotificationChannel string
+
+const (
+	NotificationChannelInbox NotificationChannel = "inbox"
+	// Messages are whispered to the Event API sessions of users who opted in
+	NotificationChannelWhisper NotificationChannel = "whisper"
+)
+
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelInbox,
+	NotificationChannelWhisper,
+}
+
+// NotificationCategoryInfo describes a category and the default state of its channels
+type NotificationCategoryInfo struct {
+	Category NotificationCategory
+	// Mandatory categories cannot be turned off in the inbox
+	Mandatory bool
+	Defaults  map[NotificationChannel]bool
+}
+
+// NotificationCategories is the list of categories, in display order.
+// Whispers are off unless the user turns them on
+var NotificationCategories = []NotificationCategoryInfo{
+	{Category: NotificationCategoryBans, Mandatory: true, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+	{Category: NotificationCategoryModRequests, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+	{Category: NotificationCategoryEmoteOwnership, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+	{Category: NotificationCategoryEditors, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+	{Category: NotificationCategoryReports, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+	{Category: NotificationCategoryAnnouncements, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+	{Category: NotificationCategoryDirect, Defaults: map[NotificationChannel]bool{
+		NotificationChannelInbox: true, NotificationChannelWhisper: false,
+	}},
+}
+
+// NotificationCategoryByName returns the description of a category
+func NotificationCategoryByName(c NotificationCategory) (NotificationCategoryInfo, bool) {
+	for _, info := range NotificationCategories {
+		if info.Category == c {
+			return info, true
+		}
+	}
+
+	return NotificationCategoryInfo{}, false
+}
+
+// NotificationSettings are the choices of a user about the notifications they receive.
+// Categories and channels which are not set use their default
+type NotificationSettings struct {
+	UserID     primitive.ObjectID                                    `json:"user_id" bson:"_id"`
+	Categories map[NotificationCategory]map[NotificationChannel]bool `json:"categories" bson:"categories"`
+	UpdatedAt  time.Time                                             `json:"updated_at" bson:"updated_at"`
+}
+
+// Enabled tells whether the user receives notifications of a category through a channel
+func (s NotificationSettings) Enabled(c NotificationCategory, ch NotificationChannel) bool {
+	info, ok := NotificationCategoryByName(c)
+	if !ok {
+		return ch == NotificationChannelInbox
+	}
+
+	if info.Mandatory && ch == NotificationChannelInbox {
+		return true
+	}
+
+	if v, ok := s.Categories[c][ch]; ok {
+		return v
+	}
+
+	return info.Defaults[ch]
+}
+
+// NotificationSettings returns the notification settings of a user
+func (q *Query) NotificationSettings(ctx context.Context, userID primitive.ObjectID) (NotificationSettings, error) {
+	s := NotificationSettings{UserID: userID}
+
+	if err := q.mongo.Collection(CollectionNameNotificationSettings).FindOne(ctx, bson.M{
+		"_id": userID,
+	}).Decode(&s); err != nil && err != mongo.ErrNoDocuments {
+		zap.S().Errorw("mongo, failed to find notification settings", "error", err)
+
+		return s, errors.ErrInternalServerError()
+	}
+
+	return s, nil
+}
+
+// NotificationRecipients returns the users from a list who receive notifications of a category through a channel
+func (q *Query) NotificationRecipients(ctx context.Context, c NotificationCategory, ch NotificationChannel, userIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return NotificationRecipients(ctx, q.mongo, c, ch, userIDs)
+}
+
+// NotificationRecipients returns the users from a list who receive notifications of a category through a channel.
+// Messages without a known category are only delivered to the inbox
+func NotificationRecipients(ctx context.Context, mi mongo.Instance, c NotificationCategory, ch NotificationChannel, userIDs []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	info, ok := NotificationCategoryByName(c)
+	if !ok {
+		return utils.Ternary(ch == NotificationChannelInbox, userIDs, []primitive.ObjectID{}), nil
+	}
+
+	if (info.Mandatory && ch == NotificationChannelInbox) || len(userIDs) == 0 {
+		return userIDs, nil
+	}
+
+	// Find the users whose choice differs from the default
+	cur, err := mi.Collection(CollectionNameNotificationSettings).Find(ctx, bson.M{
+		"_id": bson.M{"$in": userIDs},
+		"categories." + string(c) + "." + string(ch): !info.Defaults[ch],
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query notification settings", "error", err)
+
+		return nil, errors.ErrInternalServerError()
+	}
+
+	settings := []NotificationSettings{}
+	if err = cur.All(ctx, &settings); err != nil {
+		zap.S().Errorw("mongo, failed to decode notification settings", "error", err)
+
+		return nil, errors.ErrInternalServerError()
+	}
+
+	changed := make(map[primitive.ObjectID]bool, len(settings))
+	for _, s := range settings {
+		changed[s.UserID] = true
+	}
+
+	result := make([]primitive.ObjectID, 0, len(userIDs))
+
+	for _, id := range userIDs {
+		if changed[id] != info.Defaults[ch] {
+			result = append(result, id)
+		}
+	}
+
+	return result, nil
+}
@@ -17,15 +17,19 @@ import (
 
 const EMOTES_QUERY_LIMIT = 300
 
-func (q *Query) SearchEmotes(ctx context.Context, opt SearchEmotesOptions) ([]structures.Emote, int, error) {
+// CASE_SENSITIVE_SEARCH_LIMIT is how many matches of the index are checked for a case sensitive search which is not exact,
+// as the index cannot match parts of names case sensitively
+const CASE_SENSITIVE_SEARCH_LIMIT = 1000
+
+func (q *Query) SearchEmotes(ctx context.Context, opt SearchEmotesOptions) (SearchEmotesResult, error) {
+	res := SearchEmotesResult{}
+
 	req := search.EmoteSearchOptions{
-		Limit: int64(opt.Limit),
-		Page:  int64(opt.Page),
-		Sort: search.EmoteSortOptions{
-			By:        "channel_count",
-			Ascending: false,
-		},
+		Limit:     int64(opt.Limit),
+		Page:      int64(opt.Page),
+		Sort:      EmoteSearchSort(opt.Sort),
 		Lifecycle: int32(structures.EmoteLifecycleLive),
+		Facets:    opt.Facets,
 	}
 
 	// Define limit (how many emotes can be returned in a single query)
@@ -40,25 +44,54 @@ func (q *Query) SearchEmotes(ctx context.Context, opt SearchEmotesOptions) ([]st
 		req.Page = 1
 	}
 
-	if opt.Filter != nil {
-		if opt.Filter.ExactMatch != nil {
-			req.Exact = *opt.Filter.ExactMatch
+	// Define the query string
+	query := strings.Trim(opt.Query, " ")
+
+	caseSensitive := false
+
+	if f := opt.Filter; f != nil {
+		req.Exact = f.ExactMatch != nil && *f.ExactMatch
+		req.IgnoreTags = f.IgnoreTags != nil && *f.IgnoreTags
+		req.Animated = f.Animated
+		req.ZeroWidth = f.ZeroWidth
+		req.Authentic = f.Authentic
+		req.PersonalUse = f.PersonalUse
+		req.AspectRatio = f.AspectRatio
+		req.Tags = f.Tags
+		req.MatchAnyTag = f.MatchAnyTag
+		req.CreatedAfter = f.CreatedAfter
+		req.CreatedBefore = f.CreatedBefore
+
+		if !f.OwnerID.IsZero() {
+			req.OwnerID = f.OwnerID.Hex()
 		}
-	}
 
-	// Define default filter
-	//filter := opt.Filter
-	//if filter == nil {
-	//	filter = &SearchEmotesFilter{
-	//		CaseSensitive: utils.PointerOf(false),
-	//		ExactMatch:    utils.PointerOf(false),
-	//		IgnoreTags:    utils.PointerOf(false),
-	//		Document:      bson.M{},
-	//	}
-	//}
+		if f.IDs != nil {
+			req.IDs = make([]string, len(f.IDs))
+			for i, id := range f.IDs {
+				req.IDs[i] = id.Hex()
+			}
+		}
 
-	// Define the query string
-	query := strings.Trim(opt.Query, " ")
+		caseSensitive = f.CaseSensitive != nil && *f.CaseSensitive && query != ""
+	}
+
+	// The query is matched case insensitively. An exact name is filtered on by the index,
+	// other case sensitive searches are matched against the names of the first results
+	var (
+		page  = req.Page
+		limit = req.Limit
+	)
+
+	if caseSensitive {
+		if req.Exact {
+			req.Name = query
+			caseSensitive = false
+		} else {
+			req.Page = 1
+			req.Limit = CASE_SENSITIVE_SEARCH_LIMIT
+		}
+	}
 
 	// Set up db query
 
@@ -68,42 +101,100 @@ func (q *Query) SearchEmotes(ctx context.Context, opt SearchEmotesOptions) ([]st
 		req.Listed = true
 	}
 
-	if opt.Sort != nil && len(opt.Sort) > 0 {
-		for key, value := range opt.Sort {
-			sort := search.EmoteSortOptions{
-				By: key,
-			}
-			if value.(int32) > 0 {
-				sort.Ascending = true
-			}
-			req.Sort = sort
-			break
-		}
+	result, err := q.search.SearchEmotes(query, req)
+	if err != nil {
+		zap.S().Errorw("search, failed to search emotes", "error", err)
+
+		return res, errors.ErrInternalServerError()
 	}
 
-	result, totalCount, err := q.search.SearchEmotes(query, req)
+	totalCount := result.Total
+	res.Facets = result.Facets
+
+	hits := result.Emotes
+
+	if caseSensitive {
+		hits, totalCount = pageCaseSensitive(hits, query, page, limit)
+	}
 
-	emoteIds := []primitive.ObjectID{}
+	emoteIds := make([]primitive.ObjectID, len(hits))
 
-	for _, emote := range result {
-		id, _ := primitive.ObjectIDFromHex(emote.Id)
-		emoteIds = append(emoteIds, id)
+	for i, emote := range hits {
+		emoteIds[i], _ = primitive.ObjectIDFromHex(emote.Id)
 	}
 
 	emotes, err := q.Emotes(ctx, bson.M{"_id": bson.M{"$in": emoteIds}}).Items()
 	if err != nil {
 		if errors.Compare(err, errors.ErrNoItems()) {
-			return nil, 0, errors.ErrNoItems().SetDetail("Search returned no results")
+			return res, errors.ErrNoItems().SetDetail("Search returned no results")
 		}
 
 		zap.S().Errorw("mongo, failed to find emotes() gql query",
 			"error", err,
 		)
 
-		return nil, 0, errors.ErrInternalServerError().SetDetail(err.Error())
+		return res, errors.ErrInternalServerError().SetDetail(err.Error())
 	}
 
-	return sortEmoteSearchResults(emotes, req.Sort.By, req.Sort.Ascending), int(totalCount), nil
+	res.Items = sortEmoteSearchResults(emotes, req.Sort.By, req.Sort.Ascending)
+	res.TotalCount = int(totalCount)
+
+	return res, nil
+}
+
+// EmoteSearchSort reads the sort of a search, mapping a field to its order where a positive value is ascending.
+// Without a sort, emotes are sorted by popularity
+func EmoteSearchSort(sort bson.M) search.EmoteSortOptions {
+	for key, value := range sort {
+		var order int64
+
+		switch v := value.(type) {
+		case int:
+			order = int64(v)
+		case int32:
+			order = int64(v)
+		case int64:
+			order = v
+		case float64:
+			order = int64(v)
+		}
+
+		return search.EmoteSortOptions{
+			By:        key,
+			Ascending: order > 0,
+		}
+	}
+
+	return search.EmoteSortOptions{
+		By:        "channel_count",
+		Ascending: false,
+	}
+}
+
+// pageCaseSensitive keeps the hits whose name contains the query with the same case,
+// returning the requested page of them and how many there are
+func pageCaseSensitive(hits []search.EmoteResult, query string, page int64, limit int64) ([]search.EmoteResult, int64) {
+	matched := make([]search.EmoteResult, 0, len(hits))
+
+	for _, h := range hits {
+		if strings.Contains(h.Name, query) {
+			matched = append(matched, h)
+		}
+	}
+
+	total := int64(len(matched))
+
+	start := (page - 1) * limit
+	if start >= total {
+		return []search.EmoteResult{}, total
+	}
+
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return matched[start:end], total
 }
 
 func sortEmoteSearchResults(emotes []structures.Emote, sortBy string, ascending bool) []structures.Emote {
@@ -169,11 +260,33 @@ type SearchEmotesOptions struct {
 	Filter *SearchEmotesFilter
 	Sort   bson.M
 	Actor  *structures.User
+	// Whether to compute facet counts for the query
+	Facets bool
+}
+
+type SearchEmotesResult struct {
+	Items      []structures.Emote
+	TotalCount int
+	// Only set when facets were requested
+	Facets *search.EmoteFacets
 }
 
 type SearchEmotesFilter struct {
-	CaseSensitive *bool  `json:"cs"`
-	ExactMatch    *bool  `json:"exm"`
-	IgnoreTags    *bool  `json:"ignt"`
-	Document      bson.M `json:"doc"`
+	CaseSensitive *bool                          `json:"cs"`
+	ExactMatch    *bool                          `json:"exm"`
+	IgnoreTags    *bool                          `json:"ignt"`
+	Animated      *bool                          `json:"anim"`
+	ZeroWidth     *bool                          `json:"zw"`
+	Authentic     *bool                          `json:"auth"`
+	PersonalUse   *bool                          `json:"pu"`
+	AspectRatio   *search.EmoteAspectRatioFilter `json:"ar"`
+	// Restrict the results to these emotes
+	IDs []primitive.ObjectID `json:"ids"`
+	// Restrict the results to emotes with all of these tags, or any of them with MatchAnyTag
+	Tags        []string           `json:"tags"`
+	MatchAnyTag bool               `json:"tags_any"`
+	OwnerID     primitive.ObjectID `json:"owner_id"`
+	// Restrict the results to emotes created within a date range
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
 }
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/seventv/api/internal/search"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestEmoteSearchSort(t *testing.T) {
+	cases := []struct {
+		name      string
+		sort      bson.M
+		by        string
+		ascending bool
+	}{
+		{"default", nil, "channel_count", false},
+		{"empty", bson.M{}, "channel_count", false},
+		{"int descending", bson.M{"created_at": -1}, "created_at", false},
+		{"int ascending", bson.M{"created_at": 1}, "created_at", true},
+		{"int32 descending", bson.M{"channel_count": int32(-1)}, "channel_count", false},
+		{"int32 ascending", bson.M{"channel_count": int32(1)}, "channel_count", true},
+		{"int64 ascending", bson.M{"channel_count": int64(1)}, "channel_count", true},
+		{"float64 ascending", bson.M{"channel_count": float64(1)}, "channel_count", true},
+		{"unknown type", bson.M{"channel_count": "asc"}, "channel_count", false},
+	}
+
+	for _, c := range cases {
+		s := EmoteSearchSort(c.sort)
+
+		testutil.Assert(t, c.by, s.By, c.name+": field")
+		testutil.Assert(t, c.ascending, s.Ascending, c.name+": order")
+	}
+}
+
+func TestPageCaseSensitive(t *testing.T) {
+	hits := []search.EmoteResult{
+		{Id: "1", Name: "KEKW"},
+		{Id: "2", Name: "kekw"},
+		{Id: "3", Name: "KEKWait"},
+		{Id: "4", Name: "xKEKWx"},
+		{Id: "5", Name: "Kekw"},
+	}
+
+	page, total := pageCaseSensitive(hits, "KEKW", 1, 2)
+	testutil.Assert(t, int64(3), total, "only names with the same case are counted")
+	testutil.Assert(t, 2, len(page), "the first page is full")
+	testutil.Assert(t, "1", page[0].Id, "the order of the index is kept")
+	testutil.Assert(t, "3", page[1].Id, "the order of the index is kept")
+
+	page, total = pageCaseSensitive(hits, "KEKW", 2, 2)
+	testutil.Assert(t, int64(3), total, "the count does not depend on the page")
+	testutil.Assert(t, 1, len(page), "the last page has the remaining match")
+	testutil.Assert(t, "4", page[0].Id, "the last page has the remaining match")
+
+	page, total = pageCaseSensitive(hits, "KEKW", 3, 2)
+	testutil.Assert(t, int64(3), total, "the count does not depend on the page")
+	testutil.Assert(t, 0, len(page), "pages past the end are empty")
+}
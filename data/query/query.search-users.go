@@ -2,9 +2,10 @@ package query
 
 import (
 	"context"
+	"regexp"
 	"strings"
+	"time"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/structures/v3"
 	"github.com/seventv/common/structures/v3/aggregations"
@@ -13,24 +14,22 @@ import (
 	"go.uber.org/zap"
 )
 
-func (q *Query) SearchUsers(ctx context.Context, filter bson.M, opts ...UserSearchOptions) ([]structures.User, error) {
+// SearchUsers returns the users matching a filter and search options, along with the total count of matches
+func (q *Query) SearchUsers(ctx context.Context, filter bson.M, opts ...UserSearchOptions) ([]structures.User, int, error) {
 	mtx := q.mtx("SearchUsers")
 	mtx.Lock()
 	defer mtx.Unlock()
 
 	items := []structures.User{}
 
+	search := mongo.Pipeline{}
 	paginate := mongo.Pipeline{}
 
 	if len(opts) > 0 {
 		opt := opts[0]
-		sort := bson.M{"searchIndex": 1, "exact": -1}
+		sort := userSearchSort(opt.Sort)
 
-		for k, v := range opt.Sort {
-			sort[k] = v
-		}
-
-		paginate = append(paginate, []bson.D{
+		search = append(search, []bson.D{
 			{{
 				Key: "$set",
 				Value: bson.M{
@@ -48,7 +47,20 @@ func (q *Query) SearchUsers(ctx context.Context, filter bson.M, opts ...UserSear
 			{{Key: "$match", Value: bson.M{
 				"searchIndex": bson.M{"$gte": 0},
 			}}},
+		}...)
+
+		if opt.Filter != nil {
+			search = append(search, opt.Filter.pipeline()...)
+		}
+
+		page := opt.Page
+		if page < 1 {
+			page = 1
+		}
+
+		paginate = append(paginate, []bson.D{
 			{{Key: "$sort", Value: sort}},
+			{{Key: "$skip", Value: (page - 1) * opt.Limit}},
 			{{Key: "$limit", Value: opt.Limit}},
 		}...)
 	}
@@ -60,27 +72,197 @@ func (q *Query) SearchUsers(ctx context.Context, filter bson.M, opts ...UserSear
 				Value: filter,
 			}},
 		},
-		paginate,
+		search,
+		mongo.Pipeline{
+			{{
+				Key: "$facet",
+				Value: bson.M{
+					"users":       aggregations.Combine(paginate, mongo.Pipeline{{{Key: "$unset", Value: bson.A{"searchIndex", "exact", "_active_bans", "_owned_emotes", "_role_entitlements"}}}}),
+					"total_count": bson.A{bson.M{"$count": "count"}},
+				},
+			}},
+			{{
+				Key:   "$set",
+				Value: bson.M{"total_count": bson.M{"$ifNull": bson.A{bson.M{"$first": "$total_count.count"}, 0}}},
+			}},
+		},
 	))
 	if err != nil {
 		zap.S().Errorw("failed to aggregate search users", "error", err)
 
-		return items, err
+		return items, 0, err
 	}
 
-	// Map all objects
-	if err := cur.All(ctx, &items); err != nil {
-		return items, nil // nothing found!
+	defer cur.Close(ctx)
+
+	result := aggregatedUsersResult{}
+
+	if cur.Next(ctx) {
+		if err = cur.Decode(&result); err != nil {
+			zap.S().Errorw("failed to decode search users", "error", err)
+
+			return items, 0, err
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		zap.S().Errorw("failed to iterate search users", "error", err)
+
+		return items, 0, err
+	}
+
+	items = append(items, result.Users...)
+
+	return items, result.TotalCount, nil
+}
+
+// userSearchSort puts a requested sort first, with the closest matches breaking ties.
+// Otherwise the closest matches come first, followed by the most prominent users
+func userSearchSort(requested bson.D) bson.D {
+	relevance := bson.D{{Key: "exact", Value: -1}, {Key: "searchIndex", Value: 1}}
+
+	if len(requested) > 0 {
+		return append(append(bson.D{}, requested...), relevance...)
 	}
 
-	return items, multierror.Append(err, cur.Close(ctx)).ErrorOrNil()
+	return append(relevance,
+		bson.E{Key: "state.role_position", Value: -1},
+		bson.E{Key: "connections.data.view_count", Value: -1},
+	)
 }
 
 type UserSearchOptions struct {
 	Limit int
+	Page  int
 	Query string
-	Sort  bson.M
+	// Sort is the order requested by the caller, which takes precedence over relevance
+	Sort   bson.D
+	Filter *UserSearchFilter
+}
+
+type UserSearchFilter struct {
+	// Match users with a connection on this platform
+	ConnectionPlatform structures.UserConnectionPlatform
+	// Match users with a connection whose ID starts with this value
+	ConnectionIDPrefix string
+	// Match users who have this role, bound directly or through an entitlement
+	RoleID primitive.ObjectID
+	// Match users created within a date range
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Match users who are or aren't currently banned
+	Banned *bool
+	// Match users by the amount of emotes they own
+	MinEmotes *int
+	MaxEmotes *int
 }
+
+func (f *UserSearchFilter) pipeline() mongo.Pipeline {
+	p := mongo.Pipeline{}
+	match := bson.M{}
+
+	if f.ConnectionPlatform != "" || f.ConnectionIDPrefix != "" {
+		elem := bson.M{}
+
+		if f.ConnectionPlatform != "" {
+			elem["platform"] = f.ConnectionPlatform
+		}
+
+		if f.ConnectionIDPrefix != "" {
+			elem["id"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.ConnectionIDPrefix)}
+		}
+
+		match["connections"] = bson.M{"$elemMatch": elem}
+	}
+
+	if f.CreatedAfter != nil || f.CreatedBefore != nil {
+		id := bson.M{}
+
+		if f.CreatedAfter != nil {
+			id["$gte"] = primitive.NewObjectIDFromTimestamp(*f.CreatedAfter)
+		}
+
+		if f.CreatedBefore != nil {
+			id["$lte"] = primitive.NewObjectIDFromTimestamp(*f.CreatedBefore)
+		}
+
+		match["_id"] = id
+	}
+
+	if len(match) > 0 {
+		p = append(p, bson.D{{Key: "$match", Value: match}})
+	}
+
+	if !f.RoleID.IsZero() {
+		p = append(p, []bson.D{
+			{{Key: "$lookup", Value: bson.M{
+				"from": mongo.CollectionNameEntitlements,
+				"let":  bson.M{"uid": "$_id"},
+				"pipeline": mongo.Pipeline{
+					{{Key: "$match", Value: bson.M{
+						"kind":     structures.EntitlementKindRole,
+						"data.ref": f.RoleID,
+						"disabled": bson.M{"$ne": true},
+						"$expr":    bson.M{"$eq": bson.A{"$user_id", "$$uid"}},
+					}}},
+					{{Key: "$limit", Value: 1}},
+				},
+				"as": "_role_entitlements",
+			}}},
+			{{Key: "$match", Value: bson.M{"$or": bson.A{
+				bson.M{"role_ids": f.RoleID},
+				bson.M{"_role_entitlements.0": bson.M{"$exists": true}},
+			}}}},
+		}...)
+	}
+
+	if f.Banned != nil {
+		p = append(p, []bson.D{
+			{{Key: "$lookup", Value: bson.M{
+				"from": mongo.CollectionNameBans,
+				"let":  bson.M{"uid": "$_id"},
+				"pipeline": mongo.Pipeline{
+					{{Key: "$match", Value: bson.M{"$expr": bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{"$victim_id", "$$uid"}},
+						bson.M{"$gt": bson.A{"$expire_at", "$$NOW"}},
+					}}}}},
+					{{Key: "$limit", Value: 1}},
+				},
+				"as": "_active_bans",
+			}}},
+			{{Key: "$match", Value: bson.M{"_active_bans.0": bson.M{"$exists": *f.Banned}}}},
+		}...)
+	}
+
+	if f.MinEmotes != nil || f.MaxEmotes != nil {
+		count := bson.M{}
+
+		if f.MinEmotes != nil {
+			count["$gte"] = *f.MinEmotes
+		}
+
+		if f.MaxEmotes != nil {
+			count["$lte"] = *f.MaxEmotes
+		}
+
+		p = append(p, []bson.D{
+			{{Key: "$lookup", Value: bson.M{
+				"from": mongo.CollectionNameEmotes,
+				"let":  bson.M{"uid": "$_id"},
+				"pipeline": mongo.Pipeline{
+					{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{"$owner_id", "$$uid"}}}}},
+					{{Key: "$project", Value: bson.M{"_id": 1}}},
+				},
+				"as": "_owned_emotes",
+			}}},
+			{{Key: "$set", Value: bson.M{"_owned_emotes": bson.M{"$size": "$_owned_emotes"}}}},
+			{{Key: "$match", Value: bson.M{"_owned_emotes": count}}},
+		}...)
+	}
+
+	return p
+}
+
 type aggregatedUsersResult struct {
 	Users            []structures.User                  `bson:"users"`
 	RoleEntitlements []structures.Entitlement[bson.Raw] `bson:"role_entitlements"`
@@ -0,0 +1,34 @@
+package query
+
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestUserSearchSort(t *testing.T) {
+	keys := func(d bson.D) string {
+		v := make([]string, len(d))
+		for i, e := range d {
+			v[i] = e.Key
+		}
+
+		return strings.Join(v, ",")
+	}
+
+	testutil.Assert(t,
+		"exact,searchIndex,state.role_position,connections.data.view_count",
+		keys(userSearchSort(nil)),
+		"without a requested sort the closest matches come first",
+	)
+
+	requested := bson.D{{Key: "_id", Value: 1}}
+	sort := userSearchSort(requested)
+
+	testutil.Assert(t, "_id,exact,searchIndex", keys(sort), "a requested sort comes first")
+	testutil.Assert(t, 1, sort[0].Value.(int), "a requested sort keeps its order")
+	testutil.Assert(t, 1, len(requested), "the requested sort is left as is")
+}
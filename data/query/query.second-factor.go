@@ -0,0 +1,99 @@
+package query
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+var CollectionNameSecondFactors mongo.CollectionName = "user_second_factors"
+
+const (
+	// SECOND_FACTOR_MAX_ATTEMPTS is how many invalid codes a user may enter before they are locked out
+	SECOND_FACTOR_MAX_ATTEMPTS = 5
+	// SECOND_FACTOR_LOCKOUT is for how long invalid codes are counted, which is also how long a lockout lasts
+	SECOND_FACTOR_LOCKOUT = 15 * time.Minute
+)
+
+// SecondFactor is a user's TOTP enrollment
+type SecondFactor struct {
+	UserID primitive.ObjectID `json:"user_id" bson:"_id"`
+	// the base32-encoded TOTP secret
+	Secret string `json:"-" bson:"secret"`
+	// whether the enrollment was confirmed with a valid code
+	Enabled   bool      `json:"enabled" bson:"enabled"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	EnabledAt time.Time `json:"enabled_at,omitempty" bson:"enabled_at,omitempty"`
+}
+
+// SecondFactorVerificationKey is the redis key marking a user as having recently passed a second-factor check
+func SecondFactorVerificationKey(r redis.Instance, userID primitive.ObjectID) redis.Key {
+	return r.ComposeKey("api", "second-factor", "verified", userID.Hex())
+}
+
+// SecondFactorAttemptsKey is the redis key counting the invalid codes a user entered recently
+func SecondFactorAttemptsKey(r redis.Instance, userID primitive.ObjectID) redis.Key {
+	return r.ComposeKey("api", "second-factor", "attempts", userID.Hex())
+}
+
+// SecondFactorLastStepKey is the redis key holding the time step of the last code a user entered successfully
+func SecondFactorLastStepKey(r redis.Instance, userID primitive.ObjectID) redis.Key {
+	return r.ComposeKey("api", "second-factor", "last-step", userID.Hex())
+}
+
+// SecondFactorStepKey is the redis key marking the code of a time step as used by a user
+func SecondFactorStepKey(r redis.Instance, userID primitive.ObjectID, step int64) redis.Key {
+	return r.ComposeKey("api", "second-factor", "step", userID.Hex(), strconv.FormatInt(step, 10))
+}
+
+// SecondFactor returns the user's second factor enrollment, or ErrNoItems if there is none
+func (q *Query) SecondFactor(ctx context.Context, userID primitive.ObjectID) (SecondFactor, error) {
+	sf := SecondFactor{}
+
+	if err := q.mongo.Collection(CollectionNameSecondFactors).FindOne(ctx, bson.M{"_id": userID}).Decode(&sf); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return sf, errors.ErrNoItems()
+		}
+
+		zap.S().Errorw("mongo, failed to query second factor", "error", err)
+
+		return sf, errors.ErrInternalServerError()
+	}
+
+	return sf, nil
+}
+
+// SecondFactorVerified returns whether or not the user has passed a second-factor check recently
+func (q *Query) SecondFactorVerified(ctx context.Context, userID primitive.ObjectID) bool {
+	n, err := q.redis.Exists(ctx, SecondFactorVerificationKey(q.redis, userID))
+	if err != nil {
+		zap.S().Errorw("redis, failed to check second factor verification", "error", err)
+
+		return false
+	}
+
+	return n > 0
+}
+
+// SecondFactorLocked returns whether the user entered too many invalid codes recently
+func (q *Query) SecondFactorLocked(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	s, err := q.redis.Get(ctx, SecondFactorAttemptsKey(q.redis, userID))
+	if err == redis.Nil {
+		return false, nil
+	} else if err != nil {
+		zap.S().Errorw("redis, failed to check second factor attempts", "error", err)
+
+		return false, errors.ErrInternalServerError()
+	}
+
+	n, _ := strconv.Atoi(s)
+
+	return n >= SECOND_FACTOR_MAX_ATTEMPTS, nil
+}
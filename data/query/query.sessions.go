@@ -0,0 +1,130 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/redis"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+var CollectionNameSessions mongo.CollectionName = "user_sessions"
+
+// Session is a login to the app, bound to an access token
+type Session struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id"`
+	UserID primitive.ObjectID `json:"user_id" bson:"user_id"`
+	// the user agent of the client which signed in
+	UserAgent string `json:"user_agent" bson:"user_agent"`
+	// the region the session was last seen from
+	CountryCode string    `json:"country_code,omitempty" bson:"country_code,omitempty"`
+	CountryName string    `json:"country_name,omitempty" bson:"country_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at" bson:"last_seen_at"`
+	ExpireAt    time.Time `json:"expire_at" bson:"expire_at"`
+}
+
+const (
+	// SESSION_CHECK_INTERVAL is for how long a session found in the database is trusted before it is looked up again
+	SESSION_CHECK_INTERVAL = time.Minute
+	// SESSION_TOUCH_INTERVAL is how often the last-seen time of a session is refreshed
+	SESSION_TOUCH_INTERVAL = time.Minute * 5
+)
+
+// SessionActiveKey is the redis key marking a session as recently found in the database
+func SessionActiveKey(r redis.Instance, sessionID primitive.ObjectID) redis.Key {
+	return r.ComposeKey("api", "sessions", "active", sessionID.Hex())
+}
+
+// SessionRevocationKey is the redis key marking a session as revoked until its token expires
+func SessionRevocationKey(r redis.Instance, sessionID primitive.ObjectID) redis.Key {
+	return r.ComposeKey("api", "sessions", "revoked", sessionID.Hex())
+}
+
+// Sessions returns the active sessions of a user, most recently seen first
+func (q *Query) Sessions(ctx context.Context, userID primitive.ObjectID) ([]Session, error) {
+	result := []Session{}
+
+	cur, err := q.mongo.Collection(CollectionNameSessions).Find(ctx, bson.M{
+		"user_id":   userID,
+		"expire_at": bson.M{"$gt": time.Now()},
+	}, options.Find().SetSort(bson.M{"last_seen_at": -1}))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to query sessions", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if err = cur.All(ctx, &result); err != nil {
+		zap.S().Errorw("mongo, failed to decode sessions", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	return result, nil
+}
+
+// Session returns a single session, or ErrNoItems if it does not exist
+func (q *Query) Session(ctx context.Context, sessionID primitive.ObjectID) (Session, error) {
+	s := Session{}
+
+	if err := q.mongo.Collection(CollectionNameSessions).FindOne(ctx, bson.M{"_id": sessionID}).Decode(&s); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return s, errors.ErrNoItems()
+		}
+
+		zap.S().Errorw("mongo, failed to query session", "error", err)
+
+		return s, errors.ErrInternalServerError()
+	}
+
+	return s, nil
+}
+
+// SessionRevoked returns whether or not a session was signed out.
+//
+// Revocations are kept in Redis, and revoked sessions are deleted from the database. A session which Redis
+// knows nothing about is looked up in the database, so that losing the Redis data does not bring revoked sessions back.
+// An error is returned when the session cannot be checked, in which case it must be rejected
+func (q *Query) SessionRevoked(ctx context.Context, sessionID primitive.ObjectID) (bool, error) {
+	n, err := q.redis.Exists(ctx, SessionRevocationKey(q.redis, sessionID))
+	if err == nil && n > 0 {
+		return true, nil
+	}
+
+	if err == nil {
+		n, err = q.redis.Exists(ctx, SessionActiveKey(q.redis, sessionID))
+		if err == nil && n > 0 {
+			return false, nil
+		}
+	}
+
+	if err != nil {
+		zap.S().Warnw("redis, failed to check session revocation, checking the database instead", "error", err)
+	}
+
+	count, err := q.mongo.Collection(CollectionNameSessions).CountDocuments(ctx, bson.M{
+		"_id":       sessionID,
+		"expire_at": bson.M{"$gt": time.Now()},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		zap.S().Errorw("mongo, failed to check session", "error", err)
+
+		return false, errors.ErrInternalServerError()
+	}
+
+	if count == 0 {
+		return true, nil
+	}
+
+	if err := q.redis.SetEX(ctx, SessionActiveKey(q.redis, sessionID), 1, SESSION_CHECK_INTERVAL); err != nil {
+		zap.S().Warnw("redis, failed to cache session check", "error", err)
+	}
+
+	return false, nil
+}
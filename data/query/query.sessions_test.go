@@ -0,0 +1,103 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+// sessionRedis holds the keys of sessions in memory, or fails every call when err is set
+type sessionRedis struct {
+	redis.Instance
+	keys map[redis.Key]bool
+	err  error
+}
+
+func (r *sessionRedis) ComposeKey(svc string, args ...string) redis.Key {
+	return redis.Key(fmt.Sprintf("%s:%s", svc, strings.Join(args, ":")))
+}
+
+func (r *sessionRedis) Exists(ctx context.Context, keys ...redis.Key) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n := 0
+
+	for _, k := range keys {
+		if r.keys[k] {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (r *sessionRedis) SetEX(ctx context.Context, key redis.Key, value interface{}, expiry time.Duration) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.keys[key] = true
+
+	return nil
+}
+
+// unreachableMongo is a database which cannot be reached, so that every query fails
+type unreachableMongo struct {
+	mongo.Instance
+	db *driver.Database
+}
+
+func (m unreachableMongo) Collection(name mongo.CollectionName) *driver.Collection {
+	return m.db.Collection(string(name))
+}
+
+func TestSessionRevoked(t *testing.T) {
+	client, err := driver.NewClient(options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(time.Millisecond * 100))
+	testutil.IsNil(t, err, "the client is created")
+
+	rdis := &sessionRedis{keys: map[redis.Key]bool{}}
+	q := New(unreachableMongo{db: client.Database("test")}, rdis, nil)
+	ctx := context.Background()
+
+	revokedID := primitive.NewObjectID()
+	activeID := primitive.NewObjectID()
+	unknownID := primitive.NewObjectID()
+
+	rdis.keys[SessionRevocationKey(rdis, revokedID)] = true
+	rdis.keys[SessionActiveKey(rdis, activeID)] = true
+
+	revoked, err := q.SessionRevoked(ctx, revokedID)
+	testutil.IsNil(t, err, "a revoked session is checked")
+	testutil.Assert(t, true, revoked, "a revoked session is rejected")
+
+	revoked, err = q.SessionRevoked(ctx, activeID)
+	testutil.IsNil(t, err, "a recently found session is checked")
+	testutil.Assert(t, false, revoked, "a recently found session is accepted")
+
+	// A revocation wins over a recent check
+	rdis.keys[SessionRevocationKey(rdis, activeID)] = true
+
+	revoked, _ = q.SessionRevoked(ctx, activeID)
+	testutil.Assert(t, true, revoked, "a session revoked after it was found is rejected")
+
+	// Sessions unknown to Redis are looked up in the database, and rejected when it cannot be reached
+	_, err = q.SessionRevoked(ctx, unknownID)
+	testutil.IsNotNil(t, err, "an unknown session cannot be checked without the database")
+
+	rdis.err = fmt.Errorf("connection refused")
+
+	_, err = q.SessionRevoked(ctx, activeID)
+	testutil.IsNotNil(t, err, "a session cannot be checked without Redis and the database")
+}
@@ -0,0 +1,181 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/redis"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/internal/search"
+)
+
+const (
+	EMOTE_SUGGESTIONS_LIMIT       = 25
+	EMOTE_SUGGESTIONS_QUERY_LIMIT = 100
+	EMOTE_SUGGESTIONS_CACHE_TTL   = time.Second * 30
+	// EMOTE_SUGGESTIONS_ID_FILTER_LIMIT is the most active emotes passed to the search as a filter.
+	// Past this, suggestions are searched among all emotes and filtered afterwards
+	EMOTE_SUGGESTIONS_ID_FILTER_LIMIT = 500
+	// EMOTE_SUGGESTIONS_UNFILTERED_LIMIT is how many suggestions are searched when they are filtered afterwards
+	EMOTE_SUGGESTIONS_UNFILTERED_LIMIT = 250
+)
+
+type SuggestEmotesOptions struct {
+	Query string
+	Limit int
+	Actor *structures.User
+	// Only suggest emotes from the sets the actor has active
+	ActiveSetsOnly bool
+}
+
+// SuggestEmotes returns emotes whose names complete the query, best matches first
+func (q *Query) SuggestEmotes(ctx context.Context, opt SuggestEmotesOptions) ([]search.EmoteSuggestion, error) {
+	result := []search.EmoteSuggestion{}
+
+	query := strings.TrimSpace(opt.Query)
+	if query == "" {
+		return result, nil
+	}
+
+	if len(query) > EMOTE_SUGGESTIONS_QUERY_LIMIT {
+		return result, errors.ErrInvalidRequest().SetDetail("query is too long")
+	}
+
+	req := search.EmoteSuggestOptions{
+		Limit:     int64(opt.Limit),
+		Listed:    true,
+		Lifecycle: int32(structures.EmoteLifecycleLive),
+	}
+
+	if req.Limit > EMOTE_SUGGESTIONS_LIMIT {
+		req.Limit = EMOTE_SUGGESTIONS_LIMIT
+	} else if req.Limit < 1 {
+		req.Limit = 1
+	}
+
+	var (
+		cacheKey redis.Key
+		active   map[string]bool
+	)
+
+	if opt.ActiveSetsOnly {
+		if opt.Actor == nil || opt.Actor.ID.IsZero() {
+			return result, errors.ErrUnauthorized()
+		}
+
+		ids, err := q.activeEmoteIDs(ctx, *opt.Actor)
+		if err != nil || len(ids) == 0 {
+			return result, err
+		}
+
+		// Emotes in the actor's sets are suggested even if unlisted
+		req.Listed = false
+
+		if len(ids) <= EMOTE_SUGGESTIONS_ID_FILTER_LIMIT {
+			req.IDs = ids
+		} else {
+			active = make(map[string]bool, len(ids))
+			for _, id := range ids {
+				active[id] = true
+			}
+		}
+	} else {
+		// Only global suggestions are shared, so only those are cached
+		h := sha256.New()
+		h.Write([]byte(strings.ToLower(query)))
+		h.Write([]byte(strconv.Itoa(int(req.Limit))))
+
+		cacheKey = q.key("emote-suggestions:" + hex.EncodeToString(h.Sum(nil)))
+		if ok := q.getFromMemCache(ctx, cacheKey, &result); ok {
+			return result, nil
+		}
+	}
+
+	limit := req.Limit
+	if active != nil {
+		req.Limit = EMOTE_SUGGESTIONS_UNFILTERED_LIMIT
+	}
+
+	suggestions, err := q.search.SuggestEmotes(query, req)
+	if err != nil {
+		zap.S().Warnw("search, failed to suggest emotes", "error", err)
+
+		return result, errors.ErrInternalServerError()
+	}
+
+	if active != nil {
+		suggestions = filterEmoteSuggestions(suggestions, active, int(limit))
+	}
+
+	result = append(result, suggestions...)
+
+	if cacheKey != "" {
+		if err := q.setInMemCache(ctx, cacheKey, result, EMOTE_SUGGESTIONS_CACHE_TTL); err != nil {
+			zap.S().Debugw("failed to cache emote suggestions", "error", err)
+		}
+	}
+
+	return result, nil
+}
+
+// activeEmoteIDs returns the IDs of the emotes in the sets a user has active on their connections
+func (q *Query) activeEmoteIDs(ctx context.Context, user structures.User) ([]string, error) {
+	setIDs := []primitive.ObjectID{}
+
+	for _, con := range user.Connections {
+		if !con.EmoteSetID.IsZero() {
+			setIDs = append(setIDs, con.EmoteSetID)
+		}
+	}
+
+	if len(setIDs) == 0 {
+		return nil, nil
+	}
+
+	sets, err := q.EmoteSets(ctx, bson.M{"_id": bson.M{"$in": setIDs}}).Items()
+	if err != nil && !errors.Compare(err, errors.ErrNoItems()) {
+		return nil, err
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+	ids := []string{}
+
+	for _, set := range sets {
+		for _, ae := range set.Emotes {
+			if seen[ae.ID] {
+				continue
+			}
+
+			seen[ae.ID] = true
+			ids = append(ids, ae.ID.Hex())
+		}
+	}
+
+	return ids, nil
+}
+
+// filterEmoteSuggestions keeps up to limit suggestions of the given emotes, in order
+func filterEmoteSuggestions(suggestions []search.EmoteSuggestion, ids map[string]bool, limit int) []search.EmoteSuggestion {
+	result := []search.EmoteSuggestion{}
+
+	for _, sg := range suggestions {
+		if len(result) >= limit {
+			break
+		}
+
+		if ids[sg.Id] {
+			result = append(result, sg)
+		}
+	}
+
+	return result
+}
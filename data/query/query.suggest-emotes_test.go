@@ -0,0 +1,27 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/seventv/api/internal/search"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestFilterEmoteSuggestions(t *testing.T) {
+	suggestions := []search.EmoteSuggestion{
+		{Id: "1", Name: "KEKW"},
+		{Id: "2", Name: "KEKL"},
+		{Id: "3", Name: "KEKWait"},
+		{Id: "4", Name: "KEKHeim"},
+	}
+	active := map[string]bool{"2": true, "3": true, "4": true}
+
+	result := filterEmoteSuggestions(suggestions, active, 2)
+
+	testutil.Assert(t, 2, len(result), "the suggestions are limited")
+	testutil.Assert(t, "2", result[0].Id, "only active emotes are suggested, in order")
+	testutil.Assert(t, "3", result[1].Id, "only active emotes are suggested, in order")
+
+	result = filterEmoteSuggestions(suggestions, map[string]bool{}, 10)
+	testutil.Assert(t, 0, len(result), "nothing is suggested without active emotes")
+}
@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"github.com/seventv/api/internal/global"
+)
+
+func init() {
+	register(Command{
+		Name:    "validate-config",
+		Offline: true,
+		Run:     validateConfig,
+	})
+}
+
+func validateConfig(gctx global.Context, run *Run) error {
+	problems := gctx.Config().Validate()
+
+	for _, p := range problems {
+		run.Problem("%s", p)
+	}
+
+	run.Count("problems", len(problems))
+
+	return nil
+}
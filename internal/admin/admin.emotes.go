@@ -0,0 +1,177 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/global"
+)
+
+func init() {
+	register(Command{
+		Name: "purge-orphaned-emotes",
+		Run:  purgeOrphanedEmotes,
+	})
+
+	register(Command{
+		Name: "migrate-emote-set-origins",
+		Run:  migrateEmoteSetOrigins,
+	})
+}
+
+// purgeOrphanedEmotes deletes the emotes whose owner no longer exists, unless they are still active in a set
+func purgeOrphanedEmotes(gctx global.Context, run *Run) error {
+	ctx, cancel := context.WithTimeout(gctx, time.Hour)
+	defer cancel()
+
+	cur, err := gctx.Inst().Mongo.Collection(mongo.CollectionNameEmotes).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"owner_id": bson.M{"$ne": primitive.NilObjectID},
+			"versions": bson.M{"$elemMatch": bson.M{"state.lifecycle": bson.M{"$ne": structures.EmoteLifecycleDeleted}}},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         mongo.CollectionNameUsers,
+			"localField":   "owner_id",
+			"foreignField": "_id",
+			"as":           "owner_user",
+		}}},
+		{{Key: "$match", Value: bson.M{"owner_user": bson.M{"$size": 0}}}},
+		{{Key: "$project", Value: bson.M{"owner_user": 0}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	emotes := []structures.Emote{}
+	if err = cur.All(ctx, &emotes); err != nil {
+		return err
+	}
+
+	actor := SystemActor()
+
+	for _, emote := range emotes {
+		inUse, err := gctx.Inst().Mongo.Collection(mongo.CollectionNameEmoteSets).CountDocuments(ctx, bson.M{
+			"emotes.id": emote.ID,
+		}, options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+
+		if inUse > 0 {
+			run.Record(emote.ID.Hex(), "skipped", "still active in a set")
+
+			continue
+		}
+
+		run.Record(emote.ID.Hex(), "purged", emote.Name)
+
+		if run.DryRun {
+			continue
+		}
+
+		if err := gctx.Inst().Mutate.DeleteEmote(ctx, structures.NewEmoteBuilder(emote), mutate.DeleteEmoteOptions{
+			Actor:  actor,
+			Reason: "Orphaned: the owner of the emote no longer exists",
+		}); err != nil {
+			run.Problem("failed to purge emote %s: %s", emote.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
+
+// migrateEmoteSetOrigins gives sets with origins an explicit conflict policy, pinning the behavior they had
+// before policies existed, and reports origins which point to sets that no longer exist
+func migrateEmoteSetOrigins(gctx global.Context, run *Run) error {
+	ctx, cancel := context.WithTimeout(gctx, time.Hour)
+	defer cancel()
+
+	cur, err := gctx.Inst().Mongo.Collection(mongo.CollectionNameEmoteSets).Find(ctx, bson.M{
+		"origins.0": bson.M{"$exists": true},
+	}, options.Find().SetProjection(bson.M{"emotes": 0}))
+	if err != nil {
+		return err
+	}
+
+	sets := []structures.EmoteSet{}
+	if err = cur.All(ctx, &sets); err != nil {
+		return err
+	}
+
+	setIDs := make([]primitive.ObjectID, len(sets))
+	originIDs := []primitive.ObjectID{}
+
+	for i, set := range sets {
+		setIDs[i] = set.ID
+
+		for _, o := range set.Origins {
+			originIDs = append(originIDs, o.ID)
+		}
+	}
+
+	policies, err := gctx.Inst().Query.EmoteSetOriginPolicies(ctx, setIDs)
+	if err != nil {
+		return err
+	}
+
+	// Find which origins still exist
+	existing := map[primitive.ObjectID]bool{}
+
+	cur, err = gctx.Inst().Mongo.Collection(mongo.CollectionNameEmoteSets).Find(ctx, bson.M{
+		"_id": bson.M{"$in": originIDs},
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+
+	for cur.Next(ctx) {
+		if id, ok := cur.Current.Lookup("_id").ObjectIDOK(); ok {
+			existing[id] = true
+		}
+	}
+
+	if err = cur.Err(); err != nil {
+		return err
+	}
+
+	actor := SystemActor()
+
+	for _, set := range sets {
+		for _, o := range set.Origins {
+			if !existing[o.ID] {
+				run.Record(set.ID.Hex(), "dangling_origin", o.ID.Hex())
+			}
+		}
+
+		if _, ok := policies[set.ID]; ok {
+			run.Count("skipped", 1)
+
+			continue
+		}
+
+		run.Record(set.ID.Hex(), "pinned_policy", string(query.EmoteSetOriginConflictModeOriginWins))
+
+		if run.DryRun {
+			continue
+		}
+
+		if err := gctx.Inst().Mutate.SetEmoteSetOriginPolicy(ctx, structures.NewEmoteSetBuilder(set), query.EmoteSetOriginPolicy{
+			Mode: query.EmoteSetOriginConflictModeOriginWins,
+		}, mutate.EmoteSetMutationOptions{
+			Actor:          actor,
+			SkipValidation: true,
+		}); err != nil {
+			run.Problem("failed to set the origin policy of emote set %s: %s", set.ID.Hex(), err)
+		}
+	}
+
+	return nil
+}
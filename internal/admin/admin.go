@@ -0,0 +1,201 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/seventv/common/structures/v3"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/internal/global"
+)
+
+// Command is an operational task run with the API binary instead of starting the servers,
+// i.e "api grant-role <user> <role>".
+//
+// Commands must be safe to run again: changes which were already made are skipped
+type Command struct {
+	Name  string
+	Usage string
+	// The amount of arguments which the command requires
+	Args int
+	// Whether the command runs without connecting to the backends
+	Offline bool
+	Run     func(gctx global.Context, run *Run) error
+}
+
+var commands = map[string]Command{}
+
+func register(cmd Command) {
+	commands[cmd.Name] = cmd
+}
+
+// Lookup returns the command with the given name
+func Lookup(name string) (Command, bool) {
+	cmd, ok := commands[name]
+
+	return cmd, ok
+}
+
+// Usage lists the available commands
+func Usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	sb.WriteString("commands:\n")
+
+	for _, name := range names {
+		sb.WriteString("  " + strings.TrimSpace(name+" "+commands[name].Usage) + "\n")
+	}
+
+	sb.WriteString("flags:\n  --dry-run  report the changes without making them\n  --report <file>  write a JSON report, or - for stdout\n")
+
+	return sb.String()
+}
+
+// Run is a single execution of a command, collecting what it changed
+type Run struct {
+	Command    string         `json:"command"`
+	Args       []string       `json:"args"`
+	DryRun     bool           `json:"dry_run"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Success    bool           `json:"success"`
+	Error      string         `json:"error,omitempty"`
+	Counts     map[string]int `json:"counts"`
+	Items      []RunItem      `json:"items"`
+	Problems   []string       `json:"problems,omitempty"`
+}
+
+// RunItem is a change made, or which would have been made, to an object
+type RunItem struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Record adds a change to the report and counts it by its action
+func (r *Run) Record(id string, action string, detail string) {
+	r.Items = append(r.Items, RunItem{
+		ID:     id,
+		Action: action,
+		Detail: detail,
+	})
+
+	r.Count(action, 1)
+
+	zap.S().Infow(action,
+		"id", id,
+		"detail", detail,
+		"dry_run", r.DryRun,
+	)
+}
+
+// Count adds to a counter of the report without listing the objects
+func (r *Run) Count(key string, n int) {
+	r.Counts[key] += n
+}
+
+// Problem adds an issue which the command found but did not fix
+func (r *Run) Problem(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+
+	r.Problems = append(r.Problems, msg)
+
+	zap.S().Warn(msg)
+}
+
+// Execute runs a command with the arguments given on the command line and writes its report.
+// It returns the exit code of the process
+func Execute(gctx global.Context, cmd Command) int {
+	args := gctx.Config().Args[1:]
+
+	if len(args) != cmd.Args {
+		fmt.Fprintf(os.Stderr, "usage: %s %s\n", cmd.Name, cmd.Usage)
+
+		return 2
+	}
+
+	run := &Run{
+		Command:   cmd.Name,
+		Args:      args,
+		DryRun:    gctx.Config().DryRun,
+		StartedAt: time.Now(),
+		Counts:    map[string]int{},
+		Items:     []RunItem{},
+	}
+
+	err := cmd.Run(gctx, run)
+
+	run.FinishedAt = time.Now()
+	run.Success = err == nil && len(run.Problems) == 0
+
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	zap.S().Infow("command finished",
+		"command", cmd.Name,
+		"dry_run", run.DryRun,
+		"counts", run.Counts,
+		"problems", len(run.Problems),
+		"error", err,
+		"duration", run.FinishedAt.Sub(run.StartedAt),
+	)
+
+	if err := writeReport(gctx.Config().Report, run); err != nil {
+		zap.S().Errorw("failed to write report", "error", err)
+
+		return 1
+	}
+
+	if !run.Success {
+		return 1
+	}
+
+	return 0
+}
+
+func writeReport(path string, run *Run) error {
+	if path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		_, err = os.Stdout.Write(append(b, '\n'))
+
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o644)
+}
+
+// systemRole is held by the actor of commands, so that they pass the permission checks of mutations
+var systemRole = structures.Role{
+	Name:     "System",
+	Position: math.MaxInt32,
+	Allowed:  structures.RolePermissionSuperAdministrator,
+}
+
+// SystemActor returns the user which changes made by commands are attributed to in the audit log
+func SystemActor() structures.User {
+	actor := structures.SystemUser
+	actor.Roles = []structures.Role{systemRole}
+
+	return actor
+}
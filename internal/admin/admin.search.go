@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/search"
+)
+
+// REINDEX_BATCH_SIZE is how many emote documents are sent to the index at once
+const REINDEX_BATCH_SIZE = 500
+
+func init() {
+	register(Command{
+		Name: "reindex-search",
+		// Required after the attributes of emote documents change, see search.ConfigureEmoteIndex
+		Run: reindexSearch,
+	})
+
+	register(Command{
+		Name: "recompute-trending",
+		Run:  recomputeTrending,
+	})
+}
+
+// reindexSearch writes the documents of all emotes to the search index, and removes those of deleted emotes
+func reindexSearch(gctx global.Context, run *Run) error {
+	ctx, cancel := context.WithTimeout(gctx, time.Hour)
+	defer cancel()
+
+	if !run.DryRun {
+		if err := gctx.Inst().Meilisearch.ConfigureEmoteIndex(ctx); err != nil {
+			return err
+		}
+	}
+
+	cur, err := gctx.Inst().Mongo.Collection(mongo.CollectionNameEmotes).Find(ctx, bson.M{},
+		options.Find().SetBatchSize(REINDEX_BATCH_SIZE).SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(context.Background())
+
+	docs := []search.EmoteDocument{}
+	removed := []string{}
+
+	flush := func() error {
+		run.Count("indexed", len(docs))
+		run.Count("removed", len(removed))
+
+		if !run.DryRun {
+			if err := gctx.Inst().Meilisearch.IndexEmotes(docs); err != nil {
+				return err
+			}
+
+			if err := gctx.Inst().Meilisearch.RemoveEmotes(removed); err != nil {
+				return err
+			}
+		}
+
+		docs = docs[:0]
+		removed = removed[:0]
+
+		return nil
+	}
+
+	for cur.Next(ctx) {
+		emote := structures.Emote{}
+		if err := cur.Decode(&emote); err != nil {
+			run.Problem("failed to decode emote %v: %s", cur.Current.Lookup("_id"), err)
+
+			continue
+		}
+
+		if emote.GetLatestVersion(false).ID.IsZero() {
+			removed = append(removed, emote.ID.Hex())
+		} else {
+			docs = append(docs, search.NewEmoteDocument(emote))
+		}
+
+		if len(docs)+len(removed) >= REINDEX_BATCH_SIZE {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cur.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// recomputeTrending drops the cached trending emotes, so that they are computed again on the next request
+func recomputeTrending(gctx global.Context, run *Run) error {
+	ctx, cancel := context.WithTimeout(gctx, time.Minute)
+	defer cancel()
+
+	pattern := gctx.Inst().Redis.ComposeKey("api-gql", "trending-emotes", "*").String()
+
+	iter := gctx.Inst().Redis.RawClient().Scan(ctx, 0, pattern, 100).Iterator()
+
+	for iter.Next(ctx) {
+		key := iter.Val()
+
+		run.Record(key, "dropped_cache", "")
+
+		if run.DryRun {
+			continue
+		}
+
+		if err := gctx.Inst().Redis.RawClient().Del(ctx, key).Err(); err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
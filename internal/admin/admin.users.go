@@ -0,0 +1,149 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/internal/global"
+)
+
+// BAN_DURATION is how long bans made with the ban command last, which is to say indefinitely
+const BAN_DURATION = time.Hour * 24 * 365 * 100
+
+func init() {
+	register(Command{
+		Name:  "grant-role",
+		Usage: "<user> <role>",
+		Args:  2,
+		Run:   grantRole,
+	})
+
+	register(Command{
+		Name:  "ban",
+		Usage: "<user>",
+		Args:  1,
+		Run:   ban,
+	})
+}
+
+func grantRole(gctx global.Context, run *Run) error {
+	ctx, cancel := context.WithTimeout(gctx, time.Minute)
+	defer cancel()
+
+	user, err := findUser(gctx, ctx, run.Args[0])
+	if err != nil {
+		return err
+	}
+
+	roles, err := gctx.Inst().Query.Roles(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	role := structures.Role{}
+
+	for _, r := range roles {
+		if r.ID.Hex() == run.Args[1] || strings.EqualFold(r.Name, run.Args[1]) {
+			role = r
+
+			break
+		}
+	}
+
+	if role.ID.IsZero() {
+		return fmt.Errorf("unknown role %q", run.Args[1])
+	}
+
+	if utils.Contains(user.RoleIDs, role.ID) {
+		run.Record(user.ID.Hex(), "skipped", "already has the role "+role.Name)
+
+		return nil
+	}
+
+	run.Record(user.ID.Hex(), "granted_role", role.Name)
+
+	if run.DryRun {
+		return nil
+	}
+
+	return gctx.Inst().Mutate.SetRole(ctx, structures.NewUserBuilder(user), mutate.SetUserRoleOptions{
+		Role:   role,
+		Actor:  SystemActor(),
+		Action: structures.ListItemActionAdd,
+	})
+}
+
+func ban(gctx global.Context, run *Run) error {
+	ctx, cancel := context.WithTimeout(gctx, time.Minute)
+	defer cancel()
+
+	victim, err := findUser(gctx, ctx, run.Args[0])
+	if err != nil {
+		return err
+	}
+
+	// Read the bans from the database rather than the cache, so that a rerun sees the ban it made
+	active, err := gctx.Inst().Mongo.Collection(mongo.CollectionNameBans).CountDocuments(ctx, bson.M{
+		"victim_id": victim.ID,
+		"expire_at": bson.M{"$gt": time.Now()},
+		"effects":   bson.M{"$bitsAllSet": structures.BanEffectNoAuth},
+	})
+	if err != nil {
+		return err
+	}
+
+	if active > 0 {
+		run.Record(victim.ID.Hex(), "skipped", "already banned")
+
+		return nil
+	}
+
+	run.Record(victim.ID.Hex(), "banned", victim.Username)
+
+	if run.DryRun {
+		return nil
+	}
+
+	actor := SystemActor()
+
+	bb := structures.NewBanBuilder(structures.Ban{}).
+		SetActorID(actor.ID).
+		SetVictimID(victim.ID).
+		SetReason("Banned by an administrator").
+		SetExpireAt(time.Now().Add(BAN_DURATION)).
+		SetEffects(structures.BanEffectNoAuth | structures.BanEffectNoPermissions | structures.BanEffectNoOwnership)
+
+	return gctx.Inst().Mutate.CreateBan(ctx, bb, mutate.CreateBanOptions{
+		Actor:  &actor,
+		Victim: &victim,
+	})
+}
+
+// findUser finds a user by their ID or username
+func findUser(gctx global.Context, ctx context.Context, s string) (structures.User, error) {
+	filter := bson.M{"username": strings.ToLower(s)}
+	if id, err := primitive.ObjectIDFromHex(s); err == nil {
+		filter = bson.M{"_id": id}
+	}
+
+	user, err := gctx.Inst().Query.Users(ctx, filter).First()
+	if err != nil {
+		if errors.Compare(err, errors.ErrNoItems()) {
+			return user, fmt.Errorf("unknown user %q", s)
+		}
+
+		return user, err
+	}
+
+	return user, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/seventv/api/data/events"
 	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/flags"
 	"github.com/seventv/common/utils"
 	"go.uber.org/zap"
 )
@@ -15,6 +16,10 @@ import (
 const SESSION_ID_KEY = utils.Key("session_id")
 
 func handle(gctx global.Context, body []byte) ([]events.Message[json.RawMessage], error) {
+	if gctx.Config().Http.DisableEventBridge || !gctx.Inst().Flags.Enabled(flags.FlagEventBridge, nil, true) {
+		return nil, nil
+	}
+
 	ctx, cancel := context.WithCancel(gctx)
 	defer cancel()
 
@@ -27,8 +32,9 @@ func New(gctx global.Context) <-chan struct{} {
 
 	createUserStateLoader(gctx)
 
-	go func() {
-		err := http.ListenAndServe(gctx.Config().EventBridge.Bind, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	srv := &http.Server{
+		Addr: gctx.Config().EventBridge.Bind,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var err error
 
 			// read body into byte slice
@@ -59,18 +65,27 @@ func New(gctx global.Context) <-chan struct{} {
 			}
 
 			w.WriteHeader(200)
-		}))
+		}),
+	}
 
-		if err != nil {
-			zap.S().Errorw("eventapi bridge failed", "error", err)
+	go func() {
+		defer close(done)
 
-			close(done)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			zap.S().Errorw("eventapi bridge failed", "error", err)
 		}
 	}()
 
+	// Stop accepting commands once the drain period has passed, and finish those in flight
 	go func() {
-		<-gctx.Done()
-		close(done)
+		select {
+		case <-gctx.Inst().Lifecycle.Stopping():
+		case <-gctx.Done():
+		}
+
+		if err := srv.Shutdown(context.Background()); err != nil {
+			zap.S().Errorw("failed to shut down eventapi bridge", "error", err)
+		}
 	}()
 
 	return done
@@ -87,7 +87,7 @@ func createUserStateLoader(gctx global.Context) {
 						}
 
 						for p, ids := range m {
-							users, errs = l(p).LoadAll(ids)
+							users, errs = l(p).LoadAll(gctx, ids)
 							mx.Lock()
 
 							for i := range users {
@@ -7,7 +7,9 @@ import (
 	"github.com/fasthttp/router"
 	"github.com/seventv/api/internal/constant"
 	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/tracing"
 	"github.com/seventv/common/utils"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 
 	v3 "github.com/seventv/api/internal/api/gql/v3"
@@ -49,8 +51,14 @@ func New(gctx global.Context) error {
 
 			ctx.SetUserValue(constant.ClientIP, ip)
 
+			span := tracing.StartRequest(ctx, "gql "+string(ctx.Method()))
+
 			defer func() {
+				defer tracing.EndRequest(ctx, span, "")
+
 				if err := recover(); err != nil {
+					span.SetStatus(codes.Error, fmt.Sprint(err))
+
 					zap.S().Errorw("panic in gql request handler",
 						"panic", err,
 						"status", ctx.Response.StatusCode(),
@@ -87,6 +95,11 @@ func New(gctx global.Context) error {
 			ctx.Response.Header.Set("X-Node-Name", gctx.Config().K8S.NodeName)
 			ctx.Response.Header.Set("X-Pod-Name", gctx.Config().K8S.PodName)
 
+			// While draining, clients are asked not to reuse the connection so that they move to another pod
+			if !gctx.Inst().Lifecycle.Ready() {
+				ctx.SetConnectionClose()
+			}
+
 			if err := doCORS(ctx); err != nil {
 				return
 			}
@@ -110,11 +123,26 @@ func New(gctx global.Context) error {
 		MaxRequestBodySize: int(6 * 1024 * 1024), // 6MB
 	}
 
+	// Stop accepting connections once the drain period has passed, and wait for the requests in flight
+	shutdown := make(chan struct{})
+
 	go func() {
-		<-gctx.Done()
+		select {
+		case <-gctx.Inst().Lifecycle.Stopping():
+		case <-gctx.Done():
+		}
 
 		_ = server.Shutdown()
+
+		close(shutdown)
 	}()
 
-	return server.ListenAndServe(fmt.Sprintf("%s:%d", gctx.Config().Http.Addr, port))
+	if err := server.ListenAndServe(fmt.Sprintf("%s:%d", gctx.Config().Http.Addr, port)); err != nil {
+		return err
+	}
+
+	// Serving ends as soon as the listener is closed, before the requests in flight have finished
+	<-shutdown
+
+	return nil
 }
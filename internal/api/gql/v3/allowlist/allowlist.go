@@ -0,0 +1,125 @@
+package allowlist
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/patrickmn/go-cache"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/complexity"
+	"github.com/seventv/api/internal/configure"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/persisted"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+)
+
+// How long an unknown operation is not reported again after being logged
+const REPORT_INTERVAL = time.Minute * 10
+
+// Allowlist restricts untrusted clients to the operations of the persisted query registry.
+//
+// Operations of the registry which have a body may be sent by their hash alone. It must be used
+// after gqlgen's ComplexityLimit extension, and before AutomaticPersistedQuery
+type Allowlist struct {
+	gctx     global.Context
+	reported *cache.Cache
+}
+
+func New(gctx global.Context) *Allowlist {
+	return &Allowlist{
+		gctx:     gctx,
+		reported: cache.New(REPORT_INTERVAL, REPORT_INTERVAL),
+	}
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationParameterMutator
+	graphql.OperationContextMutator
+} = &Allowlist{}
+
+func (*Allowlist) ExtensionName() string {
+	return "PersistedQueryAllowlist"
+}
+
+func (*Allowlist) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters fills in the text of operations sent by hash which the registry knows of
+func (a *Allowlist) MutateOperationParameters(ctx context.Context, params *graphql.RawParams) *gqlerror.Error {
+	if params.Query != "" {
+		return nil
+	}
+
+	ext, ok := params.Extensions["persistedQuery"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	hash, _ := ext["sha256Hash"].(string)
+
+	if op, ok := a.gctx.Inst().Persisted.Lookup(hash); ok && op.Body != "" {
+		params.Query = op.Body
+	}
+
+	return nil
+}
+
+func (a *Allowlist) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	hash := persisted.Hash(rc.RawQuery)
+
+	if _, ok := a.gctx.Inst().Persisted.Lookup(hash); ok || a.trusted(ctx) {
+		return nil
+	}
+
+	if a.gctx.Config().Limits.PersistedQueries.Mode == configure.PersistedQueriesModeEnforce {
+		return gqlerror.WrapPath(nil, errors.ErrInsufficientPrivilege().SetDetail("This operation is not allowed for untrusted clients"))
+	}
+
+	// Report mode
+	if _, found := a.reported.Get(hash); found {
+		return nil
+	}
+
+	a.reported.SetDefault(hash, struct{}{})
+
+	cost := 0
+	if stats, ok := complexity.Stats(rc); ok {
+		cost = stats.Complexity
+	}
+
+	zap.S().Infow("gql, operation not in persisted query registry",
+		"hash", hash,
+		"operation", rc.OperationName,
+		"cost", cost,
+	)
+
+	return nil
+}
+
+// trusted tells whether the actor may run operations which are not in the registry
+func (a *Allowlist) trusted(ctx context.Context) bool {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false
+	}
+
+	if actor.HasPermission(structures.RolePermissionManageStack) {
+		return true
+	}
+
+	for _, id := range a.gctx.Config().Limits.PersistedQueries.TrustedRoles {
+		for _, r := range actor.RoleIDs {
+			if r.Hex() == id {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -5,9 +5,16 @@ import (
 
 	"github.com/seventv/api/internal/constant"
 	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func For(ctx context.Context) structures.User {
 	raw, _ := ctx.Value(constant.UserKey).(structures.User)
 	return raw
 }
+
+// SessionFor returns the session of the current access token, if any
+func SessionFor(ctx context.Context) primitive.ObjectID {
+	raw, _ := ctx.Value(constant.SessionKey).(primitive.ObjectID)
+	return raw
+}
@@ -1,13 +1,164 @@
-// TODO
 package complexity
 
 import (
 	"github.com/seventv/api/internal/api/gql/v3/gen/generated"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
 	"github.com/seventv/api/internal/global"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// The amount of pages after which deep pagination adds a unit of cost
+const PAGE_COST_STEP = 5
+
+// Estimated sizes of lists which are not limited by an argument
+const (
+	emoteSetEmotesEstimate   = 300
+	userEmoteSetsEstimate    = 5
+	userEditorsEstimate      = 15
+	userOwnedEmotesEstimate  = 100
+	userConnectionsEstimate  = 4
+	userCollectionsEstimate  = 10
+	collectionEmotesEstimate = 100
+)
+
+// New returns the cost functions of the schema.
+//
+// A field costs one unit plus the cost of its selection. List fields multiply the cost of their
+// selection by the amount of items they may return, taken from their limit argument when they have one
 func New(ctx global.Context) generated.ComplexityRoot {
-	return generated.ComplexityRoot{}
+	c := generated.ComplexityRoot{}
+
+	// Query
+
+	c.Query.Emotes = func(childComplexity int, query string, page *int, limit *int, filter *model.EmoteSearchFilter, sort *model.Sort) int {
+		return paged(list(childComplexity, limitOr(limit, 20, 300)), page)
+	}
+	c.Query.Users = func(childComplexity int, query string, page *int, limit *int, filter *model.UserSearchFilter, sort *model.Sort) int {
+		return paged(list(childComplexity, limitOr(limit, 25, 500)), page)
+	}
+	c.Query.UserSearch = func(childComplexity int, query string, page *int, limit *int, filter *model.UserSearchFilter, sort *model.Sort) int {
+		return paged(list(childComplexity, limitOr(limit, 25, 500)), page)
+	}
+	c.Query.EmoteSuggestions = func(childComplexity int, query string, limit *int, activeOnly *bool) int {
+		return list(childComplexity, limitOr(limit, 10, 100))
+	}
+	c.Query.EmotesByID = func(childComplexity int, ids []primitive.ObjectID) int {
+		return list(childComplexity, len(ids))
+	}
+	c.Query.UsersByID = func(childComplexity int, ids []primitive.ObjectID) int {
+		return list(childComplexity, len(ids))
+	}
+	c.Query.EmoteSetsByID = func(childComplexity int, ids []primitive.ObjectID) int {
+		return list(childComplexity, len(ids))
+	}
+	c.Query.Cosmetics = func(childComplexity int, ids []primitive.ObjectID) int {
+		return list(childComplexity, len(ids))
+	}
+	c.Query.Inbox = func(childComplexity int, userID primitive.ObjectID, afterID *primitive.ObjectID, limit *int, locale *string) int {
+		return list(childComplexity, limitOr(limit, 100, 1000))
+	}
+	c.Query.ModRequests = func(childComplexity int, afterID *primitive.ObjectID, limit *int, wish *string, country *string) int {
+		return list(childComplexity, limitOr(limit, 50, 500))
+	}
+	c.Query.News = func(childComplexity int, locale *string, includeDismissed *bool, includeInactive *bool, page *int, limit *int) int {
+		return paged(list(childComplexity, limitOr(limit, 100, 100)), page)
+	}
+	c.Query.Reports = func(childComplexity int, status *model.ReportStatus, limit *int, afterID *primitive.ObjectID, beforeID *primitive.ObjectID) int {
+		return list(childComplexity, limitOr(limit, 12, 100))
+	}
+
+	// Emote
+
+	c.Emote.Channels = func(childComplexity int, page *int, limit *int) int {
+		return paged(list(childComplexity, limitOr(limit, 50, 50)), page)
+	}
+	c.Emote.Activity = func(childComplexity int, limit *int) int {
+		return list(childComplexity, limitOr(limit, 50, 300))
+	}
+	c.Emote.Comments = func(childComplexity int, after *primitive.ObjectID, limit *int) int {
+		return list(childComplexity, limitOr(limit, 20, 100))
+	}
+	c.EmoteComment.Replies = func(childComplexity int, after *primitive.ObjectID, limit *int) int {
+		return list(childComplexity, limitOr(limit, 20, 100))
+	}
+
+	// Emote Set
+
+	c.EmoteSet.Emotes = func(childComplexity int, limit *int, origins *bool) int {
+		return list(childComplexity, limitOr(limit, emoteSetEmotesEstimate, emoteSetEmotesEstimate))
+	}
+	c.EmoteSet.Changes = func(childComplexity int, after *primitive.ObjectID, limit *int) int {
+		return list(childComplexity, limitOr(limit, 100, 100))
+	}
+	c.EmoteCollection.Emotes = func(childComplexity int, limit *int) int {
+		return list(childComplexity, limitOr(limit, collectionEmotesEstimate, collectionEmotesEstimate))
+	}
+
+	// User
+
+	c.User.Activity = func(childComplexity int, limit *int) int {
+		return list(childComplexity, limitOr(limit, 50, 300))
+	}
+	c.User.EmoteSets = func(childComplexity int, entitled *bool) int {
+		return list(childComplexity, userEmoteSetsEstimate)
+	}
+	c.User.Editors = func(childComplexity int) int {
+		return list(childComplexity, userEditorsEstimate)
+	}
+	c.User.EditorOf = func(childComplexity int) int {
+		return list(childComplexity, userEditorsEstimate)
+	}
+	c.User.OwnedEmotes = func(childComplexity int) int {
+		return list(childComplexity, userOwnedEmotesEstimate)
+	}
+	c.User.Connections = func(childComplexity int, platforms []model.ConnectionPlatform) int {
+		return list(childComplexity, userConnectionsEstimate)
+	}
+	c.User.Collections = func(childComplexity int, followed *bool) int {
+		return list(childComplexity, userCollectionsEstimate)
+	}
+	c.UserPartial.EmoteSets = func(childComplexity int) int {
+		return list(childComplexity, userEmoteSetsEstimate)
+	}
+	c.UserPartial.Connections = func(childComplexity int, platforms []model.ConnectionPlatform) int {
+		return list(childComplexity, userConnectionsEstimate)
+	}
+	c.Role.Members = func(childComplexity int, page *int, limit *int) int {
+		return paged(list(childComplexity, limitOr(limit, 50, 300)), page)
+	}
+
+	return c
+}
+
+// list is the cost of a field returning up to n items
+func list(childComplexity int, n int) int {
+	if n < 1 {
+		n = 1
+	}
+
+	return 1 + n*childComplexity
+}
+
+// limitOr reads a limit argument, falling back to the resolver's default and capped at its maximum
+func limitOr(limit *int, def int, most int) int {
+	if limit == nil {
+		return def
+	}
+
+	if *limit > most {
+		return most
+	}
+
+	return *limit
+}
+
+// paged adds the cost of skipping to a page
+func paged(cost int, page *int) int {
+	if page == nil || *page <= 1 {
+		return cost
+	}
+
+	return cost + (*page-1)/PAGE_COST_STEP
 }
 
 func NewOps(ctx global.Context) generated.ComplexityRoot {
@@ -0,0 +1,115 @@
+package complexity
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/common/errors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// The highest cost of an operation, unless configured otherwise
+const DEFAULT_LIMIT = 5000
+
+// The name of the stats extension set by gqlgen's ComplexityLimit
+const complexityStatsKey = "ComplexityLimit"
+
+// Limit returns the highest cost of an operation sent by the actor of the context.
+// Users with a role which has its own limit get the highest of their limits
+func Limit(gctx global.Context, ctx context.Context) int {
+	cfg := gctx.Config().Limits.Complexity
+
+	limit := cfg.Default
+	if limit <= 0 {
+		limit = DEFAULT_LIMIT
+	}
+
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() || len(cfg.Roles) == 0 {
+		return limit
+	}
+
+	for _, id := range actor.RoleIDs {
+		if l := cfg.Roles[id.Hex()]; l > limit {
+			limit = l
+		}
+	}
+
+	for _, r := range actor.Roles {
+		if l := cfg.Roles[r.ID.Hex()]; l > limit {
+			limit = l
+		}
+	}
+
+	return limit
+}
+
+// RateLimitFunc charges a cost against the rate limit bucket of the client
+type RateLimitFunc = func(cost int64) errors.APIError
+
+// Cost charges the computed cost of operations to the client's rate limit,
+// on top of the unit charged for each request, and reports it in the response extensions.
+//
+// It must be used after gqlgen's ComplexityLimit extension, which computes the cost
+type Cost struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationContextMutator
+	graphql.ResponseInterceptor
+} = Cost{}
+
+// CostStats is the cost of an operation, as reported in the "cost" response extension
+type CostStats struct {
+	// The computed cost of the operation
+	Requested int `json:"requested"`
+	// The highest cost allowed for the client
+	Limit int `json:"limit"`
+}
+
+func (Cost) ExtensionName() string {
+	return "QueryCost"
+}
+
+func (Cost) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// Stats returns the cost of an operation, once computed by gqlgen's ComplexityLimit
+func Stats(rc *graphql.OperationContext) (*extension.ComplexityStats, bool) {
+	stats, ok := rc.Stats.GetExtension(complexityStatsKey).(*extension.ComplexityStats)
+
+	return stats, ok
+}
+
+func (Cost) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	stats, ok := Stats(rc)
+	if !ok {
+		return nil
+	}
+
+	if charge, ok := ctx.Value(helpers.RateLimitFunc).(RateLimitFunc); ok {
+		if err := charge(int64(stats.Complexity)); err != nil {
+			return gqlerror.WrapPath(nil, err)
+		}
+	}
+
+	return nil
+}
+
+func (Cost) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if graphql.HasOperationContext(ctx) {
+		if stats, ok := Stats(graphql.GetOperationContext(ctx)); ok {
+			graphql.RegisterExtension(ctx, "cost", CostStats{
+				Requested: stats.Complexity,
+				Limit:     stats.ComplexityLimit,
+			})
+		}
+	}
+
+	return next(ctx)
+}
@@ -1,8 +1,13 @@
 package helpers
 
 import (
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/svc/status"
 	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func ReportStructureToModel(s structures.Report) *model.Report {
@@ -37,3 +42,314 @@ func BanStructureToModel(s structures.Ban) *model.Ban {
 		VictimID:  s.VictimID,
 	}
 }
+
+func EmoteCommentToModel(c query.EmoteComment) *model.EmoteComment {
+	m := &model.EmoteComment{
+		ID:            c.ID,
+		EmoteID:       c.Data.EmoteID,
+		Content:       c.Data.Content,
+		CreatedAt:     c.CreatedAt,
+		EditedAt:      c.Data.EditedAt,
+		ReplyCount:    int(c.Data.ReplyCount),
+		Authoritative: c.Data.Authoritative,
+		Pinned:        c.Data.Pinned,
+		Hidden:        c.Data.Hidden,
+		Deleted:       c.Data.Deleted,
+	}
+
+	// The author of a deleted comment is not shown
+	if !c.AuthorID.IsZero() && !c.Data.Deleted {
+		m.AuthorID = &c.AuthorID
+	}
+
+	if !c.Data.ParentID.IsZero() {
+		m.ParentID = &c.Data.ParentID
+	}
+
+	if !c.Data.ReplyToID.IsZero() {
+		m.ReplyToID = &c.Data.ReplyToID
+	}
+
+	return m
+}
+
+func EmoteCommentsToModel(res query.EmoteCommentsResult) *model.EmoteCommentList {
+	list := &model.EmoteCommentList{
+		Items:   make([]*model.EmoteComment, len(res.Items)),
+		HasMore: res.HasMore,
+	}
+
+	for i, c := range res.Items {
+		list.Items[i] = EmoteCommentToModel(c)
+	}
+
+	if !res.Cursor.IsZero() {
+		list.Cursor = &res.Cursor
+	}
+
+	return list
+}
+
+func NewsPostToModel(p query.NewsPost) *model.NewsPost {
+	m := &model.NewsPost{
+		ID:        p.ID,
+		CreatedAt: p.CreatedAt,
+		Title:     p.Data.Title,
+		Content:   p.Data.Content,
+		Important: p.Data.Important,
+		StartsAt:  p.Data.StartsAt,
+		EndsAt:    p.Data.EndsAt,
+		Audience: &model.NewsAudience{
+			RoleIds:   utils.Ternary(p.Data.Audience.RoleIDs == nil, []primitive.ObjectID{}, p.Data.Audience.RoleIDs),
+			Platforms: make([]model.ConnectionPlatform, len(p.Data.Audience.Platforms)),
+			Locales:   utils.Ternary(p.Data.Audience.Locales == nil, []string{}, p.Data.Audience.Locales),
+		},
+		Dismissed: p.Dismissed,
+	}
+
+	for i, pl := range p.Data.Audience.Platforms {
+		m.Audience.Platforms[i] = model.ConnectionPlatform(pl)
+	}
+
+	if !p.AuthorID.IsZero() {
+		m.AuthorID = &p.AuthorID
+	}
+
+	if p.Data.ImageURL != "" {
+		m.ImageURL = &p.Data.ImageURL
+	}
+
+	return m
+}
+
+func NewsPostInputToData(in model.NewsPostInput) query.NewsPostData {
+	d := query.NewsPostData{
+		Title:   in.Title,
+		Content: in.Content,
+		EndsAt:  in.EndsAt,
+	}
+
+	if in.ImageURL != nil {
+		d.ImageURL = *in.ImageURL
+	}
+
+	if in.Important != nil {
+		d.Important = *in.Important
+	}
+
+	if in.StartsAt != nil {
+		d.StartsAt = *in.StartsAt
+	}
+
+	if in.Audience != nil {
+		d.Audience = query.NewsAudience{
+			RoleIDs: in.Audience.RoleIds,
+			Locales: in.Audience.Locales,
+		}
+
+		for _, pl := range in.Audience.Platforms {
+			d.Audience.Platforms = append(d.Audience.Platforms, pl.String())
+		}
+	}
+
+	return d
+}
+
+func FeatureFlagToModel(f query.FeatureFlag) *model.FeatureFlag {
+	m := &model.FeatureFlag{
+		ID:          f.ID,
+		Key:         f.Key,
+		Description: f.Description,
+		Enabled:     f.Enabled,
+		Public:      f.Public,
+		Targeting: &model.FeatureFlagTargeting{
+			UserIds:    utils.Ternary(f.Targeting.UserIDs == nil, []primitive.ObjectID{}, f.Targeting.UserIDs),
+			RoleIds:    utils.Ternary(f.Targeting.RoleIDs == nil, []primitive.ObjectID{}, f.Targeting.RoleIDs),
+			Platforms:  make([]model.ConnectionPlatform, len(f.Targeting.Platforms)),
+			Percentage: f.Targeting.Percentage,
+		},
+		UpdatedAt: f.UpdatedAt,
+	}
+
+	for i, pl := range f.Targeting.Platforms {
+		m.Targeting.Platforms[i] = model.ConnectionPlatform(pl)
+	}
+
+	return m
+}
+
+func FeatureFlagInputToData(key string, in model.FeatureFlagInput) query.FeatureFlag {
+	f := query.FeatureFlag{
+		Key:     key,
+		Enabled: in.Enabled,
+	}
+
+	if in.Description != nil {
+		f.Description = *in.Description
+	}
+
+	if in.Public != nil {
+		f.Public = *in.Public
+	}
+
+	if in.Targeting != nil {
+		f.Targeting.UserIDs = in.Targeting.UserIds
+		f.Targeting.RoleIDs = in.Targeting.RoleIds
+
+		for _, pl := range in.Targeting.Platforms {
+			f.Targeting.Platforms = append(f.Targeting.Platforms, pl.String())
+		}
+
+		if in.Targeting.Percentage != nil {
+			f.Targeting.Percentage = *in.Targeting.Percentage
+		}
+	}
+
+	// A flag is on for everyone unless it targets some users
+	if (in.Targeting == nil || in.Targeting.Percentage == nil) && !f.Targeting.Targeted() {
+		f.Targeting.Percentage = 100
+	}
+
+	return f
+}
+
+func NotificationSettingsToModel(s query.NotificationSettings) []*model.NotificationSetting {
+	result := make([]*model.NotificationSetting, len(query.NotificationCategories))
+
+	for i, info := range query.NotificationCategories {
+		result[i] = &model.NotificationSetting{
+			Category:  model.NotificationCategory(info.Category),
+			Mandatory: info.Mandatory,
+			Inbox:     s.Enabled(info.Category, query.NotificationChannelInbox),
+			Whisper:   s.Enabled(info.Category, query.NotificationChannelWhisper),
+		}
+	}
+
+	return result
+}
+
+func EmoteCollectionToModel(c query.EmoteCollection) *model.EmoteCollection {
+	return &model.EmoteCollection{
+		ID:            c.ID,
+		Kind:          model.EmoteCollectionKind(c.Kind),
+		Name:          c.Name,
+		Description:   c.Description,
+		Private:       c.Private,
+		OwnerID:       c.OwnerID,
+		EmoteIds:      c.EmoteIDs(),
+		EmoteCount:    len(c.Emotes),
+		FollowerCount: int(c.FollowerCount),
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+}
+
+func AuditLogStructureToModel(l structures.AuditLog) *model.AuditLog {
+	a := &model.AuditLog{
+		ID:         l.ID,
+		Kind:       int(l.Kind),
+		ActorID:    l.ActorID,
+		TargetID:   l.TargetID,
+		TargetKind: int(l.TargetKind),
+		CreatedAt:  l.ID.Timestamp(),
+		Changes:    make([]*model.AuditLogChange, len(l.Changes)),
+		Reason:     l.Reason,
+	}
+
+	for i, c := range l.Changes {
+		val := map[string]any{}
+		aryval := model.AuditLogChangeArray{}
+
+		switch c.Format {
+		case structures.AuditLogChangeFormatSingleValue:
+			_ = bson.Unmarshal(c.Value, &val)
+		case structures.AuditLogChangeFormatArrayChange:
+			_ = bson.Unmarshal(c.Value, &aryval)
+		}
+
+		a.Changes[i] = &model.AuditLogChange{
+			Format:     int(c.Format),
+			Key:        c.Key,
+			Value:      val,
+			ArrayValue: &aryval,
+		}
+	}
+
+	return a
+}
+
+func AuditLogFilterToData(in *model.AuditLogFilter) query.AuditLogFilter {
+	f := query.AuditLogFilter{}
+	if in == nil {
+		return f
+	}
+
+	if in.ActorID != nil {
+		f.ActorID = *in.ActorID
+	}
+
+	if in.TargetKind != nil {
+		f.TargetKind = utils.PointerOf(structures.ObjectKind(*in.TargetKind))
+	}
+
+	if in.TargetID != nil {
+		f.TargetID = *in.TargetID
+	}
+
+	if in.Kind != nil {
+		f.Kind = utils.PointerOf(structures.AuditLogKind(*in.Kind))
+	}
+
+	if in.Since != nil {
+		f.Since = *in.Since
+	}
+
+	if in.Until != nil {
+		f.Until = *in.Until
+	}
+
+	if in.ChangeKey != nil {
+		f.ChangeKey = *in.ChangeKey
+	}
+
+	return f
+}
+
+// systemServiceNames are the names which dependencies are shown to clients as, by what relies on them
+var systemServiceNames = map[string]string{
+	status.DependencyMongo:        "database",
+	status.DependencyRedis:        "cache",
+	status.DependencyNats:         "events",
+	status.DependencyMessageQueue: "image_processing",
+	status.DependencyS3:           "storage",
+	status.DependencyMeilisearch:  "search",
+}
+
+var systemStates = map[status.State]model.SystemState{
+	status.StateOK:       model.SystemStateOk,
+	status.StateDegraded: model.SystemStateDegraded,
+	status.StateDown:     model.SystemStateDown,
+}
+
+// SystemStatusToModel strips a health report of the details of the infrastructure, such as errors and latencies
+func SystemStatusToModel(r status.Report) *model.SystemStatus {
+	services := make([]*model.SystemService, 0, len(r.Dependencies))
+
+	for _, dep := range r.Dependencies {
+		name, ok := systemServiceNames[dep.Name]
+		if !ok {
+			continue
+		}
+
+		services = append(services, &model.SystemService{
+			Name:  name,
+			State: systemStates[dep.State],
+		})
+	}
+
+	return &model.SystemStatus{
+		State:     systemStates[r.State],
+		CheckedAt: r.CheckedAt,
+		Services:  services,
+	}
+}
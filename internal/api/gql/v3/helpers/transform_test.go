@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestFeatureFlagInputToDataPercentage(t *testing.T) {
+	target := &structures.User{ID: primitive.NewObjectID()}
+	other := &structures.User{ID: primitive.NewObjectID()}
+	half := 50
+
+	cases := []struct {
+		name       string
+		targeting  *model.FeatureFlagTargetingInput
+		percentage int
+	}{
+		{"no targeting", nil, 100},
+		{"empty targeting", &model.FeatureFlagTargetingInput{}, 100},
+		{"user targeting", &model.FeatureFlagTargetingInput{UserIds: []primitive.ObjectID{target.ID}}, 0},
+		{"role targeting", &model.FeatureFlagTargetingInput{RoleIds: []primitive.ObjectID{primitive.NewObjectID()}}, 0},
+		{"platform targeting", &model.FeatureFlagTargetingInput{Platforms: []model.ConnectionPlatform{model.ConnectionPlatformTwitch}}, 0},
+		{"explicit percentage", &model.FeatureFlagTargetingInput{UserIds: []primitive.ObjectID{target.ID}, Percentage: &half}, 50},
+	}
+
+	for _, c := range cases {
+		f := FeatureFlagInputToData("test", model.FeatureFlagInput{Enabled: true, Targeting: c.targeting})
+
+		testutil.Assert(t, c.percentage, f.Targeting.Percentage, c.name)
+	}
+
+	// A flag targeting a single user is on for that user only
+	f := FeatureFlagInputToData("test", model.FeatureFlagInput{
+		Enabled:   true,
+		Targeting: &model.FeatureFlagTargetingInput{UserIds: []primitive.ObjectID{target.ID}},
+	})
+
+	testutil.Assert(t, true, f.IsOn(target), "the targeted user has the flag on")
+	testutil.Assert(t, false, f.IsOn(other), "other users have the flag off")
+	testutil.Assert(t, false, f.IsOn(nil), "anonymous requests have the flag off")
+}
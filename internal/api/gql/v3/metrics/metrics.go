@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/seventv/api/internal/svc/persisted"
+	"github.com/seventv/api/internal/svc/prometheus"
+)
+
+// Metrics records the duration and outcome of operations, and of the fields resolved by a resolver.
+//
+// Operation names are chosen by clients, so operations are labelled by the name they have in the
+// persisted query registry, and those which are not in it are grouped together
+type Metrics struct {
+	Prometheus prometheus.Instance
+	Persisted  persisted.Instance
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Metrics{}
+
+func (Metrics) ExtensionName() string {
+	return "Metrics"
+}
+
+func (Metrics) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (m Metrics) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+
+	if !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	rc := graphql.GetOperationContext(ctx)
+	if rc.Operation == nil {
+		return resp
+	}
+
+	name := "other"
+	if op, ok := m.Persisted.Lookup(persisted.Hash(rc.RawQuery)); ok {
+		name = op.Name
+		if name == "" {
+			name = "anonymous"
+		}
+	}
+
+	m.Prometheus.ObserveGQLOperation(name, string(rc.Operation.Operation), resp == nil || len(resp.Errors) > 0, time.Since(rc.Stats.OperationStart))
+
+	return resp
+}
+
+func (m Metrics) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+
+	start := time.Now()
+
+	res, err := next(ctx)
+
+	m.Prometheus.ObserveGQLField(fc.Object, fc.Field.Name, err != nil, time.Since(start))
+
+	return res, err
+}
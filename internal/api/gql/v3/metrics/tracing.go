@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/seventv/api/internal/svc/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Operation names are chosen by clients, so spans are named after those which look like one
+var operationNameRegex = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]{0,63}$`)
+
+// Tracing names the span of the request after its operation, and adds a span for the
+// resolvers which took at least the threshold
+type Tracing struct {
+	Threshold time.Duration
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = Tracing{}
+
+func (Tracing) ExtensionName() string {
+	return "Tracing"
+}
+
+func (Tracing) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (Tracing) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() || !graphql.HasOperationContext(ctx) {
+		return resp
+	}
+
+	rc := graphql.GetOperationContext(ctx)
+	if rc.Operation == nil {
+		return resp
+	}
+
+	name := rc.Operation.Name
+	if name == "" || !operationNameRegex.MatchString(name) {
+		name = "anonymous"
+	}
+
+	span.SetName("gql " + string(rc.Operation.Operation) + " " + name)
+	span.SetAttributes(
+		attribute.String("graphql.operation.name", rc.Operation.Name),
+		attribute.String("graphql.operation.type", string(rc.Operation.Operation)),
+	)
+
+	if resp != nil && len(resp.Errors) > 0 {
+		span.SetAttributes(attribute.Int("graphql.errors", len(resp.Errors)))
+	}
+
+	return resp
+}
+
+func (t Tracing) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsResolver || !trace.SpanFromContext(ctx).IsRecording() {
+		return next(ctx)
+	}
+
+	start := time.Now()
+
+	res, err := next(ctx)
+
+	// Most resolvers are quick, so the span is only added once the duration is known
+	if time.Since(start) >= t.Threshold {
+		_, span := tracing.StartChild(ctx, "resolve "+fc.Object+"."+fc.Field.Name,
+			trace.WithTimestamp(start),
+			trace.WithAttributes(attribute.String("graphql.field.path", fc.Path().String())),
+		)
+
+		tracing.End(span, err)
+	}
+
+	return res, err
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/seventv/api/internal/api/gql/v3/auth"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
 	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/middleware"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/structures/v3"
 )
@@ -65,6 +66,11 @@ func hasPermission(gCtx global.Context) func(ctx context.Context, obj interface{
 			return nil, errors.ErrUnauthorized()
 		}
 
+		// Sensitive permissions may require a recent second-factor check, whether they guard a query or a mutation
+		if err := middleware.RequireSecondFactor(gCtx, ctx, user, perms); err != nil {
+			return nil, err
+		}
+
 		return next(ctx)
 	}
 }
@@ -18,7 +18,7 @@ func New(r types.Resolver) generated.BanResolver {
 }
 
 func (r *Resolver) Victim(ctx context.Context, obj *model.Ban) (*model.User, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.VictimID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.VictimID)
 	if err != nil {
 		return nil, err
 	}
@@ -27,7 +27,7 @@ func (r *Resolver) Victim(ctx context.Context, obj *model.Ban) (*model.User, err
 }
 
 func (r *Resolver) Actor(ctx context.Context, obj *model.Ban) (*model.User, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.ActorID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.ActorID)
 	if err != nil {
 		return nil, err
 	}
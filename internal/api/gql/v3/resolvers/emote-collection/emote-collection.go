@@ -0,0 +1,70 @@
+package emote_collection
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/generated"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/types"
+	"github.com/seventv/api/internal/loaders"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+)
+
+type Resolver struct {
+	types.Resolver
+}
+
+func New(r types.Resolver) generated.EmoteCollectionResolver {
+	return &Resolver{r}
+}
+
+func (r *Resolver) Owner(ctx context.Context, obj *model.EmoteCollection) (*model.UserPartial, error) {
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.OwnerID)
+	if err != nil {
+		if errors.Compare(err, errors.ErrUnknownUser()) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(user).ToPartial()), nil
+}
+
+// Emotes returns the emotes of the collection, in the order they were added. Deleted emotes are left out
+func (r *Resolver) Emotes(ctx context.Context, obj *model.EmoteCollection, limit *int) ([]*model.EmotePartial, error) {
+	ids := obj.EmoteIds
+	if limit != nil && *limit >= 0 && *limit < len(ids) {
+		ids = ids[:*limit]
+	}
+
+	emotes, errs := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, ids)
+
+	result := make([]*model.EmotePartial, 0, len(emotes))
+
+	for _, e := range emotes {
+		if e.ID.IsZero() || e.ID == structures.DeletedEmote.ID {
+			continue
+		}
+
+		result = append(result, modelgql.EmotePartialModel(r.Ctx.Inst().Modelizer.Emote(e).ToPartial()))
+	}
+
+	return result, multierror.Append(nil, errs...).ErrorOrNil()
+}
+
+func (r *Resolver) Followed(ctx context.Context, obj *model.EmoteCollection) (bool, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false, nil
+	}
+
+	return r.Ctx.Inst().Loaders.EmoteCollectionFollowed().Load(ctx, loaders.EmoteCollectionFollowKey{
+		UserID:       actor.ID,
+		CollectionID: obj.ID,
+	})
+}
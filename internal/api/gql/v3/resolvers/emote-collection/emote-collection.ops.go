@@ -0,0 +1,87 @@
+package emote_collection
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/generated"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/api/internal/api/gql/v3/types"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ResolverOps struct {
+	types.Resolver
+}
+
+func NewOps(r types.Resolver) generated.EmoteCollectionOpsResolver {
+	return &ResolverOps{r}
+}
+
+func (r *ResolverOps) Update(ctx context.Context, obj *model.EmoteCollectionOps, data model.EmoteCollectionInput) (*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	col, err := r.Ctx.Inst().Mutate.EditEmoteCollection(ctx, obj.ID, mutate.EmoteCollectionOptions{
+		Actor:       actor,
+		Name:        data.Name,
+		Description: data.Description,
+		Private:     data.Private,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCollectionToModel(col), nil
+}
+
+func (r *ResolverOps) Delete(ctx context.Context, obj *model.EmoteCollectionOps) (bool, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false, errors.ErrUnauthorized()
+	}
+
+	if err := r.Ctx.Inst().Mutate.DeleteEmoteCollection(ctx, actor, obj.ID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *ResolverOps) Emotes(ctx context.Context, obj *model.EmoteCollectionOps, id primitive.ObjectID, action model.ListItemAction) (*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	col, err := r.Ctx.Inst().Mutate.EditEmotesInCollection(ctx, actor, obj.ID, id, structures.ListItemAction(action))
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCollectionToModel(col), nil
+}
+
+func (r *ResolverOps) Follow(ctx context.Context, obj *model.EmoteCollectionOps, follow bool) (*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	if err := r.Ctx.Inst().Mutate.FollowEmoteCollection(ctx, actor, obj.ID, follow); err != nil {
+		return nil, err
+	}
+
+	col, err := r.Ctx.Inst().Query.EmoteCollection(ctx, &actor, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCollectionToModel(col), nil
+}
@@ -0,0 +1,90 @@
+package emote_comment
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/generated"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/api/internal/api/gql/v3/types"
+	"github.com/seventv/common/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const EMOTE_COMMENTS_QUERY_LIMIT = 100
+
+type Resolver struct {
+	types.Resolver
+}
+
+func New(r types.Resolver) generated.EmoteCommentResolver {
+	return &Resolver{r}
+}
+
+func (r *Resolver) Author(ctx context.Context, obj *model.EmoteComment) (*model.UserPartial, error) {
+	if obj.AuthorID == nil {
+		return nil, nil
+	}
+
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, *obj.AuthorID)
+	if err != nil {
+		if errors.Compare(err, errors.ErrUnknownUser()) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(user).ToPartial()), nil
+}
+
+func (r *Resolver) Replies(ctx context.Context, obj *model.EmoteComment, after *primitive.ObjectID, limitArg *int) (*model.EmoteCommentList, error) {
+	// Replies belong to top-level comments
+	if obj.ParentID != nil || obj.ReplyCount == 0 {
+		return &model.EmoteCommentList{Items: []*model.EmoteComment{}}, nil
+	}
+
+	limit, err := CommentsLimit(limitArg)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := auth.For(ctx)
+
+	opt := query.EmoteCommentsQueryOptions{
+		Actor:    &actor,
+		ParentID: obj.ID,
+		Limit:    limit,
+	}
+
+	if after != nil {
+		opt.After = *after
+	}
+
+	res, err := r.Ctx.Inst().Query.EmoteComments(ctx, obj.EmoteID, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCommentsToModel(res), nil
+}
+
+// CommentsLimit reads the page size of a comment list
+func CommentsLimit(limitArg *int) (int, error) {
+	limit := 20
+
+	if limitArg != nil {
+		limit = *limitArg
+
+		if limit > EMOTE_COMMENTS_QUERY_LIMIT {
+			return 0, errors.ErrInvalidRequest().SetDetail("limit must be less than %d", EMOTE_COMMENTS_QUERY_LIMIT)
+		} else if limit < 1 {
+			return 0, errors.ErrInvalidRequest().SetDetail("limit must be greater than 0")
+		}
+	}
+
+	return limit, nil
+}
@@ -2,6 +2,7 @@ package emote
 
 import (
 	"context"
+	"math"
 	"strconv"
 
 	"github.com/seventv/api/data/model/modelgql"
@@ -56,9 +57,15 @@ func (r *Resolver) Channels(ctx context.Context, obj *model.Emote, pageArg *int,
 		models[i] = modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(u).ToPartial())
 	}
 
+	maxPage := int(math.Ceil(float64(count) / float64(limit)))
+	if maxPage > EMOTE_CHANNEL_QUERY_PAGE_CAP {
+		maxPage = EMOTE_CHANNEL_QUERY_PAGE_CAP
+	}
+
 	results := model.UserSearchResult{
-		Total: int(count),
-		Items: models,
+		Total:   int(count),
+		MaxPage: maxPage,
+		Items:   models,
 	}
 
 	return &results, nil
@@ -0,0 +1,43 @@
+package emote
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	emote_comment "github.com/seventv/api/internal/api/gql/v3/resolvers/emote-comment"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Resolver) Comments(ctx context.Context, obj *model.Emote, after *primitive.ObjectID, limitArg *int) (*model.EmoteCommentList, error) {
+	limit, err := emote_comment.CommentsLimit(limitArg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The id may be that of a version, comments are kept on the emote
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := auth.For(ctx)
+
+	opt := query.EmoteCommentsQueryOptions{
+		Actor: &actor,
+		Limit: limit,
+	}
+
+	if after != nil {
+		opt.After = *after
+	}
+
+	res, err := r.Ctx.Inst().Query.EmoteComments(ctx, emote.ID, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCommentsToModel(res), nil
+}
@@ -30,7 +30,7 @@ func New(r types.Resolver) generated.EmoteResolver {
 }
 
 func (r *Resolver) Owner(ctx context.Context, obj *model.Emote) (*model.UserPartial, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.OwnerID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.OwnerID)
 	if err != nil {
 		if errors.Compare(err, errors.ErrUnknownUser()) {
 			return nil, nil
@@ -248,7 +248,7 @@ func (r *Resolver) Activity(ctx context.Context, obj *model.Emote, limitArg *int
 		i++
 	}
 
-	actors, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(actorIDs)
+	actors, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, actorIDs)
 	if multierror.Append(nil, errs...).ErrorOrNil() != nil {
 		return result, errors.ErrInternalServerError()
 	}
@@ -264,3 +264,12 @@ func (r *Resolver) Activity(ctx context.Context, obj *model.Emote, limitArg *int
 
 	return result, nil
 }
+
+func (r *Resolver) FavoriteCount(ctx context.Context, obj *model.Emote) (int, error) {
+	count, err := r.Ctx.Inst().Loaders.EmoteFavoriteCountByID().Load(ctx, obj.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
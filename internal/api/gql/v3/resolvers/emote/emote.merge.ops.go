@@ -56,7 +56,7 @@ func (r *ResolverOps) Merge(ctx context.Context, obj *model.EmoteOps, targetID p
 		return nil, err
 	}
 
-	returnEmote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(targetEmote.ID)
+	returnEmote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, targetEmote.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -133,7 +133,7 @@ func (r *ResolverOps) Update(ctx context.Context, obj *model.EmoteOps, params mo
 		}
 	}
 
-	emote, err = r.Ctx.Inst().Loaders.EmoteByID().Load(obj.ID)
+	emote, err = r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
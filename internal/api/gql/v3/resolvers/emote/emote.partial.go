@@ -24,7 +24,7 @@ func (r *ResolverPartial) Owner(ctx context.Context, obj *model.EmotePartial) (*
 		return obj.Owner, nil
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.OwnerID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.OwnerID)
 	if err != nil {
 		if errors.Compare(err, errors.ErrUnknownUser()) {
 			return nil, nil
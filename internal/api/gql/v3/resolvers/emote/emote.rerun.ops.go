@@ -26,8 +26,10 @@ func (r *ResolverOps) Rerun(ctx context.Context, obj *model.EmoteOps) (*model.Em
 		return nil, errors.ErrInsufficientPrivilege()
 	}
 
+	cfg := r.Ctx.Config()
+
 	// Get the emote
-	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(obj.ID)
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -45,12 +47,12 @@ func (r *ResolverOps) Rerun(ctx context.Context, obj *model.EmoteOps) (*model.Em
 		ID:    emote.ID.Hex(),
 		Flags: task.TaskFlagALL,
 		Input: task.TaskInput{
-			Bucket: r.Ctx.Config().S3.InternalBucket,
+			Bucket: cfg.S3.InternalBucket,
 			Key:    filekey,
 		},
 		Output: task.TaskOutput{
 			Prefix:       r.Ctx.Inst().S3.ComposeKey("emote", path.Dir(ver.InputFile.Key)),
-			Bucket:       r.Ctx.Config().S3.PublicBucket,
+			Bucket:       cfg.S3.PublicBucket,
 			CacheControl: *s3.DefaultCacheControl,
 		},
 		SmallestMaxWidth:  96,
@@ -58,21 +60,21 @@ func (r *ResolverOps) Rerun(ctx context.Context, obj *model.EmoteOps) (*model.Em
 		Scales:            []int{1, 2, 3, 4},
 		ResizeRatio:       task.ResizeRatioNothing,
 		Limits: task.TaskLimits{
-			MaxProcessingTime: time.Duration(r.Ctx.Config().Limits.Emotes.MaxProcessingTimeSeconds) * time.Second,
-			MaxFrameCount:     r.Ctx.Config().Limits.Emotes.MaxFrameCount,
-			MaxWidth:          r.Ctx.Config().Limits.Emotes.MaxWidth,
-			MaxHeight:         r.Ctx.Config().Limits.Emotes.MaxHeight,
+			MaxProcessingTime: time.Duration(cfg.Limits.Emotes.MaxProcessingTimeSeconds) * time.Second,
+			MaxFrameCount:     cfg.Limits.Emotes.MaxFrameCount,
+			MaxWidth:          cfg.Limits.Emotes.MaxWidth,
+			MaxHeight:         cfg.Limits.Emotes.MaxHeight,
 		},
 	})
 
 	if err == nil {
 		err = r.Ctx.Inst().MessageQueue.Publish(ctx, messagequeue.OutgoingMessage{
-			Queue:   r.Ctx.Config().MessageQueue.ImageProcessorJobsQueueName,
+			Queue:   cfg.MessageQueue.ImageProcessorJobsQueueName,
 			Headers: messagequeue.MessageHeaders{},
 			Flags: messagequeue.MessageFlags{
 				ID:          emote.ID.Hex(),
 				ContentType: "application/json",
-				ReplyTo:     r.Ctx.Config().MessageQueue.ImageProcessorResultsQueueName,
+				ReplyTo:     cfg.MessageQueue.ImageProcessorResultsQueueName,
 				Timestamp:   time.Now(),
 				RMQ: messagequeue.MessageFlagsRMQ{
 					DeliveryMode: messagequeue.RMQDeliveryModePersistent,
@@ -23,7 +23,7 @@ func (r *Resolver) Actor(ctx context.Context, obj *model.ActiveEmote) (*model.Us
 		return nil, nil
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.Actor.ID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.Actor.ID)
 	if err != nil {
 		if errors.Compare(err, errors.ErrUnknownUser()) {
 			return nil, nil
@@ -36,7 +36,7 @@ func (r *Resolver) Actor(ctx context.Context, obj *model.ActiveEmote) (*model.Us
 }
 
 func (r *Resolver) Data(ctx context.Context, obj *model.ActiveEmote) (*model.EmotePartial, error) {
-	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(obj.ID)
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, obj.ID)
 	if err != nil {
 		if errors.Compare(err, errors.ErrUnknownEmote()) {
 			return nil, nil
@@ -0,0 +1,88 @@
+package emoteset
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Resolver) Changes(ctx context.Context, obj *model.EmoteSet, after *primitive.ObjectID, limitArg *int) (*model.EmoteSetChangeList, error) {
+	limit := 100
+	if limitArg != nil {
+		limit = *limitArg
+
+		if limit > 500 {
+			return nil, errors.ErrInvalidRequest().SetDetail("limit must be less than 500")
+		} else if limit < 1 {
+			return nil, errors.ErrInvalidRequest().SetDetail("limit must be greater than 0")
+		}
+	}
+
+	cursor := primitive.NilObjectID
+	if after != nil {
+		cursor = *after
+	}
+
+	res, err := r.Ctx.Inst().Query.EmoteSetChanges(ctx, obj.ID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.EmoteSetChangeList{
+		Items:     make([]*model.EmoteSetChange, len(res.Changes)),
+		HasMore:   res.HasMore,
+		Truncated: res.Truncated,
+	}
+
+	if !res.Cursor.IsZero() {
+		result.Cursor = &res.Cursor
+	}
+
+	// Fetch the actors
+	actorIDs := make(utils.Set[primitive.ObjectID])
+	for _, c := range res.Changes {
+		actorIDs.Add(c.ActorID)
+	}
+
+	actorMap := make(map[primitive.ObjectID]structures.User)
+
+	actors, _ := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, actorIDs.Values())
+	for _, u := range actors {
+		actorMap[u.ID] = u
+	}
+
+	for i, c := range res.Changes {
+		ch := &model.EmoteSetChange{
+			ID:        c.ID,
+			Kind:      model.EmoteSetChangeKind(c.Kind),
+			ActorID:   c.ActorID,
+			Timestamp: c.Timestamp,
+		}
+
+		if !c.EmoteID.IsZero() {
+			ch.EmoteID = utils.PointerOf(c.EmoteID)
+			ch.Name = utils.PointerOf(c.Name)
+		}
+
+		if c.Field != "" {
+			ch.Field = utils.PointerOf(c.Field)
+		}
+
+		if c.OldName != "" {
+			ch.OldName = utils.PointerOf(c.OldName)
+		}
+
+		if actor, ok := actorMap[c.ActorID]; ok && !actor.ID.IsZero() {
+			ch.Actor = modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(actor).ToPartial())
+		}
+
+		result.Items[i] = ch
+	}
+
+	return result, nil
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 
 	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/gql/v3/gen/generated"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
 	"github.com/seventv/api/internal/api/gql/v3/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Resolver struct {
@@ -22,7 +24,7 @@ func (r *Resolver) Owner(ctx context.Context, obj *model.EmoteSet) (*model.UserP
 		return nil, nil
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(*obj.OwnerID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, *obj.OwnerID)
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +53,34 @@ func (*Resolver) Emotes(ctx context.Context, obj *model.EmoteSet, limit *int, or
 
 	return emotes, nil
 }
+
+func (r *Resolver) SourceID(ctx context.Context, obj *model.EmoteSet) (*primitive.ObjectID, error) {
+	sourceID, err := r.Ctx.Inst().Loaders.EmoteSetSourceID().Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceID.IsZero() {
+		return nil, nil
+	}
+
+	return &sourceID, nil
+}
+
+func (r *Resolver) OriginPolicy(ctx context.Context, obj *model.EmoteSet) (*model.EmoteSetOriginPolicy, error) {
+	policies, err := r.Ctx.Inst().Query.EmoteSetOriginPolicies(ctx, []primitive.ObjectID{obj.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	policy, ok := policies[obj.ID]
+	if !ok {
+		policy.Mode = query.EmoteSetOriginConflictModeOriginWins
+	}
+
+	return &model.EmoteSetOriginPolicy{
+		Mode:   model.EmoteSetOriginConflictMode(policy.Mode),
+		Prefix: policy.Prefix,
+		Suffix: policy.Suffix,
+	}, nil
+}
@@ -27,7 +27,7 @@ func NewOps(r types.Resolver) generated.EmoteSetOpsResolver {
 	return &ResolverOps{r}
 }
 
-func (r *ResolverOps) Emotes(ctx context.Context, obj *model.EmoteSetOps, id primitive.ObjectID, action model.ListItemAction, nameArg *string) ([]*model.ActiveEmote, error) {
+func (r *ResolverOps) Emotes(ctx context.Context, obj *model.EmoteSetOps, id *primitive.ObjectID, action model.ListItemAction, nameArg *string, collectionID *primitive.ObjectID) ([]*model.ActiveEmote, error) {
 	done := r.Ctx.Inst().Limiter.AwaitMutation(ctx)
 	defer done()
 
@@ -36,12 +36,40 @@ func (r *ResolverOps) Emotes(ctx context.Context, obj *model.EmoteSetOps, id pri
 		return nil, errors.ErrUnauthorized()
 	}
 
-	// Get the emote set
-	name := ""
-	if nameArg != nil {
-		name = *nameArg
+	opt := mutate.EmoteSetMutationSetEmoteOptions{
+		Actor: actor,
+	}
+
+	// Either a single emote or a collection is edited
+	switch {
+	case collectionID != nil:
+		if id != nil || action != model.ListItemActionAdd {
+			return nil, errors.ErrInvalidRequest().SetDetail("A collection can only be added on its own")
+		}
+
+		col, err := r.Ctx.Inst().Query.EmoteCollection(ctx, &actor, *collectionID)
+		if err != nil {
+			return nil, err
+		}
+
+		opt.Collection = &col
+	case id != nil:
+		name := ""
+		if nameArg != nil {
+			name = *nameArg
+		}
+
+		opt.Emotes = []mutate.EmoteSetMutationSetEmoteItem{{
+			Action: structures.ListItemAction(action),
+			ID:     *id,
+			Name:   name,
+			Flags:  0,
+		}}
+	default:
+		return nil, errors.ErrMissingRequiredField().SetDetail("id")
 	}
 
+	// Get the emote set
 	set, err := r.Ctx.Inst().Query.EmoteSets(ctx, bson.M{"_id": obj.ID}, query.QueryEmoteSetsOptions{FetchOrigins: true}).First()
 	if err != nil {
 		if errors.Compare(err, errors.ErrNoItems()) {
@@ -54,22 +82,23 @@ func (r *ResolverOps) Emotes(ctx context.Context, obj *model.EmoteSetOps, id pri
 	b := structures.NewEmoteSetBuilder(set)
 
 	// Mutate the thing
-	if err := r.Ctx.Inst().Mutate.EditEmotesInSet(ctx, b, mutate.EmoteSetMutationSetEmoteOptions{
-		Actor: actor,
-		Emotes: []mutate.EmoteSetMutationSetEmoteItem{{
-			Action: structures.ListItemAction(action),
-			ID:     id,
-			Name:   name,
-			Flags:  0,
-		}},
-	}); err != nil {
+	if err := r.Ctx.Inst().Mutate.EditEmotesInSet(ctx, b, opt); err != nil {
 		return nil, err
 	}
 
 	// Clear cache keys for active sets / channel count
-	k := r.Ctx.Inst().Redis.ComposeKey("gql-v3", fmt.Sprintf("emote:%s", id.Hex()))
-	_, _ = r.Ctx.Inst().Redis.Del(ctx, k+":active_sets")
-	_, _ = r.Ctx.Inst().Redis.Del(ctx, k+":channel_count")
+	var changedIDs []primitive.ObjectID
+	if opt.Collection != nil {
+		changedIDs = opt.Collection.EmoteIDs()
+	} else {
+		changedIDs = []primitive.ObjectID{*id}
+	}
+
+	for _, eid := range changedIDs {
+		k := r.Ctx.Inst().Redis.ComposeKey("gql-v3", fmt.Sprintf("emote:%s", eid.Hex()))
+		_, _ = r.Ctx.Inst().Redis.Del(ctx, k+":active_sets")
+		_, _ = r.Ctx.Inst().Redis.Del(ctx, k+":channel_count")
+	}
 
 	emoteIDs := make([]primitive.ObjectID, len(b.EmoteSet.Emotes))
 	for i, e := range b.EmoteSet.Emotes {
@@ -77,7 +106,7 @@ func (r *ResolverOps) Emotes(ctx context.Context, obj *model.EmoteSetOps, id pri
 	}
 
 	setModel := modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(b.EmoteSet))
-	emotes, errs := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(emoteIDs)
+	emotes, errs := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, emoteIDs)
 
 	for i, e := range emotes {
 		if ae := setModel.Emotes[i]; ae != nil {
@@ -151,6 +180,32 @@ func (r *ResolverOps) Update(ctx context.Context, obj *model.EmoteSetOps, data m
 		}))
 	}
 
+	// Update the conflict policy
+	if data.OriginPolicy != nil {
+		policy := query.EmoteSetOriginPolicy{
+			Mode: query.EmoteSetOriginConflictMode(data.OriginPolicy.Mode),
+		}
+
+		if data.OriginPolicy.Prefix != nil {
+			policy.Prefix = *data.OriginPolicy.Prefix
+		}
+
+		if data.OriginPolicy.Suffix != nil {
+			policy.Suffix = *data.OriginPolicy.Suffix
+		}
+
+		if err := r.Ctx.Inst().Mutate.SetEmoteSetOriginPolicy(ctx, esb, policy, mutate.EmoteSetMutationOptions{
+			Actor: actor,
+		}); err != nil {
+			return nil, err
+		}
+
+		// Nothing else to update
+		if data.Name == nil && data.Capacity == nil && data.Origins == nil {
+			return modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(esb.EmoteSet)), nil
+		}
+	}
+
 	// Do update
 	if err := r.Ctx.Inst().Mutate.UpdateEmoteSet(ctx, esb, mutate.EmoteSetMutationOptions{
 		Actor: actor,
@@ -160,3 +215,33 @@ func (r *ResolverOps) Update(ctx context.Context, obj *model.EmoteSetOps, data m
 
 	return modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(esb.EmoteSet)), nil
 }
+
+func (r *ResolverOps) Sync(ctx context.Context, obj *model.EmoteSetOps) (*model.EmoteSet, error) {
+	done := r.Ctx.Inst().Limiter.AwaitMutation(ctx)
+	defer done()
+
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	// Get the emote set
+	es, err := r.Ctx.Inst().Query.EmoteSets(ctx, bson.M{"_id": obj.ID}).First()
+	if err != nil {
+		if errors.Compare(err, errors.ErrNoItems()) {
+			return nil, errors.ErrUnknownEmoteSet()
+		}
+
+		return nil, err
+	}
+
+	esb := structures.NewEmoteSetBuilder(es)
+
+	if err := r.Ctx.Inst().Mutate.SyncEmoteSet(ctx, esb, mutate.EmoteSetMutationOptions{
+		Actor: actor,
+	}); err != nil {
+		return nil, err
+	}
+
+	return modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(esb.EmoteSet)), nil
+}
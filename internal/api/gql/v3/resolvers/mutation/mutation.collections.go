@@ -0,0 +1,46 @@
+package mutation
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Resolver) Collection(ctx context.Context, id primitive.ObjectID) (*model.EmoteCollectionOps, error) {
+	return &model.EmoteCollectionOps{
+		ID: id,
+	}, nil
+}
+
+// CreateCollection implements generated.MutationResolver
+func (r *Resolver) CreateCollection(ctx context.Context, data model.EmoteCollectionInput) (*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+
+	col, err := r.Ctx.Inst().Mutate.CreateEmoteCollection(ctx, mutate.EmoteCollectionOptions{
+		Actor:       actor,
+		Name:        data.Name,
+		Description: data.Description,
+		Private:     data.Private,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCollectionToModel(col), nil
+}
+
+// FavoriteEmote implements generated.MutationResolver
+func (r *Resolver) FavoriteEmote(ctx context.Context, emoteID primitive.ObjectID, favorite bool) (*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+
+	col, err := r.Ctx.Inst().Mutate.FavoriteEmote(ctx, actor, emoteID, favorite)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCollectionToModel(col), nil
+}
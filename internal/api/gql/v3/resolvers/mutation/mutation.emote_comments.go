@@ -0,0 +1,100 @@
+package mutation
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/api/internal/svc/limiter"
+	"github.com/seventv/common/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Resolver) CreateEmoteComment(ctx context.Context, emoteID primitive.ObjectID, content string, replyTo *primitive.ObjectID, authoritative *bool) (*model.EmoteComment, error) {
+	if ok := r.Ctx.Inst().Limiter.Test(ctx, "create-emote-comment", 5, time.Minute, limiter.TestOptions{
+		Incr: 1,
+	}); !ok {
+		return nil, errors.ErrRateLimited()
+	}
+
+	actor := auth.For(ctx)
+
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, emoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := mutate.EmoteCommentOptions{
+		Actor:         actor,
+		Emote:         emote,
+		Content:       content,
+		Authoritative: authoritative != nil && *authoritative,
+	}
+
+	if replyTo != nil {
+		opt.ReplyToID = *replyTo
+	}
+
+	comment, err := r.Ctx.Inst().Mutate.CreateEmoteComment(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCommentToModel(comment), nil
+}
+
+func (r *Resolver) EditEmoteComment(ctx context.Context, id primitive.ObjectID, content string) (*model.EmoteComment, error) {
+	if ok := r.Ctx.Inst().Limiter.Test(ctx, "edit-emote-comment", 10, time.Minute, limiter.TestOptions{
+		Incr: 1,
+	}); !ok {
+		return nil, errors.ErrRateLimited()
+	}
+
+	actor := auth.For(ctx)
+
+	comment, err := r.Ctx.Inst().Mutate.EditEmoteComment(ctx, id, content, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCommentToModel(comment), nil
+}
+
+func (r *Resolver) DeleteEmoteComment(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	actor := auth.For(ctx)
+
+	if err := r.Ctx.Inst().Mutate.DeleteEmoteComment(ctx, id, actor); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Resolver) ModerateEmoteComment(ctx context.Context, id primitive.ObjectID, hidden *bool, pinned *bool) (*model.EmoteComment, error) {
+	actor := auth.For(ctx)
+
+	comment, err := r.Ctx.Inst().Query.EmoteComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, comment.Data.EmoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err = r.Ctx.Inst().Mutate.ModerateEmoteComment(ctx, id, mutate.EmoteCommentModerationOptions{
+		Actor:  actor,
+		Emote:  emote,
+		Hidden: hidden,
+		Pinned: pinned,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.EmoteCommentToModel(comment), nil
+}
@@ -2,16 +2,22 @@ package mutation
 
 import (
 	"context"
+	"time"
 
 	"github.com/seventv/api/data/model/modelgql"
 	"github.com/seventv/api/data/mutate"
 	"github.com/seventv/api/internal/api/gql/v3/auth"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/svc/limiter"
+	"github.com/seventv/common/errors"
 	"github.com/seventv/common/structures/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// The number of emotes in new sets, unless configured otherwise
+const DEFAULT_EMOTE_SET_CAPACITY = 300
+
 func (r *Resolver) EmoteSet(ctx context.Context, id primitive.ObjectID) (*model.EmoteSetOps, error) {
 	return &model.EmoteSetOps{
 		ID: id,
@@ -32,7 +38,7 @@ func (r *Resolver) CreateEmoteSet(ctx context.Context, userID primitive.ObjectID
 		SetName(input.Name).
 		SetPrivileged(isPrivileged).
 		SetOwnerID(userID).
-		SetCapacity(300)
+		SetCapacity(r.emoteSetCapacity())
 
 	// Execute mutation
 	if err := r.Ctx.Inst().Mutate.CreateEmoteSet(ctx, b, mutate.EmoteSetMutationOptions{
@@ -48,3 +54,57 @@ func (r *Resolver) CreateEmoteSet(ctx context.Context, userID primitive.ObjectID
 
 	return modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(emoteSet)), nil
 }
+
+// CloneEmoteSet: create a new emote set as a copy of another
+func (r *Resolver) CloneEmoteSet(ctx context.Context, sourceID primitive.ObjectID, name string, userIDArg *primitive.ObjectID, link *bool) (*model.EmoteSet, error) {
+	if ok := r.Ctx.Inst().Limiter.Test(ctx, "clone-emote-set", 5, time.Minute*10, limiter.TestOptions{
+		Incr: 1,
+	}); !ok {
+		return nil, errors.ErrRateLimited()
+	}
+
+	actor := auth.For(ctx)
+
+	userID := actor.ID
+	if userIDArg != nil {
+		userID = *userIDArg
+	}
+
+	source, err := r.Ctx.Inst().Query.EmoteSets(ctx, bson.M{"_id": sourceID}).First()
+	if err != nil {
+		if errors.Compare(err, errors.ErrNoItems()) {
+			return nil, errors.ErrUnknownEmoteSet()
+		}
+
+		return nil, err
+	}
+
+	b := structures.NewEmoteSetBuilder(structures.EmoteSet{Emotes: []structures.ActiveEmote{}}).
+		SetName(name).
+		SetOwnerID(userID).
+		SetCapacity(r.emoteSetCapacity())
+
+	if err := r.Ctx.Inst().Mutate.CloneEmoteSet(ctx, b, mutate.EmoteSetCloneOptions{
+		Actor:  actor,
+		Source: source,
+		Link:   link != nil && *link,
+	}); err != nil {
+		return nil, err
+	}
+
+	emoteSet, err := r.Ctx.Inst().Query.EmoteSets(ctx, bson.M{"_id": b.EmoteSet.ID}).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(emoteSet)), nil
+}
+
+// emoteSetCapacity returns the number of emotes in sets created through the API
+func (r *Resolver) emoteSetCapacity() int32 {
+	if c := r.Ctx.Config().Limits.EmoteSets.Capacity; c > 0 {
+		return c
+	}
+
+	return DEFAULT_EMOTE_SET_CAPACITY
+}
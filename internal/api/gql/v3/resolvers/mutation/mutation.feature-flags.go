@@ -0,0 +1,66 @@
+package mutation
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+)
+
+// CreateFeatureFlag implements generated.MutationResolver
+func (r *Resolver) CreateFeatureFlag(ctx context.Context, key string, data model.FeatureFlagInput) (*model.FeatureFlag, error) {
+	actor := auth.For(ctx)
+
+	flag, err := r.Ctx.Inst().Mutate.CreateFeatureFlag(ctx, mutate.FeatureFlagOptions{
+		Actor: actor,
+		Data:  helpers.FeatureFlagInputToData(key, data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.refreshFeatureFlags(ctx)
+
+	return helpers.FeatureFlagToModel(flag), nil
+}
+
+// EditFeatureFlag implements generated.MutationResolver
+func (r *Resolver) EditFeatureFlag(ctx context.Context, id primitive.ObjectID, data model.FeatureFlagInput) (*model.FeatureFlag, error) {
+	actor := auth.For(ctx)
+
+	flag, err := r.Ctx.Inst().Mutate.UpdateFeatureFlag(ctx, id, mutate.FeatureFlagOptions{
+		Actor: actor,
+		Data:  helpers.FeatureFlagInputToData("", data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.refreshFeatureFlags(ctx)
+
+	return helpers.FeatureFlagToModel(flag), nil
+}
+
+// DeleteFeatureFlag implements generated.MutationResolver
+func (r *Resolver) DeleteFeatureFlag(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	actor := auth.For(ctx)
+
+	if err := r.Ctx.Inst().Mutate.DeleteFeatureFlag(ctx, actor, id); err != nil {
+		return false, err
+	}
+
+	r.refreshFeatureFlags(ctx)
+
+	return true, nil
+}
+
+// refreshFeatureFlags applies a change to the flags on this pod right away, other pods pick it up on their next refresh
+func (r *Resolver) refreshFeatureFlags(ctx context.Context) {
+	if err := r.Ctx.Inst().Flags.Refresh(ctx); err != nil {
+		zap.S().Warnw("failed to refresh feature flags", "error", err)
+	}
+}
@@ -66,7 +66,7 @@ func (r *Resolver) ReadMessages(ctx context.Context, messageIds []primitive.Obje
 	return updated, nil
 }
 
-func (r *Resolver) SendInboxMessage(ctx context.Context, recipientsArg []primitive.ObjectID, subject string, content string, importantArg *bool, anonArg *bool) (*model.InboxMessage, error) {
+func (r *Resolver) SendInboxMessage(ctx context.Context, recipientsArg []primitive.ObjectID, subject string, content string, importantArg *bool, anonArg *bool, placeholders map[string]string) (*model.InboxMessage, error) {
 	actor := auth.For(ctx)
 	if actor.ID.IsZero() {
 		return nil, errors.ErrUnauthorized()
@@ -121,14 +121,17 @@ func (r *Resolver) SendInboxMessage(ctx context.Context, recipientsArg []primiti
 		SetTimestamp(time.Now()).
 		SetAnonymous(anonymous).
 		SetData(structures.MessageDataInbox{
-			Subject:   subject,
-			Content:   content,
-			Important: important,
+			Subject:      subject,
+			Content:      content,
+			Important:    important,
+			Locale:       r.Ctx.Inst().Templates.Has(subject) || r.Ctx.Inst().Templates.Has(content),
+			Placeholders: placeholders,
 		})
 	if err := r.Ctx.Inst().Mutate.SendInboxMessage(ctx, mb, mutate.SendInboxMessageOptions{
 		Actor:                &actor,
 		Recipients:           recipientsArg,
 		ConsiderBlockedUsers: !actor.HasPermission(structures.RolePermissionBypassPrivacy),
+		Category:             query.NotificationCategoryDirect,
 	}); err != nil {
 		return nil, err
 	}
@@ -151,87 +154,5 @@ func (r *Resolver) SendInboxMessage(ctx context.Context, recipientsArg []primiti
 }
 
 func (r *Resolver) DismissVoidTargetModRequests(ctx context.Context, objectKind int) (int, error) {
-	targetKind := structures.ObjectKind(objectKind)
-
-	cur, err := r.Ctx.Inst().Mongo.Collection(mongo.CollectionNameMessages).Aggregate(ctx, mongo.Pipeline{
-		{{
-			Key: "$match",
-			Value: bson.M{
-				"kind":             structures.MessageKindModRequest,
-				"data.target_kind": targetKind,
-			},
-		}},
-		{{
-			Key: "$lookup",
-			Value: mongo.Lookup{
-				From: map[structures.ObjectKind]mongo.CollectionName{
-					structures.ObjectKindUser:  mongo.CollectionNameUsers,
-					structures.ObjectKindEmote: mongo.CollectionNameEmotes,
-				}[targetKind],
-				LocalField: "data.target_id",
-				ForeignField: map[structures.ObjectKind]string{
-					structures.ObjectKindUser:  "_id",
-					structures.ObjectKindEmote: "versions.id",
-				}[targetKind],
-				As: "_target",
-			},
-		}},
-		{{
-			Key: "$set",
-			Value: bson.M{
-				"data.target": bson.M{"$arrayElemAt": bson.A{"$_target", 0}},
-			},
-		}},
-		{{Key: "$unset", Value: bson.A{"_target"}}},
-	})
-	if err != nil {
-		r.Z().Errorw("failed to fetch mod requests", "error", err)
-
-		return 0, errors.ErrInternalServerError().SetDetail(err.Error())
-	}
-
-	reqs := []structures.Message[structures.MessageDataModRequest]{}
-
-	if err := cur.All(ctx, &reqs); err != nil {
-		r.Z().Errorw("failed to fetch mod requests", "error", err)
-
-		return 0, errors.ErrInternalServerError().SetDetail(err.Error())
-	}
-
-	w := []mongo.WriteModel{}
-
-	switch targetKind {
-	case structures.ObjectKindEmote:
-		for _, req := range reqs {
-			emote := structures.Emote{}
-
-			if err := bson.Unmarshal(req.Data.Target, &emote); err != nil {
-				continue
-			}
-
-			ver, _ := emote.GetVersion(req.Data.TargetID)
-			if !ver.ID.IsZero() && !ver.IsUnavailable() {
-				continue
-			}
-
-			// Only close requests attached to an emote in an unavailable state
-			w = append(w, &mongo.UpdateOneModel{
-				Filter: bson.M{"message_id": req.ID},
-				Update: bson.M{
-					"$set": bson.M{"read": true, "_manual_dismiss": 1},
-				},
-			})
-		}
-	}
-
-	r.Z().Infow("dismissing mod requests", "count", len(w))
-
-	res, err := r.Ctx.Inst().Mongo.Collection(mongo.CollectionNameMessagesRead).BulkWrite(ctx, w)
-	if err != nil {
-		r.Z().Errorw("failed to dismiss mod requests", "error", err)
-
-		return 0, errors.ErrInternalServerError().SetDetail(err.Error())
-	}
-
-	return int(res.ModifiedCount), nil
+	return r.Ctx.Inst().Mutate.DismissVoidTargetModRequests(ctx, structures.ObjectKind(objectKind))
 }
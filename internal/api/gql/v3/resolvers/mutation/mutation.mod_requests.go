@@ -0,0 +1,92 @@
+package mutation
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/common/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ClaimModRequest implements generated.MutationResolver
+func (r *Resolver) ClaimModRequest(ctx context.Context, id primitive.ObjectID) (*model.ModRequestClaim, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	claim, err := r.Ctx.Inst().Mutate.ClaimModRequest(ctx, actor, id, r.modRequestClaimTimeout())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ModRequestClaim{
+		MessageID:   claim.MessageID,
+		ModeratorID: claim.ModeratorID,
+		ClaimedAt:   claim.ClaimedAt,
+		ExpiresAt:   claim.ExpireAt,
+	}, nil
+}
+
+// ReleaseModRequest implements generated.MutationResolver
+func (r *Resolver) ReleaseModRequest(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false, errors.ErrUnauthorized()
+	}
+
+	if err := r.Ctx.Inst().Mutate.ReleaseModRequest(ctx, actor, id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DecideModRequest implements generated.MutationResolver
+func (r *Resolver) DecideModRequest(ctx context.Context, id primitive.ObjectID, decision model.ModRequestDecisionKind, reasonID string, note *string) (*model.ModRequestDecision, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	opt := mutate.ModRequestDecisionOptions{
+		Actor:     actor,
+		MessageID: id,
+		Kind:      query.ModRequestDecisionKind(decision),
+		ReasonID:  reasonID,
+	}
+
+	if note != nil {
+		opt.Note = *note
+	}
+
+	d, err := r.Ctx.Inst().Mutate.DecideModRequest(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ModRequestDecision{
+		ID:          d.ID,
+		MessageID:   d.MessageID,
+		TargetKind:  int(d.TargetKind),
+		TargetID:    d.TargetID,
+		Wish:        d.Wish,
+		Kind:        model.ModRequestDecisionKind(d.Kind),
+		ReasonID:    d.ReasonID,
+		Note:        d.Note,
+		ModeratorID: d.ModeratorID,
+		DecidedAt:   d.DecidedAt,
+	}, nil
+}
+
+func (r *Resolver) modRequestClaimTimeout() time.Duration {
+	if sec := r.Ctx.Config().ModRequests.ClaimTimeout; sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+
+	return 10 * time.Minute
+}
@@ -0,0 +1,63 @@
+package mutation
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CreateNews implements generated.MutationResolver
+func (r *Resolver) CreateNews(ctx context.Context, data model.NewsPostInput) (*model.NewsPost, error) {
+	actor := auth.For(ctx)
+
+	post, err := r.Ctx.Inst().Mutate.CreateNewsPost(ctx, mutate.NewsPostOptions{
+		Actor: actor,
+		Data:  helpers.NewsPostInputToData(data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.NewsPostToModel(post), nil
+}
+
+// EditNews implements generated.MutationResolver
+func (r *Resolver) EditNews(ctx context.Context, id primitive.ObjectID, data model.NewsPostInput) (*model.NewsPost, error) {
+	actor := auth.For(ctx)
+
+	post, err := r.Ctx.Inst().Mutate.EditNewsPost(ctx, id, mutate.NewsPostOptions{
+		Actor: actor,
+		Data:  helpers.NewsPostInputToData(data),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.NewsPostToModel(post), nil
+}
+
+// DeleteNews implements generated.MutationResolver
+func (r *Resolver) DeleteNews(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	actor := auth.For(ctx)
+
+	if err := r.Ctx.Inst().Mutate.DeleteNewsPost(ctx, actor, id); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DismissNews implements generated.MutationResolver
+func (r *Resolver) DismissNews(ctx context.Context, id primitive.ObjectID, dismissed *bool) (bool, error) {
+	actor := auth.For(ctx)
+
+	if err := r.Ctx.Inst().Mutate.DismissNewsPost(ctx, actor, id, dismissed == nil || *dismissed); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/gql/v3/auth"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
 	"github.com/seventv/api/internal/api/gql/v3/helpers"
@@ -205,6 +206,7 @@ func (r *Resolver) EditReport(ctx context.Context, reportID primitive.ObjectID,
 				Actor:                &actor,
 				Recipients:           []primitive.ObjectID{report.ActorID},
 				ConsiderBlockedUsers: false,
+				Category:             query.NotificationCategoryReports,
 			})
 		} else {
 			rb.SetClosedAt(time.Time{})
@@ -0,0 +1,145 @@
+package mutation
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+)
+
+func (r *Resolver) EnrollSecondFactor(ctx context.Context) (*model.SecondFactorEnrollment, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	secret, err := r.Ctx.Inst().Auth.CreateTOTPSecret()
+	if err != nil {
+		return nil, errors.ErrInternalServerError().SetDetail(err.Error())
+	}
+
+	if err := r.Ctx.Inst().Mutate.CreateSecondFactor(ctx, actor, secret); err != nil {
+		return nil, err
+	}
+
+	issuer := r.Ctx.Config().SecondFactor.Issuer
+	if issuer == "" {
+		issuer = "7TV"
+	}
+
+	return &model.SecondFactorEnrollment{
+		Secret: secret,
+		URI:    r.Ctx.Inst().Auth.TOTPURI(issuer, actor.Username, secret),
+	}, nil
+}
+
+func (r *Resolver) ConfirmSecondFactor(ctx context.Context, code string) (bool, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false, errors.ErrUnauthorized()
+	}
+
+	sf, err := r.Ctx.Inst().Query.SecondFactor(ctx, actor.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if sf.Enabled {
+		return false, errors.ErrDontBeSilly().SetDetail("Second factor is already enabled")
+	}
+
+	if err := r.checkSecondFactorCode(ctx, actor, sf.Secret, code); err != nil {
+		return false, err
+	}
+
+	if err := r.Ctx.Inst().Mutate.EnableSecondFactor(ctx, actor); err != nil {
+		return false, err
+	}
+
+	// A successful confirmation also counts as a check
+	if err := r.Ctx.Inst().Mutate.SetSecondFactorVerified(ctx, actor, r.secondFactorMaxAge()); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Resolver) VerifySecondFactor(ctx context.Context, code string) (*time.Time, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return nil, errors.ErrUnauthorized()
+	}
+
+	sf, err := r.Ctx.Inst().Query.SecondFactor(ctx, actor.ID)
+	if err != nil || !sf.Enabled {
+		return nil, errors.ErrDontBeSilly().SetDetail("Second factor is not enabled")
+	}
+
+	if err := r.checkSecondFactorCode(ctx, actor, sf.Secret, code); err != nil {
+		return nil, err
+	}
+
+	ttl := r.secondFactorMaxAge()
+	if err := r.Ctx.Inst().Mutate.SetSecondFactorVerified(ctx, actor, ttl); err != nil {
+		return nil, err
+	}
+
+	expireAt := time.Now().Add(ttl)
+
+	return &expireAt, nil
+}
+
+func (r *Resolver) DisableSecondFactor(ctx context.Context, code string) (bool, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false, errors.ErrUnauthorized()
+	}
+
+	sf, err := r.Ctx.Inst().Query.SecondFactor(ctx, actor.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := r.checkSecondFactorCode(ctx, actor, sf.Secret, code); err != nil {
+		return false, err
+	}
+
+	if err := r.Ctx.Inst().Mutate.DeleteSecondFactor(ctx, actor); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// checkSecondFactorCode validates a code entered by the actor. Users who enter too many invalid codes
+// are locked out for a while, and a code cannot be used twice
+func (r *Resolver) checkSecondFactorCode(ctx context.Context, actor structures.User, secret string, code string) error {
+	locked, err := r.Ctx.Inst().Query.SecondFactorLocked(ctx, actor.ID)
+	if err != nil {
+		return err
+	}
+
+	if locked {
+		return errors.ErrRateLimited().SetDetail("Too many invalid codes, try again later")
+	}
+
+	step, ok := r.Ctx.Inst().Auth.ValidateTOTP(secret, code)
+	if !ok {
+		r.Ctx.Inst().Mutate.RecordSecondFactorFailure(ctx, actor)
+
+		return errors.ErrInvalidRequest().SetDetail("Invalid code")
+	}
+
+	return r.Ctx.Inst().Mutate.ConsumeSecondFactorCode(ctx, actor, step)
+}
+
+func (r *Resolver) secondFactorMaxAge() time.Duration {
+	if age := r.Ctx.Config().SecondFactor.MaxAge; age > 0 {
+		return time.Duration(age) * time.Second
+	}
+
+	return 15 * time.Minute
+}
@@ -0,0 +1,40 @@
+package mutation
+
+import (
+	"context"
+
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/middleware"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Resolver) RevokeSession(ctx context.Context, id primitive.ObjectID) (bool, error) {
+	actor := auth.For(ctx)
+	if actor.ID.IsZero() {
+		return false, errors.ErrUnauthorized()
+	}
+
+	session, err := r.Ctx.Inst().Query.Session(ctx, id)
+	if err != nil {
+		return false, err
+	}
+
+	// Only the owner of the session or a privileged user may revoke it
+	if session.UserID != actor.ID {
+		if !actor.HasPermission(structures.RolePermissionManageUsers) {
+			return false, errors.ErrInsufficientPrivilege()
+		}
+
+		if err := middleware.RequireSecondFactor(r.Ctx, ctx, actor, structures.RolePermissionManageUsers); err != nil {
+			return false, err
+		}
+	}
+
+	if err := r.Ctx.Inst().Mutate.RevokeSession(ctx, actor, session); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
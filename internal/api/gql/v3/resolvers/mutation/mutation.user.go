@@ -9,7 +9,7 @@ import (
 )
 
 func (r *Resolver) User(ctx context.Context, id primitive.ObjectID) (*model.UserOps, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(id)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,74 @@
+package query
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditLogs implements generated.QueryResolver
+func (r *Resolver) AuditLogs(ctx context.Context, filter *model.AuditLogFilter, after *primitive.ObjectID, limitArg *int) (*model.AuditLogList, error) {
+	limit := 50
+	if limitArg != nil {
+		limit = *limitArg
+
+		if limit > 300 {
+			return nil, errors.ErrInvalidRequest().SetDetail("limit must be at most 300")
+		} else if limit < 1 {
+			return nil, errors.ErrInvalidRequest().SetDetail("limit must be greater than 0")
+		}
+	}
+
+	cursor := primitive.NilObjectID
+	if after != nil {
+		cursor = *after
+	}
+
+	res, err := r.Ctx.Inst().Query.AuditLogs(ctx, helpers.AuditLogFilterToData(filter), cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.AuditLogList{
+		Items:   make([]*model.AuditLog, len(res.Logs)),
+		HasMore: res.HasMore,
+	}
+
+	if !res.Cursor.IsZero() {
+		result.Cursor = &res.Cursor
+	}
+
+	// Fetch the actors
+	actorIDs := make(utils.Set[primitive.ObjectID])
+	for _, l := range res.Logs {
+		actorIDs.Add(l.ActorID)
+	}
+
+	actorMap := make(map[primitive.ObjectID]structures.User)
+
+	actors, _ := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, actorIDs.Values())
+	for _, u := range actors {
+		actorMap[u.ID] = u
+	}
+
+	for i, l := range res.Logs {
+		a := helpers.AuditLogStructureToModel(l)
+
+		actor, ok := actorMap[l.ActorID]
+		if !ok || actor.ID.IsZero() {
+			actor = structures.DeletedUser
+		}
+
+		a.Actor = modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(actor).ToPartial())
+
+		result.Items[i] = a
+	}
+
+	return result, nil
+}
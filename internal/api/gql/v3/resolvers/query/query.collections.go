@@ -0,0 +1,27 @@
+package query
+
+import (
+	"context"
+
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/common/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Collection implements generated.QueryResolver
+func (r *Resolver) Collection(ctx context.Context, id primitive.ObjectID) (*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+
+	col, err := r.Ctx.Inst().Query.EmoteCollection(ctx, &actor, id)
+	if err != nil {
+		if errors.Compare(err, errors.ErrNoItems()) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return helpers.EmoteCollectionToModel(col), nil
+}
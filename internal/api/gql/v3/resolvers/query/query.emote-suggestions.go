@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/svc/limiter"
+	"github.com/seventv/common/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Resolver) EmoteSuggestions(ctx context.Context, queryValue string, limitArg *int, activeOnly *bool) ([]*model.EmotePartial, error) {
+	// Suggestions are requested on every keystroke, so the limit is generous
+	if ok := r.Ctx.Inst().Limiter.Test(ctx, "suggest-emotes", 30, time.Second*5, limiter.TestOptions{
+		Incr: 1,
+	}); !ok {
+		return nil, errors.ErrRateLimited()
+	}
+
+	actor := auth.For(ctx)
+
+	limit := 10
+	if limitArg != nil {
+		limit = *limitArg
+	}
+
+	if limit > query.EMOTE_SUGGESTIONS_LIMIT {
+		return nil, errors.ErrInvalidRequest().SetDetail("limit must be at most %d", query.EMOTE_SUGGESTIONS_LIMIT)
+	}
+
+	suggestions, err := r.Ctx.Inst().Query.SuggestEmotes(ctx, query.SuggestEmotesOptions{
+		Query:          queryValue,
+		Limit:          limit,
+		Actor:          &actor,
+		ActiveSetsOnly: activeOnly != nil && *activeOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(suggestions))
+
+	for _, sg := range suggestions {
+		if id, err := primitive.ObjectIDFromHex(sg.Id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, ids)
+
+	result := make([]*model.EmotePartial, 0, len(emotes))
+
+	for _, emote := range emotes {
+		if emote.ID.IsZero() {
+			continue
+		}
+
+		result = append(result, modelgql.EmotePartialModel(r.Ctx.Inst().Modelizer.Emote(emote).ToPartial()))
+	}
+
+	return result, nil
+}
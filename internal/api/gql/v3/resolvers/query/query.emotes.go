@@ -2,7 +2,6 @@ package query
 
 import (
 	"context"
-	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,18 +18,23 @@ import (
 	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/gql/v3/auth"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/api/internal/search"
 	"github.com/seventv/api/internal/svc/limiter"
 )
 
 const EMOTES_QUERY_LIMIT = 300
 
+// TRENDING_EMOTES_LIMIT is how many of the most used emotes are listed by the TRENDING_DAY search category
+const TRENDING_EMOTES_LIMIT = 250
+
 var sortFieldMap = map[string]string{
 	"age":        "created_at",
 	"popularity": "channel_count",
 }
 
 func (r *Resolver) Emote(ctx context.Context, id primitive.ObjectID) (*model.Emote, error) {
-	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(id)
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +47,7 @@ func (r *Resolver) Emote(ctx context.Context, id primitive.ObjectID) (*model.Emo
 }
 
 func (r *Resolver) EmotesByID(ctx context.Context, list []primitive.ObjectID) ([]*model.EmotePartial, error) {
-	emotes, errs := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(list)
+	emotes, errs := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, list)
 	if err := multierror.Append(nil, errs...).ErrorOrNil(); err != nil {
 		r.Z().Errorw("failed to load emotes", "error", err)
 
@@ -68,6 +72,7 @@ func (r *Resolver) Emotes(ctx context.Context, queryValue string, pageArg *int,
 	}
 
 	actor := auth.For(ctx)
+	cfg := r.Ctx.Config()
 
 	// Define limit (how many emotes can be returned in a single query)
 	limit := 20
@@ -103,33 +108,15 @@ func (r *Resolver) Emotes(ctx context.Context, queryValue string, pageArg *int,
 		page = 1
 	}
 
-	if page > r.Ctx.Config().Limits.MaxPage && !actor.HasPermission(structures.RolePermissionEditAnyEmote) {
-		page = r.Ctx.Config().Limits.MaxPage
-	}
-
-	// Retrieve sorting options
-	sortopt := &model.Sort{
-		Value: "popularity",
-		Order: model.SortOrderDescending,
-	}
-	if sortArg != nil {
-		sortopt = sortArg
-	}
-
-	// Define sorting
-	// (will be ignored in the case of exact search)
-	order, validOrder := sortOrderMap[string(sortopt.Order)]
-	field, validField := sortFieldMap[sortopt.Value]
-	sortMap := bson.M{}
-
-	if validField && validOrder {
-		sortMap = bson.M{field: order}
+	if page > cfg.Limits.MaxPage && !actor.HasPermission(structures.RolePermissionEditAnyEmote) {
+		page = cfg.Limits.MaxPage
 	}
 
 	// Run query
 	var (
 		result     []structures.Emote
 		totalCount int
+		facets     *search.EmoteFacets
 		err        error
 	)
 
@@ -138,134 +125,137 @@ func (r *Resolver) Emotes(ctx context.Context, queryValue string, pageArg *int,
 		cat = *filter.Category
 	}
 
-	switch cat {
-	case model.EmoteSearchCategoryTrendingDay:
-		ids, useMap, err2 := r.emoteCategoryTrending(ctx, trendingCategoryOptions{
-			Days: map[model.EmoteSearchCategory]uint32{
-				model.EmoteSearchCategoryTrendingDay: 1,
-			}[cat],
-			UserMinAge:    7,
-			EmoteMaxAge:   365,
-			UsageThresold: 10,
-			Limit:         250,
-		})
-		if err2 != nil {
-			return nil, err
-		}
+	sortMap := emoteSearchSort(sortArg, cat)
+
+	searchFilter := &query.SearchEmotesFilter{
+		CaseSensitive: filter.CaseSensitive,
+		ExactMatch:    filter.ExactMatch,
+		IgnoreTags:    filter.IgnoreTags,
+		Animated:      filter.Animated,
+		ZeroWidth:     filter.ZeroWidth,
+		Authentic:     filter.Authentic,
+		PersonalUse:   filter.PersonalUse,
+		Tags:          filter.Tags,
+		MatchAnyTag:   filter.TagMode != nil && *filter.TagMode == model.EmoteSearchTagModeAny,
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+	}
 
-		sort.Slice(ids, func(i, j int) bool {
-			return useMap[ids[i]] > useMap[ids[j]]
-		})
+	if len(filter.Tags) > 10 {
+		return nil, errors.ErrInvalidRequest().SetDetail("Too many tags")
+	}
 
-		totalCount = len(ids)
+	if filter.OwnerID != nil {
+		searchFilter.OwnerID = *filter.OwnerID
+	}
 
-		// shrink the fetch list if needed
-		if page > 1 {
-			min := math.Min(float64(len(ids)), float64((page-1)*limit))
-			ids = ids[int(min):]
+	// Aspect ratio filter, formatted as "width:height" or "width:height:tolerance"
+	if filter.AspectRatio != nil && *filter.AspectRatio != "" {
+		sp := strings.Split(*filter.AspectRatio, ":")
+		if len(sp) < 2 {
+			return nil, errors.ErrInvalidRequest().SetDetail("Invalid format for aspect ratio")
 		}
 
-		if len(ids) > limit+1 {
-			ids = ids[:limit]
-		}
+		// Parse the aspect ratio
+		r1, er1 := strconv.ParseFloat(sp[0], 32)
+		r2, er2 := strconv.ParseFloat(sp[1], 32)
 
-		emotes, errs := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ids)
-		if err := multierror.Append(nil, errs...).ErrorOrNil(); err != nil {
-			return nil, errors.ErrNoItems()
+		if er1 != nil || er2 != nil || r1 <= 0 || r2 <= 0 {
+			return nil, errors.ErrInvalidRequest().SetDetail("Invalid format for aspect ratio (could not parse int)")
 		}
 
-		result = emotes
-	default:
-		extraDoc := bson.M{}
+		// Parse tolerance
+		var tolerance uint8
 
-		// Flag fitlers
-		flags := structures.BitField[structures.EmoteFlag](0)
+		if len(sp) >= 3 {
+			t, err := strconv.ParseUint(sp[2], 10, 8)
+			if err != nil || t > 100 {
+				return nil, errors.ErrInvalidRequest().SetDetail("Invalid format for aspect ratio (bad tolerance value)")
+			}
 
-		if filter.ZeroWidth != nil && *filter.ZeroWidth {
-			flags = flags.Set(structures.EmoteFlagsZeroWidth)
+			tolerance = uint8(t)
 		}
 
-		if filter.Authentic != nil && *filter.Authentic {
-			flags = flags.Set(structures.EmoteFlagsAuthentic)
+		searchFilter.AspectRatio = &search.EmoteAspectRatioFilter{
+			Ratio:     r1 / r2,
+			Tolerance: float64(tolerance),
 		}
+	}
 
-		if flags.Value() != 0 {
-			extraDoc["flags"] = flags
-		}
+	// Trending emotes are searched among the most used emotes, and sorted by their usage
+	trending := cat == model.EmoteSearchCategoryTrendingDay
+	usage := map[primitive.ObjectID]uint64{}
 
-		// Aspect ratio filter
-		if filter.AspectRatio != nil && *filter.AspectRatio != "" {
-			sp := strings.Split(*filter.AspectRatio, ":")
-			if len(sp) < 2 {
-				return nil, errors.ErrInvalidRequest().SetDetail("Invalid format for aspect ratio")
-			}
+	switch cat {
+	case model.EmoteSearchCategoryTrendingDay:
+		if searchFilter.IDs, usage, err = r.emoteCategoryTrending(ctx, trendingCategoryOptions{
+			Days:          1,
+			UserMinAge:    7,
+			EmoteMaxAge:   365,
+			UsageThresold: 10,
+			Limit:         TRENDING_EMOTES_LIMIT,
+		}); err != nil {
+			return nil, err
+		}
 
-			// Parse the aspect ratio
-			r1, er1 := strconv.ParseFloat(sp[0], 32)
-			r2, er2 := strconv.ParseFloat(sp[1], 32)
+		if len(searchFilter.IDs) == 0 {
+			return &model.EmoteSearchResult{
+				MaxPage: cfg.Limits.MaxPage,
+				Items:   []*model.Emote{},
+			}, nil
+		}
+	case model.EmoteSearchCategoryGlobal:
+		// Only emotes in the global set
+		sys, err := r.Ctx.Inst().Mongo.System(ctx)
+		if err != nil {
+			return nil, errors.ErrInternalServerError()
+		}
 
-			// Parse tolerance
-			var tolerance uint8
+		if searchFilter.IDs, err = r.emoteSetEmoteIDs(ctx, sys.EmoteSetID); err != nil {
+			return nil, err
+		}
+	case model.EmoteSearchCategoryFeatured:
+		// Only emotes in the featured set
+		setID, _ := primitive.ObjectIDFromHex(cfg.Limits.Emotes.FeaturedSetID)
+		if setID.IsZero() {
+			return &model.EmoteSearchResult{
+				MaxPage: cfg.Limits.MaxPage,
+				Items:   []*model.Emote{},
+			}, nil
+		}
 
-			if len(sp) >= 3 {
-				t, err := strconv.ParseUint(sp[2], 10, 8)
-				if err != nil || t > 100 {
-					return nil, errors.ErrInvalidRequest().SetDetail("Invalid format for aspect ratio (bad tolerance value)")
-				}
+		if searchFilter.IDs, err = r.emoteSetEmoteIDs(ctx, setID); err != nil {
+			return nil, err
+		}
+	}
 
-				tolerance = uint8(t)
-			}
+	// Facets are only computed when requested
+	_, wantFacets := helpers.GetFields(ctx)["facets"]
 
-			if er1 != nil || er2 != nil {
-				return nil, errors.ErrInvalidRequest().SetDetail("Invalid format for aspect ratio (could not parse int)")
-			}
+	var res query.SearchEmotesResult
 
-			// Calculate the width / height values
-			wMin := math.Floor(float64(128) * (r1))
-			w := bson.M{
-				"$gte": wMin * (1 - float64(tolerance)/100),
-				"$lte": wMin * ((float64(tolerance) / 100) + 1),
-			}
+	opt := query.SearchEmotesOptions{
+		Actor:  &actor,
+		Query:  queryValue,
+		Page:   page,
+		Limit:  limit,
+		Sort:   sortMap,
+		Filter: searchFilter,
+		Facets: wantFacets,
+	}
 
-			hMin := math.Floor(float64(128) * (r2))
-			h := bson.M{
-				"$gte": hMin * (1 - float64(tolerance)/100),
-				"$lte": hMin * ((float64(tolerance) / 100) + 1),
-			}
+	// All the trending emotes matching the filter are searched at once, and paged once sorted by usage
+	if trending {
+		opt.Page = 1
+		opt.Limit = TRENDING_EMOTES_LIMIT
+	}
 
-			extraDoc["versions.image_files"] = bson.M{
-				"$elemMatch": bson.M{
-					"name":         "4x",
-					"content_type": "image/webp",
-					"width":        w,
-					"height":       h,
-				},
-			}
-		}
+	res, err = r.Ctx.Inst().Query.SearchEmotes(ctx, opt)
 
-		// Animated
-		if filter.Animated != nil && *filter.Animated {
-			extraDoc["versions.animated"] = true
-		}
+	result, totalCount, facets = res.Items, res.TotalCount, res.Facets
 
-		// Personal Use
-		if filter.PersonalUse != nil {
-			extraDoc["versions.state.allow_personal"] = *filter.PersonalUse
-		}
-
-		result, totalCount, err = r.Ctx.Inst().Query.SearchEmotes(ctx, query.SearchEmotesOptions{
-			Actor: &actor,
-			Query: queryValue,
-			Page:  page,
-			Limit: limit,
-			Sort:  sortMap,
-			Filter: &query.SearchEmotesFilter{
-				CaseSensitive: filter.CaseSensitive,
-				ExactMatch:    filter.ExactMatch,
-				IgnoreTags:    filter.IgnoreTags,
-				Document:      extraDoc,
-			},
-		})
+	if trending && err == nil {
+		result = pageTrendingEmotes(result, usage, page, limit)
 	}
 
 	if err != nil {
@@ -287,7 +277,117 @@ func (r *Resolver) Emotes(ctx context.Context, queryValue string, pageArg *int,
 
 	return &model.EmoteSearchResult{
 		Count:   totalCount,
-		MaxPage: r.Ctx.Config().Limits.MaxPage,
+		MaxPage: cfg.Limits.MaxPage,
 		Items:   models,
+		Facets:  r.emoteSearchFacets(ctx, facets),
 	}, nil
 }
+
+// pageTrendingEmotes sorts emotes by their usage, most used first, and returns the requested page
+func pageTrendingEmotes(emotes []structures.Emote, usage map[primitive.ObjectID]uint64, page int, limit int) []structures.Emote {
+	// Emotes are used by the ID of one of their versions
+	uses := func(e structures.Emote) uint64 {
+		n := usage[e.ID]
+
+		for _, ver := range e.Versions {
+			if ver.ID != e.ID {
+				n += usage[ver.ID]
+			}
+		}
+
+		return n
+	}
+
+	sort.SliceStable(emotes, func(i, j int) bool {
+		return uses(emotes[i]) > uses(emotes[j])
+	})
+
+	start := (page - 1) * limit
+	if start >= len(emotes) {
+		return []structures.Emote{}
+	}
+
+	return emotes[start:utils.Ternary(start+limit < len(emotes), start+limit, len(emotes))]
+}
+
+// emoteSearchSort returns the sort of an emote search. Emotes of the NEW category are always sorted by age
+func emoteSearchSort(sortArg *model.Sort, cat model.EmoteSearchCategory) bson.M {
+	if cat == model.EmoteSearchCategoryNew {
+		return bson.M{"created_at": sortOrderMap[string(model.SortOrderDescending)]}
+	}
+
+	sortopt := &model.Sort{
+		Value: "popularity",
+		Order: model.SortOrderDescending,
+	}
+	if sortArg != nil {
+		sortopt = sortArg
+	}
+
+	order, validOrder := sortOrderMap[string(sortopt.Order)]
+	field, validField := sortFieldMap[sortopt.Value]
+
+	if !validField || !validOrder {
+		return bson.M{}
+	}
+
+	return bson.M{field: order}
+}
+
+// emoteSetEmoteIDs returns the IDs of the emotes in an emote set
+func (r *Resolver) emoteSetEmoteIDs(ctx context.Context, setID primitive.ObjectID) ([]primitive.ObjectID, error) {
+	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, setID)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map(set.Emotes, func(x structures.ActiveEmote) primitive.ObjectID {
+		return x.ID
+	}), nil
+}
+
+func (r *Resolver) emoteSearchFacets(ctx context.Context, facets *search.EmoteFacets) *model.EmoteSearchFacets {
+	if facets == nil {
+		return nil
+	}
+
+	result := &model.EmoteSearchFacets{
+		Tags:      make([]*model.EmoteSearchFacetCount, len(facets.Tags)),
+		Owners:    []*model.EmoteSearchOwnerFacetCount{},
+		Animated:  int(facets.Animated),
+		Static:    int(facets.Static),
+		ZeroWidth: int(facets.ZeroWidth),
+	}
+
+	for i, fc := range facets.Tags {
+		result.Tags[i] = &model.EmoteSearchFacetCount{
+			Value: fc.Value,
+			Count: int(fc.Count),
+		}
+	}
+
+	ownerIDs := make([]primitive.ObjectID, 0, len(facets.Owners))
+	ownerCounts := make([]int, 0, len(facets.Owners))
+
+	for _, fc := range facets.Owners {
+		if id, err := primitive.ObjectIDFromHex(fc.Value); err == nil {
+			ownerIDs = append(ownerIDs, id)
+			ownerCounts = append(ownerCounts, int(fc.Count))
+		}
+	}
+
+	users, _ := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, ownerIDs)
+
+	for i, u := range users {
+		if u.ID.IsZero() {
+			continue // deleted user
+		}
+
+		result.Owners = append(result.Owners, &model.EmoteSearchOwnerFacetCount{
+			User:  modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(u).ToPartial()),
+			Count: ownerCounts[i],
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func TestEmoteSearchSort(t *testing.T) {
+	sorts := []struct {
+		value string
+		field string
+	}{
+		{"popularity", "channel_count"},
+		{"age", "created_at"},
+	}
+
+	for _, cat := range model.AllEmoteSearchCategory {
+		for _, s := range sorts {
+			for _, order := range model.AllSortOrder {
+				name := string(cat) + " " + s.value + " " + string(order)
+
+				// The sort is passed on to the search, which must be able to read it
+				opt := query.EmoteSearchSort(emoteSearchSort(&model.Sort{Value: s.value, Order: order}, cat))
+
+				if cat == model.EmoteSearchCategoryNew {
+					testutil.Assert(t, "created_at", opt.By, name+": new emotes are sorted by age")
+					testutil.Assert(t, false, opt.Ascending, name+": new emotes come first")
+
+					continue
+				}
+
+				testutil.Assert(t, s.field, opt.By, name+": field")
+				testutil.Assert(t, order == model.SortOrderAscending, opt.Ascending, name+": order")
+			}
+		}
+
+		opt := query.EmoteSearchSort(emoteSearchSort(nil, cat))
+		if cat == model.EmoteSearchCategoryNew {
+			testutil.Assert(t, "created_at", opt.By, string(cat)+" default: field")
+		} else {
+			testutil.Assert(t, "channel_count", opt.By, string(cat)+" default: field")
+		}
+
+		testutil.Assert(t, false, opt.Ascending, string(cat)+" default: order")
+	}
+}
+
+func TestPageTrendingEmotes(t *testing.T) {
+	emotes := make([]structures.Emote, 5)
+	usage := map[primitive.ObjectID]uint64{}
+
+	for i := range emotes {
+		emotes[i] = structures.Emote{ID: primitive.NewObjectID()}
+		usage[emotes[i].ID] = uint64(i * 10)
+	}
+
+	// Usage may be counted on a version of the emote
+	ver := primitive.NewObjectID()
+	emotes[0].Versions = []structures.EmoteVersion{{ID: ver}}
+	usage[ver] = 100
+
+	first := pageTrendingEmotes(append([]structures.Emote{}, emotes...), usage, 1, 2)
+	testutil.Assert(t, 2, len(first), "the first page is full")
+	testutil.Assert(t, emotes[0].ID, first[0].ID, "the emote used the most through its version comes first")
+	testutil.Assert(t, emotes[4].ID, first[1].ID, "emotes are sorted by usage")
+
+	last := pageTrendingEmotes(append([]structures.Emote{}, emotes...), usage, 3, 2)
+	testutil.Assert(t, 1, len(last), "the last page holds the remaining emote")
+	testutil.Assert(t, emotes[1].ID, last[0].ID, "the emote used the least comes last")
+
+	testutil.Assert(t, 0, len(pageTrendingEmotes(emotes, usage, 4, 2)), "pages after the last are empty")
+}
@@ -5,14 +5,18 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func (r *Resolver) EmoteSet(ctx context.Context, id primitive.ObjectID) (*model.EmoteSet, error) {
-	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(id)
+	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -21,7 +25,7 @@ func (r *Resolver) EmoteSet(ctx context.Context, id primitive.ObjectID) (*model.
 }
 
 func (r *Resolver) EmoteSetsByID(ctx context.Context, ids []primitive.ObjectID) ([]*model.EmoteSet, error) {
-	sets, errs := r.Ctx.Inst().Loaders.EmoteSetByID().LoadAll(ids)
+	sets, errs := r.Ctx.Inst().Loaders.EmoteSetByID().LoadAll(ctx, ids)
 	if err := multierror.Append(nil, errs...).ErrorOrNil(); err != nil {
 		return nil, err
 	}
@@ -55,10 +59,84 @@ func (r *Resolver) NamedEmoteSet(ctx context.Context, name model.EmoteSetName) (
 		return nil, errors.ErrUnknownEmoteSet()
 	}
 
-	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(setID)
+	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, setID)
 	if err != nil {
 		return nil, err
 	}
 
 	return modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(set)), nil
 }
+
+func (r *Resolver) EmoteSetOriginPreview(ctx context.Context, id primitive.ObjectID, origins []*model.EmoteSetOriginInput, policyArg *model.EmoteSetOriginPolicyInput) (*model.EmoteSetOriginPreview, error) {
+	set, err := r.Ctx.Inst().Query.EmoteSets(ctx, bson.M{"_id": id}).First()
+	if err != nil {
+		if errors.Compare(err, errors.ErrNoItems()) {
+			return nil, errors.ErrUnknownEmoteSet()
+		}
+
+		return nil, err
+	}
+
+	if origins != nil {
+		set.Origins = utils.Map(origins, func(x *model.EmoteSetOriginInput) structures.EmoteSetOrigin {
+			s := make([]uint32, len(x.Slices))
+			for i, v := range x.Slices {
+				s[i] = uint32(v)
+			}
+
+			return structures.EmoteSetOrigin{
+				ID:     x.ID,
+				Weight: int32(x.Weight),
+				Slices: s,
+			}
+		})
+	}
+
+	var policy *query.EmoteSetOriginPolicy
+	if policyArg != nil {
+		policy = &query.EmoteSetOriginPolicy{
+			SetID: set.ID,
+			Mode:  query.EmoteSetOriginConflictMode(policyArg.Mode),
+		}
+
+		if policyArg.Prefix != nil {
+			policy.Prefix = *policyArg.Prefix
+		}
+
+		if policyArg.Suffix != nil {
+			policy.Suffix = *policyArg.Suffix
+		}
+	}
+
+	preview, err := r.Ctx.Inst().Query.PreviewEmoteSetOrigins(ctx, set, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.EmoteSetOriginPreview{
+		Emotes:    modelgql.EmoteSetModel(r.Ctx.Inst().Modelizer.EmoteSet(preview.Set)).Emotes,
+		Conflicts: make([]*model.EmoteSetOriginConflict, len(preview.Conflicts)),
+	}
+
+	for i, c := range preview.Conflicts {
+		mc := &model.EmoteSetOriginConflict{
+			Name:            c.Name,
+			EmoteID:         c.EmoteID,
+			OriginID:        c.OriginID,
+			ConflictEmoteID: c.ConflictEmoteID,
+			Resolution:      model.EmoteSetOriginConflictResolution(c.Resolution),
+		}
+
+		if !c.ConflictOriginID.IsZero() {
+			mc.ConflictOriginID = utils.PointerOf(c.ConflictOriginID)
+		}
+
+		if c.RenamedTo != "" {
+			mc.RenamedTo = utils.PointerOf(c.RenamedTo)
+		}
+
+		result.Conflicts[i] = mc
+	}
+
+	return result, nil
+}
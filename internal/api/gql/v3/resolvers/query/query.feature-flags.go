@@ -0,0 +1,23 @@
+package query
+
+import (
+	"context"
+
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+)
+
+// FeatureFlags implements generated.QueryResolver
+func (r *Resolver) FeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	flags, err := r.Ctx.Inst().Query.FeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.FeatureFlag, len(flags))
+	for i, f := range flags {
+		result[i] = helpers.FeatureFlagToModel(f)
+	}
+
+	return result, nil
+}
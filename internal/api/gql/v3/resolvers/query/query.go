@@ -64,7 +64,7 @@ func (r *Resolver) ProxiedEndpoint(ctx context.Context, id int, userID *primitiv
 		userID = &actor.ID
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(*userID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, *userID)
 	if err != nil {
 		return "", err
 	}
@@ -127,7 +127,7 @@ func (r *Resolver) Actor(ctx context.Context) (*model.User, error) {
 		return nil, nil
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(actor.ID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, actor.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -147,7 +147,7 @@ func (r *Resolver) User(ctx context.Context, id primitive.ObjectID) (*model.User
 		return nil, errors.ErrUnknownUser()
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(id)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"context"
+	"strings"
+
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/templates"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/utils"
+)
+
+// InboxTemplates implements generated.QueryResolver
+func (r *Resolver) InboxTemplates(ctx context.Context) ([]*model.InboxTemplate, error) {
+	reg := r.Ctx.Inst().Templates
+	result := []*model.InboxTemplate{}
+
+	// A template is made of a subject and a content key sharing the same prefix
+	for _, k := range reg.Keys("inbox.") {
+		if !strings.HasSuffix(k, ".subject") {
+			continue
+		}
+
+		key := strings.TrimSuffix(k, ".subject")
+		if !reg.Has(key + ".content") {
+			continue
+		}
+
+		result = append(result, &model.InboxTemplate{
+			Key:          key,
+			Placeholders: inboxTemplatePlaceholders(reg, key),
+			Locales:      reg.Locales(),
+		})
+	}
+
+	return result, nil
+}
+
+// InboxTemplatePreview implements generated.QueryResolver
+func (r *Resolver) InboxTemplatePreview(ctx context.Context, key string, locale *string, placeholders map[string]string) (*model.InboxTemplatePreview, error) {
+	reg := r.Ctx.Inst().Templates
+
+	if !reg.Has(key+".subject") || !reg.Has(key+".content") {
+		return nil, errors.ErrInvalidRequest().SetDetail("Unknown template")
+	}
+
+	loc := templates.DefaultLocale
+	if locale != nil {
+		loc = reg.Resolve(*locale)
+	}
+
+	missing := []string{}
+
+	for _, p := range inboxTemplatePlaceholders(reg, key) {
+		if _, ok := placeholders[p]; !ok {
+			missing = append(missing, p)
+		}
+	}
+
+	return &model.InboxTemplatePreview{
+		Key:                 key,
+		Locale:              loc,
+		Subject:             reg.Render(loc, key+".subject", placeholders),
+		Content:             reg.Render(loc, key+".content", placeholders),
+		MissingPlaceholders: missing,
+	}, nil
+}
+
+func inboxTemplatePlaceholders(reg *templates.Registry, key string) []string {
+	result := reg.Placeholders(key + ".subject")
+
+	for _, p := range reg.Placeholders(key + ".content") {
+		if !utils.Contains(result, p) {
+			result = append(result, p)
+		}
+	}
+
+	return result
+}
@@ -15,7 +15,7 @@ import (
 
 const INBOX_QUERY_LIMIT_MOST = 1000
 
-func (r *Resolver) Inbox(ctx context.Context, userID primitive.ObjectID, afterIDArg *primitive.ObjectID, limitArg *int) ([]*model.InboxMessage, error) {
+func (r *Resolver) Inbox(ctx context.Context, userID primitive.ObjectID, afterIDArg *primitive.ObjectID, limitArg *int, locale *string) ([]*model.InboxMessage, error) {
 	actor := auth.For(ctx)
 	if actor.ID.IsZero() {
 		return nil, errors.ErrUnauthorized()
@@ -57,6 +57,15 @@ func (r *Resolver) Inbox(ctx context.Context, userID primitive.ObjectID, afterID
 	for i, msg := range messages {
 		if msg, err := structures.ConvertMessage[structures.MessageDataInbox](msg); err == nil {
 			result[i] = modelgql.InboxMessageModel(r.Ctx.Inst().Modelizer.InboxMessage(msg))
+
+			// Render the message's templates in the requested language
+			if locale != nil {
+				subject := r.Ctx.Inst().Templates.Render(*locale, msg.Data.Subject, msg.Data.Placeholders)
+				content := r.Ctx.Inst().Templates.Render(*locale, msg.Data.Content, msg.Data.Placeholders)
+
+				result[i].RenderedSubject = &subject
+				result[i].RenderedContent = &content
+			}
 		}
 	}
 
@@ -0,0 +1,84 @@
+package query
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/data/model/modelgql"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ModRequestReasons implements generated.QueryResolver
+func (r *Resolver) ModRequestReasons(ctx context.Context) ([]*model.ModRequestReason, error) {
+	result := make([]*model.ModRequestReason, len(query.ModRequestReasons))
+
+	for i, reason := range query.ModRequestReasons {
+		wish := reason.Wish
+		if wish == nil {
+			wish = []string{}
+		}
+
+		result[i] = &model.ModRequestReason{
+			ID:           reason.ID,
+			Label:        reason.Label,
+			Kind:         model.ModRequestDecisionKind(reason.Kind),
+			Wish:         wish,
+			NoteRequired: reason.Note,
+		}
+	}
+
+	return result, nil
+}
+
+// ModRequestReviewerStats implements generated.QueryResolver
+func (r *Resolver) ModRequestReviewerStats(ctx context.Context, after *time.Time, moderatorID *primitive.ObjectID) ([]*model.ModRequestReviewerStats, error) {
+	opt := query.ModRequestReviewerStatsOptions{}
+
+	if after != nil {
+		opt.After = *after
+	}
+
+	if moderatorID != nil {
+		opt.ModeratorID = *moderatorID
+	}
+
+	stats, err := r.Ctx.Inst().Query.ModRequestReviewerStats(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, len(stats))
+	for i, st := range stats {
+		ids[i] = st.ModeratorID
+	}
+
+	users, _ := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, ids)
+
+	userMap := make(map[primitive.ObjectID]structures.User, len(users))
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+
+	result := make([]*model.ModRequestReviewerStats, len(stats))
+
+	for i, st := range stats {
+		u, ok := userMap[st.ModeratorID]
+		if !ok {
+			u = structures.DeletedUser
+		}
+
+		result[i] = &model.ModRequestReviewerStats{
+			Moderator:            modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(u).ToPartial()),
+			Approved:             st.Approved,
+			Denied:               st.Denied,
+			AverageReviewSeconds: st.AverageReviewTime,
+			AverageWaitSeconds:   st.AverageWaitTime,
+			LastDecisionAt:       st.LastDecisionAt,
+		}
+	}
+
+	return result, nil
+}
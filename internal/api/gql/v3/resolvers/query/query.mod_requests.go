@@ -8,6 +8,7 @@ import (
 	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/gql/v3/auth"
 	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/structures/v3"
 	"go.mongodb.org/mongo-driver/bson"
@@ -44,11 +45,16 @@ func (r *Resolver) ModRequests(ctx context.Context, afterIDArg *primitive.Object
 		limit = *limitArg
 	}
 
+	// Counting is costly, so the total is only counted when it was selected
+	_, wantTotal := helpers.GetFields(ctx)["total"]
+
 	msgQuery := r.Ctx.Inst().Query.ModRequestMessages(ctx, query.ModRequestMessagesQueryOptions{
-		Actor:  &actor,
-		Filter: match,
-		Limit:  limit,
-		Sort:   bson.D{{Key: "weight", Value: -1}, {Key: "_id", Value: 1}}, // bson.M{"_id": 1, "weight": -1},
+		Actor:       &actor,
+		Filter:      match,
+		Limit:       limit,
+		HideClaimed: true,
+		CountTotal:  wantTotal,
+		Sort:        bson.D{{Key: "weight", Value: -1}, {Key: "_id", Value: 1}}, // bson.M{"_id": 1, "weight": -1},
 		Targets: map[structures.ObjectKind]bool{
 			structures.ObjectKindEmote: true,
 		},
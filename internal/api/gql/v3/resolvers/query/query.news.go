@@ -0,0 +1,45 @@
+package query
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+)
+
+// News implements generated.QueryResolver
+func (r *Resolver) News(ctx context.Context, locale *string, includeDismissed *bool, includeInactive *bool, page *int, limit *int) ([]*model.NewsPost, error) {
+	actor := auth.For(ctx)
+
+	opt := query.NewsQueryOptions{
+		Actor:            &actor,
+		IncludeDismissed: includeDismissed != nil && *includeDismissed,
+		IncludeInactive:  includeInactive != nil && *includeInactive,
+	}
+
+	if locale != nil {
+		opt.Locale = *locale
+	}
+
+	if page != nil {
+		opt.Page = *page
+	}
+
+	if limit != nil {
+		opt.Limit = *limit
+	}
+
+	posts, err := r.Ctx.Inst().Query.News(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.NewsPost, len(posts))
+	for i, p := range posts {
+		result[i] = helpers.NewsPostToModel(p)
+	}
+
+	return result, nil
+}
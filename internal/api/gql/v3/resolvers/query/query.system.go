@@ -0,0 +1,15 @@
+package query
+
+import (
+	"context"
+
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+)
+
+// System implements generated.QueryResolver
+func (r *Resolver) System(ctx context.Context) (*model.System, error) {
+	return &model.System{
+		Status: helpers.SystemStatusToModel(r.Ctx.Inst().Status.Report()),
+	}, nil
+}
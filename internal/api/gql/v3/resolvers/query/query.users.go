@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"math"
 	"strings"
 	"time"
 
@@ -20,7 +21,7 @@ import (
 
 // EmotesByIDs implements generated.QueryResolver
 func (r *Resolver) UsersByID(ctx context.Context, list []primitive.ObjectID) ([]*model.UserPartial, error) {
-	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(list)
+	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, list)
 	if err := multierror.Append(nil, errs...).ErrorOrNil(); err != nil {
 		r.Z().Errorw("failed to load users", "error", err)
 
@@ -36,7 +37,25 @@ func (r *Resolver) UsersByID(ctx context.Context, list []primitive.ObjectID) ([]
 	return result, nil
 }
 
-func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, limitArg *int) ([]*model.UserPartial, error) {
+func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, limitArg *int, filter *model.UserSearchFilter, sortArg *model.Sort) ([]*model.UserPartial, error) {
+	result, err := r.searchUsers(ctx, queryArg, pageArg, limitArg, filter, sortArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Items, nil
+}
+
+func (r *Resolver) UserSearch(ctx context.Context, queryArg string, pageArg *int, limitArg *int, filter *model.UserSearchFilter, sortArg *model.Sort) (*model.UserSearchResult, error) {
+	return r.searchUsers(ctx, queryArg, pageArg, limitArg, filter, sortArg)
+}
+
+var userSortFieldMap = map[string]string{
+	"age":      "_id",
+	"username": "username",
+}
+
+func (r *Resolver) searchUsers(ctx context.Context, queryArg string, pageArg *int, limitArg *int, filter *model.UserSearchFilter, sortArg *model.Sort) (*model.UserSearchResult, error) {
 	// Rate limit
 	if ok := r.Ctx.Inst().Limiter.Test(ctx, "search-users", 10, time.Second*5, limiter.TestOptions{
 		Incr: 1,
@@ -47,15 +66,25 @@ func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, lim
 	actor := auth.For(ctx)
 
 	isManager := actor.HasPermission(structures.RolePermissionManageUsers)
+	canBypassPrivacy := isManager || actor.HasPermission(structures.RolePermissionBypassPrivacy)
 
 	// Temporary measure until search is optimized
-	if !isManager {
-		user, err := r.Ctx.Inst().Loaders.UserByUsername().Load(strings.ToLower((queryArg)))
+	if !isManager && filter == nil {
+		user, err := r.Ctx.Inst().Loaders.UserByUsername().Load(ctx, strings.ToLower((queryArg)))
 		if err != nil {
 			return nil, err
 		}
 
-		return []*model.UserPartial{modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(user).ToPartial())}, nil
+		return &model.UserSearchResult{
+			Total:   1,
+			MaxPage: 1,
+			Items:   []*model.UserPartial{modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(user).ToPartial())},
+		}, nil
+	}
+
+	searchFilter, err := r.userSearchFilter(filter, isManager, canBypassPrivacy)
+	if err != nil {
+		return nil, err
 	}
 
 	// Unprivileged users must provide a query
@@ -63,8 +92,10 @@ func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, lim
 		return nil, errors.ErrInvalidRequest().SetDetail("query must be at least 2 characters long")
 	}
 
+	page := 1
+
 	if pageArg != nil {
-		page := *pageArg
+		page = *pageArg
 
 		// Disallow unprivileged users from paginating
 		// This measure is to prevent scraping
@@ -73,6 +104,10 @@ func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, lim
 		}
 	}
 
+	if page < 1 {
+		page = 1
+	}
+
 	limit := 25
 	if limitArg != nil {
 		limit = *limitArg
@@ -81,19 +116,36 @@ func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, lim
 			return nil, errors.ErrInsufficientPrivilege().SetDetail("limit cannot be higher than 25")
 		} else if limit > 500 {
 			return nil, errors.ErrInsufficientPrivilege().SetDetail("limit cannot be higher than 500")
+		} else if limit < 1 {
+			return nil, errors.ErrInvalidRequest().SetDetail("limit cannot be less than 1")
+		}
+	}
+
+	var sort bson.D
+
+	if sortArg != nil {
+		order, validOrder := sortOrderMap[string(sortArg.Order)]
+		field, validField := userSortFieldMap[sortArg.Value]
+
+		if !validOrder || !validField {
+			return nil, errors.ErrInvalidRequest().SetDetail("Unknown sort value")
 		}
+
+		sort = bson.D{{Key: field, Value: order}}
 	}
 
-	searchResult, err := r.Ctx.Inst().Query.SearchUsers(ctx, bson.M{}, query.UserSearchOptions{
-		Limit: limit,
-		Query: queryArg,
-		Sort: map[string]interface{}{
-			"state.role_position":         -1,
-			"connections.data.view_count": -1,
-		},
+	searchResult, total, err := r.Ctx.Inst().Query.SearchUsers(ctx, bson.M{}, query.UserSearchOptions{
+		Limit:  limit,
+		Page:   page,
+		Query:  queryArg,
+		Sort:   sort,
+		Filter: searchFilter,
 	})
+	if err != nil {
+		return nil, errors.ErrInternalServerError()
+	}
 
-	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(utils.Map(searchResult, func(v structures.User) primitive.ObjectID {
+	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, utils.Map(searchResult, func(v structures.User) primitive.ObjectID {
 		return v.ID
 	}))
 	if err := multierror.Append(nil, errs...).ErrorOrNil(); err != nil {
@@ -105,11 +157,61 @@ func (r *Resolver) Users(ctx context.Context, queryArg string, pageArg *int, lim
 		result[i] = modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(u).ToPartial())
 	}
 
-	return result, err
+	maxPage := int(math.Ceil(float64(total) / float64(limit)))
+	if !isManager && maxPage > 1 {
+		maxPage = 1
+	}
+
+	return &model.UserSearchResult{
+		Total:   total,
+		MaxPage: maxPage,
+		Items:   result,
+	}, nil
+}
+
+// userSearchFilter checks the permissions required by a user search filter and converts it
+func (r *Resolver) userSearchFilter(filter *model.UserSearchFilter, isManager bool, canBypassPrivacy bool) (*query.UserSearchFilter, error) {
+	if filter == nil {
+		return nil, nil
+	}
+
+	if !canBypassPrivacy {
+		return nil, errors.ErrInsufficientPrivilege().SetDetail("You are not allowed to filter users")
+	}
+
+	if (filter.MinEmotes != nil || filter.MaxEmotes != nil) && !isManager {
+		return nil, errors.ErrInsufficientPrivilege().SetFields(errors.Fields{"MISSING_PERMISSION": "MANAGE_USERS"})
+	}
+
+	result := &query.UserSearchFilter{
+		CreatedAfter:  filter.CreatedAfter,
+		CreatedBefore: filter.CreatedBefore,
+		Banned:        filter.Banned,
+		MinEmotes:     filter.MinEmotes,
+		MaxEmotes:     filter.MaxEmotes,
+	}
+
+	if filter.ConnectionPlatform != nil {
+		result.ConnectionPlatform = structures.UserConnectionPlatform(*filter.ConnectionPlatform)
+	}
+
+	if filter.ConnectionID != nil {
+		if *filter.ConnectionID == "" || len(*filter.ConnectionID) > 100 {
+			return nil, errors.ErrInvalidRequest().SetDetail("connection_id must be between 1 and 100 characters long")
+		}
+
+		result.ConnectionIDPrefix = *filter.ConnectionID
+	}
+
+	if filter.RoleID != nil {
+		result.RoleID = *filter.RoleID
+	}
+
+	return result, nil
 }
 
 func (r *Resolver) UserByConnection(ctx context.Context, platform model.ConnectionPlatform, id string) (*model.User, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByConnectionID(structures.UserConnectionPlatform(platform)).Load(id)
+	user, err := r.Ctx.Inst().Loaders.UserByConnectionID(structures.UserConnectionPlatform(platform)).Load(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -17,7 +17,7 @@ type Resolver struct {
 
 // Actor implements generated.ReportResolver
 func (r *Resolver) Actor(ctx context.Context, obj *model.Report) (*model.User, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.ActorID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.ActorID)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +30,7 @@ func New(r types.Resolver) generated.ReportResolver {
 }
 
 func (r *Resolver) Reporter(ctx context.Context, obj *model.Report) (*model.User, error) {
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.Actor.ID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.Actor.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +44,7 @@ func (r *Resolver) Assignees(ctx context.Context, obj *model.Report) ([]*model.U
 		ids[i] = v.ID
 	}
 
-	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ids)
+	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, ids)
 
 	err := multierror.Append(nil, errs...).ErrorOrNil()
 	if err != nil {
@@ -5,6 +5,8 @@ import (
 	"github.com/seventv/api/internal/api/gql/v3/resolvers/ban"
 	"github.com/seventv/api/internal/api/gql/v3/resolvers/cosmetics"
 	"github.com/seventv/api/internal/api/gql/v3/resolvers/emote"
+	emote_collection "github.com/seventv/api/internal/api/gql/v3/resolvers/emote-collection"
+	emote_comment "github.com/seventv/api/internal/api/gql/v3/resolvers/emote-comment"
 	"github.com/seventv/api/internal/api/gql/v3/resolvers/emoteset"
 	activeemote "github.com/seventv/api/internal/api/gql/v3/resolvers/emoteset/active-emote"
 	imagehost "github.com/seventv/api/internal/api/gql/v3/resolvers/image-host"
@@ -44,6 +46,18 @@ func (r *Resolver) EmotePartial() generated.EmotePartialResolver {
 	return emote.NewPartial(r.Resolver)
 }
 
+func (r *Resolver) EmoteComment() generated.EmoteCommentResolver {
+	return emote_comment.New(r.Resolver)
+}
+
+func (r *Resolver) EmoteCollection() generated.EmoteCollectionResolver {
+	return emote_collection.New(r.Resolver)
+}
+
+func (r *Resolver) EmoteCollectionOps() generated.EmoteCollectionOpsResolver {
+	return emote_collection.NewOps(r.Resolver)
+}
+
 func (r *Resolver) CosmeticOps() generated.CosmeticOpsResolver {
 	return cosmetics.NewOps(r.Resolver)
 }
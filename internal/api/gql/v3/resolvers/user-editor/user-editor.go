@@ -23,7 +23,7 @@ func (r *Resolver) User(ctx context.Context, obj *model.UserEditor) (*model.User
 		return obj.User, nil
 	}
 
-	u, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.ID)
+	u, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.ID)
 	if err != nil {
 		return modelgql.UserPartialModel(r.Ctx.Inst().Modelizer.User(structures.DeletedUser).ToPartial()), nil
 	}
@@ -0,0 +1,30 @@
+package user
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+)
+
+// Collections implements generated.UserResolver
+func (r *Resolver) Collections(ctx context.Context, obj *model.User, followed *bool) ([]*model.EmoteCollection, error) {
+	actor := auth.For(ctx)
+
+	cols, err := r.Ctx.Inst().Query.UserEmoteCollections(ctx, obj.ID, query.UserEmoteCollectionsOptions{
+		Actor:    &actor,
+		Followed: followed != nil && *followed,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.EmoteCollection, len(cols))
+	for i, c := range cols {
+		result[i] = helpers.EmoteCollectionToModel(c)
+	}
+
+	return result, nil
+}
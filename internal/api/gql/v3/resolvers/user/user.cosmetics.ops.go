@@ -59,7 +59,7 @@ func (r *ResolverOps) Cosmetics(ctx context.Context, obj *model.UserOps, update
 		})
 
 		// Get user's current cosmetics
-		ents, err := r.Ctx.Inst().Loaders.EntitlementsLoader().Load(obj.ID)
+		ents, err := r.Ctx.Inst().Loaders.EntitlementsLoader().Load(ctx, obj.ID)
 		if err != nil {
 			r.Z().Errorw("failed to get user entitlements", "error", err)
 
@@ -137,7 +137,7 @@ func (r *ResolverOps) Cosmetics(ctx context.Context, obj *model.UserOps, update
 	}
 
 	if res.ModifiedCount > 0 {
-		r.Ctx.Inst().Events.Dispatch(events.EventTypeUpdateUser, events.ChangeMap{
+		r.Ctx.Inst().Events.Dispatch(ctx, events.EventTypeUpdateUser, events.ChangeMap{
 			ID:      obj.ID,
 			Kind:    structures.ObjectKindUser,
 			Actor:   r.Ctx.Inst().Modelizer.User(actor).ToPartial(),
@@ -25,13 +25,13 @@ func (r *ResolverOps) Editors(
 	}
 
 	// Find the user being edited
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(obj.ID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, obj.ID)
 	if err != nil {
 		return nil, errors.ErrUnknownUser().SetDetail("Target")
 	}
 
 	// Find the editor whose editor privileges are being updated
-	editor, err := r.Ctx.Inst().Loaders.UserByID().Load(editorID)
+	editor, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, editorID)
 	if err != nil {
 		return nil, errors.ErrUnknownUser().SetDetail("Editor")
 	}
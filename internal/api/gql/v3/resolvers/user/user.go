@@ -29,7 +29,7 @@ func New(r types.Resolver) generated.UserResolver {
 }
 
 func (r *Resolver) EmoteSets(ctx context.Context, obj *model.User, entitled *bool) ([]*model.EmoteSet, error) {
-	sets, err := r.Ctx.Inst().Loaders.EmoteSetByUserID().Load(obj.ID)
+	sets, err := r.Ctx.Inst().Loaders.EmoteSetByUserID().Load(ctx, obj.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -44,14 +44,14 @@ func (r *Resolver) EmoteSets(ctx context.Context, obj *model.User, entitled *boo
 			ownedSets.Add(set.ID)
 		}
 
-		res, err := r.Ctx.Inst().Loaders.EntitlementsLoader().Load(obj.ID)
+		res, err := r.Ctx.Inst().Loaders.EntitlementsLoader().Load(ctx, obj.ID)
 		if err == nil {
 			ids := make(utils.Set[primitive.ObjectID])
 			ids.Fill(utils.Map(res.EmoteSets, func(x structures.Entitlement[structures.EntitlementDataEmoteSet]) primitive.ObjectID {
 				return x.Data.RefID
 			})...)
 
-			entitledSets, _ := r.Ctx.Inst().Loaders.EmoteSetByID().LoadAll(ids.Values())
+			entitledSets, _ := r.Ctx.Inst().Loaders.EmoteSetByID().LoadAll(ctx, ids.Values())
 
 			for _, set := range entitledSets {
 				if set.OwnerID == obj.ID {
@@ -104,7 +104,7 @@ func (r *Resolver) Editors(ctx context.Context, obj *model.User) ([]*model.UserE
 		ids[i] = v.ID
 	}
 
-	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ids)
+	users, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, ids)
 	result := []*model.UserEditor{}
 
 	for i, e := range obj.Editors {
@@ -158,7 +158,7 @@ func (r *Resolver) OwnedEmotes(ctx context.Context, obj *model.User) ([]*model.E
 	result := []*model.Emote{}
 	errs := []error{}
 
-	emotes, err := r.Ctx.Inst().Loaders.EmoteByOwnerID().Load(obj.ID)
+	emotes, err := r.Ctx.Inst().Loaders.EmoteByOwnerID().Load(ctx, obj.ID)
 	if err != nil {
 		if errors.Compare(err, errors.ErrNoItems()) {
 			return result, nil
@@ -282,7 +282,7 @@ func (r *Resolver) Activity(ctx context.Context, obj *model.User, limitArg *int)
 		i++
 	}
 
-	actors, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(actorIDs)
+	actors, errs := r.Ctx.Inst().Loaders.UserByID().LoadAll(ctx, actorIDs)
 	if multierror.Append(nil, errs...).ErrorOrNil() != nil {
 		return result, errors.ErrInternalServerError()
 	}
@@ -300,7 +300,7 @@ func (r *Resolver) Activity(ctx context.Context, obj *model.User, limitArg *int)
 }
 
 func (r *Resolver) Style(ctx context.Context, obj *model.User) (*model.UserStyle, error) {
-	badge, paint := userEntitlements(r.Ctx, obj.ID)
+	badge, paint := userEntitlements(ctx, r.Ctx, obj.ID)
 
 	return &model.UserStyle{
 		Color:   obj.Style.Color,
@@ -311,8 +311,8 @@ func (r *Resolver) Style(ctx context.Context, obj *model.User) (*model.UserStyle
 	}, nil
 }
 
-func userEntitlements(gctx global.Context, userID primitive.ObjectID) (*model.CosmeticBadge, *model.CosmeticPaint) {
-	ents, _ := gctx.Inst().Loaders.EntitlementsLoader().Load(userID)
+func userEntitlements(ctx context.Context, gctx global.Context, userID primitive.ObjectID) (*model.CosmeticBadge, *model.CosmeticPaint) {
+	ents, _ := gctx.Inst().Loaders.EntitlementsLoader().Load(ctx, userID)
 
 	badge, _, _ := ents.ActiveBadge()
 	paint, _, _ := ents.ActivePaint()
@@ -0,0 +1,61 @@
+package user
+
+import (
+	"context"
+
+	"github.com/seventv/api/data/mutate"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+)
+
+// NotificationSettings implements generated.UserResolver. Only visible to the user themselves and privileged users
+func (r *Resolver) NotificationSettings(ctx context.Context, obj *model.User) ([]*model.NotificationSetting, error) {
+	actor := auth.For(ctx)
+	if actor.ID != obj.ID && !actor.HasPermission(structures.RolePermissionManageUsers) {
+		return nil, errors.ErrInsufficientPrivilege()
+	}
+
+	settings, err := r.Ctx.Inst().Query.NotificationSettings(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.NotificationSettingsToModel(settings), nil
+}
+
+// NotificationSettings implements generated.UserOpsResolver
+func (r *ResolverOps) NotificationSettings(ctx context.Context, obj *model.UserOps, settings []*model.NotificationSettingInput) ([]*model.NotificationSetting, error) {
+	actor := auth.For(ctx)
+
+	changes := []mutate.NotificationSettingChange{}
+
+	for _, s := range settings {
+		channels := map[query.NotificationChannel]*bool{
+			query.NotificationChannelInbox:   s.Inbox,
+			query.NotificationChannelWhisper: s.Whisper,
+		}
+
+		for _, ch := range query.NotificationChannels {
+			if channels[ch] == nil {
+				continue
+			}
+
+			changes = append(changes, mutate.NotificationSettingChange{
+				Category: query.NotificationCategory(s.Category),
+				Channel:  ch,
+				Enabled:  *channels[ch],
+			})
+		}
+	}
+
+	result, err := r.Ctx.Inst().Mutate.SetNotificationSettings(ctx, actor, obj.ID, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return helpers.NotificationSettingsToModel(result), nil
+}
@@ -139,7 +139,7 @@ func (r *ResolverOps) Connections(ctx context.Context, obj *model.UserOps, id st
 				return nil, errors.ErrInternalServerError()
 			}
 
-			r.Ctx.Inst().Events.Dispatch(events.EventTypeUpdateUser, events.ChangeMap{
+			r.Ctx.Inst().Events.Dispatch(ctx, events.EventTypeUpdateUser, events.ChangeMap{
 				ID:    obj.ID,
 				Kind:  structures.ObjectKindUser,
 				Actor: r.Ctx.Inst().Modelizer.User(ub.User).ToPartial(),
@@ -158,8 +158,8 @@ func (r *ResolverOps) Connections(ctx context.Context, obj *model.UserOps, id st
 				return nil, errors.ErrUnknownUserConnection()
 			}
 
-			newSet, _ := r.Ctx.Inst().Loaders.EmoteSetByID().Load(*d.EmoteSetID)
-			oldSet, _ := r.Ctx.Inst().Loaders.EmoteSetByID().Load(conn.EmoteSetID)
+			newSet, _ := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, *d.EmoteSetID)
+			oldSet, _ := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, conn.EmoteSetID)
 
 			if err = r.Ctx.Inst().Mutate.SetUserConnectionActiveEmoteSet(ctx, ub, mutate.SetUserActiveEmoteSet{
 				NewSet:       newSet,
@@ -53,7 +53,7 @@ func (r *ResolverPartial) EmoteSets(ctx context.Context, obj *model.UserPartial)
 }
 
 func (r *ResolverPartial) Style(ctx context.Context, obj *model.UserPartial) (*model.UserStyle, error) {
-	badge, paint := userEntitlements(r.Ctx, obj.ID)
+	badge, paint := userEntitlements(ctx, r.Ctx, obj.ID)
 
 	return &model.UserStyle{
 		Color:   obj.Style.Color,
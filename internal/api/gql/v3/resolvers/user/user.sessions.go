@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+
+	"github.com/seventv/api/internal/api/gql/v3/auth"
+	"github.com/seventv/api/internal/api/gql/v3/gen/model"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+)
+
+// Sessions lists the active logins of a user. Only visible to the user themselves and privileged users
+func (r *Resolver) Sessions(ctx context.Context, obj *model.User) ([]*model.UserSession, error) {
+	actor := auth.For(ctx)
+	if actor.ID != obj.ID && !actor.HasPermission(structures.RolePermissionManageUsers) {
+		return nil, errors.ErrInsufficientPrivilege()
+	}
+
+	sessions, err := r.Ctx.Inst().Query.Sessions(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := auth.SessionFor(ctx)
+
+	result := make([]*model.UserSession, len(sessions))
+	for i, s := range sessions {
+		result[i] = &model.UserSession{
+			ID:          s.ID,
+			UserAgent:   s.UserAgent,
+			CountryCode: s.CountryCode,
+			CountryName: s.CountryName,
+			CreatedAt:   s.CreatedAt,
+			LastSeenAt:  s.LastSeenAt,
+			ExpireAt:    s.ExpireAt,
+			Current:     !current.IsZero() && s.ID == current,
+		}
+	}
+
+	return result, nil
+}
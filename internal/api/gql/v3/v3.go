@@ -16,16 +16,19 @@ import (
 	"github.com/seventv/common/errors"
 	"go.uber.org/zap"
 
+	"github.com/seventv/api/internal/api/gql/v3/allowlist"
 	"github.com/seventv/api/internal/api/gql/v3/cache"
 	"github.com/seventv/api/internal/api/gql/v3/complexity"
 	"github.com/seventv/api/internal/api/gql/v3/gen/generated"
 	"github.com/seventv/api/internal/api/gql/v3/helpers"
+	"github.com/seventv/api/internal/api/gql/v3/metrics"
 	middlewarev3 "github.com/seventv/api/internal/api/gql/v3/middleware"
 	"github.com/seventv/api/internal/api/gql/v3/resolvers"
 	"github.com/seventv/api/internal/api/gql/v3/types"
 	"github.com/seventv/api/internal/constant"
 	"github.com/seventv/api/internal/global"
 	"github.com/seventv/api/internal/middleware"
+	"github.com/seventv/api/internal/svc/tracing"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpadaptor"
 	"github.com/vektah/gqlparser/v2/gqlerror"
@@ -46,9 +49,21 @@ func GqlHandlerV3(gCtx global.Context) func(ctx *fasthttp.RequestCtx) {
 
 	srv.Use(&extension.ComplexityLimit{
 		Func: func(ctx context.Context, rc *graphql.OperationContext) int {
-			return 80
+			return complexity.Limit(gCtx, ctx)
 		},
 	})
+	if gCtx.Config().Limits.PersistedQueries.Mode != "" {
+		srv.Use(allowlist.New(gCtx))
+	}
+
+	srv.Use(complexity.Cost{})
+	srv.Use(metrics.Metrics{Prometheus: gCtx.Inst().Prometheus, Persisted: gCtx.Inst().Persisted})
+
+	if gCtx.Config().Tracing.Enabled {
+		srv.Use(metrics.Tracing{
+			Threshold: time.Millisecond * time.Duration(gCtx.Config().Tracing.FieldThreshold),
+		})
+	}
 
 	srv.Use(extension.Introspection{})
 	srv.Use(extension.AutomaticPersistedQuery{
@@ -81,29 +96,32 @@ func GqlHandlerV3(gCtx global.Context) func(ctx *fasthttp.RequestCtx) {
 		return helpers.ErrInternalServerError
 	})
 
-	rateLimitFunc := middleware.RateLimit(gCtx, "gql-v3", gCtx.Config().Limits.Buckets.GQL3[0], time.Second*time.Duration(gCtx.Config().Limits.Buckets.GQL3[1]))
+	return func(ctx *fasthttp.RequestCtx) {
+		lCtx := context.WithValue(gCtx, constant.UserKey, ctx.UserValue(constant.UserKey))
+		lCtx = context.WithValue(lCtx, constant.ClientIP, ctx.UserValue(string(constant.ClientIP)))
+		lCtx = context.WithValue(lCtx, constant.SessionKey, ctx.UserValue(constant.SessionKey))
+
+		// The bucket is read on each request, so that it can be changed by reloading the config
+		charge := complexity.RateLimitFunc(func(cost int64) errors.APIError {
+			rate := gCtx.Config().Limits.Buckets.GQL3
+
+			return middleware.RateLimitCost(gCtx, "gql-v3", rate[0], time.Second*time.Duration(rate[1]))(ctx, cost)
+		})
 
-	checkLimit := func(ctx *fasthttp.RequestCtx) bool {
-		if err := rateLimitFunc(ctx); err != nil {
+		// Each request is charged once before it is parsed, so that invalid queries are rate limited as well
+		if err := charge(1); err != nil {
 			j, _ := json.Marshal(errorPresenter(ctx, err))
 
 			ctx.SetContentType("application/json")
 			ctx.SetBody(j)
 
-			return false
-		}
-
-		return true
-	}
-
-	return func(ctx *fasthttp.RequestCtx) {
-		lCtx := context.WithValue(gCtx, constant.UserKey, ctx.UserValue(constant.UserKey))
-		lCtx = context.WithValue(lCtx, constant.ClientIP, ctx.UserValue(string(constant.ClientIP)))
-
-		if ok := checkLimit(ctx); !ok {
 			return
 		}
 
+		// Operations are then charged their cost once it is known, see complexity.Cost
+		lCtx = context.WithValue(lCtx, helpers.RateLimitFunc, charge)
+		lCtx = tracing.WithRequest(lCtx, ctx)
+
 		fasthttpadaptor.NewFastHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			srv.ServeHTTP(w, r.WithContext(lCtx))
 		}))(ctx)
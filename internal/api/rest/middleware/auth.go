@@ -3,15 +3,31 @@ package middleware
 import (
 	"github.com/seventv/api/internal/api/rest/rest"
 	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/middleware"
 	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
 )
 
-func Auth(gCtx global.Context, required bool) rest.Middleware {
+// Auth requires a signed-in actor if required is true.
+// Routes acting with privileged permissions should list them so that a second-factor check can be enforced
+func Auth(gCtx global.Context, required bool, perms ...structures.RolePermission) rest.Middleware {
+	var sensitive structures.RolePermission
+	for _, p := range perms {
+		sensitive |= p
+	}
+
 	return func(ctx *rest.Ctx) rest.APIError {
-		if _, ok := ctx.GetActor(); !ok && required {
+		actor, ok := ctx.GetActor()
+		if !ok && required {
 			return errors.ErrUnauthorized().SetDetail("Sign-In Required")
 		}
 
+		if ok && sensitive != 0 && actor.HasPermission(sensitive) {
+			if err := middleware.RequireSecondFactor(gCtx, ctx, actor, sensitive); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}
 }
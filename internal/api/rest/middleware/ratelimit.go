@@ -13,7 +13,16 @@ import (
 )
 
 func RateLimit(gctx global.Context, bucket string, rate [2]int64) rest.Middleware {
+	return RateLimitFunc(gctx, bucket, func() [2]int64 {
+		return rate
+	})
+}
+
+// RateLimitFunc is a rate limit whose limit and duration are read on each request, such as those set in the config
+func RateLimitFunc(gctx global.Context, bucket string, rateFn func() [2]int64) rest.Middleware {
 	return func(ctx *rest.Ctx) rest.APIError {
+		rate := rateFn()
+
 		identifier, _ := ctx.UserValue(constant.ClientIP).String()
 
 		actor, ok := ctx.GetActor()
@@ -25,7 +34,7 @@ func RateLimit(gctx global.Context, bucket string, rate [2]int64) rest.Middlewar
 			return nil
 		}
 
-		limit, remaining, ttl, err := middleware.DoRateLimit(gctx, ctx, bucket, rate[0], identifier, time.Second*time.Duration(rate[1]))
+		limit, remaining, ttl, err := middleware.DoRateLimit(gctx, ctx, bucket, rate[0], identifier, time.Second*time.Duration(rate[1]), 1)
 		if err != nil {
 			switch e := err.(type) {
 			case errors.APIError:
@@ -41,6 +50,8 @@ func RateLimit(gctx global.Context, bucket string, rate [2]int64) rest.Middlewar
 		ctx.Response.Header.Set("X-RateLimit-Reset", strconv.Itoa(int(ttl)))
 
 		if remaining < 1 {
+			gctx.Inst().Prometheus.RateLimited(bucket)
+
 			return errors.ErrRateLimited()
 		}
 
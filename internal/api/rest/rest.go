@@ -10,8 +10,10 @@ import (
 	"github.com/seventv/api/internal/constant"
 	"github.com/seventv/api/internal/global"
 	"github.com/seventv/api/internal/middleware"
+	"github.com/seventv/api/internal/svc/tracing"
 	"github.com/seventv/common/utils"
 	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 )
 
@@ -36,6 +38,7 @@ func New(gctx global.Context) error {
 	}
 
 	s.router = router.New()
+	s.router.SaveMatchedRoutePath = true
 
 	// Add versions
 	s.SetupHandlers()
@@ -57,8 +60,17 @@ func New(gctx global.Context) error {
 
 			ctx.SetUserValue(constant.ClientIP, ip)
 
+			span := tracing.StartRequest(ctx, "rest "+string(ctx.Method()))
+
 			defer func() {
+				// The pattern of the route which matched, if any
+				route, _ := ctx.UserValue(router.MatchedRoutePathParam).(string)
+
+				defer tracing.EndRequest(ctx, span, route)
+
 				if err := recover(); err != nil {
+					span.SetStatus(codes.Error, fmt.Sprint(err))
+
 					zap.S().Errorw("panic in rest request handler",
 						"panic", err,
 						"status", ctx.Response.StatusCode(),
@@ -72,6 +84,14 @@ func New(gctx global.Context) error {
 					mills := time.Since(start) / time.Millisecond
 					status := ctx.Response.StatusCode()
 
+					// Label by the pattern of the route rather than the path, which would add a series per ID
+					label := route
+					if label == "" {
+						label = "unmatched"
+					}
+
+					gctx.Inst().Prometheus.ObserveRequest("rest", label, string(ctx.Method()), status, time.Since(start))
+
 					logFn := zap.S().Debugw
 					if mills >= 500 {
 						logFn = zap.S().Infow
@@ -94,6 +114,11 @@ func New(gctx global.Context) error {
 			ctx.Response.Header.Set("X-Node-Name", gctx.Config().K8S.NodeName)
 			ctx.Response.Header.Set("X-Pod-Name", gctx.Config().K8S.PodName)
 
+			// While draining, clients are asked not to reuse the connection so that they move to another pod
+			if !gctx.Inst().Lifecycle.Ready() {
+				ctx.SetConnectionClose()
+			}
+
 			if err := doCORS(ctx); err != nil {
 				return
 			}
@@ -126,12 +151,26 @@ func New(gctx global.Context) error {
 		portal.Serve(gctx)
 	}()
 
-	// Gracefully exit when the global context is canceled
+	// Stop accepting connections once the drain period has passed, and wait for the requests in flight
+	shutdown := make(chan struct{})
+
 	go func() {
-		<-gctx.Done()
+		select {
+		case <-gctx.Inst().Lifecycle.Stopping():
+		case <-gctx.Done():
+		}
 
 		_ = srv.Shutdown()
+
+		close(shutdown)
 	}()
 
-	return srv.Serve(s.listener)
+	if err := srv.Serve(s.listener); err != nil {
+		return err
+	}
+
+	// Serving ends as soon as the listener is closed, before the requests in flight have finished
+	<-shutdown
+
+	return nil
 }
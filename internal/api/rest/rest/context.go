@@ -7,6 +7,7 @@ import (
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/structures/v3"
 	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/zap"
 )
 
@@ -57,6 +58,16 @@ func (c *Ctx) GetActor() (structures.User, bool) {
 	}
 }
 
+// Get the session of the current access token
+func (c *Ctx) GetSessionID() (primitive.ObjectID, bool) {
+	switch v := c.RequestCtx.UserValue(constant.SessionKey).(type) {
+	case primitive.ObjectID:
+		return v, true
+	default:
+		return primitive.NilObjectID, false
+	}
+}
+
 func (c *Ctx) Log() *zap.SugaredLogger {
 	z := zap.S().Named("api/rest").With(
 		"request_id", c.ID(),
@@ -39,6 +39,8 @@ func (r *Route) Handler(ctx *rest.Ctx) errors.APIError {
 	platform, _ := ctx.UserValue("platform").String()
 	branch, _ := ctx.UserValue("branch").String()
 
+	cfg := r.Ctx.Config()
+
 	var (
 		download         string
 		portableDownload string
@@ -49,32 +51,32 @@ func (r *Route) Handler(ctx *rest.Ctx) errors.APIError {
 	case "stable":
 		switch platform {
 		case "win":
-			download = r.Ctx.Config().Chatterino.Stable.Win.Download
-			portableDownload = r.Ctx.Config().Chatterino.Stable.Win.PortableDownload
-			updateExe = r.Ctx.Config().Chatterino.Stable.Win.UpdateExe
+			download = cfg.Chatterino.Stable.Win.Download
+			portableDownload = cfg.Chatterino.Stable.Win.PortableDownload
+			updateExe = cfg.Chatterino.Stable.Win.UpdateExe
 		case "linux":
-			download = r.Ctx.Config().Chatterino.Stable.Linux.Download
-			portableDownload = r.Ctx.Config().Chatterino.Stable.Linux.PortableDownload
-			updateExe = r.Ctx.Config().Chatterino.Stable.Linux.UpdateExe
+			download = cfg.Chatterino.Stable.Linux.Download
+			portableDownload = cfg.Chatterino.Stable.Linux.PortableDownload
+			updateExe = cfg.Chatterino.Stable.Linux.UpdateExe
 		case "macos":
-			download = r.Ctx.Config().Chatterino.Stable.Macos.Download
-			portableDownload = r.Ctx.Config().Chatterino.Stable.Macos.PortableDownload
-			updateExe = r.Ctx.Config().Chatterino.Stable.Macos.UpdateExe
+			download = cfg.Chatterino.Stable.Macos.Download
+			portableDownload = cfg.Chatterino.Stable.Macos.PortableDownload
+			updateExe = cfg.Chatterino.Stable.Macos.UpdateExe
 		}
 	case "beta":
 		switch platform {
 		case "win":
-			download = r.Ctx.Config().Chatterino.Beta.Win.Download
-			portableDownload = r.Ctx.Config().Chatterino.Beta.Win.PortableDownload
-			updateExe = r.Ctx.Config().Chatterino.Beta.Win.UpdateExe
+			download = cfg.Chatterino.Beta.Win.Download
+			portableDownload = cfg.Chatterino.Beta.Win.PortableDownload
+			updateExe = cfg.Chatterino.Beta.Win.UpdateExe
 		case "linux":
-			download = r.Ctx.Config().Chatterino.Beta.Linux.Download
-			portableDownload = r.Ctx.Config().Chatterino.Beta.Linux.PortableDownload
-			updateExe = r.Ctx.Config().Chatterino.Beta.Linux.UpdateExe
+			download = cfg.Chatterino.Beta.Linux.Download
+			portableDownload = cfg.Chatterino.Beta.Linux.PortableDownload
+			updateExe = cfg.Chatterino.Beta.Linux.UpdateExe
 		case "macos":
-			download = r.Ctx.Config().Chatterino.Beta.Macos.Download
-			portableDownload = r.Ctx.Config().Chatterino.Beta.Macos.PortableDownload
-			updateExe = r.Ctx.Config().Chatterino.Beta.Macos.UpdateExe
+			download = cfg.Chatterino.Beta.Macos.Download
+			portableDownload = cfg.Chatterino.Beta.Macos.PortableDownload
+			updateExe = cfg.Chatterino.Beta.Macos.UpdateExe
 		}
 	}
 
@@ -82,7 +84,7 @@ func (r *Route) Handler(ctx *rest.Ctx) errors.APIError {
 		Download:         download,
 		PortableDownload: portableDownload,
 		UpdateExe:        updateExe,
-		Version:          r.Ctx.Config().Chatterino.Version,
+		Version:          cfg.Chatterino.Version,
 	}
 
 	return ctx.JSON(rest.OK, result)
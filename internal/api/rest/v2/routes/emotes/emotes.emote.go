@@ -41,7 +41,7 @@ func (r *emote) Handler(ctx *rest.Ctx) errors.APIError {
 		return errors.From(err)
 	}
 
-	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(emoteID)
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, emoteID)
 	if err != nil {
 		return errors.From(err)
 	}
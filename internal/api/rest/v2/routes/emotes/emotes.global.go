@@ -50,7 +50,7 @@ func (r *globals) Handler(ctx *rest.Ctx) errors.APIError {
 		return a.ID
 	})
 
-	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(emoteIDs)
+	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, emoteIDs)
 
 	emoteMap := map[primitive.ObjectID]structures.Emote{}
 	for _, emote := range emotes {
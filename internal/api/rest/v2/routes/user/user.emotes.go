@@ -94,7 +94,7 @@ func (r *emotes) Handler(ctx *rest.Ctx) errors.APIError {
 		con = tw.ToRaw()
 	}
 
-	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(con.EmoteSetID)
+	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, con.EmoteSetID)
 	if err != nil {
 		return errors.From(err)
 	}
@@ -104,7 +104,7 @@ func (r *emotes) Handler(ctx *rest.Ctx) errors.APIError {
 		return a.ID
 	})
 
-	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(emoteIDs)
+	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, emoteIDs)
 
 	emoteMap := map[primitive.ObjectID]structures.Emote{}
 	for _, emote := range emotes {
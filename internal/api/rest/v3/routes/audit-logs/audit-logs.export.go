@@ -0,0 +1,239 @@
+package audit_logs
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/rest/middleware"
+	"github.com/seventv/api/internal/api/rest/rest"
+	"github.com/seventv/api/internal/global"
+)
+
+// AUDIT_LOG_EXPORT_TIMEOUT bounds how long a single export may stream for
+const AUDIT_LOG_EXPORT_TIMEOUT = time.Minute * 10
+
+// AUDIT_LOG_EXPORT_LIMIT is how many logs a single export may contain
+const AUDIT_LOG_EXPORT_LIMIT = 100000
+
+type exportRoute struct {
+	gctx global.Context
+}
+
+func New(gctx global.Context) rest.Route {
+	return &exportRoute{gctx}
+}
+
+func (r *exportRoute) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:    "/audit-logs/export",
+		Method: rest.GET,
+		Middleware: []rest.Middleware{
+			middleware.Auth(r.gctx, true, structures.RolePermissionManageUsers),
+			middleware.RateLimit(r.gctx, "ExportAuditLogs", [2]int64{5, 3600}),
+		},
+	}
+}
+
+// @Summary Export Audit Logs
+// @Description Streams the audit logs matching the filters as newline-delimited JSON, oldest first.
+// @Description At least one of actor_id, target_id or since is required, and at most 100000 logs are exported.
+// @Description The last line is an exportTrailer: an export which doesn't end with one was cut short
+// @Tags audit-logs
+// @Produce application/x-ndjson
+// @Param actor_id query string false "only logs made by this user"
+// @Param target_kind query int false "only logs targeting this kind of object"
+// @Param target_id query string false "only logs targeting this object"
+// @Param kind query int false "only logs of this kind"
+// @Param since query string false "only logs created at or after this RFC3339 date"
+// @Param until query string false "only logs created before this RFC3339 date"
+// @Param change_key query string false "only logs with a change to this key"
+// @Success 200 {object} exportedAuditLog
+// @Router /audit-logs/export [get]
+func (r *exportRoute) Handler(ctx *rest.Ctx) rest.APIError {
+	actor, ok := ctx.GetActor()
+	if !ok || !actor.HasPermission(structures.RolePermissionManageUsers) {
+		return errors.ErrInsufficientPrivilege()
+	}
+
+	filter, err := parseFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Unfiltered exports would scan the whole collection
+	if filter.ActorID.IsZero() && filter.TargetID.IsZero() && filter.Since.IsZero() {
+		return errors.ErrInvalidRequest().SetDetail("actor_id, target_id or since is required")
+	}
+
+	ctx.Log().Infow("audit log export started",
+		"filter", filter,
+		"actor_id", actor.ID.Hex(),
+	)
+
+	ctx.SetStatusCode(rest.OK)
+	ctx.SetContentType("application/x-ndjson")
+
+	// The body is written once the handler returned, so the request can't be used as the context
+	ctx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		sctx, cancel := context.WithTimeout(r.gctx, AUDIT_LOG_EXPORT_TIMEOUT)
+		defer cancel()
+
+		enc := json.NewEncoder(w)
+		count := 0
+
+		err := r.gctx.Inst().Query.ExportAuditLogs(sctx, filter, AUDIT_LOG_EXPORT_LIMIT, func(l structures.AuditLog) error {
+			if err := enc.Encode(newExportedAuditLog(l)); err != nil {
+				return err
+			}
+
+			count++
+
+			// Flush regularly, so that a disconnected client ends the export
+			if count%100 == 0 {
+				return w.Flush()
+			}
+
+			return nil
+		})
+		// The status was sent before streaming, so the outcome of the export is told by its last line
+		trailer := exportTrailer{
+			Done:      err == nil,
+			Count:     count,
+			Truncated: count >= AUDIT_LOG_EXPORT_LIMIT,
+		}
+
+		if err != nil {
+			zap.S().Errorw("audit log export failed",
+				"error", err,
+				"exported", count,
+				"actor_id", actor.ID.Hex(),
+			)
+
+			trailer.Error = "the export failed"
+		}
+
+		_ = enc.Encode(trailer)
+		_ = w.Flush()
+	})
+
+	return nil
+}
+
+func parseFilter(ctx *rest.Ctx) (query.AuditLogFilter, rest.APIError) {
+	var (
+		filter = query.AuditLogFilter{}
+		args   = ctx.QueryArgs()
+		err    error
+	)
+
+	if s := utils.B2S(args.Peek("actor_id")); s != "" {
+		if filter.ActorID, err = primitive.ObjectIDFromHex(s); err != nil {
+			return filter, errors.ErrInvalidRequest().SetDetail("actor_id must be an object id")
+		}
+	}
+
+	if s := utils.B2S(args.Peek("target_kind")); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return filter, errors.ErrInvalidRequest().SetDetail("target_kind must be an integer")
+		}
+
+		filter.TargetKind = utils.PointerOf(structures.ObjectKind(i))
+	}
+
+	if s := utils.B2S(args.Peek("target_id")); s != "" {
+		if filter.TargetID, err = primitive.ObjectIDFromHex(s); err != nil {
+			return filter, errors.ErrInvalidRequest().SetDetail("target_id must be an object id")
+		}
+	}
+
+	if s := utils.B2S(args.Peek("kind")); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return filter, errors.ErrInvalidRequest().SetDetail("kind must be an integer")
+		}
+
+		filter.Kind = utils.PointerOf(structures.AuditLogKind(i))
+	}
+
+	if s := utils.B2S(args.Peek("since")); s != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, s); err != nil {
+			return filter, errors.ErrInvalidRequest().SetDetail("since must be an RFC3339 date")
+		}
+	}
+
+	if s := utils.B2S(args.Peek("until")); s != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, s); err != nil {
+			return filter, errors.ErrInvalidRequest().SetDetail("until must be an RFC3339 date")
+		}
+	}
+
+	filter.ChangeKey = string(args.Peek("change_key"))
+
+	return filter, nil
+}
+
+// exportedAuditLog is an audit log with its changes decoded, as they are stored in BSON
+type exportedAuditLog struct {
+	ID         primitive.ObjectID       `json:"id"`
+	Kind       structures.AuditLogKind  `json:"kind"`
+	ActorID    primitive.ObjectID       `json:"actor_id"`
+	TargetID   primitive.ObjectID       `json:"target_id"`
+	TargetKind structures.ObjectKind    `json:"target_kind"`
+	CreatedAt  time.Time                `json:"created_at"`
+	Changes    []exportedAuditLogChange `json:"changes"`
+	Reason     string                   `json:"reason"`
+}
+
+// exportTrailer is the last line of an export
+type exportTrailer struct {
+	// Whether every log was exported
+	Done  bool `json:"done"`
+	Count int  `json:"count"`
+	// Whether more logs matched than an export may contain
+	Truncated bool   `json:"truncated"`
+	Error     string `json:"error,omitempty"`
+}
+
+type exportedAuditLogChange struct {
+	Format structures.AuditLogChangeFormat `json:"format"`
+	Key    string                          `json:"key"`
+	Value  bson.M                          `json:"value"`
+}
+
+func newExportedAuditLog(l structures.AuditLog) exportedAuditLog {
+	result := exportedAuditLog{
+		ID:         l.ID,
+		Kind:       l.Kind,
+		ActorID:    l.ActorID,
+		TargetID:   l.TargetID,
+		TargetKind: l.TargetKind,
+		CreatedAt:  l.ID.Timestamp(),
+		Changes:    make([]exportedAuditLogChange, len(l.Changes)),
+		Reason:     l.Reason,
+	}
+
+	for i, c := range l.Changes {
+		val := bson.M{}
+		_ = bson.Unmarshal(c.Value, &val)
+
+		result.Changes[i] = exportedAuditLogChange{
+			Format: c.Format,
+			Key:    c.Key,
+			Value:  val,
+		}
+	}
+
+	return result
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/seventv/api/data/events"
+	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/api/rest/middleware"
 	"github.com/seventv/api/internal/api/rest/rest"
 	"github.com/seventv/api/internal/global"
@@ -120,7 +122,7 @@ func (r *Route) Handler(ctx *rest.Ctx) errors.APIError {
 			return errors.From(err)
 		}
 
-		token, expiry, err := setupToken(r.gctx, ub)
+		token, expiry, err := setupToken(r.gctx, ctx, ub)
 		if err != nil {
 			return errors.From(err)
 		}
@@ -227,7 +229,7 @@ func setupUser(
 			userConn = con
 
 			// eventapi: dispatch the connection create event
-			gctx.Inst().Events.Dispatch(events.EventTypeUpdateUser, events.ChangeMap{
+			gctx.Inst().Events.Dispatch(ctx, events.EventTypeUpdateUser, events.ChangeMap{
 				ID:    ub.User.ID,
 				Kind:  structures.ObjectKindUser,
 				Actor: gctx.Inst().Modelizer.User(ub.User).ToPartial(),
@@ -270,15 +272,44 @@ func setupUser(
 	return nil
 }
 
-func setupToken(gctx global.Context, ub *structures.UserBuilder) (token string, expiry time.Time, err error) {
+func setupToken(gctx global.Context, ctx *rest.Ctx, ub *structures.UserBuilder) (token string, expiry time.Time, err error) {
+	sessionID := primitive.NewObjectID()
+
 	// Sign an access token
-	token, expiry, err = gctx.Inst().Auth.CreateAccessToken(ub.User.ID, ub.User.TokenVersion)
+	token, expiry, err = gctx.Inst().Auth.CreateAccessToken(ub.User.ID, ub.User.TokenVersion, sessionID)
 	if err != nil {
 		zap.S().Errorw("auth, create access token", "error", err)
 
 		return "", time.Time{}, errors.ErrInternalServerError()
 	}
 
+	// Record the session
+	if err = gctx.Inst().Mutate.CreateSession(ctx, query.Session{
+		ID:        sessionID,
+		UserID:    ub.User.ID,
+		UserAgent: utils.B2S(ctx.UserAgent()),
+		ExpireAt:  expiry,
+	}); err != nil {
+		return "", time.Time{}, err
+	}
+
+	// Resolve the region of the session in the background
+	if clientIP := ctx.ClientIP(); clientIP != "" {
+		go func() {
+			lctx, cancel := context.WithTimeout(gctx, time.Second*10)
+			defer cancel()
+
+			loc, err := gctx.Inst().Auth.LocateIP(lctx, clientIP)
+			if err != nil {
+				zap.S().Warnw("auth, failed to locate session", "error", err)
+
+				return
+			}
+
+			_ = gctx.Inst().Mutate.SetSessionLocation(lctx, sessionID, loc.CountryCode, loc.CountryName)
+		}()
+	}
+
 	return token, expiry, nil
 }
 
@@ -27,6 +27,20 @@ func (r *logoutRoute) Config() rest.RouteConfig {
 }
 
 func (r *logoutRoute) Handler(ctx *rest.Ctx) errors.APIError {
+	// Revoke the current session, so that the token cannot be reused
+	if sessionID, ok := ctx.GetSessionID(); ok {
+		actor, _ := ctx.GetActor()
+
+		session, err := r.gctx.Inst().Query.Session(ctx, sessionID)
+		if err == nil {
+			if err := r.gctx.Inst().Mutate.RevokeSession(ctx, actor, session); err != nil {
+				return errors.From(err)
+			}
+		} else if !errors.Compare(err, errors.ErrNoItems()) {
+			return errors.From(err)
+		}
+	}
+
 	cookie := r.gctx.Inst().Auth.Cookie(auth.COOKIE_AUTH, "", 0)
 
 	ctx.Response.Header.SetCookie(cookie)
@@ -139,7 +139,7 @@ func (r *verifyRoute) Handler(ctx *rest.Ctx) errors.APIError {
 			return errors.From(err)
 		}
 
-		token, _, err := setupToken(r.gctx, ub)
+		token, _, err := setupToken(r.gctx, ctx, ub)
 		if err != nil {
 			return errors.From(err)
 		}
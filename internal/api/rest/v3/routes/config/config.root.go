@@ -5,6 +5,7 @@ import (
 	"github.com/seventv/api/internal/api/rest/rest"
 	"github.com/seventv/api/internal/global"
 	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
 )
 
 type Route struct {
@@ -33,6 +34,10 @@ func (r *Route) Handler(ctx *rest.Ctx) rest.APIError {
 		return errors.ErrInvalidRequest().SetDetail("Missing config name")
 	}
 
+	if name == "flags" {
+		return r.flags(ctx)
+	}
+
 	sys, err := r.Ctx.Inst().Mongo.System(ctx)
 	if err != nil {
 		ctx.Log().Errorw("failed to get system config",
@@ -55,3 +60,23 @@ func (r *Route) Handler(ctx *rest.Ctx) rest.APIError {
 
 	return ctx.JSON(rest.OK, t)
 }
+
+// flags returns whether each feature flag readable by clients is on for the requesting user
+func (r *Route) flags(ctx *rest.Ctx) rest.APIError {
+	var user *structures.User
+
+	if actor, ok := ctx.GetActor(); ok {
+		user = &actor
+	}
+
+	// The result depends on the user, so it must not be cached by shared caches
+	ctx.Response.Header.Set("Cache-Control", "private, max-age=60")
+
+	return ctx.JSON(rest.OK, flagsResponse{
+		Flags: r.Ctx.Inst().Flags.Public(user),
+	})
+}
+
+type flagsResponse struct {
+	Flags map[string]bool `json:"flags"`
+}
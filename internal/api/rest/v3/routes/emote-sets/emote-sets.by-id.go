@@ -64,7 +64,7 @@ func (r *emoteSetByIDRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		}
 	}
 
-	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(setID)
+	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, setID)
 	if err != nil {
 		return errors.From(err)
 	}
@@ -74,7 +74,7 @@ func (r *emoteSetByIDRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		return a.ID
 	})
 
-	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(emoteIDs)
+	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, emoteIDs)
 
 	emoteMap := map[primitive.ObjectID]structures.Emote{}
 
@@ -86,7 +86,7 @@ func (r *emoteSetByIDRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		emoteMap[emote.ID] = emote
 	}
 
-	setOwner, _ := r.Ctx.Inst().Loaders.UserByID().Load(set.OwnerID)
+	setOwner, _ := r.Ctx.Inst().Loaders.UserByID().Load(ctx, set.OwnerID)
 	if !setOwner.ID.IsZero() {
 		set.Owner = &setOwner
 	}
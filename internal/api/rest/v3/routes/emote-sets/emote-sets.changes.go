@@ -0,0 +1,94 @@
+package emote_sets
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/seventv/api/internal/api/rest/middleware"
+	"github.com/seventv/api/internal/api/rest/rest"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type emoteSetChangesRoute struct {
+	Ctx global.Context
+}
+
+func newEmoteSetChangesRoute(gctx global.Context) rest.Route {
+	return &emoteSetChangesRoute{gctx}
+}
+
+func (r *emoteSetChangesRoute) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:      "/{emote-set.id}/changes",
+		Method:   rest.GET,
+		Children: []rest.Route{},
+		Middleware: []rest.Middleware{
+			middleware.SetCacheControl(r.Ctx, 5, []string{"public"}),
+		},
+	}
+}
+
+// @Summary Get Emote Set Changes
+// @Description Get the changes made to a set and its emotes since a cursor or point in time, oldest first
+// @Tags emote-sets
+// @Produce json
+// @Param emote-set.id path string true "ID of the emote set"
+// @Param since query string false "a cursor from a previous response, an RFC3339 date or a unix timestamp"
+// @Param limit query int false "the maximum amount of audit entries to read (default 100, max 500)"
+// @Success 200 {object} query.EmoteSetChangesResult
+// @Router /emote-sets/{emote-set.id}/changes [get]
+func (r *emoteSetChangesRoute) Handler(ctx *rest.Ctx) rest.APIError {
+	setID, err := ctx.UserValue("emote-set.id").ObjectID()
+	if err != nil {
+		return errors.From(err)
+	}
+
+	set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, setID)
+	if err != nil {
+		return errors.From(err)
+	}
+
+	after := primitive.NilObjectID
+
+	if since := utils.B2S(ctx.QueryArgs().Peek("since")); since != "" {
+		if after, err = parseChangesCursor(since); err != nil {
+			return errors.ErrInvalidRequest().SetDetail("since must be a cursor, an RFC3339 date or a unix timestamp")
+		}
+	}
+
+	limit := 100
+
+	if s := utils.B2S(ctx.QueryArgs().Peek("limit")); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > 500 {
+			return errors.ErrInvalidRequest().SetDetail("limit must be between 1 and 500")
+		}
+	}
+
+	result, err := r.Ctx.Inst().Query.EmoteSetChanges(ctx, set.ID, after, limit)
+	if err != nil {
+		return errors.From(err)
+	}
+
+	return ctx.JSON(rest.OK, result)
+}
+
+// parseChangesCursor reads a cursor, which may also be given as a point in time
+func parseChangesCursor(s string) (primitive.ObjectID, error) {
+	if id, err := primitive.ObjectIDFromHex(s); err == nil {
+		return id, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return primitive.NewObjectIDFromTimestamp(t), nil
+	}
+
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return primitive.NewObjectIDFromTimestamp(time.Unix(sec, 0)), nil
+}
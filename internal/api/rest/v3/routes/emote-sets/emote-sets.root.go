@@ -20,6 +20,7 @@ func (r Route) Config() rest.RouteConfig {
 		Method: rest.GET,
 		Children: []rest.Route{
 			newEmoteSetByIDRoute(r.Ctx),
+			newEmoteSetChangesRoute(r.Ctx),
 		},
 	}
 }
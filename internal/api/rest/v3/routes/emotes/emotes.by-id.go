@@ -39,7 +39,7 @@ func (r *emoteRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		return errors.From(err)
 	}
 
-	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(emoteID)
+	emote, err := r.Ctx.Inst().Loaders.EmoteByID().Load(ctx, emoteID)
 	if err != nil {
 		return errors.From(err)
 	}
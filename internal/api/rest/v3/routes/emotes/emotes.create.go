@@ -41,7 +41,9 @@ func (r *create) Config() rest.RouteConfig {
 		Method: rest.POST,
 		Middleware: []rest.Middleware{
 			middleware.Auth(r.Ctx, true),
-			middleware.RateLimit(r.Ctx, "CreateEmote", r.Ctx.Config().Limits.Buckets.ImageProcessing),
+			middleware.RateLimitFunc(r.Ctx, "CreateEmote", func() [2]int64 {
+				return r.Ctx.Config().Limits.Buckets.ImageProcessing
+			}),
 		},
 	}
 }
@@ -59,6 +61,9 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 	done := r.Ctx.Inst().Limiter.AwaitMutation(ctx)
 	defer done()
 
+	// The config is read once, so that the request sees consistent limits if it is reloaded meanwhile
+	cfg := r.Ctx.Config()
+
 	ctx.SetContentType("application/json")
 
 	// Check RMQ status
@@ -100,7 +105,7 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 		}
 	}
 
-	reqLimit := r.Ctx.Config().Limits.Quota.MaxActiveModRequests
+	reqLimit := cfg.Limits.Quota.MaxActiveModRequests
 	if count, _ := r.Ctx.Inst().Mongo.Collection(mongo.CollectionNameEmotes).CountDocuments(ctx, bson.M{
 		"versions.id":              bson.M{"$in": emoteIDs},
 		"versions.state.lifecycle": structures.EmoteLifecycleLive,
@@ -148,8 +153,8 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 	// Validate: Tags
 	{
 		uniqueTags := map[string]bool{}
-		if len(args.Tags) > r.Ctx.Config().Limits.Emotes.MaxTags {
-			return errors.ErrInvalidRequest().SetDetail(fmt.Sprintf("Too many emote tags %d when the max is %d", len(args.Tags), r.Ctx.Config().Limits.Emotes.MaxTags))
+		if len(args.Tags) > cfg.Limits.Emotes.MaxTags {
+			return errors.ErrInvalidRequest().SetDetail(fmt.Sprintf("Too many emote tags %d when the max is %d", len(args.Tags), cfg.Limits.Emotes.MaxTags))
 		}
 
 		for _, v := range args.Tags {
@@ -208,7 +213,7 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 			Name:         "original",
 			ContentType:  fileType.MIME.Value,
 			Key:          filekey,
-			Bucket:       r.Ctx.Config().S3.InternalBucket,
+			Bucket:       cfg.S3.InternalBucket,
 			ACL:          *s3.AclPrivate,
 			CacheControl: *s3.DefaultCacheControl,
 		},
@@ -290,7 +295,7 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 			Body:         aws.ReadSeekCloser(bytes.NewReader(body)),
 			Key:          aws.String(filekey),
 			ACL:          s3.AclPrivate,
-			Bucket:       aws.String(r.Ctx.Config().S3.InternalBucket),
+			Bucket:       aws.String(cfg.S3.InternalBucket),
 			ContentType:  aws.String(fileType.MIME.Value),
 			CacheControl: s3.DefaultCacheControl,
 		},
@@ -319,12 +324,12 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 			task.TaskFlagWEBP |
 			task.TaskFlagWEBP_STATIC,
 		Input: task.TaskInput{
-			Bucket: r.Ctx.Config().S3.InternalBucket,
+			Bucket: cfg.S3.InternalBucket,
 			Key:    filekey,
 		},
 		Output: task.TaskOutput{
 			Prefix:       r.Ctx.Inst().S3.ComposeKey("emote", id.Hex()),
-			Bucket:       r.Ctx.Config().S3.PublicBucket,
+			Bucket:       cfg.S3.PublicBucket,
 			CacheControl: *s3.DefaultCacheControl,
 			ACL:          *s3.AclPublicRead,
 		},
@@ -333,20 +338,20 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 		Scales:            []int{1, 2, 3, 4},
 		ResizeRatio:       task.ResizeRatioNothing,
 		Limits: task.TaskLimits{
-			MaxProcessingTime: time.Duration(r.Ctx.Config().Limits.Emotes.MaxProcessingTimeSeconds) * time.Second,
-			MaxFrameCount:     r.Ctx.Config().Limits.Emotes.MaxFrameCount,
-			MaxWidth:          r.Ctx.Config().Limits.Emotes.MaxWidth,
-			MaxHeight:         r.Ctx.Config().Limits.Emotes.MaxHeight,
+			MaxProcessingTime: time.Duration(cfg.Limits.Emotes.MaxProcessingTimeSeconds) * time.Second,
+			MaxFrameCount:     cfg.Limits.Emotes.MaxFrameCount,
+			MaxWidth:          cfg.Limits.Emotes.MaxWidth,
+			MaxHeight:         cfg.Limits.Emotes.MaxHeight,
 		},
 	})
 	if err == nil {
 		err = r.Ctx.Inst().MessageQueue.Publish(ctx, messagequeue.OutgoingMessage{
-			Queue:   r.Ctx.Config().MessageQueue.ImageProcessorJobsQueueName,
+			Queue:   cfg.MessageQueue.ImageProcessorJobsQueueName,
 			Headers: messagequeue.MessageHeaders{},
 			Flags: messagequeue.MessageFlags{
 				ID:          id.Hex(),
 				ContentType: "application/json",
-				ReplyTo:     r.Ctx.Config().MessageQueue.ImageProcessorResultsQueueName,
+				ReplyTo:     cfg.MessageQueue.ImageProcessorResultsQueueName,
 				Timestamp:   time.Now(),
 				RMQ: messagequeue.MessageFlagsRMQ{
 					DeliveryMode: messagequeue.RMQDeliveryModePersistent,
@@ -21,6 +21,8 @@ func (r *Route) Config() rest.RouteConfig {
 		Method: rest.GET,
 		Children: []rest.Route{
 			newCreate(r.Ctx),
+			newEmoteSearch(r.Ctx),
+			newEmoteSuggest(r.Ctx),
 			newEmote(r.Ctx),
 		},
 		Middleware: []rest.Middleware{},
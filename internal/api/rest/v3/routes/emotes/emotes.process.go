@@ -35,8 +35,21 @@ func (epl *EmoteProcessingListener) Listen() {
 		return
 	}
 
+	// Consuming stops when the process begins draining
+	subCtx, cancel := context.WithCancel(epl.Ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-epl.Ctx.Inst().Lifecycle.Draining():
+		case <-subCtx.Done():
+		}
+
+		cancel()
+	}()
+
 	// Results queue
-	messages, err := mq.Subscribe(epl.Ctx, messagequeue.Subscription{
+	messages, err := mq.Subscribe(subCtx, messagequeue.Subscription{
 		Queue: epl.Ctx.Config().MessageQueue.ImageProcessorResultsQueueName,
 		SQS: messagequeue.SubscriptionSQS{
 			WaitTimeSeconds: 10,
@@ -49,16 +62,36 @@ func (epl *EmoteProcessingListener) Listen() {
 	evt := task.Result{}
 
 	for msg := range messages {
+		// Shutdown waits for the messages being handled, and those received after draining began are
+		// returned to the queue for another pod to handle
+		done, ok := epl.Ctx.Inst().Lifecycle.Track()
+		if !ok {
+			if err := msg.Requeue(context.Background()); err != nil && msg.Error() == nil {
+				zap.S().Errorw("failed to requeue message",
+					"error", err,
+				)
+			}
+
+			continue
+		}
+
 		if msg.Headers().ContentType() == "application/json" {
 			if err := json.Unmarshal(msg.Body(), &evt); err != nil {
 				zap.S().Errorw("bad message type from queue",
 					"msg", msg,
 				)
 
+				done()
+
 				continue
 			}
 
-			go func(msg *messagequeue.IncomingMessage) {
+			// The result is passed by value, as the next message is decoded into evt while this one is handled
+			go func(msg *messagequeue.IncomingMessage, evt task.Result) {
+				defer done()
+
+				lag := time.Since(evt.FinishedAt)
+
 				tick := time.NewTicker(time.Second * 10)
 				ctx, cancel := context.WithCancel(epl.Ctx)
 
@@ -78,7 +111,11 @@ func (epl *EmoteProcessingListener) Listen() {
 					}
 				}()
 
-				if err := epl.HandleResultEvent(ctx, evt); err != nil {
+				err := epl.HandleResultEvent(ctx, evt)
+
+				epl.Ctx.Inst().Prometheus.ObserveImageResult("emote", evt.State.String(), err == nil, lag, evt.FinishedAt.Sub(evt.StartedAt))
+
+				if err != nil {
 					zap.S().Errorw("failed to handle result",
 						"error", multierr.Append(err, msg.Nack(context.Background())),
 					)
@@ -89,8 +126,10 @@ func (epl *EmoteProcessingListener) Listen() {
 						)
 					}
 				}
-			}(msg)
+			}(msg, evt)
 		} else {
+			done()
+
 			zap.S().Warnw("bad message type from queue",
 				"msg", msg,
 			)
@@ -233,7 +272,7 @@ func (epl *EmoteProcessingListener) HandleResultEvent(ctx context.Context, evt t
 				},
 			}
 
-			emoteOwner, _ := epl.Ctx.Inst().Loaders.UserByID().Load(eb.Emote.OwnerID)
+			emoteOwner, _ := epl.Ctx.Inst().Loaders.UserByID().Load(ctx, eb.Emote.OwnerID)
 
 			if ver.State.Lifecycle == structures.EmoteLifecycleFailed {
 				fields = append(fields, events.ChangeField{
@@ -297,7 +336,7 @@ func (epl *EmoteProcessingListener) HandleResultEvent(ctx context.Context, evt t
 				}, true)
 			}
 
-			epl.Ctx.Inst().Events.Dispatch(events.EventTypeUpdateEmote, events.ChangeMap{
+			epl.Ctx.Inst().Events.Dispatch(ctx, events.EventTypeUpdateEmote, events.ChangeMap{
 				ID:      eb.Emote.ID,
 				Kind:    structures.ObjectKindEmote,
 				Actor:   epl.Ctx.Inst().Modelizer.User(emoteOwner).ToPartial(),
@@ -0,0 +1,197 @@
+package emotes
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seventv/api/data/model"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/rest/middleware"
+	"github.com/seventv/api/internal/api/rest/rest"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/search"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type emoteSearchRoute struct {
+	Ctx global.Context
+}
+
+func newEmoteSearch(gctx global.Context) rest.Route {
+	return &emoteSearchRoute{gctx}
+}
+
+func (r *emoteSearchRoute) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:      "/search",
+		Method:   rest.GET,
+		Children: []rest.Route{},
+		Middleware: []rest.Middleware{
+			middleware.Auth(r.Ctx, false),
+			middleware.RateLimit(r.Ctx, "SearchEmotes", [2]int64{10, 5}),
+			middleware.SetCacheControl(r.Ctx, 60, []string{"private"}),
+		},
+	}
+}
+
+type emoteSearchResponse struct {
+	Count   int                 `json:"count"`
+	MaxPage int                 `json:"max_page"`
+	Items   []model.EmoteModel  `json:"items"`
+	Facets  *search.EmoteFacets `json:"facets,omitempty"`
+}
+
+// @Summary Search Emotes
+// @Description Search for emotes, optionally with facet counts of the results
+// @Tags emotes
+// @Produce json
+// @Param query query string false "search by emote name / tags"
+// @Param page query int false "the page to return (default 1)"
+// @Param limit query int false "the amount of emotes per page (default 20, max 300)"
+// @Param tags query string false "a comma-separated list of tags to filter by (max 10)"
+// @Param tag_mode query string false "'all' (default) to match emotes with every tag, or 'any'"
+// @Param owner_id query string false "only return emotes owned by this user"
+// @Param created_after query string false "an RFC3339 date or a unix timestamp"
+// @Param created_before query string false "an RFC3339 date or a unix timestamp"
+// @Param animated query bool false "filter by animated or static emotes"
+// @Param zero_width query bool false "filter by zero-width emotes"
+// @Param sort query string false "'popularity' (default) or 'age'"
+// @Param facets query bool false "whether to include facet counts"
+// @Success 200 {object} emoteSearchResponse
+// @Router /emotes/search [get]
+func (r *emoteSearchRoute) Handler(ctx *rest.Ctx) rest.APIError {
+	args := ctx.QueryArgs()
+	cfg := r.Ctx.Config()
+
+	var err error
+
+	page := 1
+	if s := utils.B2S(args.Peek("page")); s != "" {
+		if page, err = strconv.Atoi(s); err != nil || page < 1 {
+			return errors.ErrInvalidRequest().SetDetail("page must be a positive number")
+		}
+	}
+
+	limit := 20
+	if s := utils.B2S(args.Peek("limit")); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > query.EMOTES_QUERY_LIMIT {
+			return errors.ErrInvalidRequest().SetDetail("limit must be between 1 and %d", query.EMOTES_QUERY_LIMIT)
+		}
+	}
+
+	actor, _ := ctx.GetActor()
+
+	if page > cfg.Limits.MaxPage && !actor.HasPermission(structures.RolePermissionEditAnyEmote) {
+		page = cfg.Limits.MaxPage
+	}
+
+	filter := &query.SearchEmotesFilter{}
+
+	if s := utils.B2S(args.Peek("tags")); s != "" {
+		for _, tag := range strings.Split(s, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, strings.ToLower(tag))
+			}
+		}
+
+		if len(filter.Tags) > 10 {
+			return errors.ErrInvalidRequest().SetDetail("Too many tags")
+		}
+	}
+
+	switch strings.ToLower(utils.B2S(args.Peek("tag_mode"))) {
+	case "", "all":
+	case "any":
+		filter.MatchAnyTag = true
+	default:
+		return errors.ErrInvalidRequest().SetDetail("tag_mode must be 'all' or 'any'")
+	}
+
+	if s := utils.B2S(args.Peek("owner_id")); s != "" {
+		if filter.OwnerID, err = primitive.ObjectIDFromHex(s); err != nil {
+			return errors.ErrInvalidRequest().SetDetail("owner_id is not a valid ObjectID")
+		}
+	}
+
+	for key, dst := range map[string]**time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	} {
+		s := utils.B2S(args.Peek(key))
+		if s == "" {
+			continue
+		}
+
+		t, err := parseSearchDate(s)
+		if err != nil {
+			return errors.ErrInvalidRequest().SetDetail("%s must be an RFC3339 date or a unix timestamp", key)
+		}
+
+		*dst = &t
+	}
+
+	for key, dst := range map[string]**bool{
+		"animated":   &filter.Animated,
+		"zero_width": &filter.ZeroWidth,
+	} {
+		if args.Has(key) {
+			*dst = utils.PointerOf(args.GetBool(key))
+		}
+	}
+
+	sortMap := bson.M{}
+
+	switch utils.B2S(args.Peek("sort")) {
+	case "", "popularity":
+		sortMap["channel_count"] = int32(-1)
+	case "age":
+		sortMap["created_at"] = int32(-1)
+	default:
+		return errors.ErrInvalidRequest().SetDetail("sort must be 'popularity' or 'age'")
+	}
+
+	result, err := r.Ctx.Inst().Query.SearchEmotes(ctx, query.SearchEmotesOptions{
+		Actor:  &actor,
+		Query:  utils.B2S(args.Peek("query")),
+		Page:   page,
+		Limit:  limit,
+		Sort:   sortMap,
+		Filter: filter,
+		Facets: args.GetBool("facets"),
+	})
+	if err != nil && !errors.Compare(err, errors.ErrNoItems()) {
+		return errors.From(err)
+	}
+
+	res := emoteSearchResponse{
+		Count:   result.TotalCount,
+		MaxPage: cfg.Limits.MaxPage,
+		Items:   make([]model.EmoteModel, len(result.Items)),
+		Facets:  result.Facets,
+	}
+
+	for i, e := range result.Items {
+		res.Items[i] = r.Ctx.Inst().Modelizer.Emote(e)
+	}
+
+	return ctx.JSON(rest.OK, res)
+}
+
+// parseSearchDate reads a date given as RFC3339 or a unix timestamp
+func parseSearchDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(sec, 0), nil
+}
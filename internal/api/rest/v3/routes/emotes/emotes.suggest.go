@@ -0,0 +1,121 @@
+package emotes
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/seventv/api/data/model"
+	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/api/rest/middleware"
+	"github.com/seventv/api/internal/api/rest/rest"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type emoteSuggestRoute struct {
+	Ctx global.Context
+}
+
+func newEmoteSuggest(gctx global.Context) rest.Route {
+	return &emoteSuggestRoute{gctx}
+}
+
+func (r *emoteSuggestRoute) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:      "/suggest",
+		Method:   rest.GET,
+		Children: []rest.Route{},
+		Middleware: []rest.Middleware{
+			middleware.Auth(r.Ctx, false),
+			middleware.RateLimit(r.Ctx, "SuggestEmotes", [2]int64{30, 5}),
+			middleware.SetCacheControl(r.Ctx, 30, []string{"public"}),
+		},
+	}
+}
+
+// @Summary Suggest Emotes
+// @Description Get emotes whose names complete a query, for autocompletion. Supports conditional requests with ETag
+// @Tags emotes
+// @Produce json
+// @Param q query string true "the partial emote name"
+// @Param limit query int false "the amount of suggestions (default 10, max 25)"
+// @Param active_only query bool false "only suggest emotes from the sets the authenticated user has active"
+// @Success 200 {array} model.EmotePartialModel
+// @Success 304
+// @Router /emotes/suggest [get]
+func (r *emoteSuggestRoute) Handler(ctx *rest.Ctx) rest.APIError {
+	args := ctx.QueryArgs()
+
+	var err error
+
+	limit := 10
+	if s := utils.B2S(args.Peek("limit")); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 1 || limit > query.EMOTE_SUGGESTIONS_LIMIT {
+			return errors.ErrInvalidRequest().SetDetail("limit must be between 1 and %d", query.EMOTE_SUGGESTIONS_LIMIT)
+		}
+	}
+
+	activeOnly := args.GetBool("active_only")
+
+	actor, _ := ctx.GetActor()
+
+	suggestions, err := r.Ctx.Inst().Query.SuggestEmotes(ctx, query.SuggestEmotesOptions{
+		Query:          utils.B2S(args.Peek("q")),
+		Limit:          limit,
+		Actor:          &actor,
+		ActiveSetsOnly: activeOnly,
+	})
+	if err != nil {
+		return errors.From(err)
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(suggestions))
+
+	for _, sg := range suggestions {
+		if id, err := primitive.ObjectIDFromHex(sg.Id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, ids)
+
+	result := make([]model.EmotePartialModel, 0, len(emotes))
+
+	for _, emote := range emotes {
+		if emote.ID.IsZero() {
+			continue
+		}
+
+		result = append(result, r.Ctx.Inst().Modelizer.Emote(emote).ToPartial())
+	}
+
+	b, err := json.Marshal(result)
+	if err != nil {
+		return errors.ErrInternalServerError()
+	}
+
+	// Suggestions scoped to the actor must not be stored by shared caches
+	if activeOnly {
+		ctx.Response.Header.Set("Cache-Control", "max-age=30, private")
+	}
+
+	h := sha1.Sum(b)
+	etag := "\"" + hex.EncodeToString(h[:]) + "\""
+
+	ctx.Response.Header.Set("ETag", etag)
+
+	if utils.B2S(ctx.Request.Header.Peek("If-None-Match")) == etag {
+		ctx.SetStatusCode(rest.NotModified)
+
+		return nil
+	}
+
+	ctx.SetStatusCode(rest.OK)
+	ctx.SetContentType("application/json")
+	ctx.SetBody(b)
+
+	return nil
+}
@@ -67,7 +67,7 @@ func (r *create) Handler(ctx *rest.Ctx) rest.APIError {
 
 	// Set claim (only if UserID empty)
 	if body.Claim != nil && body.UserID.IsZero() {
-		u, _ := r.gctx.Inst().Loaders.UserByConnectionID(body.Claim.Platform).Load(body.Claim.ID)
+		u, _ := r.gctx.Inst().Loaders.UserByConnectionID(body.Claim.Platform).Load(ctx, body.Claim.ID)
 
 		if u.ID.IsZero() {
 			eb.SetClaim(structures.EntitlementClaim{
@@ -0,0 +1,55 @@
+package persisted_queries
+
+import (
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+
+	"github.com/seventv/api/internal/api/rest/middleware"
+	"github.com/seventv/api/internal/api/rest/rest"
+	"github.com/seventv/api/internal/global"
+)
+
+// The persisted query registry: manifests of the operations sent by first-party clients
+type persistedQueriesRoute struct {
+	gctx global.Context
+}
+
+func New(gctx global.Context) rest.Route {
+	return &persistedQueriesRoute{gctx}
+}
+
+func (r *persistedQueriesRoute) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:    "/persisted-queries",
+		Method: rest.GET,
+		Children: []rest.Route{
+			newPublish(r.gctx),
+			newRemove(r.gctx),
+		},
+		Middleware: []rest.Middleware{
+			middleware.Auth(r.gctx, true, structures.RolePermissionManageStack),
+		},
+	}
+}
+
+// @Summary List Persisted Query Manifests
+// @Description Lists the clients with an uploaded manifest and the amount of operations in each
+// @Tags persisted-queries
+// @Produce json
+// @Success 200 {object} map[string]int
+// @Router /persisted-queries [get]
+func (r *persistedQueriesRoute) Handler(ctx *rest.Ctx) rest.APIError {
+	actor, ok := ctx.GetActor()
+	if !ok || !actor.HasPermission(structures.RolePermissionManageStack) {
+		return errors.ErrInsufficientPrivilege()
+	}
+
+	clients, err := r.gctx.Inst().Persisted.Clients(ctx)
+	if err != nil {
+		ctx.Log().Errorw("failed to list persisted query manifests", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	return ctx.JSON(rest.OK, clients)
+}
@@ -0,0 +1,122 @@
+package persisted_queries
+
+import (
+	"encoding/json"
+
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+
+	"github.com/seventv/api/internal/api/rest/middleware"
+	"github.com/seventv/api/internal/api/rest/rest"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/persisted"
+)
+
+type publish struct {
+	gctx global.Context
+}
+
+func newPublish(gctx global.Context) rest.Route {
+	return &publish{gctx}
+}
+
+func (r *publish) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:    "/{client}",
+		Method: rest.PUT,
+		Middleware: []rest.Middleware{
+			middleware.Auth(r.gctx, true, structures.RolePermissionManageStack),
+		},
+	}
+}
+
+// @Summary Publish Persisted Query Manifest
+// @Description Replaces the manifest of a client, such as "website" or "extension"
+// @Tags persisted-queries
+// @Accept json
+// @Produce json
+// @Param client path string true "name of the client"
+// @Success 200 {object} publishResponse
+// @Router /persisted-queries/{client} [put]
+func (r *publish) Handler(ctx *rest.Ctx) rest.APIError {
+	actor, ok := ctx.GetActor()
+	if !ok || !actor.HasPermission(structures.RolePermissionManageStack) {
+		return errors.ErrInsufficientPrivilege()
+	}
+
+	client, _ := ctx.UserValue("client").String()
+
+	var m persisted.Manifest
+	if err := json.Unmarshal(ctx.Request.Body(), &m); err != nil {
+		return errors.ErrInvalidRequest().SetDetail("Invalid manifest")
+	}
+
+	if err := m.Validate(); err != nil {
+		return errors.ErrInvalidRequest().SetDetail(err.Error())
+	}
+
+	if err := r.gctx.Inst().Persisted.Publish(ctx, client, m); err != nil {
+		ctx.Log().Errorw("failed to publish persisted query manifest", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	ctx.Log().Infow("persisted query manifest published",
+		"client", client,
+		"operations", len(m.Operations),
+		"actor_id", actor.ID.Hex(),
+	)
+
+	return ctx.JSON(rest.OK, publishResponse{
+		Client:     client,
+		Operations: len(m.Operations),
+	})
+}
+
+type publishResponse struct {
+	Client     string `json:"client"`
+	Operations int    `json:"operations"`
+}
+
+type remove struct {
+	gctx global.Context
+}
+
+func newRemove(gctx global.Context) rest.Route {
+	return &remove{gctx}
+}
+
+func (r *remove) Config() rest.RouteConfig {
+	return rest.RouteConfig{
+		URI:    "/{client}",
+		Method: rest.DELETE,
+		Middleware: []rest.Middleware{
+			middleware.Auth(r.gctx, true, structures.RolePermissionManageStack),
+		},
+	}
+}
+
+// @Summary Remove Persisted Query Manifest
+// @Description Removes the manifest of a client. Its operations are no longer allowed in enforcement mode
+// @Tags persisted-queries
+// @Param client path string true "name of the client"
+// @Success 204
+// @Router /persisted-queries/{client} [delete]
+func (r *remove) Handler(ctx *rest.Ctx) rest.APIError {
+	actor, ok := ctx.GetActor()
+	if !ok || !actor.HasPermission(structures.RolePermissionManageStack) {
+		return errors.ErrInsufficientPrivilege()
+	}
+
+	client, _ := ctx.UserValue("client").String()
+
+	if err := r.gctx.Inst().Persisted.Remove(ctx, client); err != nil {
+		ctx.Log().Errorw("failed to remove persisted query manifest", "error", err)
+
+		return errors.ErrInternalServerError()
+	}
+
+	ctx.SetStatusCode(rest.NoContent)
+
+	return nil
+}
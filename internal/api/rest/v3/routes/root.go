@@ -6,12 +6,14 @@ import (
 
 	"github.com/seventv/api/internal/api/rest/middleware"
 	"github.com/seventv/api/internal/api/rest/rest"
+	audit_logs "github.com/seventv/api/internal/api/rest/v3/routes/audit-logs"
 	"github.com/seventv/api/internal/api/rest/v3/routes/auth"
 	"github.com/seventv/api/internal/api/rest/v3/routes/config"
 	"github.com/seventv/api/internal/api/rest/v3/routes/docs"
 	emote_sets "github.com/seventv/api/internal/api/rest/v3/routes/emote-sets"
 	"github.com/seventv/api/internal/api/rest/v3/routes/emotes"
 	"github.com/seventv/api/internal/api/rest/v3/routes/entitlements"
+	persisted_queries "github.com/seventv/api/internal/api/rest/v3/routes/persisted-queries"
 	"github.com/seventv/api/internal/api/rest/v3/routes/users"
 	"github.com/seventv/api/internal/global"
 )
@@ -38,6 +40,8 @@ func (r *Route) Config() rest.RouteConfig {
 			emote_sets.New(r.Ctx),
 			users.New(r.Ctx),
 			entitlements.New(r.Ctx),
+			persisted_queries.New(r.Ctx),
+			audit_logs.New(r.Ctx),
 		},
 		Middleware: []rest.Middleware{
 			middleware.SetCacheControl(r.Ctx, 30, nil),
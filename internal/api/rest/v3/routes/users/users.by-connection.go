@@ -59,7 +59,7 @@ func (r *userConnectionRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	}
 
 	// Fetch user data
-	user, err := r.Ctx.Inst().Loaders.UserByConnectionID(platform).Load(connID)
+	user, err := r.Ctx.Inst().Loaders.UserByConnectionID(platform).Load(ctx, connID)
 	if err != nil {
 		return errors.From(err)
 	}
@@ -75,7 +75,7 @@ func (r *userConnectionRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	var emoteSetModel model.EmoteSetModel
 
 	if !uc.EmoteSetID.IsZero() {
-		set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(uc.EmoteSetID)
+		set, err := r.Ctx.Inst().Loaders.EmoteSetByID().Load(ctx, uc.EmoteSetID)
 		if err != nil && !errors.Compare(err, errors.ErrUnknownEmoteSet()) {
 			return errors.From(err)
 		}
@@ -84,7 +84,7 @@ func (r *userConnectionRoute) Handler(ctx *rest.Ctx) rest.APIError {
 			return a.ID
 		})
 
-		emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(emoteIDs)
+		emotes, _ := r.Ctx.Inst().Loaders.EmoteByID().LoadAll(ctx, emoteIDs)
 
 		emoteMap := map[primitive.ObjectID]structures.Emote{}
 
@@ -96,7 +96,7 @@ func (r *userConnectionRoute) Handler(ctx *rest.Ctx) rest.APIError {
 			emoteMap[emote.ID] = emote
 		}
 
-		setOwner, _ := r.Ctx.Inst().Loaders.UserByID().Load(set.OwnerID)
+		setOwner, _ := r.Ctx.Inst().Loaders.UserByID().Load(ctx, set.OwnerID)
 		if !setOwner.ID.IsZero() {
 			set.Owner = &setOwner
 		}
@@ -124,7 +124,7 @@ func (r *userConnectionRoute) Handler(ctx *rest.Ctx) rest.APIError {
 
 	// Construct the final response structure
 	userModel := r.Ctx.Inst().Modelizer.User(user)
-	userModel.EmoteSets = userWithEntitledEmoteSets(r.Ctx, user)
+	userModel.EmoteSets = userWithEntitledEmoteSets(ctx, r.Ctx, user)
 
 	userConnModel := r.Ctx.Inst().Modelizer.UserConnection(uc)
 	userConnModel.User = &userModel
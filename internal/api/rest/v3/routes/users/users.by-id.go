@@ -1,6 +1,7 @@
 package users
 
 import (
+	"context"
 	"sync"
 
 	"github.com/hashicorp/go-multierror"
@@ -47,7 +48,7 @@ func (r *userRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		return errors.From(err)
 	}
 
-	user, err := r.Ctx.Inst().Loaders.UserByID().Load(userID)
+	user, err := r.Ctx.Inst().Loaders.UserByID().Load(ctx, userID)
 	if err != nil {
 		return errors.From(err)
 	}
@@ -64,7 +65,7 @@ func (r *userRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	go func() {
 		defer wg.Done()
 
-		entitledSetIDs = utils.Map(userWithEntitledEmoteSets(r.Ctx, user), func(x model.EmoteSetPartialModel) primitive.ObjectID {
+		entitledSetIDs = utils.Map(userWithEntitledEmoteSets(ctx, r.Ctx, user), func(x model.EmoteSetPartialModel) primitive.ObjectID {
 			return x.ID
 		})
 	}()
@@ -72,7 +73,7 @@ func (r *userRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	go func() {
 		defer wg.Done()
 
-		sets, err = r.Ctx.Inst().Loaders.EmoteSetByUserID().Load(user.ID)
+		sets, err = r.Ctx.Inst().Loaders.EmoteSetByUserID().Load(ctx, user.ID)
 		if err != nil {
 			zap.S().Errorw("failed to load emote sets of user", "error", err)
 		}
@@ -98,8 +99,8 @@ func (r *userRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	return ctx.JSON(rest.OK, result)
 }
 
-func userWithEntitledEmoteSets(gctx global.Context, user structures.User) []model.EmoteSetPartialModel {
-	ents, err := gctx.Inst().Loaders.EntitlementsLoader().Load(user.ID)
+func userWithEntitledEmoteSets(ctx context.Context, gctx global.Context, user structures.User) []model.EmoteSetPartialModel {
+	ents, err := gctx.Inst().Loaders.EntitlementsLoader().Load(ctx, user.ID)
 	if err != nil {
 		return nil
 	}
@@ -114,7 +115,7 @@ func userWithEntitledEmoteSets(gctx global.Context, user structures.User) []mode
 
 	result := make([]model.EmoteSetPartialModel, len(ents.EmoteSets))
 
-	sets, errs := gctx.Inst().Loaders.EmoteSetByID().LoadAll(setIDs)
+	sets, errs := gctx.Inst().Loaders.EmoteSetByID().LoadAll(ctx, setIDs)
 	if err = multierror.Append(nil, errs...).ErrorOrNil(); err != nil {
 		return nil
 	}
@@ -25,7 +25,7 @@ func (r *userDeleteRoute) Config() rest.RouteConfig {
 		URI:    "/{user.id}",
 		Method: rest.DELETE,
 		Middleware: []rest.Middleware{
-			middleware.Auth(r.gctx, true),
+			middleware.Auth(r.gctx, true, structures.RolePermissionManageUsers),
 		},
 	}
 }
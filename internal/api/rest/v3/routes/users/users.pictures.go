@@ -62,6 +62,8 @@ func (r *pictureUploadRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	done := r.Ctx.Inst().Limiter.AwaitMutation(ctx)
 	defer done()
 
+	cfg := r.Ctx.Config()
+
 	actor, ok := ctx.GetActor()
 	if !ok {
 		return errors.ErrUnauthorized()
@@ -82,7 +84,7 @@ func (r *pictureUploadRoute) Handler(ctx *rest.Ctx) rest.APIError {
 			return errors.From(err)
 		}
 
-		victim, err = r.Ctx.Inst().Loaders.UserByID().Load(oid)
+		victim, err = r.Ctx.Inst().Loaders.UserByID().Load(ctx, oid)
 		if err != nil {
 			return errors.From(err)
 		}
@@ -129,7 +131,7 @@ func (r *pictureUploadRoute) Handler(ctx *rest.Ctx) rest.APIError {
 			Body:         aws.ReadSeekCloser(bytes.NewReader(body)),
 			Key:          aws.String(rawFilekey),
 			ACL:          s3.AclPrivate,
-			Bucket:       aws.String(r.Ctx.Config().S3.InternalBucket),
+			Bucket:       aws.String(cfg.S3.InternalBucket),
 			ContentType:  aws.String(fileType.MIME.Value),
 			CacheControl: s3.DefaultCacheControl,
 		},
@@ -146,19 +148,19 @@ func (r *pictureUploadRoute) Handler(ctx *rest.Ctx) rest.APIError {
 	victimIDBytes, _ := json.Marshal(pictureTaskMetadata{
 		UserID:          victim.ID,
 		InputFileKey:    rawFilekey,
-		InputFileBucket: r.Ctx.Config().S3.InternalBucket,
+		InputFileBucket: cfg.S3.InternalBucket,
 	})
 
 	taskData, err := json.Marshal(task.Task{
 		ID:    id.Hex(),
 		Flags: utils.Ternary(allowAnim, task.TaskFlagWEBP|task.TaskFlagAVIF|task.TaskFlagGIF, 0) | task.TaskFlagWEBP_STATIC | task.TaskFlagAVIF_STATIC | task.TaskFlagPNG | task.TaskFlagPNG_STATIC,
 		Input: task.TaskInput{
-			Bucket: r.Ctx.Config().S3.InternalBucket,
+			Bucket: cfg.S3.InternalBucket,
 			Key:    rawFilekey,
 		},
 		Output: task.TaskOutput{
 			Prefix:       r.Ctx.Inst().S3.ComposeKey("user", victim.ID.Hex(), fmt.Sprintf("av_%s", id.Hex())),
-			Bucket:       r.Ctx.Config().S3.PublicBucket,
+			Bucket:       cfg.S3.PublicBucket,
 			CacheControl: *s3.DefaultCacheControl,
 			ACL:          *s3.AclPublicRead,
 		},
@@ -167,21 +169,21 @@ func (r *pictureUploadRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		Scales:            []int{1, 2, 3},
 		ResizeRatio:       task.ResizeRatioPaddingCenter,
 		Limits: task.TaskLimits{
-			MaxProcessingTime: time.Duration(r.Ctx.Config().Limits.Emotes.MaxProcessingTimeSeconds) * time.Second,
+			MaxProcessingTime: time.Duration(cfg.Limits.Emotes.MaxProcessingTimeSeconds) * time.Second,
 			MaxFrameCount:     500,
-			MaxWidth:          r.Ctx.Config().Limits.Emotes.MaxWidth,
-			MaxHeight:         r.Ctx.Config().Limits.Emotes.MaxHeight,
+			MaxWidth:          cfg.Limits.Emotes.MaxWidth,
+			MaxHeight:         cfg.Limits.Emotes.MaxHeight,
 		},
 		Metadata: victimIDBytes,
 	})
 	if err == nil {
 		err = r.Ctx.Inst().MessageQueue.Publish(ctx, messagequeue.OutgoingMessage{
-			Queue:   r.Ctx.Config().MessageQueue.ImageProcessorJobsQueueName,
+			Queue:   cfg.MessageQueue.ImageProcessorJobsQueueName,
 			Headers: map[string]string{},
 			Flags: messagequeue.MessageFlags{
 				ID:          id.Hex(),
 				ContentType: "application/json",
-				ReplyTo:     r.Ctx.Config().MessageQueue.ImageProcessorUserPicturesResultsQueueName,
+				ReplyTo:     cfg.MessageQueue.ImageProcessorUserPicturesResultsQueueName,
 				Timestamp:   time.Now(),
 				RMQ: messagequeue.MessageFlagsRMQ{
 					DeliveryMode: messagequeue.RMQDeliveryModePersistent,
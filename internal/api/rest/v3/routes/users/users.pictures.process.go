@@ -34,8 +34,21 @@ func (ppl *PictureProcessingListener) Listen() {
 		return
 	}
 
+	// Consuming stops when the process begins draining
+	subCtx, cancel := context.WithCancel(ppl.Ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-ppl.Ctx.Inst().Lifecycle.Draining():
+		case <-subCtx.Done():
+		}
+
+		cancel()
+	}()
+
 	// Results queue
-	messages, err := mq.Subscribe(ppl.Ctx, messagequeue.Subscription{
+	messages, err := mq.Subscribe(subCtx, messagequeue.Subscription{
 		Queue: ppl.Ctx.Config().MessageQueue.ImageProcessorUserPicturesResultsQueueName,
 		SQS: messagequeue.SubscriptionSQS{
 			WaitTimeSeconds: 10,
@@ -48,16 +61,36 @@ func (ppl *PictureProcessingListener) Listen() {
 	evt := task.Result{}
 
 	for msg := range messages {
+		// Shutdown waits for the messages being handled, and those received after draining began are
+		// returned to the queue for another pod to handle
+		done, ok := ppl.Ctx.Inst().Lifecycle.Track()
+		if !ok {
+			if err := msg.Requeue(context.Background()); err != nil && msg.Error() == nil {
+				zap.S().Errorw("failed to requeue message",
+					"error", err,
+				)
+			}
+
+			continue
+		}
+
 		if msg.Headers().ContentType() == "application/json" {
 			if err := json.Unmarshal(msg.Body(), &evt); err != nil {
 				zap.S().Errorw("bad message type from queue",
 					"msg", msg,
 				)
 
+				done()
+
 				continue
 			}
 
-			go func(msg *messagequeue.IncomingMessage) {
+			// The result is passed by value, as the next message is decoded into evt while this one is handled
+			go func(msg *messagequeue.IncomingMessage, evt task.Result) {
+				defer done()
+
+				lag := time.Since(evt.FinishedAt)
+
 				tick := time.NewTicker(time.Second * 10)
 				ctx, cancel := context.WithCancel(ppl.Ctx)
 
@@ -77,7 +110,11 @@ func (ppl *PictureProcessingListener) Listen() {
 					}
 				}()
 
-				if err := ppl.HandleResultEvent(ctx, evt); err != nil {
+				err := ppl.HandleResultEvent(ctx, evt)
+
+				ppl.Ctx.Inst().Prometheus.ObserveImageResult("user_picture", evt.State.String(), err == nil, lag, evt.FinishedAt.Sub(evt.StartedAt))
+
+				if err != nil {
 					zap.S().Errorw("failed to handle result",
 						"error", multierr.Append(err, msg.Nack(context.Background())),
 					)
@@ -88,8 +125,10 @@ func (ppl *PictureProcessingListener) Listen() {
 						)
 					}
 				}
-			}(msg)
+			}(msg, evt)
 		} else {
+			done()
+
 			zap.S().Warnw("bad message type from queue",
 				"msg", msg,
 			)
@@ -125,7 +164,7 @@ func (ppl *PictureProcessingListener) HandleResultEvent(ctx context.Context, evt
 
 	// Find the user that triggered this job
 	// Fetch the full data about the actor
-	actor, err := ppl.Ctx.Inst().Loaders.UserByID().Load(metadata.UserID)
+	actor, err := ppl.Ctx.Inst().Loaders.UserByID().Load(ctx, metadata.UserID)
 	if err != nil {
 		l.Errorw("failed to fetch actor")
 		return err
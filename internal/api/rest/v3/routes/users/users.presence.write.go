@@ -7,6 +7,7 @@ import (
 	"github.com/seventv/api/data/model"
 	"github.com/seventv/api/internal/api/rest/rest"
 	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/flags"
 	"github.com/seventv/api/internal/svc/presences"
 	"github.com/seventv/common/errors"
 	"github.com/seventv/common/structures/v3"
@@ -39,7 +40,14 @@ func (r *userPresenceWriteRoute) Config() rest.RouteConfig {
 // @Success 200 {object} model.PresenceModel
 // @Router /users/{user.id}/presences [post]
 func (r *userPresenceWriteRoute) Handler(ctx *rest.Ctx) rest.APIError {
-	if r.gctx.Config().Http.DisablePresences {
+	actor, ok := ctx.GetActor()
+
+	var flagUser *structures.User
+	if ok {
+		flagUser = &actor
+	}
+
+	if r.gctx.Config().Http.DisablePresences || !r.gctx.Inst().Flags.Enabled(flags.FlagPresences, flagUser, true) {
 		return errors.ErrNothingHappened().SetDetail("Presences are currently disabled")
 	}
 
@@ -50,8 +58,6 @@ func (r *userPresenceWriteRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		return errors.From(err)
 	}
 
-	actor, ok := ctx.GetActor()
-
 	authentic := ok && actor.ID == userID
 
 	if err := json.Unmarshal(ctx.Request.Body(), &body); err != nil {
@@ -75,7 +81,7 @@ func (r *userPresenceWriteRoute) Handler(ctx *rest.Ctx) rest.APIError {
 		}
 
 		var known bool
-		if user, err := r.gctx.Inst().Loaders.UserByConnectionID(pd.Platform).Load(pd.ID); err == nil && !user.ID.IsZero() {
+		if user, err := r.gctx.Inst().Loaders.UserByConnectionID(pd.Platform).Load(ctx, pd.ID); err == nil && !user.ID.IsZero() {
 			known = true
 		}
 
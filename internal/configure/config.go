@@ -23,6 +23,35 @@ func checkErr(err error) {
 func New() *Config {
 	initLogging("info")
 
+	pflag.String("config", "config.yaml", "Config file location")
+	pflag.Bool("noheader", false, "Disable the startup header")
+	pflag.Bool("dry-run", false, "Report what an admin command would change without changing it")
+	pflag.String("report", "", "Write the JSON report of an admin command to this file, or - for stdout")
+
+	pflag.Parse()
+
+	config := read()
+
+	// File
+	if err := config.ReadInConfig(); err == nil {
+		checkErr(config.MergeInConfig())
+	}
+
+	// Print final config
+	c := &Config{}
+	checkErr(config.Unmarshal(&c))
+
+	initLogging(c.Level)
+
+	// Arguments left after the flags select an admin command instead of starting the servers
+	c.Args = pflag.Args()
+
+	return c
+}
+
+// read sets up the sources of the config: the defaults, the flags, the config file and the environment.
+// The config file is not read yet
+func read() *viper.Viper {
 	config := viper.New()
 
 	// Default config
@@ -36,20 +65,11 @@ func New() *Config {
 	checkErr(tmp.ReadConfig(defaultConfig))
 	checkErr(config.MergeConfigMap(viper.AllSettings()))
 
-	pflag.String("config", "config.yaml", "Config file location")
-	pflag.Bool("noheader", false, "Disable the startup header")
-
-	pflag.Parse()
 	checkErr(config.BindPFlags(pflag.CommandLine))
 
-	// File
 	config.SetConfigFile(config.GetString("config"))
 	config.AddConfigPath(".")
 
-	if err := config.ReadInConfig(); err == nil {
-		checkErr(config.MergeInConfig())
-	}
-
 	bindEnvs(config, Config{})
 
 	// Environment
@@ -58,13 +78,7 @@ func New() *Config {
 	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	config.AllowEmptyEnv(true)
 
-	// Print final config
-	c := &Config{}
-	checkErr(config.Unmarshal(&c))
-
-	initLogging(c.Level)
-
-	return c
+	return config
 }
 
 func bindEnvs(config *viper.Viper, iface interface{}, parts ...string) {
@@ -118,14 +132,26 @@ const (
 	MessageQueueModeSQS = "SQS"
 )
 
+type PersistedQueriesMode string
+
+const (
+	PersistedQueriesModeReport  PersistedQueriesMode = "report"
+	PersistedQueriesModeEnforce PersistedQueriesMode = "enforce"
+)
+
 type Config struct {
 	Level         string `mapstructure:"level" json:"level"`
 	ConfigFile    string `mapstructure:"config" json:"config"`
 	NoHeader      bool   `mapstructure:"noheader" json:"noheader"`
+	DryRun        bool   `mapstructure:"dry-run" json:"dry-run"`
+	Report        string `mapstructure:"report" json:"report"`
 	WebsiteURL    string `mapstructure:"website_url" json:"website_url"`
 	OldWebsiteURL string `mapstructure:"website_old_url" json:"website_old_url"`
 	CdnURL        string `mapstructure:"cdn_url" json:"cdn_url"`
 
+	// The admin command and its arguments, if any
+	Args []string `json:"-"`
+
 	K8S struct {
 		NodeName string `mapstructure:"node_name" json:"node_name"`
 		PodName  string `mapstructure:"pod_name" json:"pod_name"`
@@ -165,6 +191,14 @@ type Config struct {
 		Bind    string `mapstructure:"bind" json:"bind"`
 	} `mapstructure:"health" json:"health"`
 
+	Shutdown struct {
+		// For how long (in seconds) the servers keep serving after a SIGTERM while reporting as not ready,
+		// so that load balancers stop routing to the pod before it stops accepting connections
+		DrainPeriod int `mapstructure:"drain_period" json:"drain_period"`
+		// For how long (in seconds) shutdown may take in total before the process is killed
+		Timeout int `mapstructure:"timeout" json:"timeout"`
+	} `mapstructure:"shutdown" json:"shutdown"`
+
 	PProf struct {
 		Enabled bool   `mapstructure:"enabled" json:"enabled"`
 		Bind    string `mapstructure:"bind" json:"bind"`
@@ -176,6 +210,18 @@ type Config struct {
 		Labels  Labels `mapstructure:"labels" json:"labels"`
 	} `mapstructure:"monitoring" json:"monitoring"`
 
+	Tracing struct {
+		Enabled bool `mapstructure:"enabled" json:"enabled"`
+		// The base URL of an OTLP/HTTP collector, i.e http://otel-collector:4318
+		Endpoint string `mapstructure:"endpoint" json:"endpoint"`
+		// Headers sent with exports, such as credentials for the collector
+		Headers map[string]string `mapstructure:"headers" json:"headers"`
+		// The fraction of traces which are sampled, from 0 to 1. Defaults to 0.01
+		SampleRate float64 `mapstructure:"sample_rate" json:"sample_rate"`
+		// GraphQL resolvers which take less time than this, in milliseconds, are not traced
+		FieldThreshold int `mapstructure:"field_threshold" json:"field_threshold"`
+	} `mapstructure:"tracing" json:"tracing"`
+
 	EventBridge struct {
 		Enabled bool   `mapstructure:"enabled" json:"enabled"`
 		Bind    string `mapstructure:"bind" json:"bind"`
@@ -254,6 +300,24 @@ type Config struct {
 			ImageProcessing [2]int64 `mapstructure:"image_processing" json:"image_processing"`
 		} `mapstructure:"buckets" json:"buckets"`
 
+		// The cost of GraphQL operations, which is also charged to the gql_v3 bucket
+		Complexity struct {
+			// The highest cost of an operation
+			Default int `mapstructure:"default" json:"default"`
+			// Higher limits for users with a role, by role ID
+			Roles map[string]int `mapstructure:"roles" json:"roles"`
+		} `mapstructure:"complexity" json:"complexity"`
+
+		// The registry of operations sent by first-party clients
+		PersistedQueries struct {
+			// Empty to disable, "report" to log unknown operations or "enforce" to reject them
+			Mode PersistedQueriesMode `mapstructure:"mode" json:"mode"`
+			// Paths of manifests loaded on startup, in addition to those uploaded through the API
+			Manifests []string `mapstructure:"manifests" json:"manifests"`
+			// Users with one of these roles may run operations which are not in the registry
+			TrustedRoles []string `mapstructure:"trusted_roles" json:"trusted_roles"`
+		} `mapstructure:"persisted_queries" json:"persisted_queries"`
+
 		Quota struct {
 			DefaultLimit         int32 `mapstructure:"default_limit" json:"default_limit"`
 			MaxBadQueries        int64 `mapstructure:"max_bad_queries" json:"max_bad_queries"`
@@ -267,7 +331,14 @@ type Config struct {
 			MaxFrameCount            int      `mapstructure:"max_frame_count" json:"max_frame_count"`
 			MaxTags                  int      `mapstructure:"max_tags" json:"max_tags"`
 			ReservedTags             []string `mapstructure:"reserved_tags" json:"reserved_tags"`
+			// The emote set listed by the FEATURED search category
+			FeaturedSetID string `mapstructure:"featured_set_id" json:"featured_set_id"`
 		} `mapstructure:"emotes" json:"emotes"`
+
+		EmoteSets struct {
+			// The number of emotes in sets created through the API, 300 unless configured otherwise
+			Capacity int32 `mapstructure:"capacity" json:"capacity"`
+		} `mapstructure:"emote_sets" json:"emote_sets"`
 	} `mapstructure:"limits" json:"limits"`
 
 	MessageQueue struct {
@@ -303,7 +374,30 @@ type Config struct {
 
 	Credentials struct {
 		JWTSecret string `mapstructure:"jwt_secret" json:"jwt_secret"`
+		// Tokens issued before sessions were tracked carry no session, and cannot be revoked on their own.
+		// They are accepted until this date (RFC 3339), or for as long as they are valid if it is empty
+		SessionlessTokensUntil string `mapstructure:"sessionless_tokens_until" json:"sessionless_tokens_until"`
 	} `mapstructure:"credentials" json:"credentials"`
+
+	SecondFactor struct {
+		// The issuer name shown in authenticator apps
+		Issuer string `mapstructure:"issuer" json:"issuer"`
+		// Permission bits which require a recent second-factor check to be used
+		Permissions int64 `mapstructure:"permissions" json:"permissions"`
+		// For how long (in seconds) a second-factor check remains valid
+		MaxAge int `mapstructure:"max_age" json:"max_age"`
+	} `mapstructure:"second_factor" json:"second_factor"`
+
+	ModRequests struct {
+		// For how long (in seconds) a moderator's claim on a mod request locks it
+		ClaimTimeout int `mapstructure:"claim_timeout" json:"claim_timeout"`
+
+		Cleanup struct {
+			Enabled bool `mapstructure:"enabled" json:"enabled"`
+			// How often (in seconds) void mod requests and expired claims are cleaned up
+			Interval int `mapstructure:"interval" json:"interval"`
+		} `mapstructure:"cleanup" json:"cleanup"`
+	} `mapstructure:"mod_requests" json:"mod_requests"`
 }
 
 type PlatformConfig struct {
@@ -0,0 +1,137 @@
+package configure
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// WATCH_DEBOUNCE is how long the config file must stay unchanged before it is reloaded,
+// so that a file written in several steps is only reloaded once
+const WATCH_DEBOUNCE = time.Second
+
+// Reload reads the config file again and returns a copy of the config where the settings which are safe to
+// change while the API is running are replaced: limits, the quota of active mod requests, reserved tags and the Chatterino versions and downloads.
+// Other settings only apply after a restart. It also returns the keys of the settings which changed.
+//
+// The config is not changed, and an error is returned, when the file cannot be read or the new settings are invalid
+func (c *Config) Reload() (*Config, []string, error) {
+	v := read()
+	if err := v.ReadInConfig(); err != nil {
+		return nil, nil, err
+	}
+
+	next := &Config{}
+	if err := v.Unmarshal(&next); err != nil {
+		return nil, nil, err
+	}
+
+	reloaded := *c
+	changed := []string{}
+
+	apply := func(key string, dst any, src any) {
+		d := reflect.ValueOf(dst).Elem()
+		if reflect.DeepEqual(d.Interface(), src) {
+			return
+		}
+
+		d.Set(reflect.ValueOf(src))
+		changed = append(changed, key)
+	}
+
+	apply("limits.max_page", &reloaded.Limits.MaxPage, next.Limits.MaxPage)
+	apply("limits.buckets", &reloaded.Limits.Buckets, next.Limits.Buckets)
+	apply("limits.complexity", &reloaded.Limits.Complexity, next.Limits.Complexity)
+	apply("limits.quota.max_active_mod_requests", &reloaded.Limits.Quota.MaxActiveModRequests, next.Limits.Quota.MaxActiveModRequests)
+	apply("limits.emotes", &reloaded.Limits.Emotes, next.Limits.Emotes)
+	apply("limits.emote_sets", &reloaded.Limits.EmoteSets, next.Limits.EmoteSets)
+	apply("chatterino", &reloaded.Chatterino, next.Chatterino)
+
+	// Problems of the settings which are not reloaded are already known, and must not prevent a reload
+	known := map[string]bool{}
+	for _, p := range c.Validate() {
+		known[p] = true
+	}
+
+	problems := []string{}
+
+	for _, p := range reloaded.Validate() {
+		if !known[p] {
+			problems = append(problems, p)
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+
+	return &reloaded, changed, nil
+}
+
+// Watch calls onChange when the config file at path is written or replaced, until the context is canceled.
+// The directory of the file is watched, so that a Kubernetes ConfigMap, which is updated by swapping a symlink, is also picked up
+func Watch(ctx context.Context, path string, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Clean(path)
+	dir, _ := filepath.Split(file)
+	realFile, _ := filepath.EvalSymlinks(file)
+
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var pending <-chan time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-pending:
+				pending = nil
+
+				onChange()
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				currentFile, _ := filepath.EvalSymlinks(file)
+
+				if (filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0) ||
+					(currentFile != "" && currentFile != realFile) {
+					realFile = currentFile
+					pending = time.After(WATCH_DEBOUNCE)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				zap.S().Warnw("config, error while watching the config file",
+					"error", err,
+				)
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package configure
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/seventv/api/internal/testutil"
+)
+
+// useConfigFile points the config flag to a file in a temporary directory, returning a function to write it
+func useConfigFile(t *testing.T) func(content string) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if pflag.Lookup("config") == nil {
+		pflag.String("config", "config.yaml", "Config file location")
+	}
+
+	testutil.IsNil(t, pflag.Set("config", path), "the config flag is set")
+
+	return func(content string) {
+		testutil.IsNil(t, os.WriteFile(path, []byte(content), 0o600), "the config file is written")
+	}
+}
+
+func TestReload(t *testing.T) {
+	write := useConfigFile(t)
+
+	current := &Config{}
+	current.Limits.MaxPage = 100
+	current.Limits.EmoteSets.Capacity = 300
+	current.Http.Addr = "0.0.0.0"
+
+	write("http:\n  addr: 127.0.0.1\nlimits:\n  max_page: 50\n  emote_sets:\n    capacity: 600\n")
+
+	next, changed, err := current.Reload()
+	testutil.IsNil(t, err, "a valid file is reloaded")
+	testutil.Assert(t, 50, next.Limits.MaxPage, "a reloadable setting is replaced")
+	testutil.Assert(t, int32(600), next.Limits.EmoteSets.Capacity, "a reloadable setting is replaced")
+	testutil.Assert(t, "0.0.0.0", next.Http.Addr, "a setting which needs a restart is kept")
+	testutil.Assert(t, "limits.max_page,limits.emote_sets", strings.Join(changed, ","), "the changed settings are listed")
+	testutil.Assert(t, 100, current.Limits.MaxPage, "the current config is left as is")
+}
+
+func TestReloadInvalid(t *testing.T) {
+	write := useConfigFile(t)
+
+	current := &Config{}
+	current.Limits.MaxPage = 100
+
+	// A problem of a setting which is not reloaded does not prevent the reload
+	testutil.Assert(t, true, len(current.Validate()) > 0, "the current config has known problems")
+
+	write("limits:\n  max_page: -1\n")
+
+	next, changed, err := current.Reload()
+	testutil.IsNotNil(t, err, "a file with an invalid setting is rejected")
+	testutil.Assert(t, true, strings.Contains(err.Error(), "limits.max_page"), "the error names the invalid setting")
+	testutil.Assert(t, true, next == nil, "no config is returned for an invalid file")
+	testutil.Assert(t, 0, len(changed), "nothing changed")
+	testutil.Assert(t, 100, current.Limits.MaxPage, "the current config is left as is")
+
+	write("limits: [\n")
+
+	next, _, err = current.Reload()
+	testutil.IsNotNil(t, err, "a file which cannot be parsed is rejected")
+	testutil.Assert(t, true, next == nil, "no config is returned for a malformed file")
+	testutil.Assert(t, 100, current.Limits.MaxPage, "the current config is left as is")
+}
@@ -0,0 +1,193 @@
+package configure
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Validate returns the problems of the config which would prevent the API from running as intended
+func (c *Config) Validate() []string {
+	problems := []string{}
+
+	add := func(key string, format string, args ...any) {
+		problems = append(problems, key+": "+fmt.Sprintf(format, args...))
+	}
+
+	required := map[string]string{
+		"mongo.uri":              c.Mongo.URI,
+		"mongo.db":               c.Mongo.DB,
+		"nats.url":               c.Nats.Url,
+		"nats.subject":           c.Nats.Subject,
+		"meilisearch.host":       c.Meilisearch.Host,
+		"meilisearch.index":      c.Meilisearch.Index,
+		"credentials.jwt_secret": c.Credentials.JWTSecret,
+		"website_url":            c.WebsiteURL,
+		"cdn_url":                c.CdnURL,
+		"http.addr":              c.Http.Addr,
+		"platforms.discord.api":  c.Platforms.Discord.API,
+	}
+
+	for key, v := range required {
+		if v == "" {
+			add(key, "is required")
+		}
+	}
+
+	if len(c.Redis.Addresses) == 0 {
+		add("redis.addresses", "at least one address is required")
+	}
+
+	if c.Redis.Sentinel && c.Redis.MasterName == "" {
+		add("redis.master_name", "is required in sentinel mode")
+	}
+
+	for key, v := range map[string]string{
+		"website_url":               c.WebsiteURL,
+		"cdn_url":                   c.CdnURL,
+		"tracing.endpoint":          c.Tracing.Endpoint,
+		"http.proxied_endpoint.url": c.Http.ProxiedEndpoint.URL,
+
+		"chatterino.stable.win.download":            c.Chatterino.Stable.Win.Download,
+		"chatterino.stable.win.portable_download":   c.Chatterino.Stable.Win.PortableDownload,
+		"chatterino.stable.win.update_exe":          c.Chatterino.Stable.Win.UpdateExe,
+		"chatterino.stable.linux.download":          c.Chatterino.Stable.Linux.Download,
+		"chatterino.stable.linux.portable_download": c.Chatterino.Stable.Linux.PortableDownload,
+		"chatterino.stable.linux.update_exe":        c.Chatterino.Stable.Linux.UpdateExe,
+		"chatterino.stable.macos.download":          c.Chatterino.Stable.Macos.Download,
+		"chatterino.stable.macos.portable_download": c.Chatterino.Stable.Macos.PortableDownload,
+		"chatterino.stable.macos.update_exe":        c.Chatterino.Stable.Macos.UpdateExe,
+		"chatterino.beta.win.download":              c.Chatterino.Beta.Win.Download,
+		"chatterino.beta.win.portable_download":     c.Chatterino.Beta.Win.PortableDownload,
+		"chatterino.beta.win.update_exe":            c.Chatterino.Beta.Win.UpdateExe,
+		"chatterino.beta.linux.download":            c.Chatterino.Beta.Linux.Download,
+		"chatterino.beta.linux.portable_download":   c.Chatterino.Beta.Linux.PortableDownload,
+		"chatterino.beta.linux.update_exe":          c.Chatterino.Beta.Linux.UpdateExe,
+		"chatterino.beta.macos.download":            c.Chatterino.Beta.Macos.Download,
+		"chatterino.beta.macos.portable_download":   c.Chatterino.Beta.Macos.PortableDownload,
+		"chatterino.beta.macos.update_exe":          c.Chatterino.Beta.Macos.UpdateExe,
+	} {
+		if v == "" {
+			continue
+		}
+
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" || u.Host == "" {
+			add(key, "%q is not an absolute URL", v)
+		}
+	}
+
+	for key, port := range map[string]int{
+		"http.ports.gql":    c.Http.Ports.GQL,
+		"http.ports.rest":   c.Http.Ports.REST,
+		"http.ports.bridge": c.Http.Ports.Bridge,
+	} {
+		if port < 0 || port > 65535 {
+			add(key, "%d is not a valid port", port)
+		}
+	}
+
+	if c.Http.Ports.GQL != 0 && c.Http.Ports.GQL == c.Http.Ports.REST {
+		add("http.ports", "gql and rest cannot listen on the same port")
+	}
+
+	switch c.MessageQueue.Mode {
+	case MessageQueueModeRMQ:
+		if c.MessageQueue.RMQ.URI == "" {
+			add("message_queue.rmq.uri", "is required in RMQ mode")
+		}
+	case MessageQueueModeSQS:
+		if c.MessageQueue.SQS.Region == "" {
+			add("message_queue.sqs.region", "is required in SQS mode")
+		}
+	default:
+		add("message_queue.mode", "%q is not one of %s or %s", c.MessageQueue.Mode, MessageQueueModeRMQ, MessageQueueModeSQS)
+	}
+
+	if c.Tracing.Enabled {
+		if c.Tracing.SampleRate < 0 || c.Tracing.SampleRate > 1 {
+			add("tracing.sample_rate", "%v is not between 0 and 1", c.Tracing.SampleRate)
+		}
+
+		if c.Tracing.FieldThreshold < 0 {
+			add("tracing.field_threshold", "cannot be negative")
+		}
+	}
+
+	switch c.Limits.PersistedQueries.Mode {
+	case "", PersistedQueriesModeReport, PersistedQueriesModeEnforce:
+	default:
+		add("limits.persisted_queries.mode", "%q is not one of %s or %s", c.Limits.PersistedQueries.Mode, PersistedQueriesModeReport, PersistedQueriesModeEnforce)
+	}
+
+	for key, bucket := range map[string][2]int64{
+		"limits.buckets.gql_v2":           c.Limits.Buckets.GQL2,
+		"limits.buckets.gql_v3":           c.Limits.Buckets.GQL3,
+		"limits.buckets.image_processing": c.Limits.Buckets.ImageProcessing,
+	} {
+		if bucket[0] < 0 || bucket[1] < 0 {
+			add(key, "the limit and duration cannot be negative")
+		}
+	}
+
+	if c.Limits.Complexity.Default < 0 {
+		add("limits.complexity.default", "cannot be negative")
+	}
+
+	if id := c.Limits.Emotes.FeaturedSetID; id != "" && !primitive.IsValidObjectID(id) {
+		add("limits.emotes.featured_set_id", "%q is not an object ID", id)
+	}
+
+	for role, limit := range c.Limits.Complexity.Roles {
+		if limit < 0 {
+			add("limits.complexity.roles."+role, "cannot be negative")
+		}
+	}
+
+	for key, v := range map[string]int64{
+		"limits.max_page":                           int64(c.Limits.MaxPage),
+		"limits.quota.default_limit":                int64(c.Limits.Quota.DefaultLimit),
+		"limits.quota.max_bad_queries":              c.Limits.Quota.MaxBadQueries,
+		"limits.quota.max_active_mod_requests":      c.Limits.Quota.MaxActiveModRequests,
+		"limits.emotes.max_processing_time_seconds": int64(c.Limits.Emotes.MaxProcessingTimeSeconds),
+		"limits.emotes.max_width":                   int64(c.Limits.Emotes.MaxWidth),
+		"limits.emotes.max_height":                  int64(c.Limits.Emotes.MaxHeight),
+		"limits.emotes.max_frame_count":             int64(c.Limits.Emotes.MaxFrameCount),
+		"limits.emotes.max_tags":                    int64(c.Limits.Emotes.MaxTags),
+		"limits.emote_sets.capacity":                int64(c.Limits.EmoteSets.Capacity),
+	} {
+		if v < 0 {
+			add(key, "cannot be negative")
+		}
+	}
+
+	for _, tag := range c.Limits.Emotes.ReservedTags {
+		if tag == "" {
+			add("limits.emotes.reserved_tags", "cannot contain an empty tag")
+
+			break
+		}
+	}
+
+	if v := c.Credentials.SessionlessTokensUntil; v != "" {
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			add("credentials.sessionless_tokens_until", "%q is not an RFC 3339 date", v)
+		}
+	}
+
+	if c.SecondFactor.MaxAge < 0 {
+		add("second_factor.max_age", "cannot be negative")
+	}
+
+	if c.Shutdown.DrainPeriod < 0 || c.Shutdown.Timeout < 0 {
+		add("shutdown", "the drain period and timeout cannot be negative")
+	} else if c.Shutdown.Timeout > 0 && c.Shutdown.DrainPeriod >= c.Shutdown.Timeout {
+		add("shutdown.drain_period", "must be shorter than the timeout")
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
@@ -3,6 +3,7 @@ package constant
 type Key string
 
 const (
-	ClientIP Key = "seventv-client-ip"
-	UserKey  Key = "seventv-user"
+	ClientIP   Key = "seventv-client-ip"
+	UserKey    Key = "seventv-user"
+	SessionKey Key = "seventv-session"
 )
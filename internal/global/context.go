@@ -2,6 +2,7 @@ package global
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/seventv/api/internal/configure"
@@ -10,18 +11,20 @@ import (
 
 type Context interface {
 	context.Context
+	// Config returns the current config. It is replaced as a whole when the config is reloaded,
+	// so a request should read it once to see consistent values
 	Config() *configure.Config
 	Inst() *instance.Instances
 }
 
 type gCtx struct {
 	context.Context
-	config *configure.Config
+	config *atomic.Value
 	inst   *instance.Instances
 }
 
 func (g *gCtx) Config() *configure.Config {
-	return g.config
+	return g.config.Load().(*configure.Config)
 }
 
 func (g *gCtx) Inst() *instance.Instances {
@@ -29,15 +32,35 @@ func (g *gCtx) Inst() *instance.Instances {
 }
 
 func New(ctx context.Context, config *configure.Config) Context {
+	v := &atomic.Value{}
+	v.Store(config)
+
 	return &gCtx{
 		Context: ctx,
-		config:  config,
+		config:  v,
 		inst:    &instance.Instances{},
 	}
 }
 
+// SetConfig replaces the config of a context and of every context derived from the same root
+func SetConfig(ctx Context, config *configure.Config) {
+	sharedConfig(ctx).Store(config)
+}
+
+// sharedConfig returns the config of a context, which derived contexts share so that they see a reload
+func sharedConfig(ctx Context) *atomic.Value {
+	if g, ok := ctx.(*gCtx); ok {
+		return g.config
+	}
+
+	v := &atomic.Value{}
+	v.Store(ctx.Config())
+
+	return v
+}
+
 func WithCancel(ctx Context) (Context, context.CancelFunc) {
-	cfg := ctx.Config()
+	cfg := sharedConfig(ctx)
 	inst := ctx.Inst()
 
 	c, cancel := context.WithCancel(ctx)
@@ -50,7 +73,7 @@ func WithCancel(ctx Context) (Context, context.CancelFunc) {
 }
 
 func WithDeadline(ctx Context, deadline time.Time) (Context, context.CancelFunc) {
-	cfg := ctx.Config()
+	cfg := sharedConfig(ctx)
 	inst := ctx.Inst()
 
 	c, cancel := context.WithDeadline(ctx, deadline)
@@ -63,7 +86,7 @@ func WithDeadline(ctx Context, deadline time.Time) (Context, context.CancelFunc)
 }
 
 func WithValue(ctx Context, key interface{}, value interface{}) Context {
-	cfg := ctx.Config()
+	cfg := sharedConfig(ctx)
 	inst := ctx.Inst()
 
 	return &gCtx{
@@ -74,7 +97,7 @@ func WithValue(ctx Context, key interface{}, value interface{}) Context {
 }
 
 func WithTimeout(ctx Context, timeout time.Duration) (Context, context.CancelFunc) {
-	cfg := ctx.Config()
+	cfg := sharedConfig(ctx)
 	inst := ctx.Inst()
 
 	c, cancel := context.WithTimeout(ctx, timeout)
@@ -14,10 +14,15 @@ import (
 	"github.com/seventv/api/internal/loaders"
 	"github.com/seventv/api/internal/search"
 	"github.com/seventv/api/internal/svc/auth"
+	"github.com/seventv/api/internal/svc/flags"
+	"github.com/seventv/api/internal/svc/lifecycle"
 	"github.com/seventv/api/internal/svc/limiter"
+	"github.com/seventv/api/internal/svc/persisted"
 	"github.com/seventv/api/internal/svc/presences"
 	"github.com/seventv/api/internal/svc/prometheus"
+	"github.com/seventv/api/internal/svc/status"
 	"github.com/seventv/api/internal/svc/youtube"
+	"github.com/seventv/api/internal/templates"
 )
 
 type Instances struct {
@@ -30,11 +35,16 @@ type Instances struct {
 	Prometheus   prometheus.Instance
 	Events       events.Instance
 	Limiter      limiter.Instance
+	Persisted    persisted.Instance
 	YouTube      youtube.Instance
 	Loaders      loaders.Instance
 	Presences    presences.Instance
 	Modelizer    model.Modelizer
 	CD           compactdisc.Instance
+	Templates    *templates.Registry
+	Lifecycle    lifecycle.Instance
+	Status       status.Instance
+	Flags        flags.Instance
 
 	Query  *query.Query
 	Mutate *mutate.Mutate
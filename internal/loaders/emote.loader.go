@@ -5,22 +5,31 @@ import (
 	"sync"
 	"time"
 
+	"github.com/seventv/api/internal/svc/tracing"
 	"github.com/seventv/common/dataloader"
 	"github.com/seventv/common/structures/v3"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func emoteLoader(ctx context.Context, x inst, key string) EmoteLoaderByID {
 	go initCache()
 
-	return dataloader.New(dataloader.Config[primitive.ObjectID, structures.Emote]{
+	links := &tracing.Links{}
+
+	loader := dataloader.New(dataloader.Config[primitive.ObjectID, structures.Emote]{
 		Wait:     time.Millisecond * 25,
 		MaxBatch: 1000,
 		Fetch: func(keys []primitive.ObjectID) ([]structures.Emote, []error) {
 			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 			defer cancel()
 
+			ctx, span := links.Start(ctx, "loader emote_by_id",
+				trace.WithAttributes(attribute.String("loader.name", "emote_by_id"), attribute.Int("loader.batch_size", len(keys))),
+			)
+
 			// Fetch emote data from the database
 			items := make([]structures.Emote, len(keys))
 			errs := make([]error, len(keys))
@@ -45,6 +54,8 @@ func emoteLoader(ctx context.Context, x inst, key string) EmoteLoaderByID {
 				remainingKeys = append(remainingKeys, key)
 			}
 
+			x.prom.ObserveLoaderCache("emote_by_id", len(keys)-len(remainingKeys), len(remainingKeys))
+
 			// Fetch emotes
 			emotes, err := x.query.Emotes(ctx, bson.M{
 				key: bson.M{"$in": remainingKeys},
@@ -75,9 +86,23 @@ func emoteLoader(ctx context.Context, x inst, key string) EmoteLoaderByID {
 				}
 			}
 
+			// Emotes which could not be found are returned as deleted rather than as errors
+			found := 0
+
+			for _, e := range items {
+				if e.ID != structures.DeletedEmote.ID {
+					found++
+				}
+			}
+
+			x.prom.ObserveLoaderBatch("emote_by_id", len(keys), found)
+			tracing.End(span, err, attribute.Int("loader.found", found))
+
 			return items, errs
 		},
 	})
+
+	return &Loader[primitive.ObjectID, structures.Emote]{loader: loader, links: links}
 }
 
 // TODO: clean up the code for cache to make it more universal for other loaders if needed
@@ -130,7 +155,7 @@ func setEmoteInCache(emote structures.Emote) {
 }
 
 func batchEmoteLoader(ctx context.Context, x inst, key string) BatchEmoteLoaderByID {
-	return dataloader.New(dataloader.Config[primitive.ObjectID, []structures.Emote]{
+	return observed(x, "emotes_by_owner", dataloader.Config[primitive.ObjectID, []structures.Emote]{
 		Wait: time.Millisecond * 25,
 		Fetch: func(keys []primitive.ObjectID) ([][]structures.Emote, []error) {
 			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
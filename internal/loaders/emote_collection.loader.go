@@ -0,0 +1,87 @@
+package loaders
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/common/dataloader"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmoteCollectionFollowKey identifies the follow of a collection by a user
+type EmoteCollectionFollowKey struct {
+	UserID       primitive.ObjectID
+	CollectionID primitive.ObjectID
+}
+
+func emoteFavoriteCountByID(ctx context.Context, x inst) EmoteFavoriteCountLoaderByID {
+	return observed(x, "emote_favorite_count_by_id", dataloader.Config[primitive.ObjectID, int64]{
+		Wait:     time.Millisecond * 50,
+		MaxBatch: 250,
+		Fetch: func(keys []primitive.ObjectID) ([]int64, []error) {
+			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+			defer cancel()
+
+			items := make([]int64, len(keys))
+			errs := make([]error, len(keys))
+
+			counts, err := x.query.EmoteFavoriteCounts(ctx, keys)
+			if err != nil {
+				for i := range errs {
+					errs[i] = err
+				}
+
+				return items, errs
+			}
+
+			for i, id := range keys {
+				items[i] = counts[id]
+			}
+
+			return items, errs
+		},
+	})
+}
+
+func emoteCollectionFollowed(ctx context.Context, x inst) EmoteCollectionFollowedLoader {
+	return observed(x, "emote_collection_followed", dataloader.Config[EmoteCollectionFollowKey, bool]{
+		Wait:     time.Millisecond * 50,
+		MaxBatch: 100,
+		Fetch: func(keys []EmoteCollectionFollowKey) ([]bool, []error) {
+			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+			defer cancel()
+
+			items := make([]bool, len(keys))
+			errs := make([]error, len(keys))
+
+			userIDs := make([]primitive.ObjectID, 0, len(keys))
+			collectionIDs := make([]primitive.ObjectID, 0, len(keys))
+
+			for _, k := range keys {
+				userIDs = append(userIDs, k.UserID)
+				collectionIDs = append(collectionIDs, k.CollectionID)
+			}
+
+			follows, err := x.query.EmoteCollectionFollows(ctx, userIDs, collectionIDs)
+			if err != nil {
+				for i := range errs {
+					errs[i] = err
+				}
+
+				return items, errs
+			}
+
+			// The query matches any user with any collection, so only the requested pairs are kept
+			followed := make(map[EmoteCollectionFollowKey]bool, len(follows))
+			for _, f := range follows {
+				followed[EmoteCollectionFollowKey{UserID: f.UserID, CollectionID: f.CollectionID}] = true
+			}
+
+			for i, k := range keys {
+				items[i] = followed[k]
+			}
+
+			return items, errs
+		},
+	})
+}
@@ -14,7 +14,7 @@ import (
 )
 
 func emoteSetByID(ctx context.Context, x inst) EmoteSetLoaderByID {
-	return dataloader.New(dataloader.Config[primitive.ObjectID, structures.EmoteSet]{
+	return observed(x, "emote_set_by_id", dataloader.Config[primitive.ObjectID, structures.EmoteSet]{
 		Wait: time.Millisecond * 100,
 		Fetch: func(keys []primitive.ObjectID) ([]structures.EmoteSet, []error) {
 			ctx, cancel := context.WithTimeout(ctx, time.Second*30)
@@ -59,7 +59,7 @@ func emoteSetByID(ctx context.Context, x inst) EmoteSetLoaderByID {
 }
 
 func emoteSetByUserID(ctx context.Context, x inst) BatchEmoteSetLoaderByID {
-	return dataloader.New(dataloader.Config[primitive.ObjectID, []structures.EmoteSet]{
+	return observed(x, "emote_sets_by_user", dataloader.Config[primitive.ObjectID, []structures.EmoteSet]{
 		Wait:     time.Millisecond * 100,
 		MaxBatch: 30,
 		Fetch: func(keys []primitive.ObjectID) ([][]structures.EmoteSet, []error) {
@@ -88,3 +88,33 @@ func emoteSetByUserID(ctx context.Context, x inst) BatchEmoteSetLoaderByID {
 		},
 	})
 }
+
+// emoteSetSourceID loads the ID of the set which a cloned emote set is linked to, or a zero ID if it is not linked
+func emoteSetSourceID(ctx context.Context, x inst) EmoteSetSourceIDLoader {
+	return observed(x, "emote_set_source_id", dataloader.Config[primitive.ObjectID, primitive.ObjectID]{
+		Wait:     time.Millisecond * 50,
+		MaxBatch: 250,
+		Fetch: func(keys []primitive.ObjectID) ([]primitive.ObjectID, []error) {
+			ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+			defer cancel()
+
+			items := make([]primitive.ObjectID, len(keys))
+			errs := make([]error, len(keys))
+
+			links, err := x.query.EmoteSetLinks(ctx, keys)
+			if err != nil {
+				for i := range errs {
+					errs[i] = err
+				}
+
+				return items, errs
+			}
+
+			for i, id := range keys {
+				items[i] = links[id].SourceID
+			}
+
+			return items, errs
+		},
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/seventv/api/data/query"
+	"github.com/seventv/api/internal/svc/prometheus"
+	"github.com/seventv/api/internal/svc/tracing"
 	"github.com/seventv/common/dataloader"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/redis"
@@ -11,6 +13,8 @@ import (
 	"github.com/seventv/common/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const LoadersKey = utils.Key("dataloaders")
@@ -25,6 +29,9 @@ type Instance interface {
 	EmoteByOwnerID() BatchEmoteLoaderByID
 	EmoteSetByID() EmoteSetLoaderByID
 	EmoteSetByUserID() BatchEmoteSetLoaderByID
+	EmoteSetSourceID() EmoteSetSourceIDLoader
+	EmoteFavoriteCountByID() EmoteFavoriteCountLoaderByID
+	EmoteCollectionFollowed() EmoteCollectionFollowedLoader
 
 	PresenceByActorID() PresenceLoaderByID
 
@@ -45,6 +52,11 @@ type inst struct {
 	// Emote Set Loaders
 	emoteSetByID     EmoteSetLoaderByID
 	emoteSetByUserID BatchEmoteSetLoaderByID
+	emoteSetSourceID EmoteSetSourceIDLoader
+
+	// Emote Collection Loaders
+	emoteFavoriteCountByID  EmoteFavoriteCountLoaderByID
+	emoteCollectionFollowed EmoteCollectionFollowedLoader
 
 	// Presence Loaders
 	presenceByActorID PresenceLoaderByID
@@ -56,24 +68,26 @@ type inst struct {
 	mongo mongo.Instance
 	redis redis.Instance
 	query *query.Query
+	prom  prometheus.Instance
 }
 
-func New(ctx context.Context, mngo mongo.Instance, rdis redis.Instance, quer *query.Query) Instance {
+func New(ctx context.Context, mngo mongo.Instance, rdis redis.Instance, quer *query.Query, prom prometheus.Instance) Instance {
 	l := inst{
 		query: quer,
 		mongo: mngo,
 		redis: rdis,
+		prom:  prom,
 	}
 
 	l.userByID = userLoader[primitive.ObjectID](ctx, l, "_id")
 	l.userByUsername = userLoader[string](ctx, l, "username")
-	l.userByConnectionID = map[structures.UserConnectionPlatform]*dataloader.DataLoader[string, structures.User]{
+	l.userByConnectionID = map[structures.UserConnectionPlatform]UserLoaderByConnectionID{
 		structures.UserConnectionPlatformTwitch:  userByConnectionLoader(ctx, l, structures.UserConnectionPlatformTwitch, "id"),
 		structures.UserConnectionPlatformYouTube: userByConnectionLoader(ctx, l, structures.UserConnectionPlatformYouTube, "id"),
 		structures.UserConnectionPlatformDiscord: userByConnectionLoader(ctx, l, structures.UserConnectionPlatformDiscord, "id"),
 		structures.UserConnectionPlatformKick:    userByConnectionLoader(ctx, l, structures.UserConnectionPlatformKick, "id"),
 	}
-	l.userByConnectionUsername = map[structures.UserConnectionPlatform]*dataloader.DataLoader[string, structures.User]{
+	l.userByConnectionUsername = map[structures.UserConnectionPlatform]UserLoaderByConnectionUsername{
 		structures.UserConnectionPlatformTwitch:  userByConnectionLoader(ctx, l, structures.UserConnectionPlatformTwitch, "data.login"),
 		structures.UserConnectionPlatformYouTube: userByConnectionLoader(ctx, l, structures.UserConnectionPlatformYouTube, "data.username"),
 		structures.UserConnectionPlatformDiscord: userByConnectionLoader(ctx, l, structures.UserConnectionPlatformDiscord, "data.username"),
@@ -83,6 +97,9 @@ func New(ctx context.Context, mngo mongo.Instance, rdis redis.Instance, quer *qu
 	l.emoteByOwnerID = batchEmoteLoader(ctx, l, "owner_id")
 	l.emoteSetByID = emoteSetByID(ctx, l)
 	l.emoteSetByUserID = emoteSetByUserID(ctx, l)
+	l.emoteSetSourceID = emoteSetSourceID(ctx, l)
+	l.emoteFavoriteCountByID = emoteFavoriteCountByID(ctx, l)
+	l.emoteCollectionFollowed = emoteCollectionFollowed(ctx, l)
 
 	l.presenceByActorID = presenceLoader[bson.Raw](ctx, l, structures.UserPresenceKindUnknown, "actor_id")
 
@@ -91,6 +108,53 @@ func New(ctx context.Context, mngo mongo.Instance, rdis redis.Instance, quer *qu
 	return &l
 }
 
+// Loader is a dataloader given the context of its callers, so that its batches are traced as part of
+// the requests they were made for. Batches gather the keys of several requests, see tracing.Links
+type Loader[K comparable, V any] struct {
+	loader *dataloader.DataLoader[K, V]
+	links  *tracing.Links
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.links.Add(ctx)
+
+	return l.loader.Load(key)
+}
+
+func (l *Loader[K, V]) LoadAll(ctx context.Context, keys []K) ([]V, []error) {
+	l.links.Add(ctx)
+
+	return l.loader.LoadAll(keys)
+}
+
+// observed records the size of the loader's batches and how many of their keys were resolved without error
+func observed[K comparable, V any](x inst, name string, cfg dataloader.Config[K, V]) *Loader[K, V] {
+	links := &tracing.Links{}
+	fetch := cfg.Fetch
+
+	cfg.Fetch = func(keys []K) ([]V, []error) {
+		_, span := links.Start(context.Background(), "loader "+name,
+			trace.WithAttributes(attribute.String("loader.name", name), attribute.Int("loader.batch_size", len(keys))),
+		)
+
+		items, errs := fetch(keys)
+
+		found := len(keys)
+		for _, err := range errs {
+			if err != nil {
+				found--
+			}
+		}
+
+		x.prom.ObserveLoaderBatch(name, len(keys), found)
+		tracing.End(span, nil, attribute.Int("loader.found", found))
+
+		return items, errs
+	}
+
+	return &Loader[K, V]{loader: dataloader.New(cfg), links: links}
+}
+
 func (l inst) UserByID() UserLoaderByID {
 	return l.userByID
 }
@@ -129,6 +193,18 @@ func (l inst) EmoteSetByUserID() BatchEmoteSetLoaderByID {
 	return l.emoteSetByUserID
 }
 
+func (l inst) EmoteSetSourceID() EmoteSetSourceIDLoader {
+	return l.emoteSetSourceID
+}
+
+func (l inst) EmoteFavoriteCountByID() EmoteFavoriteCountLoaderByID {
+	return l.emoteFavoriteCountByID
+}
+
+func (l inst) EmoteCollectionFollowed() EmoteCollectionFollowedLoader {
+	return l.emoteCollectionFollowed
+}
+
 // EmoteByOwnerID implements Instance
 func (l *inst) EmoteByOwnerID() BatchEmoteLoaderByID {
 	return l.emoteByOwnerID
@@ -143,17 +219,21 @@ func (l *inst) EntitlementsLoader() EntitlementsLoader {
 }
 
 type (
-	UserLoaderByID                 = *dataloader.DataLoader[primitive.ObjectID, structures.User]
-	UserLoaderByUsername           = *dataloader.DataLoader[string, structures.User]
-	UserLoaderByConnectionID       = *dataloader.DataLoader[string, structures.User]
-	UserLoaderByConnectionUsername = *dataloader.DataLoader[string, structures.User]
+	UserLoaderByID                 = *Loader[primitive.ObjectID, structures.User]
+	UserLoaderByUsername           = *Loader[string, structures.User]
+	UserLoaderByConnectionID       = *Loader[string, structures.User]
+	UserLoaderByConnectionUsername = *Loader[string, structures.User]
+
+	EmoteLoaderByID         = *Loader[primitive.ObjectID, structures.Emote]
+	BatchEmoteLoaderByID    = *Loader[primitive.ObjectID, []structures.Emote]
+	EmoteSetLoaderByID      = *Loader[primitive.ObjectID, structures.EmoteSet]
+	BatchEmoteSetLoaderByID = *Loader[primitive.ObjectID, []structures.EmoteSet]
+	EmoteSetSourceIDLoader  = *Loader[primitive.ObjectID, primitive.ObjectID]
 
-	EmoteLoaderByID         = *dataloader.DataLoader[primitive.ObjectID, structures.Emote]
-	BatchEmoteLoaderByID    = *dataloader.DataLoader[primitive.ObjectID, []structures.Emote]
-	EmoteSetLoaderByID      = *dataloader.DataLoader[primitive.ObjectID, structures.EmoteSet]
-	BatchEmoteSetLoaderByID = *dataloader.DataLoader[primitive.ObjectID, []structures.EmoteSet]
+	EmoteFavoriteCountLoaderByID  = *Loader[primitive.ObjectID, int64]
+	EmoteCollectionFollowedLoader = *Loader[EmoteCollectionFollowKey, bool]
 
-	PresenceLoaderByID = *dataloader.DataLoader[primitive.ObjectID, []structures.UserPresence[bson.Raw]]
+	PresenceLoaderByID = *Loader[primitive.ObjectID, []structures.UserPresence[bson.Raw]]
 
-	EntitlementsLoader = *dataloader.DataLoader[primitive.ObjectID, query.EntitlementQueryResult]
+	EntitlementsLoader = *Loader[primitive.ObjectID, query.EntitlementQueryResult]
 )
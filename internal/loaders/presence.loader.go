@@ -11,8 +11,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func presenceLoader[T structures.UserPresenceData](ctx context.Context, x inst, kind structures.UserPresenceKind, key string) *dataloader.DataLoader[primitive.ObjectID, []structures.UserPresence[T]] {
-	return dataloader.New(dataloader.Config[primitive.ObjectID, []structures.UserPresence[T]]{
+func presenceLoader[T structures.UserPresenceData](ctx context.Context, x inst, kind structures.UserPresenceKind, key string) *Loader[primitive.ObjectID, []structures.UserPresence[T]] {
+	return observed(x, "presences_by_actor", dataloader.Config[primitive.ObjectID, []structures.UserPresence[T]]{
 		Wait:     time.Millisecond * 75,
 		MaxBatch: 100,
 		Fetch: func(keys []primitive.ObjectID) ([][]structures.UserPresence[T], []error) {
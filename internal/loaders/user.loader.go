@@ -3,6 +3,7 @@ package loaders
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/seventv/api/data/query"
@@ -14,8 +15,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func userLoader[T comparable](ctx context.Context, x inst, keyName string) *dataloader.DataLoader[T, structures.User] {
-	return dataloader.New(dataloader.Config[T, structures.User]{
+func userLoader[T comparable](ctx context.Context, x inst, keyName string) *Loader[T, structures.User] {
+	return observed(x, "user_by_"+strings.TrimPrefix(keyName, "_"), dataloader.Config[T, structures.User]{
 		Wait:     time.Millisecond * 25,
 		MaxBatch: 500,
 		Fetch: func(keys []T) ([]structures.User, []error) {
@@ -70,8 +71,8 @@ func userLoader[T comparable](ctx context.Context, x inst, keyName string) *data
 	})
 }
 
-func userByConnectionLoader(ctx context.Context, x inst, platform structures.UserConnectionPlatform, key string) *dataloader.DataLoader[string, structures.User] {
-	return dataloader.New(dataloader.Config[string, structures.User]{
+func userByConnectionLoader(ctx context.Context, x inst, platform structures.UserConnectionPlatform, key string) *Loader[string, structures.User] {
+	return observed(x, "user_by_connection", dataloader.Config[string, structures.User]{
 		Wait:     time.Millisecond * 75,
 		MaxBatch: 500,
 		Fetch: func(keys []string) ([]structures.User, []error) {
@@ -135,8 +136,8 @@ func userByConnectionLoader(ctx context.Context, x inst, platform structures.Use
 	})
 }
 
-func entitlementsLoader(ctx context.Context, x inst) *dataloader.DataLoader[primitive.ObjectID, query.EntitlementQueryResult] {
-	return dataloader.New(dataloader.Config[primitive.ObjectID, query.EntitlementQueryResult]{
+func entitlementsLoader(ctx context.Context, x inst) *Loader[primitive.ObjectID, query.EntitlementQueryResult] {
+	return observed(x, "entitlements", dataloader.Config[primitive.ObjectID, query.EntitlementQueryResult]{
 		Wait:     time.Millisecond * 100,
 		MaxBatch: 500,
 		Fetch: func(keys []primitive.ObjectID) ([]query.EntitlementQueryResult, []error) {
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/patrickmn/go-cache"
 	"github.com/seventv/api/data/query"
 	"github.com/seventv/api/internal/constant"
 	"github.com/seventv/api/internal/global"
@@ -18,6 +19,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// touchedSessions are the sessions whose last-seen time was recently refreshed
+var touchedSessions = cache.New(query.SESSION_TOUCH_INTERVAL, query.SESSION_TOUCH_INTERVAL*2)
+
 func Auth(gctx global.Context) Middleware {
 	return func(ctx *fasthttp.RequestCtx) errors.APIError {
 		token := utils.B2S(ctx.Request.Header.Cookie(string(auth.COOKIE_AUTH)))
@@ -37,7 +41,7 @@ func Auth(gctx global.Context) Middleware {
 			token = s[1]
 		}
 
-		user, err := DoAuth(gctx, token)
+		user, sessionID, err := DoAuth(gctx, token)
 		if err != nil {
 			return err
 		}
@@ -93,6 +97,19 @@ func Auth(gctx global.Context) Middleware {
 			}
 		}
 
+		if !sessionID.IsZero() {
+			ctx.SetUserValue(constant.SessionKey, sessionID)
+
+			// Sessions are only touched once per interval from each process
+			if _, touched := touchedSessions.Get(sessionID.Hex()); !touched {
+				touchedSessions.SetDefault(sessionID.Hex(), true)
+
+				go func() {
+					_ = gctx.Inst().Mutate.TouchSession(gctx, sessionID)
+				}()
+			}
+		}
+
 		ctx.SetUserValue(constant.UserKey, user)
 		ctx.Response.Header.Set("X-Actor-ID", user.ID.Hex())
 
@@ -100,34 +117,60 @@ func Auth(gctx global.Context) Middleware {
 	}
 }
 
-func DoAuth(ctx global.Context, t string) (structures.User, errors.APIError) {
+// DoAuth verifies an access token and returns the user it belongs to,
+// as well as the session it was issued for, if any
+func DoAuth(ctx global.Context, t string) (structures.User, primitive.ObjectID, errors.APIError) {
 	// Verify the token
 	claims := &auth.JWTClaimUser{}
 
 	user := structures.User{}
+	sessionID := primitive.NilObjectID
 
 	_, err := ctx.Inst().Auth.VerifyJWT(strings.Split(t, "."), claims)
 	if err != nil {
-		return user, errors.ErrUnauthorized().SetDetail(err.Error())
+		return user, sessionID, errors.ErrUnauthorized().SetDetail(err.Error())
 	}
 
 	// User ID from parsed token
 	if claims.UserID == "" {
-		return user, errors.ErrUnauthorized().SetDetail("Bad Token")
+		return user, sessionID, errors.ErrUnauthorized().SetDetail("Bad Token")
 	}
 
 	userID, err := primitive.ObjectIDFromHex(claims.UserID)
 	if err != nil {
-		return user, errors.ErrUnauthorized().SetDetail(err.Error())
+		return user, sessionID, errors.ErrUnauthorized().SetDetail(err.Error())
 	}
 
 	user, err = ctx.Inst().Query.Users(ctx, bson.M{"_id": userID}).First()
 	if err != nil {
-		return user, errors.From(err)
+		return user, sessionID, errors.From(err)
 	}
 
 	if user.TokenVersion != claims.TokenVersion {
-		return user, errors.ErrUnauthorized().SetDetail("Token Version Mismatch")
+		return user, sessionID, errors.ErrUnauthorized().SetDetail("Token Version Mismatch")
+	}
+
+	// Check whether the session was signed out.
+	// Tokens issued before sessions were tracked carry no session ID, and are only revoked by a change of the token version
+	if claims.ID == "" {
+		if until := ctx.Config().Credentials.SessionlessTokensUntil; until != "" {
+			if t, err := time.Parse(time.RFC3339, until); err == nil && time.Now().After(t) {
+				return user, sessionID, errors.ErrUnauthorized().SetDetail("Session Expired")
+			}
+		}
+	} else {
+		if sessionID, err = primitive.ObjectIDFromHex(claims.ID); err != nil {
+			return user, sessionID, errors.ErrUnauthorized().SetDetail("Bad Token")
+		}
+
+		revoked, err := ctx.Inst().Query.SessionRevoked(ctx, sessionID)
+		if err != nil {
+			return user, sessionID, errors.From(err)
+		}
+
+		if revoked {
+			return user, sessionID, errors.ErrUnauthorized().SetDetail("Session Revoked")
+		}
 	}
 
 	// Check bans
@@ -135,7 +178,7 @@ func DoAuth(ctx global.Context, t string) (structures.User, errors.APIError) {
 		Filter: bson.M{"effects": bson.M{"$bitsAnySet": structures.BanEffectNoAuth | structures.BanEffectNoPermissions}},
 	})
 	if err != nil {
-		return user, errors.ErrInternalServerError().SetDetail("Failed")
+		return user, sessionID, errors.ErrInternalServerError().SetDetail("Failed")
 	}
 
 	if _, noRights := bans.NoPermissions[userID]; noRights {
@@ -145,7 +188,7 @@ func DoAuth(ctx global.Context, t string) (structures.User, errors.APIError) {
 	if ban, noAuth := bans.NoAuth[userID]; noAuth {
 		user.Bans = append(user.Bans, ban)
 
-		return user, errors.ErrBanned().SetDetail(ban.Reason).SetFields(errors.Fields{
+		return user, sessionID, errors.ErrBanned().SetDetail(ban.Reason).SetFields(errors.Fields{
 			"ban": map[string]string{
 				"reason":    ban.Reason,
 				"expire_at": ban.ExpireAt.Format(time.RFC3339),
@@ -153,5 +196,5 @@ func DoAuth(ctx global.Context, t string) (structures.User, errors.APIError) {
 		})
 	}
 
-	return user, nil
+	return user, sessionID, nil
 }
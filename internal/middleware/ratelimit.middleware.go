@@ -17,7 +17,16 @@ import (
 )
 
 func RateLimit(gctx global.Context, bucket string, limit int64, ex time.Duration) Middleware {
+	charge := RateLimitCost(gctx, bucket, limit, ex)
+
 	return func(ctx *fasthttp.RequestCtx) errors.APIError {
+		return charge(ctx, 1)
+	}
+}
+
+// RateLimitCost is a rate limit where each request consumes a variable amount of the bucket
+func RateLimitCost(gctx global.Context, bucket string, limit int64, ex time.Duration) func(ctx *fasthttp.RequestCtx, cost int64) errors.APIError {
+	return func(ctx *fasthttp.RequestCtx, cost int64) errors.APIError {
 		var identifier string
 		switch t := ctx.UserValue(constant.ClientIP).(type) {
 		case string:
@@ -38,7 +47,7 @@ func RateLimit(gctx global.Context, bucket string, limit int64, ex time.Duration
 			return nil
 		}
 
-		limit, remaining, ttl, err := DoRateLimit(gctx, ctx, bucket, limit, identifier, ex)
+		limit, remaining, ttl, err := DoRateLimit(gctx, ctx, bucket, limit, identifier, ex, cost)
 		if err != nil {
 			switch e := err.(type) {
 			case errors.APIError:
@@ -53,7 +62,10 @@ func RateLimit(gctx global.Context, bucket string, limit int64, ex time.Duration
 		ctx.Response.Header.Set("X-RateLimit-Remaining", strconv.Itoa(int(remaining)))
 		ctx.Response.Header.Set("X-RateLimit-Reset", strconv.Itoa(int(ttl)))
 
-		if remaining < 1 {
+		// A request with a cost may use up what remains of the bucket
+		if remaining < 1 && (cost <= 1 || remaining < 0) {
+			gctx.Inst().Prometheus.RateLimited(bucket)
+
 			return errors.ErrRateLimited()
 		}
 
@@ -68,6 +80,7 @@ func DoRateLimit(
 	limit int64,
 	identifier string,
 	ex time.Duration,
+	cost int64,
 ) (int64, int64, int64, error) {
 	h := sha256.New()
 	h.Write(utils.S2B(identifier))
@@ -97,7 +110,7 @@ func DoRateLimit(
 		k.String(),
 		ex.Seconds(),
 		limit,
-		1,
+		cost,
 	).Result(); err != nil {
 		return 0, 0, 0, errors.ErrInternalServerError().SetDetail("Rate Limiter Error:", err)
 	} else {
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/common/errors"
+	"github.com/seventv/common/structures/v3"
+)
+
+// RequireSecondFactor rejects an action requiring the given permissions
+// if the actor holds a high-risk permission and has not recently passed a second-factor check
+func RequireSecondFactor(gctx global.Context, ctx context.Context, actor structures.User, perms structures.RolePermission) errors.APIError {
+	guarded := structures.RolePermission(gctx.Config().SecondFactor.Permissions)
+	if guarded == 0 || perms == 0 {
+		return nil
+	}
+
+	held := actor.FinalPermission() & guarded
+	if held == 0 {
+		return nil
+	}
+
+	// Super administrators bypass every permission check, so any privileged action counts as high-risk
+	if perms&guarded == 0 && held&structures.RolePermissionSuperAdministrator == 0 {
+		return nil
+	}
+
+	if gctx.Inst().Query.SecondFactorVerified(ctx, actor.ID) {
+		return nil
+	}
+
+	return errors.ErrInsufficientPrivilege().SetDetail("Second Factor Required").SetFields(errors.Fields{
+		"SECOND_FACTOR_REQUIRED": true,
+	})
+}
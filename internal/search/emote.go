@@ -1,11 +1,62 @@
 package search
 
 import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/meilisearch/meilisearch-go"
 )
 
+// Attributes of emote documents which can be filtered on.
+// The documents are written by the indexer and must include these fields
+var emoteFilterableAttributes = []string{
+	"id",
+	"name_hex",
+	"listed",
+	"lifecycle",
+	"personal",
+	"animated",
+	"zero_width",
+	"authentic",
+	"aspect_ratio",
+	"tags",
+	"owner_id",
+	"created_at",
+}
+
+// Attributes of emote documents which facet counts are computed for
+var emoteFacetAttributes = []string{
+	"tags",
+	"animated",
+	"zero_width",
+	"owner_id",
+}
+
+// EMOTE_FACET_LIMIT is how many values are returned for the tag and owner facets
+const EMOTE_FACET_LIMIT = 10
+
+// emoteFaceting has the index return the values of each facet with the highest counts,
+// instead of the first values in alphabetical order
+var emoteFaceting = faceting{
+	MaxValuesPerFacet: EMOTE_FACET_LIMIT,
+	SortFacetValuesBy: map[string]string{"*": "count"},
+}
+
+var emoteSortableAttributes = []string{
+	"channel_count",
+	"created_at",
+}
+
+var emoteSearchableAttributes = []string{
+	"name",
+	"tags",
+}
+
 type EmoteSearchOptions struct {
 	Limit     int64
 	Page      int64
@@ -14,6 +65,37 @@ type EmoteSearchOptions struct {
 	Personal  bool
 	Listed    bool
 	Lifecycle int32
+	// Only match emote names, not tags
+	IgnoreTags bool
+	// Restrict the results to emotes with exactly this name. Unlike the query, this is case sensitive
+	Name string
+	// Filter by flags. A nil value does not filter
+	Animated  *bool
+	ZeroWidth *bool
+	Authentic *bool
+	// Filter by whether the emote was approved for personal use. A nil value does not filter
+	PersonalUse *bool
+	// Filter by width/height ratio
+	AspectRatio *EmoteAspectRatioFilter
+	// Restrict the results to these emote IDs
+	IDs []string
+	// Filter by tags. By default an emote must have all of the tags, or any of them with MatchAnyTag
+	Tags        []string
+	MatchAnyTag bool
+	// Filter by the owner of the emote
+	OwnerID string
+	// Filter by the creation date of the emote
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// Whether to compute facet counts for the query
+	Facets bool
+}
+
+type EmoteAspectRatioFilter struct {
+	// The ratio of width to height, i.e 2 for 2:1
+	Ratio float64
+	// The tolerated deviation from the ratio, in percent
+	Tolerance float64
 }
 
 type EmoteSortOptions struct {
@@ -26,7 +108,112 @@ type EmoteResult struct {
 	Id   string
 }
 
-func (s *MeiliSearch) SearchEmotes(query string, opt EmoteSearchOptions) ([]EmoteResult, int64, error) {
+type EmoteSearchResult struct {
+	Emotes []EmoteResult
+	Total  int64
+	// Only set when facets were requested
+	Facets *EmoteFacets
+}
+
+// EmoteFacets are counts of the values of the emotes matched by a search
+type EmoteFacets struct {
+	Tags      []FacetCount `json:"tags"`
+	Owners    []FacetCount `json:"owners"`
+	Animated  int64        `json:"animated"`
+	Static    int64        `json:"static"`
+	ZeroWidth int64        `json:"zero_width"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// ConfigureEmoteIndex updates the settings of the emote index so that all search options can be used,
+// and waits for the index to apply them. Settings which are already up to date are not written again.
+//
+// It is called on startup. Changing the settings does not add the attributes to the documents already in the index,
+// so the "reindex-search" admin command, which also calls this, must be run after the attributes of documents change
+func (s *MeiliSearch) ConfigureEmoteIndex(ctx context.Context) error {
+	current, err := s.emoteIndex.GetSettings()
+	if err != nil {
+		return err
+	}
+
+	settings := &meilisearch.Settings{}
+	changed := false
+
+	if !sameAttributes(current.FilterableAttributes, emoteFilterableAttributes, false) {
+		settings.FilterableAttributes = emoteFilterableAttributes
+		changed = true
+	}
+
+	if !sameAttributes(current.SortableAttributes, emoteSortableAttributes, false) {
+		settings.SortableAttributes = emoteSortableAttributes
+		changed = true
+	}
+
+	// The order of searchable attributes is their ranking
+	if !sameAttributes(current.SearchableAttributes, emoteSearchableAttributes, true) {
+		settings.SearchableAttributes = emoteSearchableAttributes
+		changed = true
+	}
+
+	if changed {
+		task, err := s.emoteIndex.UpdateSettings(settings)
+		if err != nil {
+			return err
+		}
+
+		if err := s.waitForTask(ctx, task.TaskUID); err != nil {
+			return err
+		}
+	}
+
+	return s.configureFaceting(ctx, emoteFaceting)
+}
+
+func (s *MeiliSearch) waitForTask(ctx context.Context, uid int64) error {
+	result, err := s.emoteIndex.WaitForTask(uid, meilisearch.WaitParams{
+		Context:  ctx,
+		Interval: time.Second,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.Status != meilisearch.TaskStatusSucceeded {
+		return fmt.Errorf("failed to update the emote index settings: %s", result.Error.Message)
+	}
+
+	return nil
+}
+
+func sameAttributes(a, b []string, ordered bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	if !ordered {
+		a = append([]string{}, a...)
+		b = append([]string{}, b...)
+
+		sort.Strings(a)
+		sort.Strings(b)
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *MeiliSearch) SearchEmotes(query string, opt EmoteSearchOptions) (EmoteSearchResult, error) {
+	result := EmoteSearchResult{}
+
 	req := &meilisearch.SearchRequest{}
 	if opt.Limit != 0 {
 		req.HitsPerPage = opt.Limit
@@ -37,26 +224,14 @@ func (s *MeiliSearch) SearchEmotes(query string, opt EmoteSearchOptions) ([]Emot
 	if opt.Sort.By != "" {
 		req.Sort = []string{opt.Sort.By + ":" + map[bool]string{true: "asc", false: "desc"}[opt.Sort.Ascending]}
 	}
-
-	filter := ""
-
-	if opt.Personal {
-		filter = "personal = true"
-	}
-	if opt.Listed {
-		if filter != "" {
-			filter += " AND "
-		}
-		filter += "listed = true"
+	if opt.IgnoreTags {
+		req.AttributesToSearchOn = []string{"name"}
 	}
-	if opt.Lifecycle != 0 {
-		if filter != "" {
-			filter += " AND "
-		}
-		filter += "lifecycle = " + strconv.Itoa(int(opt.Lifecycle))
+	if opt.Facets {
+		req.Facets = emoteFacetAttributes
 	}
 
-	if filter != "" {
+	if filter := emoteSearchFilter(opt); filter != "" {
 		req.Filter = filter
 	}
 
@@ -67,19 +242,177 @@ func (s *MeiliSearch) SearchEmotes(query string, opt EmoteSearchOptions) ([]Emot
 	res, err := s.emoteIndex.Search(query, req)
 
 	if err != nil {
-		return nil, 0, err
+		return result, err
 	}
 
 	var hit map[string]interface{}
-	var emotes []EmoteResult
 
-	for _, result := range res.Hits {
-		hit = result.(map[string]interface{})
-		emotes = append(emotes, EmoteResult{
+	for _, h := range res.Hits {
+		hit = h.(map[string]interface{})
+		result.Emotes = append(result.Emotes, EmoteResult{
 			Name: hit["name"].(string),
 			Id:   hit["id"].(string),
 		})
 	}
 
-	return emotes, res.TotalHits, nil
+	result.Total = res.TotalHits
+
+	if opt.Facets {
+		result.Facets = emoteFacetsFromDistribution(res.FacetDistribution)
+	}
+
+	return result, nil
+}
+
+// emoteFacetsFromDistribution reads the facet distribution of a search response,
+// which maps each attribute to the count of each of its values
+func emoteFacetsFromDistribution(v interface{}) *EmoteFacets {
+	facets := &EmoteFacets{
+		Tags:   []FacetCount{},
+		Owners: []FacetCount{},
+	}
+
+	dist, _ := v.(map[string]interface{})
+
+	counts := func(attr string) []FacetCount {
+		values, _ := dist[attr].(map[string]interface{})
+
+		result := make([]FacetCount, 0, len(values))
+		for value, count := range values {
+			n, _ := count.(float64)
+
+			result = append(result, FacetCount{
+				Value: value,
+				Count: int64(n),
+			})
+		}
+
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Count == result[j].Count {
+				return result[i].Value < result[j].Value
+			}
+
+			return result[i].Count > result[j].Count
+		})
+
+		return result
+	}
+
+	top := func(fc []FacetCount) []FacetCount {
+		if len(fc) > EMOTE_FACET_LIMIT {
+			return fc[:EMOTE_FACET_LIMIT]
+		}
+
+		return fc
+	}
+
+	facets.Tags = top(counts("tags"))
+	facets.Owners = top(counts("owner_id"))
+
+	for _, fc := range counts("animated") {
+		switch fc.Value {
+		case "true":
+			facets.Animated = fc.Count
+		case "false":
+			facets.Static = fc.Count
+		}
+	}
+
+	for _, fc := range counts("zero_width") {
+		if fc.Value == "true" {
+			facets.ZeroWidth = fc.Count
+		}
+	}
+
+	return facets
+}
+
+// emoteSearchFilter builds the filter expression for a search
+func emoteSearchFilter(opt EmoteSearchOptions) string {
+	clauses := []string{}
+
+	if opt.Personal {
+		clauses = append(clauses, "personal = true")
+	}
+	if opt.Listed {
+		clauses = append(clauses, "listed = true")
+	}
+	if opt.Lifecycle != 0 {
+		clauses = append(clauses, "lifecycle = "+strconv.Itoa(int(opt.Lifecycle)))
+	}
+
+	// Filters compare strings case insensitively, so the name is matched by its hex encoding
+	if opt.Name != "" {
+		clauses = append(clauses, "name_hex = \""+hex.EncodeToString([]byte(opt.Name))+"\"")
+	}
+
+	boolFilters := []struct {
+		attr string
+		v    *bool
+	}{
+		{"animated", opt.Animated},
+		{"zero_width", opt.ZeroWidth},
+		{"authentic", opt.Authentic},
+		{"personal", opt.PersonalUse},
+	}
+
+	for _, f := range boolFilters {
+		if f.v != nil {
+			clauses = append(clauses, fmt.Sprintf("%s = %t", f.attr, *f.v))
+		}
+	}
+
+	if ar := opt.AspectRatio; ar != nil && ar.Ratio > 0 {
+		min := ar.Ratio * (1 - ar.Tolerance/100)
+		max := ar.Ratio * (1 + ar.Tolerance/100)
+
+		clauses = append(clauses, fmt.Sprintf("aspect_ratio %s TO %s",
+			strconv.FormatFloat(min, 'f', 4, 64),
+			strconv.FormatFloat(max, 'f', 4, 64),
+		))
+	}
+
+	if opt.IDs != nil {
+		quoted := make([]string, len(opt.IDs))
+		for i, id := range opt.IDs {
+			quoted[i] = quoteFilterValue(id)
+		}
+
+		clauses = append(clauses, "id IN ["+strings.Join(quoted, ", ")+"]")
+	}
+
+	if len(opt.Tags) > 0 {
+		tags := make([]string, len(opt.Tags))
+		for i, tag := range opt.Tags {
+			tags[i] = "tags = " + quoteFilterValue(tag)
+		}
+
+		op := " AND "
+		if opt.MatchAnyTag {
+			op = " OR "
+		}
+
+		clauses = append(clauses, "("+strings.Join(tags, op)+")")
+	}
+
+	if opt.OwnerID != "" {
+		clauses = append(clauses, "owner_id = "+quoteFilterValue(opt.OwnerID))
+	}
+
+	// created_at is indexed as a unix timestamp
+	if opt.CreatedAfter != nil {
+		clauses = append(clauses, "created_at >= "+strconv.FormatInt(opt.CreatedAfter.Unix(), 10))
+	}
+	if opt.CreatedBefore != nil {
+		clauses = append(clauses, "created_at <= "+strconv.FormatInt(opt.CreatedBefore.Unix(), 10))
+	}
+
+	return strings.Join(clauses, " AND ")
+}
+
+// quoteFilterValue quotes a string for a filter expression, where quotes and backslashes are escaped with a backslash
+func quoteFilterValue(s string) string {
+	return "\"" + filterValueEscaper.Replace(s) + "\""
 }
+
+var filterValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
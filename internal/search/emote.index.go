@@ -0,0 +1,89 @@
+package search
+
+import (
+	"encoding/hex"
+
+	"github.com/seventv/common/structures/v3"
+)
+
+// EmoteDocument is an emote as it is stored in the emote index
+type EmoteDocument struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// The name, hex encoded, for case sensitive filtering
+	NameHex      string   `json:"name_hex"`
+	Tags         []string `json:"tags"`
+	OwnerID      string   `json:"owner_id"`
+	Listed       bool     `json:"listed"`
+	Lifecycle    int32    `json:"lifecycle"`
+	Personal     bool     `json:"personal"`
+	Animated     bool     `json:"animated"`
+	ZeroWidth    bool     `json:"zero_width"`
+	Authentic    bool     `json:"authentic"`
+	AspectRatio  float64  `json:"aspect_ratio"`
+	ChannelCount int32    `json:"channel_count"`
+	// unix timestamp
+	CreatedAt int64 `json:"created_at"`
+}
+
+// NewEmoteDocument creates the index document of an emote, from its latest version.
+// Emotes without an available version should be removed from the index instead
+func NewEmoteDocument(e structures.Emote) EmoteDocument {
+	doc := EmoteDocument{
+		ID:        e.ID.Hex(),
+		Name:      e.Name,
+		NameHex:   hex.EncodeToString([]byte(e.Name)),
+		Tags:      e.Tags,
+		OwnerID:   e.OwnerID.Hex(),
+		ZeroWidth: e.Flags.Has(structures.EmoteFlagsZeroWidth),
+		Authentic: e.Flags.Has(structures.EmoteFlagsAuthentic),
+		CreatedAt: e.ID.Timestamp().Unix(),
+	}
+
+	if doc.Tags == nil {
+		doc.Tags = []string{}
+	}
+
+	ver := e.GetLatestVersion(false)
+
+	doc.Listed = ver.State.Listed
+	doc.Lifecycle = int32(ver.State.Lifecycle)
+	doc.Personal = ver.State.AllowPersonal != nil && *ver.State.AllowPersonal
+	doc.Animated = ver.Animated
+
+	for _, v := range e.Versions {
+		doc.ChannelCount += v.State.ChannelCount
+	}
+
+	for _, f := range ver.ImageFiles {
+		if f.Width > 0 && f.Height > 0 {
+			doc.AspectRatio = float64(f.Width) / float64(f.Height)
+
+			break
+		}
+	}
+
+	return doc
+}
+
+// IndexEmotes adds or replaces the documents of emotes in the index
+func (s *MeiliSearch) IndexEmotes(docs []EmoteDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	_, err := s.emoteIndex.UpdateDocuments(docs, "id")
+
+	return err
+}
+
+// RemoveEmotes removes the documents of emotes from the index
+func (s *MeiliSearch) RemoveEmotes(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := s.emoteIndex.DeleteDocuments(ids)
+
+	return err
+}
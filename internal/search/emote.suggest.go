@@ -0,0 +1,90 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+type EmoteSuggestOptions struct {
+	Limit     int64
+	Listed    bool
+	Lifecycle int32
+	// Restrict the suggestions to these emote IDs
+	IDs []string
+}
+
+type EmoteSuggestion struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	ChannelCount int64  `json:"channel_count"`
+}
+
+// SuggestEmotes returns emotes whose name completes the query.
+//
+// Names starting with the query come first, then the typo tolerant matches, each ordered by channel count
+func (s *MeiliSearch) SuggestEmotes(query string, opt EmoteSuggestOptions) ([]EmoteSuggestion, error) {
+	// Fetch extra hits, so that prefix matches ranked lower by the index can be brought forward
+	req := &meilisearch.SearchRequest{
+		Limit:                opt.Limit * 3,
+		AttributesToSearchOn: []string{"name"},
+		AttributesToRetrieve: []string{"id", "name", "channel_count"},
+		Sort:                 []string{"channel_count:desc"},
+	}
+
+	req.Filter = emoteSearchFilter(EmoteSearchOptions{
+		Listed:    opt.Listed,
+		Lifecycle: opt.Lifecycle,
+		IDs:       opt.IDs,
+	})
+
+	res, err := s.suggestIndex.Search(query, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]EmoteSuggestion, 0, len(res.Hits))
+
+	for _, h := range res.Hits {
+		hit, _ := h.(map[string]interface{})
+
+		sg := EmoteSuggestion{}
+		sg.Id, _ = hit["id"].(string)
+		sg.Name, _ = hit["name"].(string)
+
+		if cc, ok := hit["channel_count"].(float64); ok {
+			sg.ChannelCount = int64(cc)
+		}
+
+		if sg.Id == "" {
+			continue
+		}
+
+		result = append(result, sg)
+	}
+
+	prefix := strings.ToLower(query)
+
+	// The order of the index is kept for matches of the same kind, as it accounts for typo distance
+	sort.SliceStable(result, func(i, j int) bool {
+		pi := strings.HasPrefix(strings.ToLower(result[i].Name), prefix)
+		pj := strings.HasPrefix(strings.ToLower(result[j].Name), prefix)
+
+		if pi != pj {
+			return pi
+		}
+
+		if pi {
+			return result[i].ChannelCount > result[j].ChannelCount
+		}
+
+		return false
+	})
+
+	if int64(len(result)) > opt.Limit {
+		result = result[:opt.Limit]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,137 @@
+package search
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/seventv/api/internal/configure"
+	"github.com/seventv/api/internal/testutil"
+)
+
+func pointer[T any](v T) *T {
+	return &v
+}
+
+// emoteFilterCases are a search option for each filter, which matches some but not all of testEmoteDocuments
+var emoteFilterCases = []struct {
+	name   string
+	opt    EmoteSearchOptions
+	clause string
+}{
+	{"listed", EmoteSearchOptions{Listed: true}, "listed = true"},
+	{"lifecycle", EmoteSearchOptions{Lifecycle: 3}, "lifecycle = 3"},
+	{"name", EmoteSearchOptions{Name: "KEKW"}, `name_hex = "4b454b57"`},
+	{"animated", EmoteSearchOptions{Animated: pointer(true)}, "animated = true"},
+	{"static", EmoteSearchOptions{Animated: pointer(false)}, "animated = false"},
+	{"zero width", EmoteSearchOptions{ZeroWidth: pointer(true)}, "zero_width = true"},
+	{"authentic", EmoteSearchOptions{Authentic: pointer(true)}, "authentic = true"},
+	{"personal use", EmoteSearchOptions{PersonalUse: pointer(true)}, "personal = true"},
+	{"aspect ratio", EmoteSearchOptions{AspectRatio: &EmoteAspectRatioFilter{Ratio: 2, Tolerance: 10}}, "aspect_ratio 1.8000 TO 2.2000"},
+	{"ids", EmoteSearchOptions{IDs: []string{"a", "b"}}, `id IN ["a", "b"]`},
+	{"all tags", EmoteSearchOptions{Tags: []string{"cat", "cute"}}, `(tags = "cat" AND tags = "cute")`},
+	{"any tag", EmoteSearchOptions{Tags: []string{"cat", "dog"}, MatchAnyTag: true}, `(tags = "cat" OR tags = "dog")`},
+	{"owner", EmoteSearchOptions{OwnerID: "owner1"}, `owner_id = "owner1"`},
+	{"created after", EmoteSearchOptions{CreatedAfter: pointer(time.Unix(2000, 0))}, "created_at >= 2000"},
+	{"created before", EmoteSearchOptions{CreatedBefore: pointer(time.Unix(2000, 0))}, "created_at <= 2000"},
+}
+
+var testEmoteDocuments = []EmoteDocument{
+	{ID: "a", Name: "KEKW", Tags: []string{"cat", "cute"}, OwnerID: "owner1", Listed: true, Lifecycle: 3, Animated: true, AspectRatio: 2, CreatedAt: 1000},
+	{ID: "b", Name: "kekw", Tags: []string{"dog"}, OwnerID: "owner2", Listed: true, Lifecycle: 3, ZeroWidth: true, AspectRatio: 1, CreatedAt: 3000},
+	{ID: "c", Name: "Kekw", Tags: []string{"cat"}, OwnerID: "owner2", Lifecycle: 3, Authentic: true, AspectRatio: 1, CreatedAt: 1500},
+	{ID: "d", Name: "KEKWait", Tags: []string{}, OwnerID: "owner3", Lifecycle: 2, Personal: true, Animated: true, AspectRatio: 1, CreatedAt: 4000},
+}
+
+func TestEmoteSearchFilter(t *testing.T) {
+	testutil.Assert(t, "", emoteSearchFilter(EmoteSearchOptions{}), "no options do not filter")
+
+	for _, c := range emoteFilterCases {
+		testutil.Assert(t, c.clause, emoteSearchFilter(c.opt), c.name)
+	}
+
+	combined := emoteSearchFilter(EmoteSearchOptions{Listed: true, Animated: pointer(true), OwnerID: "owner1"})
+	testutil.Assert(t, `listed = true AND animated = true AND owner_id = "owner1"`, combined, "filters are combined")
+}
+
+func TestQuoteFilterValue(t *testing.T) {
+	testutil.Assert(t, `"cat"`, quoteFilterValue("cat"), "plain values are quoted")
+	testutil.Assert(t, `"a \"b\""`, quoteFilterValue(`a "b"`), "quotes are escaped")
+	testutil.Assert(t, `"a\\b"`, quoteFilterValue(`a\b`), "backslashes are escaped")
+	testutil.Assert(t, "\"é\n\"", quoteFilterValue("é\n"), "other characters are kept as they are")
+}
+
+func TestNewEmoteDocumentNameHex(t *testing.T) {
+	doc := NewEmoteDocument(structures.Emote{ID: primitive.NewObjectID(), Name: "KEKW"})
+
+	testutil.Assert(t, "4b454b57", doc.NameHex, "the name is hex encoded")
+	testutil.Assert(t, true, strings.Contains(emoteSearchFilter(EmoteSearchOptions{Name: "KEKW"}), doc.NameHex), "the name filter matches the document")
+	testutil.Assert(t, false, strings.Contains(emoteSearchFilter(EmoteSearchOptions{Name: "kekw"}), doc.NameHex), "the name filter is case sensitive")
+}
+
+// TestSearchEmotesFilters checks that each filter narrows the results of a search.
+// It requires a Meilisearch server, set with MEILISEARCH_TEST_HOST and MEILISEARCH_TEST_KEY
+func TestSearchEmotesFilters(t *testing.T) {
+	host := os.Getenv("MEILISEARCH_TEST_HOST")
+	if host == "" {
+		t.Skip("MEILISEARCH_TEST_HOST is not set")
+	}
+
+	cfg := &configure.Config{}
+	cfg.Meilisearch.Host = host
+	cfg.Meilisearch.Key = os.Getenv("MEILISEARCH_TEST_KEY")
+	cfg.Meilisearch.Index = "emotes_test_" + primitive.NewObjectID().Hex()
+
+	s := New(cfg)
+
+	client := meilisearch.NewClient(meilisearch.ClientConfig{Host: cfg.Meilisearch.Host, APIKey: cfg.Meilisearch.Key})
+	defer func() {
+		_, _ = client.DeleteIndex(cfg.Meilisearch.Index)
+	}()
+
+	task, err := client.CreateIndex(&meilisearch.IndexConfig{Uid: cfg.Meilisearch.Index, PrimaryKey: "id"})
+	testutil.IsNil(t, err, "the index is created")
+
+	_, err = client.WaitForTask(task.TaskUID)
+	testutil.IsNil(t, err, "the index is created")
+
+	testutil.IsNil(t, s.ConfigureEmoteIndex(context.Background()), "the index is configured")
+
+	task, err = s.emoteIndex.UpdateDocuments(testEmoteDocuments, "id")
+	testutil.IsNil(t, err, "the documents are indexed")
+
+	_, err = client.WaitForTask(task.TaskUID)
+	testutil.IsNil(t, err, "the documents are indexed")
+
+	all, err := s.SearchEmotes("", EmoteSearchOptions{Page: 1, Limit: 10})
+	testutil.IsNil(t, err, "the search succeeds")
+	testutil.Assert(t, int64(len(testEmoteDocuments)), all.Total, "all documents match without filters")
+
+	for _, c := range emoteFilterCases {
+		opt := c.opt
+		opt.Page, opt.Limit = 1, 10
+
+		res, err := s.SearchEmotes("", opt)
+		testutil.IsNil(t, err, c.name+": the search succeeds")
+
+		if res.Total == 0 || res.Total >= all.Total {
+			t.Fatalf("%s: expected the filter to narrow the results, got %d of %d", c.name, res.Total, all.Total)
+		}
+	}
+
+	res, err := s.SearchEmotes("kekw", EmoteSearchOptions{Page: 1, Limit: 10, IgnoreTags: true})
+	testutil.IsNil(t, err, "the search succeeds")
+
+	exact, err := s.SearchEmotes("kekw", EmoteSearchOptions{Page: 1, Limit: 10, IgnoreTags: true, Name: "kekw"})
+	testutil.IsNil(t, err, "the search succeeds")
+
+	if exact.Total != 1 || exact.Total >= res.Total {
+		t.Fatalf("case sensitive name: expected one of %d results, got %d", res.Total, exact.Total)
+	}
+}
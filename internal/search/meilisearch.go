@@ -1,13 +1,29 @@
 package search
 
 import (
+	"strings"
+	"time"
+
 	"github.com/meilisearch/meilisearch-go"
 
 	"github.com/seventv/api/internal/configure"
 )
 
+// EMOTE_SUGGEST_TIMEOUT is the latency budget of an emote suggestion request.
+// Suggestions are requested as the user types, so a late response is worthless
+const EMOTE_SUGGEST_TIMEOUT = time.Millisecond * 250
+
+// PING_TIMEOUT is how long a health check of the search server may take
+const PING_TIMEOUT = time.Second * 5
+
 type MeiliSearch struct {
 	emoteIndex *meilisearch.Index
+	// the emote index, accessed through a client with a short timeout
+	suggestIndex *meilisearch.Index
+	pingClient   *meilisearch.Client
+	// settings which the client does not support are written over HTTP
+	host string
+	key  string
 }
 
 func New(cfg *configure.Config) *MeiliSearch {
@@ -16,7 +32,32 @@ func New(cfg *configure.Config) *MeiliSearch {
 		APIKey: cfg.Meilisearch.Key,
 	})
 
+	suggestClient := meilisearch.NewClient(meilisearch.ClientConfig{
+		Host:    cfg.Meilisearch.Host,
+		APIKey:  cfg.Meilisearch.Key,
+		Timeout: EMOTE_SUGGEST_TIMEOUT,
+	})
+
+	pingClient := meilisearch.NewClient(meilisearch.ClientConfig{
+		Host:    cfg.Meilisearch.Host,
+		APIKey:  cfg.Meilisearch.Key,
+		Timeout: PING_TIMEOUT,
+	})
+
 	index := client.Index(cfg.Meilisearch.Index)
 
-	return &MeiliSearch{index}
+	return &MeiliSearch{
+		emoteIndex:   index,
+		suggestIndex: suggestClient.Index(cfg.Meilisearch.Index),
+		pingClient:   pingClient,
+		host:         strings.TrimSuffix(cfg.Meilisearch.Host, "/"),
+		key:          cfg.Meilisearch.Key,
+	}
+}
+
+// Ping checks that the search server is available
+func (s *MeiliSearch) Ping() error {
+	_, err := s.pingClient.Health()
+
+	return err
 }
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// faceting are the faceting settings of an index, including those which the client does not support
+type faceting struct {
+	MaxValuesPerFacet int64             `json:"maxValuesPerFacet"`
+	SortFacetValuesBy map[string]string `json:"sortFacetValuesBy"`
+}
+
+// configureFaceting updates the faceting settings of the emote index when they differ, and waits for the index to apply them
+func (s *MeiliSearch) configureFaceting(ctx context.Context, want faceting) error {
+	current := faceting{}
+	if err := s.settingsRequest(ctx, http.MethodGet, "faceting", nil, &current); err != nil {
+		return err
+	}
+
+	if current.MaxValuesPerFacet == want.MaxValuesPerFacet && fmt.Sprint(current.SortFacetValuesBy) == fmt.Sprint(want.SortFacetValuesBy) {
+		return nil
+	}
+
+	task := struct {
+		TaskUID int64 `json:"taskUid"`
+	}{}
+	if err := s.settingsRequest(ctx, http.MethodPatch, "faceting", want, &task); err != nil {
+		return err
+	}
+
+	return s.waitForTask(ctx, task.TaskUID)
+}
+
+func (s *MeiliSearch) settingsRequest(ctx context.Context, method string, setting string, body any, result any) error {
+	var payload []byte
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		payload = b
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/indexes/%s/settings/%s", s.host, s.emoteIndex.UID, setting), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if s.key != "" {
+		req.Header.Set("Authorization", "Bearer "+s.key)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("meilisearch, %s settings/%s returned %s", method, setting, res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(result)
+}
@@ -28,7 +28,7 @@ type Authorizer interface {
 	SignJWT(secret string, claim jwt.Claims) (string, error)
 	VerifyJWT(token []string, out jwt.Claims) (*jwt.Token, error)
 	CreateCSRFToken(targetID primitive.ObjectID) (value, token string, err error)
-	CreateAccessToken(targetID primitive.ObjectID, version float64) (string, time.Time, error)
+	CreateAccessToken(targetID primitive.ObjectID, version float64, sessionID primitive.ObjectID) (string, time.Time, error)
 	ValidateCSRF(state string, cookieData string) (*fasthttp.Cookie, *JWTClaimOAuth2CSRF, error)
 	Cookie(key, token string, duration time.Duration) *fasthttp.Cookie
 	QueryValues(provider structures.UserConnectionPlatform, csrfToken string) (url.Values, error)
@@ -37,6 +37,9 @@ type Authorizer interface {
 	DiscordUserData(grant string) (string, []byte, error)
 	UserData(provider structures.UserConnectionPlatform, token string) (id string, b []byte, err error)
 	LocateIP(ctx context.Context, ip string) (GeoIPResult, error)
+	CreateTOTPSecret() (string, error)
+	TOTPURI(issuer, account, secret string) string
+	ValidateTOTP(secret, code string) (int64, bool)
 }
 
 type authorizer struct {
@@ -121,13 +124,14 @@ func (a *authorizer) CreateCSRFToken(targetID primitive.ObjectID) (value, token
 	return value, token, nil
 }
 
-func (a *authorizer) CreateAccessToken(targetID primitive.ObjectID, version float64) (string, time.Time, error) {
+func (a *authorizer) CreateAccessToken(targetID primitive.ObjectID, version float64, sessionID primitive.ObjectID) (string, time.Time, error) {
 	expireAt := time.Now().Add(time.Hour * 24 * 90)
 
 	token, err := a.SignJWT(a.JWTSecret, &JWTClaimUser{
 		UserID:       targetID.Hex(),
 		TokenVersion: version,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID.Hex(), // the session this token belongs to
 			Issuer:    "seventv-api",
 			ExpiresAt: &jwt.NumericDate{Time: expireAt}, // 90 days
 			NotBefore: &jwt.NumericDate{Time: time.Now()},
@@ -0,0 +1,65 @@
+package cleanup
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/common/structures/v3"
+	"go.uber.org/zap"
+)
+
+// New periodically runs maintenance tasks on the database.
+//
+// A lock in redis ensures that only one replica runs the tasks in an interval
+func New(gctx global.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	interval := time.Hour
+	if sec := gctx.Config().ModRequests.Cleanup.Interval; sec > 0 {
+		interval = time.Duration(sec) * time.Second
+	}
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(gctx, interval)
+
+			select {
+			case <-gctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+func run(gctx global.Context, interval time.Duration) {
+	ctx, cancel := context.WithTimeout(gctx, time.Minute*5)
+	defer cancel()
+
+	k := gctx.Inst().Redis.ComposeKey("api", "cleanup", "lock")
+
+	ok, err := gctx.Inst().Redis.RawClient().SetNX(ctx, k.String(), 1, interval-time.Second).Result()
+	if err != nil {
+		zap.S().Warnw("cleanup, failed to acquire lock", "error", err)
+
+		return
+	} else if !ok {
+		return // another replica is running the cleanup
+	}
+
+	if n, err := gctx.Inst().Mutate.DismissVoidTargetModRequests(ctx, structures.ObjectKindEmote); err == nil && n > 0 {
+		zap.S().Infow("cleanup, dismissed void target mod requests", "count", n)
+	}
+
+	if n, err := gctx.Inst().Mutate.ReleaseExpiredModRequestClaims(ctx); err == nil && n > 0 {
+		zap.S().Infow("cleanup, released expired mod request claims", "count", n)
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/structures/v3"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// SetupMongo connects to Mongo in the same way as the common package's mongo.Setup,
+// with a command monitor attached to the client. The monitor can only be set when connecting
+func SetupMongo(ctx context.Context, opt mongo.SetupOptions, monitor *event.CommandMonitor) (mongo.Instance, error) {
+	uri := options.Client().ApplyURI(opt.URI)
+
+	if opt.Username != "" && opt.Password != "" {
+		uri.SetAuth(options.Credential{
+			Username: opt.Username,
+			Password: opt.Password,
+		})
+	}
+
+	readPref := readpref.Primary()
+
+	if opt.HedgedReads {
+		readPref = readpref.PrimaryPreferred(readpref.WithHedgeEnabled(true))
+	}
+
+	if monitor != nil {
+		uri.SetMonitor(monitor)
+	}
+
+	client, err := mongodriver.Connect(ctx, uri.SetDirect(opt.Direct).SetReadPreference(readPref).SetRetryReads(true))
+	if err != nil {
+		return nil, err
+	}
+
+	// Send a Ping
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		return nil, err
+	}
+
+	return &mongoInst{
+		client: client,
+		db:     client.Database(opt.DB),
+		cache:  cache.New(time.Second*10, time.Second*20),
+	}, nil
+}
+
+type mongoInst struct {
+	client *mongodriver.Client
+	db     *mongodriver.Database
+	cache  *cache.Cache
+}
+
+func (i *mongoInst) Collection(name mongo.CollectionName) *mongodriver.Collection {
+	return i.db.Collection(string(name))
+}
+
+func (i *mongoInst) ExternalCollection(db string, name mongo.CollectionName) *mongodriver.Collection {
+	return i.client.Database(db).Collection(string(name))
+}
+
+func (i *mongoInst) Ping(ctx context.Context) error {
+	return i.client.Ping(ctx, nil)
+}
+
+func (i *mongoInst) RawClient() *mongodriver.Client {
+	return i.client
+}
+
+func (i *mongoInst) RawDatabase() *mongodriver.Database {
+	return i.db
+}
+
+func (i *mongoInst) System(ctx context.Context) (structures.System, error) {
+	if v, ok := i.cache.Get("SYSTEM"); ok {
+		return v.(structures.System), nil
+	}
+
+	result := structures.System{}
+	if err := i.Collection(mongo.CollectionNameSystem).FindOne(ctx, bson.M{}).Decode(&result); err != nil {
+		return result, err
+	}
+
+	i.cache.Set("SYSTEM", result, time.Second*30)
+
+	return result, nil
+}
+
+// Monitors combines command monitors, as a client only takes one
+func Monitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package flags
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/seventv/common/structures/v3"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/data/query"
+)
+
+// REFRESH_INTERVAL is how often the flags are reloaded, picking up changes made through other pods
+const REFRESH_INTERVAL = time.Second * 15
+
+// Flags read by the API itself. They are on when they do not exist
+const (
+	FlagPresences   = "presences"
+	FlagEventBridge = "event_bridge"
+)
+
+type Instance interface {
+	// Enabled tells whether a flag is on for a user, which is nil for anonymous requests.
+	// The fallback is returned when the flag does not exist
+	Enabled(key string, user *structures.User, fallback bool) bool
+	// Public returns whether each flag readable by clients is on for a user
+	Public(user *structures.User) map[string]bool
+	// Refresh reloads the flags, so that a change made on this pod applies immediately
+	Refresh(ctx context.Context) error
+}
+
+type flagsInst struct {
+	query *query.Query
+
+	flags map[string]query.FeatureFlag
+	mx    sync.RWMutex
+}
+
+func New(ctx context.Context, q *query.Query) Instance {
+	inst := &flagsInst{
+		query: q,
+		flags: map[string]query.FeatureFlag{},
+	}
+
+	if err := inst.Refresh(ctx); err != nil {
+		zap.S().Warnw("feature flags, failed to load flags", "error", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(REFRESH_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := inst.Refresh(ctx); err != nil {
+				zap.S().Warnw("feature flags, failed to refresh flags", "error", err)
+			}
+		}
+	}()
+
+	return inst
+}
+
+func (inst *flagsInst) Enabled(key string, user *structures.User, fallback bool) bool {
+	inst.mx.RLock()
+	defer inst.mx.RUnlock()
+
+	flag, ok := inst.flags[key]
+	if !ok {
+		return fallback
+	}
+
+	return flag.IsOn(user)
+}
+
+func (inst *flagsInst) Public(user *structures.User) map[string]bool {
+	inst.mx.RLock()
+	defer inst.mx.RUnlock()
+
+	result := map[string]bool{}
+
+	for key, flag := range inst.flags {
+		if flag.Public {
+			result[key] = flag.IsOn(user)
+		}
+	}
+
+	return result
+}
+
+func (inst *flagsInst) Refresh(ctx context.Context) error {
+	list, err := inst.query.FeatureFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	flags := make(map[string]query.FeatureFlag, len(list))
+	for _, f := range list {
+		flags[f.Key] = f
+	}
+
+	inst.mx.Lock()
+	inst.flags = flags
+	inst.mx.Unlock()
+
+	return nil
+}
@@ -1,14 +1,29 @@
 package health
 
 import (
-	"context"
-	"time"
+	"encoding/json"
 
 	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/status"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 )
 
+type response struct {
+	// Whether the pod should receive traffic
+	Ready bool `json:"ready"`
+	// Whether the pod is shutting down
+	Draining bool `json:"draining"`
+	status.Report
+}
+
+// New serves the health of the process:
+//
+// /live only answers that the process is up, and restarting it would not help if a backend is down
+//
+// /ready fails when the process is draining or a critical dependency is down, but not when it is degraded
+//
+// Any other path reports the status of the dependencies, failing when a critical dependency is down
 func New(gctx global.Context) <-chan struct{} {
 	done := make(chan struct{})
 
@@ -22,38 +37,42 @@ func New(gctx global.Context) <-chan struct{} {
 				}
 			}()
 
-			var (
-				mqDown    bool
-				s3Down    bool
-				redisDown bool
-				mongoDown bool
-			)
+			ctx.SetContentType("application/json")
 
-			if gctx.Inst().Redis != nil {
-				lCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-				if err := gctx.Inst().Redis.Ping(lCtx); err != nil {
-					zap.S().Warnw("redis is not responding",
-						"error", err,
-					)
-					redisDown = true
-				}
-				cancel()
+			if string(ctx.Path()) == "/live" {
+				ctx.SetBodyString(`{"state":"ok"}`)
+
+				return
 			}
 
-			if gctx.Inst().Mongo != nil {
-				lCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-				if err := gctx.Inst().Mongo.Ping(lCtx); err != nil {
-					mongoDown = true
-					zap.S().Warnw("mongo is not responding",
-						"error", err,
-					)
+			res := response{
+				Draining: !gctx.Inst().Lifecycle.Ready(),
+			}
+
+			// Readiness fails as soon as the process is draining, so that it stops receiving traffic
+			// while requests in flight are completed
+			if res.Draining && string(ctx.Path()) == "/ready" {
+				ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+			} else {
+				res.Report = gctx.Inst().Status.Report()
+				res.Ready = !res.Draining && res.State != status.StateDown
+
+				if res.State == status.StateDown {
+					ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
 				}
-				cancel()
 			}
 
-			if mqDown || s3Down || redisDown || mongoDown {
-				ctx.SetStatusCode(500)
+			b, err := json.Marshal(res)
+			if err != nil {
+				zap.S().Errorw("failed to encode health report",
+					"error", err,
+				)
+				ctx.SetStatusCode(fasthttp.StatusInternalServerError)
+
+				return
 			}
+
+			ctx.SetBody(b)
 		},
 	}
 
@@ -0,0 +1,121 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Instance coordinates the shutdown of the process, which happens in phases:
+//
+// 1. Draining: the process reports itself as not ready and background listeners stop consuming,
+// while the servers keep serving so that load balancers can stop routing to the pod
+//
+// 2. Stopping: the servers stop accepting connections and finish the requests in flight
+//
+// 3. The global context is canceled once tracked work has completed
+type Instance interface {
+	// Ready returns whether the process should receive traffic
+	Ready() bool
+	// Draining is closed when the process is asked to shut down
+	Draining() <-chan struct{}
+	// Stopping is closed once the drain period has passed, when the servers must shut down
+	Stopping() <-chan struct{}
+	// Track registers a unit of background work, such as a message being handled, which shutdown waits for.
+	// It returns false once draining has begun, in which case the work must not be started
+	Track() (done func(), ok bool)
+	// Drain begins draining
+	Drain()
+	// Stop begins stopping, draining first if it had not begun
+	Stop()
+	// Wait blocks until tracked work has completed or the context is canceled
+	Wait(ctx context.Context) error
+}
+
+type lifecycleInst struct {
+	mx sync.Mutex
+	// 1 once draining has begun
+	drained  int32
+	draining chan struct{}
+	stopping chan struct{}
+	work     sync.WaitGroup
+}
+
+func New() Instance {
+	inst := &lifecycleInst{
+		draining: make(chan struct{}),
+		stopping: make(chan struct{}),
+	}
+
+	return inst
+}
+
+func (inst *lifecycleInst) Ready() bool {
+	return atomic.LoadInt32(&inst.drained) == 0
+}
+
+func (inst *lifecycleInst) Draining() <-chan struct{} {
+	return inst.draining
+}
+
+func (inst *lifecycleInst) Stopping() <-chan struct{} {
+	return inst.stopping
+}
+
+func (inst *lifecycleInst) Track() (func(), bool) {
+	inst.mx.Lock()
+	defer inst.mx.Unlock()
+
+	if !inst.Ready() {
+		return func() {}, false
+	}
+
+	inst.work.Add(1)
+
+	once := sync.Once{}
+
+	return func() {
+		once.Do(inst.work.Done)
+	}, true
+}
+
+func (inst *lifecycleInst) Drain() {
+	inst.mx.Lock()
+	defer inst.mx.Unlock()
+
+	if !inst.Ready() {
+		return
+	}
+
+	atomic.StoreInt32(&inst.drained, 1)
+	close(inst.draining)
+}
+
+func (inst *lifecycleInst) Stop() {
+	inst.Drain()
+
+	inst.mx.Lock()
+	defer inst.mx.Unlock()
+
+	select {
+	case <-inst.stopping:
+	default:
+		close(inst.stopping)
+	}
+}
+
+func (inst *lifecycleInst) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		inst.work.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
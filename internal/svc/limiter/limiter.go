@@ -9,6 +9,7 @@ import (
 
 	"github.com/seventv/api/internal/api/gql/v3/auth"
 	"github.com/seventv/api/internal/constant"
+	"github.com/seventv/api/internal/svc/prometheus"
 	"github.com/seventv/common/redis"
 	"github.com/seventv/common/utils"
 	"go.uber.org/zap"
@@ -25,14 +26,16 @@ type Instance interface {
 
 type limiterInst struct {
 	redis  redis.Instance
+	prom   prometheus.Instance
 	script string
 
 	mx *sync.Mutex
 }
 
-func New(ctx context.Context, rdis redis.Instance) (Instance, error) {
+func New(ctx context.Context, rdis redis.Instance, prom prometheus.Instance) (Instance, error) {
 	l := limiterInst{
 		redis: rdis,
+		prom:  prom,
 		mx:    &sync.Mutex{},
 	}
 
@@ -169,6 +172,8 @@ func (inst *limiterInst) Test(ctx context.Context, bucket string, limit int64, d
 	}
 
 	if rem <= 0 {
+		inst.prom.RateLimited(bucket)
+
 		return false
 	}
 
@@ -0,0 +1,44 @@
+package news
+
+import (
+	"context"
+	"time"
+
+	"github.com/seventv/api/internal/global"
+	"go.uber.org/zap"
+)
+
+// CHECK_INTERVAL is how often scheduled news posts are checked for having gone live
+const CHECK_INTERVAL = time.Second * 30
+
+// New announces news posts to the Event API as they go live
+func New(gctx global.Context) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(CHECK_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-gctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(gctx, CHECK_INTERVAL)
+
+			if n, err := gctx.Inst().Mutate.DispatchLiveNews(ctx); err != nil {
+				zap.S().Warnw("news, failed to announce live posts", "error", err)
+			} else if n > 0 {
+				zap.S().Infow("news, announced live posts", "count", n)
+			}
+
+			cancel()
+		}
+	}()
+
+	return done
+}
@@ -0,0 +1,240 @@
+package persisted
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/seventv/common/redis"
+	"go.uber.org/zap"
+)
+
+// REFRESH_INTERVAL is how often manifests uploaded by other pods are picked up
+const REFRESH_INTERVAL = time.Second * 30
+
+// The format of manifests, as produced by Apollo's persisted query tooling
+const MANIFEST_FORMAT = "apollo-persisted-query-manifest"
+
+type Instance interface {
+	// Lookup returns the operation of the registry with the given hash
+	Lookup(hash string) (Operation, bool)
+	// Clients returns the amount of operations uploaded for each client
+	Clients(ctx context.Context) (map[string]int, error)
+	// Publish replaces the manifest of a client
+	Publish(ctx context.Context, client string, m Manifest) error
+	// Remove deletes the manifest of a client
+	Remove(ctx context.Context, client string) error
+}
+
+// Manifest is a list of operations shipped by a client
+type Manifest struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	Operations []Operation `json:"operations"`
+}
+
+type Operation struct {
+	// The SHA-256 hash of the body, in hex
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// The text of the operation. When it is included, clients may send the hash alone
+	Body string `json:"body,omitempty"`
+}
+
+// Validate checks the manifest's format and that the operations' bodies match their hashes
+func (m Manifest) Validate() error {
+	if m.Format != "" && m.Format != MANIFEST_FORMAT {
+		return fmt.Errorf("unsupported manifest format '%s'", m.Format)
+	}
+
+	for i, op := range m.Operations {
+		if b, err := hex.DecodeString(op.ID); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("operation %d: id is not a sha256 hash", i)
+		}
+
+		if op.Body != "" && Hash(op.Body) != op.ID {
+			return fmt.Errorf("operation %d: id does not match the hash of the body", i)
+		}
+	}
+
+	return nil
+}
+
+// Hash returns the hash of an operation's text, computed as for automatic persisted queries
+func Hash(query string) string {
+	h := sha256.Sum256([]byte(query))
+
+	return hex.EncodeToString(h[:])
+}
+
+type Options struct {
+	// Paths of manifests to load on startup
+	Files []string
+}
+
+type inst struct {
+	redis redis.Instance
+
+	// Operations from manifests on disk
+	files map[string]Operation
+	// Operations from both manifests on disk and those uploaded
+	ops map[string]Operation
+	mx  sync.RWMutex
+}
+
+func New(ctx context.Context, rdis redis.Instance, opt Options) (Instance, error) {
+	p := &inst{
+		redis: rdis,
+		files: map[string]Operation{},
+		ops:   map[string]Operation{},
+	}
+
+	for _, path := range opt.Files {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		m := Manifest{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		if err := m.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		for _, op := range m.Operations {
+			p.files[op.ID] = op
+		}
+	}
+
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		ticker := time.NewTicker(REFRESH_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := p.refresh(ctx); err != nil {
+				zap.S().Warnw("persisted queries, failed to refresh manifests", "error", err)
+			}
+		}
+	}()
+
+	return p, nil
+}
+
+func (p *inst) Lookup(hash string) (Operation, bool) {
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	op, ok := p.ops[hash]
+
+	return op, ok
+}
+
+func (p *inst) Clients(ctx context.Context) (map[string]int, error) {
+	manifests, err := p.manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int, len(manifests))
+	for client, m := range manifests {
+		result[client] = len(m.Operations)
+	}
+
+	return result, nil
+}
+
+func (p *inst) Publish(ctx context.Context, client string, m Manifest) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if err := p.redis.RawClient().HSet(ctx, p.key().String(), client, b).Err(); err != nil {
+		return err
+	}
+
+	return p.refresh(ctx)
+}
+
+func (p *inst) Remove(ctx context.Context, client string) error {
+	if err := p.redis.RawClient().HDel(ctx, p.key().String(), client).Err(); err != nil {
+		return err
+	}
+
+	return p.refresh(ctx)
+}
+
+// refresh rebuilds the registry from the manifests on disk and those uploaded
+func (p *inst) refresh(ctx context.Context) error {
+	manifests, err := p.manifests(ctx)
+	if err != nil {
+		return err
+	}
+
+	ops := make(map[string]Operation, len(p.files))
+	for id, op := range p.files {
+		ops[id] = op
+	}
+
+	for _, m := range manifests {
+		for _, op := range m.Operations {
+			ops[op.ID] = op
+		}
+	}
+
+	p.mx.Lock()
+	p.ops = ops
+	p.mx.Unlock()
+
+	return nil
+}
+
+// manifests returns the uploaded manifests, by client
+func (p *inst) manifests(ctx context.Context) (map[string]Manifest, error) {
+	values, err := p.redis.RawClient().HGetAll(ctx, p.key().String()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Manifest, len(values))
+
+	for client, v := range values {
+		m := Manifest{}
+		if err := json.Unmarshal([]byte(v), &m); err != nil {
+			zap.S().Warnw("persisted queries, invalid manifest", "client", client, "error", err)
+
+			continue
+		}
+
+		result[client] = m
+	}
+
+	return result, nil
+}
+
+func (p *inst) key() redis.Key {
+	return p.redis.ComposeKey("api", "persisted_queries")
+}
@@ -3,6 +3,7 @@ package presences
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-multierror"
@@ -26,6 +27,12 @@ type ChannelPresenceFanoutOptions struct {
 func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFanoutOptions) error {
 	presence := opt.Presence
 
+	// Count the dispatches sent to the sessions of the user
+	evs := &dispatchCounter{Instance: p.events}
+	defer func() {
+		p.prom.ObservePresenceFanout(structures.UserPresenceKindChannel.String(), evs.Count())
+	}()
+
 	eventCond := events.EventCondition{
 		"ctx":      "channel",
 		"platform": string(presence.Data.Platform),
@@ -50,14 +57,14 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 	go func() {
 		// Fetch user in presence
-		user, err = p.loaders.UserByID().Load(presence.UserID)
+		user, err = p.loaders.UserByID().Load(ctx, presence.UserID)
 
 		wg.Done()
 	}()
 
 	go func() {
 		// Fetch user's active cosmetics
-		cosmetics, err = p.loaders.EntitlementsLoader().Load(presence.UserID)
+		cosmetics, err = p.loaders.EntitlementsLoader().Load(ctx, presence.UserID)
 
 		wg.Done()
 	}()
@@ -82,7 +89,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 	dispatchCosmetic := func(cos structures.Cosmetic[bson.Raw]) {
 		// Cosmetic
-		_ = p.events.DispatchWithEffect(events.EventTypeCreateCosmetic, events.ChangeMap{
+		_ = evs.DispatchWithEffect(ctx, events.EventTypeCreateCosmetic, events.ChangeMap{
 			ID:         cos.ID,
 			Kind:       structures.ObjectKindCosmetic,
 			Contextual: true,
@@ -102,7 +109,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 		// Dispatch: Entitlement
 		dispatchFactory = append(dispatchFactory, func() (events.Message[events.DispatchPayload], error) {
-			msg := p.events.DispatchWithEffect(events.EventTypeCreateEntitlement, events.ChangeMap{
+			msg := evs.DispatchWithEffect(ctx, events.EventTypeCreateEntitlement, events.ChangeMap{
 				ID:         ent.ID,
 				Kind:       structures.ObjectKindEntitlement,
 				Contextual: true,
@@ -167,7 +174,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 		}
 
 		// Fetch Emote Sets
-		sets, _ := p.loaders.EmoteSetByID().LoadAll(setIDs)
+		sets, _ := p.loaders.EmoteSetByID().LoadAll(ctx, setIDs)
 
 		for _, es := range sets {
 			if es.ID.IsZero() {
@@ -182,7 +189,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 			}
 
 			// Fetch Emotes
-			emotes, errs := p.loaders.EmoteByID().LoadAll(
+			emotes, errs := p.loaders.EmoteByID().LoadAll(ctx,
 				utils.Map(es.Emotes, func(x structures.ActiveEmote) primitive.ObjectID {
 					return x.ID
 				}),
@@ -237,7 +244,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 			// Dispatch the Emote Set data
 			es.Emotes = make([]structures.ActiveEmote, 0)
-			_ = p.events.DispatchWithEffect(events.EventTypeCreateEmoteSet, events.ChangeMap{
+			_ = evs.DispatchWithEffect(ctx, events.EventTypeCreateEmoteSet, events.ChangeMap{
 				ID:         es.ID,
 				Kind:       structures.ObjectKindEmoteSet,
 				Contextual: true,
@@ -271,7 +278,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 			// Dispatch the Emote Set's Emotes
 			go func(es structures.EmoteSet) {
-				p.events.DispatchWithEffect(events.EventTypeUpdateEmoteSet, events.ChangeMap{
+				evs.DispatchWithEffect(ctx, events.EventTypeUpdateEmoteSet, events.ChangeMap{
 					ID:         es.ID,
 					Kind:       structures.ObjectKindEmoteSet,
 					Contextual: true,
@@ -307,7 +314,7 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 		if !found || lostEntitlementKinds.Has(ent.Kind) {
 			// Entitlement is no longer active, send delete event
-			_ = p.events.DispatchWithEffect(events.EventTypeDeleteEntitlement, events.ChangeMap{
+			_ = evs.DispatchWithEffect(ctx, events.EventTypeDeleteEntitlement, events.ChangeMap{
 				ID:         ent.ID,
 				Kind:       structures.ObjectKindEntitlement,
 				Contextual: true,
@@ -347,3 +354,25 @@ func (p *inst) ChannelPresenceFanout(ctx context.Context, opt ChannelPresenceFan
 
 	return nil
 }
+
+// dispatchCounter counts the events dispatched through it
+type dispatchCounter struct {
+	events.Instance
+	n int32
+}
+
+func (d *dispatchCounter) Dispatch(ctx context.Context, t events.EventType, cm events.ChangeMap, cond ...events.EventCondition) {
+	atomic.AddInt32(&d.n, 1)
+
+	d.Instance.Dispatch(ctx, t, cm, cond...)
+}
+
+func (d *dispatchCounter) DispatchWithEffect(ctx context.Context, t events.EventType, cm events.ChangeMap, opt events.DispatchOptions, cond ...events.EventCondition) events.Message[events.DispatchPayload] {
+	atomic.AddInt32(&d.n, 1)
+
+	return d.Instance.DispatchWithEffect(ctx, t, cm, opt, cond...)
+}
+
+func (d *dispatchCounter) Count() int {
+	return int(atomic.LoadInt32(&d.n))
+}
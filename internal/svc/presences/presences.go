@@ -7,6 +7,7 @@ import (
 	"github.com/seventv/api/data/model"
 	"github.com/seventv/api/internal/configure"
 	"github.com/seventv/api/internal/loaders"
+	"github.com/seventv/api/internal/svc/prometheus"
 	"github.com/seventv/common/mongo"
 	"github.com/seventv/common/structures/v3"
 	"go.mongodb.org/mongo-driver/bson"
@@ -25,6 +26,7 @@ type inst struct {
 	loaders loaders.Instance
 	events  events.Instance
 	config  *configure.Config
+	prom    prometheus.Instance
 
 	modelizer model.Modelizer
 }
@@ -35,22 +37,24 @@ func New(opt Options) Instance {
 		loaders: opt.Loaders,
 		events:  opt.Events,
 		config:  opt.Config,
+		prom:    opt.Prometheus,
 
 		modelizer: opt.Modelizer,
 	}
 }
 
 type Options struct {
-	Mongo   mongo.Instance
-	Loaders loaders.Instance
-	Events  events.Instance
-	Config  *configure.Config
+	Mongo      mongo.Instance
+	Loaders    loaders.Instance
+	Events     events.Instance
+	Config     *configure.Config
+	Prometheus prometheus.Instance
 
 	Modelizer model.Modelizer
 }
 
 func (p *inst) ChannelPresence(ctx context.Context, actorID primitive.ObjectID) PresenceManager[structures.UserPresenceDataChannel] {
-	presences, _ := p.loaders.PresenceByActorID().Load(actorID)
+	presences, _ := p.loaders.PresenceByActorID().Load(ctx, actorID)
 
 	items := filterPresenceList[structures.UserPresenceDataChannel](presences, structures.UserPresenceKindChannel)
 
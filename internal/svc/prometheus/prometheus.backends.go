@@ -0,0 +1,71 @@
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func (m *promInst) MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			m.mongoCommandDuration.WithLabelValues(evt.CommandName, "ok").Observe(time.Duration(evt.DurationNanos).Seconds())
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			m.mongoCommandDuration.WithLabelValues(evt.CommandName, "error").Observe(time.Duration(evt.DurationNanos).Seconds())
+		},
+	}
+}
+
+func (m *promInst) RedisHook() redis.Hook {
+	return redisHook{m}
+}
+
+type redisStartKey struct{}
+
+// redisHook times redis commands. Pipelines are recorded as a single "pipeline" command
+type redisHook struct {
+	m *promInst
+}
+
+func (h redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (h redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	h.observe(ctx, cmd.Name(), cmd.Err())
+
+	return nil
+}
+
+func (h redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, redisStartKey{}, time.Now()), nil
+}
+
+func (h redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+
+			break
+		}
+	}
+
+	h.observe(ctx, "pipeline", err)
+
+	return nil
+}
+
+func (h redisHook) observe(ctx context.Context, name string, err error) {
+	start, ok := ctx.Value(redisStartKey{}).(time.Time)
+	if !ok {
+		return
+	}
+
+	// A missing key is an expected answer rather than a failure
+	h.m.redisCommandDuration.WithLabelValues(name, status(err != nil && err != redis.Nil)).Observe(time.Since(start).Seconds())
+}
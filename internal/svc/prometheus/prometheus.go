@@ -1,11 +1,51 @@
 package prometheus
 
 import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/event"
 )
 
 type Instance interface {
 	Register(r prometheus.Registerer)
+
+	// ObserveRequest records a request served by an HTTP API, on the pattern of its route
+	ObserveRequest(api string, route string, method string, status int, d time.Duration)
+	// ObserveGQLOperation records a GraphQL operation, by name and type
+	ObserveGQLOperation(name string, kind string, failed bool, d time.Duration)
+	// ObserveGQLField records the resolution of a GraphQL field backed by a resolver
+	ObserveGQLField(object string, field string, failed bool, d time.Duration)
+
+	// RateLimited records a request rejected by a rate limit bucket
+	RateLimited(bucket string)
+
+	// ObserveLoaderBatch records a batch fetched by a dataloader and how many of its keys were found
+	ObserveLoaderBatch(loader string, size int, found int)
+	// ObserveLoaderCache records the lookups of a dataloader in its in-memory cache
+	ObserveLoaderCache(loader string, hits int, misses int)
+
+	// MongoMonitor returns a command monitor recording the latency of Mongo commands
+	MongoMonitor() *event.CommandMonitor
+	// RedisHook returns a hook recording the latency of Redis commands
+	RedisHook() redis.Hook
+
+	// ObserveImageResult records a result of the image processor: how long it waited in the
+	// queue, how long processing took, its state and whether the result could be applied
+	ObserveImageResult(kind string, state string, handled bool, lag time.Duration, processing time.Duration)
+
+	// ObservePresenceFanout records a presence fanning out dispatches to the sessions of a user
+	ObservePresenceFanout(kind string, dispatches int)
+	// EventDispatched records an event published to the event API
+	EventDispatched(eventType string)
+	// OriginNotificationQueued records a notification of the sets using an emote set as an origin,
+	// which is dropped when the queue of notifications is full
+	OriginNotificationQueued(dropped bool)
+
+	// ConfigReloaded records a reload of the config file, which failed when the file could not be read or was invalid
+	ConfigReloaded(failed bool)
 }
 
 type Options struct {
@@ -13,11 +53,250 @@ type Options struct {
 }
 
 func New(o Options) Instance {
-	return &promInst{}
+	return &promInst{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_http_requests_total",
+			Help:        "The total number of HTTP requests, by route and status",
+			ConstLabels: o.Labels,
+		}, []string{"api", "route", "method", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_http_request_duration_seconds",
+			Help:        "The time taken to serve HTTP requests, by route",
+			ConstLabels: o.Labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"api", "route", "method"}),
+
+		gqlOperations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_gql_operations_total",
+			Help:        "The total number of GraphQL operations, by name and outcome",
+			ConstLabels: o.Labels,
+		}, []string{"operation", "type", "status"}),
+		gqlOperationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_gql_operation_duration_seconds",
+			Help:        "The time taken to execute GraphQL operations, by name",
+			ConstLabels: o.Labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"operation", "type"}),
+		gqlFields: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_gql_fields_total",
+			Help:        "The total number of resolved GraphQL fields, by outcome",
+			ConstLabels: o.Labels,
+		}, []string{"object", "field", "status"}),
+		gqlFieldDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_gql_field_duration_seconds",
+			Help:        "The time taken to resolve GraphQL fields",
+			ConstLabels: o.Labels,
+			Buckets:     []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"object", "field"}),
+
+		rateLimitRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_rate_limit_rejections_total",
+			Help:        "The total number of requests rejected by a rate limit, by bucket",
+			ConstLabels: o.Labels,
+		}, []string{"bucket"}),
+
+		loaderBatchSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_loader_batch_size",
+			Help:        "The amount of keys in batches fetched by dataloaders",
+			ConstLabels: o.Labels,
+			Buckets:     []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+		}, []string{"loader"}),
+		loaderKeys: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_loader_keys_total",
+			Help:        "The total number of keys fetched by dataloaders, by whether they were found",
+			ConstLabels: o.Labels,
+		}, []string{"loader", "result"}),
+		loaderCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_loader_cache_lookups_total",
+			Help:        "The total number of lookups in the in-memory cache of dataloaders",
+			ConstLabels: o.Labels,
+		}, []string{"loader", "result"}),
+
+		mongoCommandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_mongo_command_duration_seconds",
+			Help:        "The latency of Mongo commands, by command and outcome",
+			ConstLabels: o.Labels,
+			Buckets:     backendBuckets,
+		}, []string{"command", "status"}),
+		redisCommandDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_redis_command_duration_seconds",
+			Help:        "The latency of Redis commands, by command and outcome",
+			ConstLabels: o.Labels,
+			Buckets:     backendBuckets,
+		}, []string{"command", "status"}),
+
+		imageResults: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_image_processor_results_total",
+			Help:        "The total number of results received from the image processor, by state and outcome",
+			ConstLabels: o.Labels,
+		}, []string{"kind", "state", "outcome"}),
+		imageQueueLag: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_image_processor_queue_lag_seconds",
+			Help:        "The time between the image processor finishing a task and its result being received",
+			ConstLabels: o.Labels,
+			Buckets:     []float64{.1, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		}, []string{"kind"}),
+		imageProcessingDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:        "api_image_processor_duration_seconds",
+			Help:        "The time taken by the image processor to process a task",
+			ConstLabels: o.Labels,
+			Buckets:     []float64{.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		}, []string{"kind"}),
+
+		presenceFanouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_presence_fanouts_total",
+			Help:        "The total number of presence fanouts",
+			ConstLabels: o.Labels,
+		}, []string{"kind"}),
+		presenceFanoutDispatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_presence_fanout_dispatches_total",
+			Help:        "The total number of dispatches sent by presence fanouts",
+			ConstLabels: o.Labels,
+		}, []string{"kind"}),
+		eventDispatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_event_dispatches_total",
+			Help:        "The total number of events published to the event API, by type",
+			ConstLabels: o.Labels,
+		}, []string{"type"}),
+		originNotifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_origin_notifications_total",
+			Help:        "The total number of notifications of emote set origin dependents, by whether they were queued or dropped",
+			ConstLabels: o.Labels,
+		}, []string{"status"}),
+
+		configReloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        "api_config_reloads_total",
+			Help:        "The total number of reloads of the config file, by status",
+			ConstLabels: o.Labels,
+		}, []string{"status"}),
+	}
 }
 
+// Buckets for the latency of calls to the databases
+var backendBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5}
+
 type promInst struct {
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+
+	gqlOperations        *prometheus.CounterVec
+	gqlOperationDuration *prometheus.HistogramVec
+	gqlFields            *prometheus.CounterVec
+	gqlFieldDuration     *prometheus.HistogramVec
+
+	rateLimitRejections *prometheus.CounterVec
+
+	loaderBatchSize *prometheus.HistogramVec
+	loaderKeys      *prometheus.CounterVec
+	loaderCache     *prometheus.CounterVec
+
+	mongoCommandDuration *prometheus.HistogramVec
+	redisCommandDuration *prometheus.HistogramVec
+
+	imageResults            *prometheus.CounterVec
+	imageQueueLag           *prometheus.HistogramVec
+	imageProcessingDuration *prometheus.HistogramVec
+
+	presenceFanouts          *prometheus.CounterVec
+	presenceFanoutDispatches *prometheus.CounterVec
+	eventDispatches          *prometheus.CounterVec
+	originNotifications      *prometheus.CounterVec
+
+	configReloads *prometheus.CounterVec
 }
 
 func (m *promInst) Register(r prometheus.Registerer) {
+	r.MustRegister(
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.gqlOperations,
+		m.gqlOperationDuration,
+		m.gqlFields,
+		m.gqlFieldDuration,
+		m.rateLimitRejections,
+		m.loaderBatchSize,
+		m.loaderKeys,
+		m.loaderCache,
+		m.mongoCommandDuration,
+		m.redisCommandDuration,
+		m.imageResults,
+		m.imageQueueLag,
+		m.imageProcessingDuration,
+		m.presenceFanouts,
+		m.presenceFanoutDispatches,
+		m.eventDispatches,
+		m.originNotifications,
+		m.configReloads,
+	)
+}
+
+func (m *promInst) ObserveRequest(api string, route string, method string, status int, d time.Duration) {
+	m.httpRequests.WithLabelValues(api, route, method, strconv.Itoa(status)).Inc()
+	m.httpRequestDuration.WithLabelValues(api, route, method).Observe(d.Seconds())
+}
+
+func (m *promInst) ObserveGQLOperation(name string, kind string, failed bool, d time.Duration) {
+	m.gqlOperations.WithLabelValues(name, kind, status(failed)).Inc()
+	m.gqlOperationDuration.WithLabelValues(name, kind).Observe(d.Seconds())
+}
+
+func (m *promInst) ObserveGQLField(object string, field string, failed bool, d time.Duration) {
+	m.gqlFields.WithLabelValues(object, field, status(failed)).Inc()
+	m.gqlFieldDuration.WithLabelValues(object, field).Observe(d.Seconds())
+}
+
+func (m *promInst) RateLimited(bucket string) {
+	m.rateLimitRejections.WithLabelValues(bucket).Inc()
+}
+
+func (m *promInst) ObserveLoaderBatch(loader string, size int, found int) {
+	m.loaderBatchSize.WithLabelValues(loader).Observe(float64(size))
+	m.loaderKeys.WithLabelValues(loader, "hit").Add(float64(found))
+	m.loaderKeys.WithLabelValues(loader, "miss").Add(float64(size - found))
+}
+
+func (m *promInst) ObserveLoaderCache(loader string, hits int, misses int) {
+	m.loaderCache.WithLabelValues(loader, "hit").Add(float64(hits))
+	m.loaderCache.WithLabelValues(loader, "miss").Add(float64(misses))
+}
+
+func (m *promInst) ObserveImageResult(kind string, state string, handled bool, lag time.Duration, processing time.Duration) {
+	outcome := "applied"
+	if !handled {
+		outcome = "error"
+	}
+
+	m.imageResults.WithLabelValues(kind, state, outcome).Inc()
+	m.imageQueueLag.WithLabelValues(kind).Observe(lag.Seconds())
+	m.imageProcessingDuration.WithLabelValues(kind).Observe(processing.Seconds())
+}
+
+func (m *promInst) ObservePresenceFanout(kind string, dispatches int) {
+	m.presenceFanouts.WithLabelValues(kind).Inc()
+	m.presenceFanoutDispatches.WithLabelValues(kind).Add(float64(dispatches))
+}
+
+func (m *promInst) EventDispatched(eventType string) {
+	m.eventDispatches.WithLabelValues(eventType).Inc()
+}
+
+func (m *promInst) OriginNotificationQueued(dropped bool) {
+	outcome := "queued"
+	if dropped {
+		outcome = "dropped"
+	}
+
+	m.originNotifications.WithLabelValues(outcome).Inc()
+}
+
+func (m *promInst) ConfigReloaded(failed bool) {
+	m.configReloads.WithLabelValues(status(failed)).Inc()
+}
+
+func status(failed bool) string {
+	if failed {
+		return "error"
+	}
+
+	return "ok"
 }
@@ -0,0 +1,52 @@
+package reload
+
+import (
+	"github.com/seventv/api/internal/configure"
+	"github.com/seventv/api/internal/global"
+	"go.uber.org/zap"
+)
+
+// New watches the config file, applying the settings which are safe to change while the API is running
+// when it changes. See configure.Config.Reload for which settings these are
+func New(gctx global.Context) error {
+	return configure.Watch(gctx, gctx.Config().ConfigFile, func() {
+		Apply(gctx)
+	})
+}
+
+// Apply reloads the config file and replaces the config of the API, which requests read from then on.
+// When the file cannot be read or is invalid, the current config is kept
+func Apply(gctx global.Context) bool {
+	current := gctx.Config()
+
+	next, changed, err := current.Reload()
+	if err != nil {
+		gctx.Inst().Prometheus.ConfigReloaded(true)
+
+		zap.S().Errorw("config, failed to reload, keeping the current config",
+			"file", current.ConfigFile,
+			"error", err,
+		)
+
+		return false
+	}
+
+	gctx.Inst().Prometheus.ConfigReloaded(false)
+
+	if len(changed) == 0 {
+		zap.S().Infow("config, reloaded without changes",
+			"file", current.ConfigFile,
+		)
+
+		return true
+	}
+
+	global.SetConfig(gctx, next)
+
+	zap.S().Infow("config, reloaded",
+		"file", current.ConfigFile,
+		"changed", changed,
+	)
+
+	return true
+}
@@ -0,0 +1,76 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/seventv/api/internal/configure"
+	"github.com/seventv/api/internal/global"
+	"github.com/seventv/api/internal/svc/prometheus"
+	"github.com/seventv/api/internal/testutil"
+)
+
+// reloadCounter counts the reloads recorded by Apply
+type reloadCounter struct {
+	prometheus.Instance
+	succeeded int
+	failed    int
+}
+
+func (p *reloadCounter) ConfigReloaded(failed bool) {
+	if failed {
+		p.failed++
+	} else {
+		p.succeeded++
+	}
+}
+
+func TestApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	if pflag.Lookup("config") == nil {
+		pflag.String("config", "config.yaml", "Config file location")
+	}
+
+	testutil.IsNil(t, pflag.Set("config", path), "the config flag is set")
+
+	write := func(content string) {
+		testutil.IsNil(t, os.WriteFile(path, []byte(content), 0o600), "the config file is written")
+	}
+
+	initial := &configure.Config{ConfigFile: path}
+	initial.Limits.MaxPage = 100
+
+	gctx := global.New(context.Background(), initial)
+	prom := &reloadCounter{}
+	gctx.Inst().Prometheus = prom
+
+	// An invalid file is rejected, and the API keeps the config it had
+	write("limits:\n  max_page: -1\n")
+
+	testutil.Assert(t, false, Apply(gctx), "an invalid file is rejected")
+	testutil.Assert(t, true, gctx.Config() == initial, "the previous config stays in place")
+	testutil.Assert(t, 100, gctx.Config().Limits.MaxPage, "the previous settings stay in place")
+	testutil.Assert(t, 1, prom.failed, "the failed reload is recorded")
+
+	// A valid file replaces the config
+	write("limits:\n  max_page: 50\n")
+
+	testutil.Assert(t, true, Apply(gctx), "a valid file is applied")
+	testutil.Assert(t, 50, gctx.Config().Limits.MaxPage, "the new settings are in place")
+	testutil.Assert(t, 100, initial.Limits.MaxPage, "the previous config is not modified")
+	testutil.Assert(t, 1, prom.succeeded, "the reload is recorded")
+
+	// A later invalid file keeps the config of the last valid reload
+	reloaded := gctx.Config()
+
+	write("limits: [\n")
+
+	testutil.Assert(t, false, Apply(gctx), "a malformed file is rejected")
+	testutil.Assert(t, true, gctx.Config() == reloaded, "the last valid config stays in place")
+	testutil.Assert(t, 2, prom.failed, "the failed reload is recorded")
+}
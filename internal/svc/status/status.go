@@ -0,0 +1,251 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nats-io/nats.go"
+	"github.com/seventv/common/mongo"
+	"github.com/seventv/common/redis"
+	messagequeue "github.com/seventv/message-queue/go"
+	"go.uber.org/zap"
+
+	"github.com/seventv/api/internal/search"
+)
+
+// CACHE_TTL is for how long a report is reused, so that probes and clients polling the status don't flood the backends
+const CACHE_TTL = time.Second * 5
+
+// CHECK_TIMEOUT is how long a dependency may take to respond before it is considered down
+const CHECK_TIMEOUT = time.Second * 5
+
+type State string
+
+const (
+	// All dependencies are available
+	StateOK State = "ok"
+	// An optional dependency is unavailable, and the features relying on it are not working
+	StateDegraded State = "degraded"
+	// A dependency the API cannot serve requests without is unavailable
+	StateDown State = "down"
+)
+
+// Names of the dependencies
+const (
+	DependencyMongo        = "mongo"
+	DependencyRedis        = "redis"
+	DependencyNats         = "nats"
+	DependencyMessageQueue = "message_queue"
+	DependencyS3           = "s3"
+	DependencyMeilisearch  = "meilisearch"
+)
+
+type Instance interface {
+	// Report returns the status of the dependencies, checking them again if the last report is stale
+	Report() Report
+}
+
+type Report struct {
+	State        State        `json:"state"`
+	CheckedAt    time.Time    `json:"checked_at"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+type Dependency struct {
+	Name  string `json:"name"`
+	State State  `json:"state"`
+	// Whether the API cannot serve requests without this dependency
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Options are the clients of the dependencies. Those which failed to set up are nil, and reported as down
+type Options struct {
+	Mongo        mongo.Instance
+	Redis        redis.Instance
+	Nats         *nats.Conn
+	MessageQueue messagequeue.Instance
+	S3           *S3Options
+	Meilisearch  *search.MeiliSearch
+}
+
+// S3Options are those of the S3 client. S3 is checked through the bucket uploads are written to,
+// which requires no permission over the other buckets of the account
+type S3Options struct {
+	Region      string
+	Endpoint    string
+	AccessToken string
+	SecretKey   string
+	Bucket      string
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       func(ctx context.Context) error
+}
+
+type statusInst struct {
+	checks []check
+
+	mx   sync.Mutex
+	last Report
+}
+
+var errNotConnected = fmt.Errorf("not connected")
+
+func New(opt Options) Instance {
+	var bucketClient *s3.S3
+
+	if opt.S3 != nil && opt.S3.Bucket != "" {
+		sess, err := session.NewSession(&aws.Config{
+			Credentials:      credentials.NewStaticCredentials(opt.S3.AccessToken, opt.S3.SecretKey, ""),
+			Region:           aws.String(opt.S3.Region),
+			S3ForcePathStyle: aws.Bool(true),
+			Endpoint:         aws.String(opt.S3.Endpoint),
+		})
+		if err != nil {
+			zap.S().Warnw("failed to setup s3 status check", "error", err)
+		} else {
+			bucketClient = s3.New(sess)
+		}
+	}
+
+	checks := []check{
+		{DependencyMongo, true, func(ctx context.Context) error {
+			if opt.Mongo == nil {
+				return errNotConnected
+			}
+
+			return opt.Mongo.Ping(ctx)
+		}},
+		{DependencyRedis, true, func(ctx context.Context) error {
+			if opt.Redis == nil {
+				return errNotConnected
+			}
+
+			return opt.Redis.Ping(ctx)
+		}},
+		{DependencyNats, false, func(ctx context.Context) error {
+			if opt.Nats == nil {
+				return errNotConnected
+			}
+
+			if st := opt.Nats.Status(); st != nats.CONNECTED {
+				return fmt.Errorf("connection is %s", st)
+			}
+
+			return opt.Nats.FlushWithContext(ctx)
+		}},
+		{DependencyMessageQueue, false, func(ctx context.Context) error {
+			if opt.MessageQueue == nil {
+				return errNotConnected
+			}
+
+			if !opt.MessageQueue.Connected(ctx) {
+				if err := opt.MessageQueue.Error(); err != nil {
+					return err
+				}
+
+				return errNotConnected
+			}
+
+			return nil
+		}},
+		{DependencyS3, false, func(ctx context.Context) error {
+			if bucketClient == nil {
+				return errNotConnected
+			}
+
+			_, err := bucketClient.HeadBucketWithContext(ctx, &s3.HeadBucketInput{
+				Bucket: aws.String(opt.S3.Bucket),
+			})
+
+			return err
+		}},
+		{DependencyMeilisearch, false, func(ctx context.Context) error {
+			if opt.Meilisearch == nil {
+				return errNotConnected
+			}
+
+			return opt.Meilisearch.Ping()
+		}},
+	}
+
+	return &statusInst{
+		checks: checks,
+	}
+}
+
+func (inst *statusInst) Report() Report {
+	inst.mx.Lock()
+	defer inst.mx.Unlock()
+
+	if time.Since(inst.last.CheckedAt) < CACHE_TTL {
+		return inst.last
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CHECK_TIMEOUT)
+	defer cancel()
+
+	report := Report{
+		State:        StateOK,
+		Dependencies: make([]Dependency, len(inst.checks)),
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(inst.checks))
+
+	for i, c := range inst.checks {
+		go func(i int, c check) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := c.fn(ctx)
+
+			dep := Dependency{
+				Name:      c.name,
+				State:     StateOK,
+				Critical:  c.critical,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+
+			if err != nil {
+				dep.State = StateDown
+				dep.Error = err.Error()
+
+				zap.S().Warnw(c.name+" is not responding",
+					"error", err,
+				)
+			}
+
+			report.Dependencies[i] = dep
+		}(i, c)
+	}
+
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		if dep.State == StateOK {
+			continue
+		}
+
+		if dep.Critical {
+			report.State = StateDown
+		} else if report.State == StateOK {
+			report.State = StateDegraded
+		}
+	}
+
+	report.CheckedAt = time.Now()
+	inst.last = report
+
+	return report
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+	messagequeue "github.com/seventv/message-queue/go"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MongoMonitor returns a command monitor adding a span for Mongo commands made within a trace
+func MongoMonitor() *event.CommandMonitor {
+	spans := sync.Map{}
+
+	end := func(requestID int64, err error) {
+		if span, ok := spans.LoadAndDelete(requestID); ok {
+			End(span.(trace.Span), err)
+		}
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			attrs := []trace.SpanStartOption{
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBName(evt.DatabaseName),
+					semconv.DBOperation(evt.CommandName),
+				),
+			}
+
+			// The collection is the value of the command's first field
+			if col, ok := evt.Command.Lookup(evt.CommandName).StringValueOK(); ok {
+				attrs = append(attrs, trace.WithAttributes(semconv.DBMongoDBCollection(col)))
+			}
+
+			_, span := StartChild(ctx, "mongo "+evt.CommandName, attrs...)
+			if !span.IsRecording() {
+				return
+			}
+
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			end(evt.RequestID, nil)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			end(evt.RequestID, failure(evt.Failure))
+		},
+	}
+}
+
+type failure string
+
+func (f failure) Error() string {
+	return string(f)
+}
+
+// RedisHook returns a hook adding a span for Redis commands made within a trace
+func RedisHook() redis.Hook {
+	return redisHook{}
+}
+
+type redisHook struct{}
+
+func (redisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = StartChild(ctx, "redis "+cmd.Name(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperation(cmd.Name())),
+	)
+
+	return ctx, nil
+}
+
+func (redisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endRedis(ctx, cmd.Err())
+
+	return nil
+}
+
+func (redisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, _ = StartChild(ctx, "redis pipeline",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis),
+	)
+
+	return ctx, nil
+}
+
+func (redisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+
+			break
+		}
+	}
+
+	endRedis(ctx, err)
+
+	return nil
+}
+
+func endRedis(ctx context.Context, err error) {
+	// A missing key is an expected answer rather than a failure
+	if err == redis.Nil {
+		err = nil
+	}
+
+	End(trace.SpanFromContext(ctx), err)
+}
+
+// MessageQueue wraps a message queue to add a span for publishes made within a trace.
+// The trace context is passed on in the headers of the message
+func MessageQueue(mq messagequeue.Instance) messagequeue.Instance {
+	return tracedQueue{mq}
+}
+
+type tracedQueue struct {
+	messagequeue.Instance
+}
+
+func (q tracedQueue) Publish(ctx context.Context, msg messagequeue.OutgoingMessage) error {
+	ctx, span := StartChild(ctx, "publish "+msg.Queue,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(semconv.MessagingDestinationName(msg.Queue)),
+	)
+
+	if span.IsRecording() {
+		headers := make(messagequeue.MessageHeaders, len(msg.Headers)+1)
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+
+		otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+
+		msg.Headers = headers
+	}
+
+	err := q.Instance.Publish(ctx, msg)
+
+	End(span, err)
+
+	return err
+}
@@ -0,0 +1,211 @@
+package tracing
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TRACER_NAME = "github.com/seventv/api"
+
+// The fraction of traces which are sampled, unless configured otherwise
+const DEFAULT_SAMPLE_RATE = 0.01
+
+// The most spans a span can be linked to, see Links
+const MAX_LINKS = 32
+
+type Options struct {
+	// The base URL of an OTLP/HTTP collector. Without one, spans are not recorded
+	Endpoint string
+	Headers  map[string]string
+	// The fraction of traces which are sampled. Defaults to DEFAULT_SAMPLE_RATE
+	SampleRate float64
+
+	ServiceName    string
+	ServiceVersion string
+	InstanceID     string
+}
+
+// Setup installs the tracer provider used by the rest of the package. Unless an endpoint is given,
+// the no-op provider of the otel package stays in place and spans cost next to nothing.
+//
+// The returned function flushes the spans which have not been exported yet
+func Setup(ctx context.Context, opt Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if opt.Endpoint == "" {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opt.ServiceName),
+		semconv.ServiceVersion(opt.ServiceVersion),
+		semconv.ServiceInstanceID(opt.InstanceID),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(opt.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	exporterOpts := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(u.Host),
+		otlptracehttp.WithURLPath(strings.TrimSuffix(u.Path, "/") + "/v1/traces"),
+		otlptracehttp.WithHeaders(opt.Headers),
+		otlptracehttp.WithTimeout(time.Second * 10),
+	}
+
+	if u.Scheme == "http" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.SampleRate <= 0 {
+		opt.SampleRate = DEFAULT_SAMPLE_RATE
+	}
+
+	// Incoming requests don't continue the trace of their caller (see StartRequest),
+	// so whether a trace is sampled is only decided by its root span
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opt.SampleRate))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// Start starts a span, child of the span of the context or of the request the context belongs to
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(withParent(ctx), name, opts...)
+}
+
+// StartChild starts a span only if the context is part of a trace, so that calls made
+// outside of a request don't each start a trace of their own
+func StartChild(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx = withParent(ctx)
+
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// Detach returns a context carrying the span of ctx, but not its deadline or cancellation,
+// for work which outlives the request it was started by
+func Detach(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(withParent(ctx)))
+}
+
+// Links gathers the spans of the callers of a batched operation. The span of the batch is a child
+// of the caller's span when there was one caller, and is linked to each of them otherwise
+type Links struct {
+	mx    sync.Mutex
+	spans []trace.SpanContext
+}
+
+// Add records the span of a caller, if the context has one
+func (l *Links) Add(ctx context.Context) {
+	sc := trace.SpanContextFromContext(withParent(ctx))
+	if !sc.IsValid() {
+		return
+	}
+
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if len(l.spans) < MAX_LINKS {
+		l.spans = append(l.spans, sc)
+	}
+}
+
+// Start starts the span of a batch, with the callers recorded since the previous batch.
+// The context should not carry a span of its own
+func (l *Links) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	l.mx.Lock()
+	spans := l.spans
+	l.spans = nil
+	l.mx.Unlock()
+
+	switch {
+	case len(spans) == 0:
+	case sameTrace(spans):
+		ctx = trace.ContextWithSpanContext(ctx, spans[0])
+	default:
+		links := make([]trace.Link, len(spans))
+		for i, sc := range spans {
+			links[i] = trace.Link{SpanContext: sc}
+		}
+
+		opts = append(opts, trace.WithLinks(links...))
+	}
+
+	return Tracer().Start(ctx, name, opts...)
+}
+
+func sameTrace(spans []trace.SpanContext) bool {
+	for _, sc := range spans[1:] {
+		if sc.TraceID() != spans[0].TraceID() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// End ends a span, marking it as failed if there was an error
+func End(span trace.Span, err error, attrs ...attribute.KeyValue) {
+	span.SetAttributes(attrs...)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// withParent looks up the span of the request when the context has none of its own.
+// Request contexts of fasthttp can't hold otel's context values, so their span is kept as a user value
+func withParent(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	if span, ok := ctx.Value(requestSpanKey{}).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+
+	return ctx
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestSpanKey struct{}
+
+// StartRequest starts the span of an incoming request. Requests come from untrusted clients, which
+// could otherwise force their traces to be sampled, so each request starts a trace of its own.
+// When the caller sent W3C trace context headers, the span is linked to the caller's span
+func StartRequest(ctx *fasthttp.RequestCtx, name string) trace.Span {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPMethod(string(ctx.Method())),
+			semconv.HTTPTarget(string(ctx.Path())),
+		),
+	}
+
+	remote := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(), headerCarrier{&ctx.Request.Header}))
+	if remote.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: remote}))
+	}
+
+	_, span := Tracer().Start(context.Background(), name, opts...)
+
+	ctx.SetUserValue(requestSpanKey{}, span)
+
+	return span
+}
+
+// EndRequest ends the span of a request once it was served. The route is the pattern of the path which matched, if any
+func EndRequest(ctx *fasthttp.RequestCtx, span trace.Span, route string) {
+	status := ctx.Response.StatusCode()
+
+	if route != "" {
+		span.SetName(string(ctx.Method()) + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCode(status))
+
+	if status >= 500 {
+		span.SetStatus(codes.Error, "")
+	}
+
+	span.End()
+}
+
+// WithRequest returns a context carrying the span of a request, for handlers which
+// don't use the request as their context
+func WithRequest(ctx context.Context, req *fasthttp.RequestCtx) context.Context {
+	if span, ok := req.UserValue(requestSpanKey{}).(trace.Span); ok {
+		return trace.ContextWithSpan(ctx, span)
+	}
+
+	return ctx
+}
+
+// headerCarrier reads and writes trace context in the headers of a fasthttp request
+type headerCarrier struct {
+	h *fasthttp.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string {
+	return string(c.h.Peek(key))
+}
+
+func (c headerCarrier) Set(key string, value string) {
+	c.h.Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := []string{}
+
+	c.h.VisitAll(func(key, value []byte) {
+		keys = append(keys, string(key))
+	})
+
+	return keys
+}
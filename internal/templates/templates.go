@@ -0,0 +1,162 @@
+package templates
+
+import (
+	"embed"
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed locales/*.json
+var files embed.FS
+
+// DefaultLocale is used when a key is missing in the requested locale
+const DefaultLocale = "en"
+
+var placeholderRegex = regexp.MustCompile(`{([A-Z0-9_]+)}`)
+
+// Registry holds the localized strings of templates, such as the subject and content of inbox messages.
+//
+// Strings are loaded from a flat JSON file per locale, mapping keys to text with {PLACEHOLDER} variables
+type Registry struct {
+	locales map[string]map[string]string
+}
+
+func New() (*Registry, error) {
+	entries, err := files.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{
+		locales: make(map[string]map[string]string, len(entries)),
+	}
+
+	for _, e := range entries {
+		b, err := files.ReadFile(path.Join("locales", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := map[string]string{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, err
+		}
+
+		r.locales[normalizeLocale(strings.TrimSuffix(e.Name(), ".json"))] = m
+	}
+
+	return r, nil
+}
+
+// Locales returns the available locales
+func (r *Registry) Locales() []string {
+	result := make([]string, 0, len(r.locales))
+
+	for l := range r.locales {
+		result = append(result, l)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Resolve returns the available locale which best matches the requested one,
+// i.e "pt-BR" falls back to "pt", then to the default locale
+func (r *Registry) Resolve(locale string) string {
+	locale = normalizeLocale(locale)
+
+	if _, ok := r.locales[locale]; ok {
+		return locale
+	}
+
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		if _, ok := r.locales[locale[:i]]; ok {
+			return locale[:i]
+		}
+	}
+
+	return DefaultLocale
+}
+
+// Has tells whether a key is defined
+func (r *Registry) Has(key string) bool {
+	_, ok := r.locales[DefaultLocale][key]
+
+	return ok
+}
+
+// Keys returns the keys starting with a prefix
+func (r *Registry) Keys(prefix string) []string {
+	result := []string{}
+
+	for k := range r.locales[DefaultLocale] {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, k)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Translate returns the text of a key in a locale, falling back to the default locale
+func (r *Registry) Translate(locale, key string) (string, bool) {
+	if s, ok := r.locales[r.Resolve(locale)][key]; ok {
+		return s, true
+	}
+
+	s, ok := r.locales[DefaultLocale][key]
+
+	return s, ok
+}
+
+// Render translates a text if it is a key, and fills in its placeholders.
+// Placeholder values which are keys themselves are translated too, and unknown placeholders are kept as they are
+func (r *Registry) Render(locale, text string, placeholders map[string]string) string {
+	if s, ok := r.Translate(locale, text); ok {
+		text = s
+	}
+
+	return placeholderRegex.ReplaceAllStringFunc(text, func(m string) string {
+		v, ok := placeholders[m[1:len(m)-1]]
+		if !ok {
+			return m
+		}
+
+		if s, ok := r.Translate(locale, v); ok {
+			return s
+		}
+
+		return v
+	})
+}
+
+// Placeholders returns the names of the placeholders used by a key or text
+func (r *Registry) Placeholders(text string) []string {
+	if s, ok := r.Translate(DefaultLocale, text); ok {
+		text = s
+	}
+
+	result := []string{}
+	seen := map[string]bool{}
+
+	for _, m := range placeholderRegex.FindAllStringSubmatch(text, -1) {
+		if seen[m[1]] {
+			continue
+		}
+
+		seen[m[1]] = true
+		result = append(result, m[1])
+	}
+
+	return result
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
@@ -91,6 +91,9 @@ resource "kubernetes_deployment" "app" {
       }
 
       spec {
+        // Leaves time for the drain period and the requests in flight, see shutdown in the config
+        termination_grace_period_seconds = 60
+
         container {
           name  = "api"
           image = local.image_url
@@ -166,7 +169,7 @@ resource "kubernetes_deployment" "app" {
           liveness_probe {
             http_get {
               port = "health"
-              path = "/"
+              path = "/live"
             }
             initial_delay_seconds = 10
             timeout_seconds       = 5
@@ -178,7 +181,7 @@ resource "kubernetes_deployment" "app" {
           readiness_probe {
             http_get {
               port = "health"
-              path = "/"
+              path = "/ready"
             }
             initial_delay_seconds = 10
             timeout_seconds       = 5